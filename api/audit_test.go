@@ -0,0 +1,80 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/pkg/audit"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// TestAuditLog fails if the resources handlers don't save the audited resources
+func TestAuditLog(t *testing.T) {
+	store := audit.NewMemoryStore(10)
+	app := fiber.New()
+	MapUrl(app, Dependencies{
+		K8sClient: fake.NewClientService(),
+		Clientset: fake.NewClientsetService(),
+		RPCClient: http.DefaultClient,
+		Auditor:   audit.NewAuditor(store, store),
+	})
+
+	resp, _ := handlertest.Request(t, app, http.MethodPost, "/api/v1/core/secrets", map[string]interface{}{
+		"name":      "my-secret",
+		"namespace": "default",
+		"type":      "password",
+		"data":      map[string]string{"password": "s3cr3t"},
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, _ = handlertest.Request(t, app, http.MethodPost, "/api/v1/ethereum/nodes", map[string]interface{}{
+		"name":    "my-node",
+		"network": "goerli",
+		"client":  "besu",
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	req := handlertest.NewRequest(t, http.MethodPatch, "/api/v1/ethereum/nodes/my-node", map[string]interface{}{
+		"logging": "debug",
+		"rpc":     false,
+	})
+	req.Header.Set(fiber.HeaderContentType, "application/merge-patch+json")
+	resp, _ = handlertest.Send(t, app, req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = handlertest.Request(t, app, http.MethodDelete, "/api/v1/ethereum/nodes/my-node?dryRun=true", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/api/v1/audit", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	entries := []audit.Entry{}
+	handlertest.Decode(t, body, &entries)
+	if !assert.Len(t, entries, 4) {
+		return
+	}
+
+	// entries are sent newest first
+	created := entries[3]
+	assert.Equal(t, "secrets", created.Resource)
+	assert.Equal(t, "create", created.Verb)
+	assert.Equal(t, "my-secret", created.Name)
+	assert.Equal(t, "default", created.Namespace)
+	assert.Contains(t, created.Diff, audit.Change{Field: "type", After: "password"})
+	assert.NotContains(t, string(body.Data), "s3cr3t")
+
+	patched := entries[1]
+	assert.Equal(t, "nodes", patched.Resource)
+	assert.Equal(t, "update", patched.Verb)
+	assert.Equal(t, "my-node", patched.Name)
+	assert.Contains(t, patched.Diff, audit.Change{Field: "logging", After: "debug"})
+	// the dto before the call isn't changed by the call
+	assert.Contains(t, patched.Diff, audit.Change{Field: "rpc", Before: true})
+
+	deleted := entries[0]
+	assert.Equal(t, "delete", deleted.Verb)
+	assert.True(t, deleted.DryRun)
+	assert.Equal(t, http.StatusNoContent, deleted.Status)
+	assert.NotEmpty(t, deleted.Diff)
+}
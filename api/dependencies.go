@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/kotalco/api/pkg/audit"
+	"github.com/kotalco/api/pkg/certs"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/server"
+	"github.com/kotalco/api/pkg/simulator"
+	"log"
+	"net/http"
+)
+
+// Dependencies are the k8s clients and the nodes JSON-RPC http client used by the api services and handlers
+// tests can replace them with in memory clients, see k8s fake package
+// Auditor records the mutating calls, they aren't audited if it's nil
+// Certificates serves the api TLS certificate, it's nil if the api is served over plain http
+// Drainer closes the websockets and watches on shutdown, they aren't closed if it's nil
+type Dependencies struct {
+	K8sClient    k8s.K8sClientServiceInterface
+	Clientset    k8s.ClientsetServiceInterface
+	RPCClient    *http.Client
+	Auditor      *audit.Auditor
+	Certificates *certs.Reloader
+	Drainer      *server.Drainer
+}
+
+// NewDependencies returns dependencies backed by the registered k8s clusters
+// or by the simulator if it's enabled using SIMULATOR=true
+// no k8s call is made until the first api call
+func NewDependencies() Dependencies {
+	if simulator.Enabled() {
+		log.Println("serving k8s calls using the simulator ...")
+		k8s.UseClusters(k8s.DefaultClusterName)
+		sim := simulator.New()
+		return Dependencies{
+			K8sClient: sim.K8sClient(),
+			Clientset: sim.ClientsetService(),
+			RPCClient: rpcClient(sim.RPCClient().Transport),
+			Drainer:   server.NewDrainer(),
+		}
+	}
+
+	return Dependencies{
+		K8sClient: k8s.NewClientService(),
+		Clientset: k8s.NewClientsetService(),
+		RPCClient: rpcClient(http.DefaultTransport),
+		Drainer:   server.NewDrainer(),
+	}
+}
+
+// rpcClient returns the nodes JSON-RPC http client, every call has stats.rpcTimeout deadline
+// so unresponsive node doesn't hang its stats websocket
+func rpcClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{Transport: transport, Timeout: configs.Get().Stats.RPCTimeout.Duration}
+}
@@ -0,0 +1,70 @@
+// Package audit handler is the representation layer for the audit log of the mutating api calls
+package audit
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/pkg/audit"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/query"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+const (
+	namespaceKeyword = "namespace"
+	sinceKeyword     = "since"
+)
+
+// Handler serves audit log api calls
+type Handler struct {
+	store audit.Store
+}
+
+// NewHandler returns audit log handler serving the entries of the given store
+func NewHandler(store audit.Store) *Handler {
+	return &Handler{store: store}
+}
+
+// List returns the audit entries newest first
+// 1-parse the filters like principal=alice, resource=nodes or status=403 and pagination qs
+// 2-read the entries from the store and keep the ones of the namespace and since qs if they're sent
+// 3-filter the entries and send the requested page
+func (handler *Handler) List(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, audit.Entry{})
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	var since time.Time
+	if value := c.Query(sinceKeyword); value != "" {
+		parsed, parseErr := time.Parse(time.RFC3339, value)
+		if parseErr != nil {
+			badReq := restErrors.NewBadRequestError(fmt.Sprintf("%s must be RFC3339 timestamp", sinceKeyword))
+			return restErrors.Send(c, badReq)
+		}
+		since = parsed
+	}
+
+	entries, storeErr := handler.store.Entries()
+	if storeErr != nil {
+		return restErrors.Send(c, restErrors.NewInternalServerError(storeErr.Error()))
+	}
+
+	namespace := c.Query(namespaceKeyword)
+	dtos := make([]audit.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if namespace != "" && entry.Namespace != namespace {
+			continue
+		}
+		if entry.Time.Before(since) {
+			continue
+		}
+		dtos = append(dtos, entry)
+	}
+
+	listQuery.Apply(&dtos)
+
+	return sharedHandlers.SendList(c, listQuery, dtos, metav1.ListMeta{})
+}
@@ -0,0 +1,59 @@
+package audit
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/pkg/audit"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestApp returns app serving the audit log of the given entries, oldest entry first
+func newTestApp(t *testing.T, entries ...audit.Entry) *fiber.App {
+	store := audit.NewMemoryStore(len(entries))
+	for i := range entries {
+		assert.Nil(t, store.Write(context.Background(), &entries[i], nil))
+	}
+
+	app := fiber.New()
+	app.Get("/audit", NewHandler(store).List)
+	return app
+}
+
+func listNames(t *testing.T, app *fiber.App, target string) []string {
+	resp, body := handlertest.Request(t, app, http.MethodGet, target, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	entries := []audit.Entry{}
+	handlertest.Decode(t, body, &entries)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func TestList(t *testing.T) {
+	now := time.Now().UTC()
+	app := newTestApp(t,
+		audit.Entry{Time: now.Add(-2 * time.Hour), Name: "old-node", Namespace: "default", Principal: "alice", Resource: "nodes", Status: 201},
+		audit.Entry{Time: now.Add(-time.Minute), Name: "my-secret", Namespace: "dev", Principal: "bob", Resource: "secrets", Status: 403},
+		audit.Entry{Time: now, Name: "my-node", Namespace: "default", Principal: "alice", Resource: "nodes", Status: 200},
+	)
+
+	assert.Equal(t, []string{"my-node", "my-secret", "old-node"}, listNames(t, app, "/audit"))
+	assert.Equal(t, []string{"my-node", "old-node"}, listNames(t, app, "/audit?principal=alice"))
+	assert.Equal(t, []string{"my-secret"}, listNames(t, app, "/audit?status=403"))
+	assert.Equal(t, []string{"my-secret"}, listNames(t, app, "/audit?namespace=dev"))
+
+	since := now.Add(-time.Hour).Format(time.RFC3339)
+	assert.Equal(t, []string{"my-node", "my-secret"}, listNames(t, app, "/audit?since="+since))
+	assert.Equal(t, []string{"my-node"}, listNames(t, app, "/audit?since="+since+"&resource=nodes"))
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/audit?since=yesterday", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "BAD_REQUEST", body.Code)
+}
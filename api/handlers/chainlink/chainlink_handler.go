@@ -3,16 +3,23 @@
 package chainlink
 
 import (
+	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/chainlink"
+	"github.com/kotalco/api/pkg/audit"
 	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/patch"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"net/http"
-	"sort"
-	"strconv"
 )
 
 const (
@@ -21,110 +28,197 @@ const (
 	defaultNamespace = "default"
 )
 
-var service = chainlink.NewChainLinkService()
+// Handler serves chainlink nodes api calls
+type Handler struct {
+	service chainlink.IService
+}
+
+// NewHandler returns chainlink nodes handler using the given service
+func NewHandler(service chainlink.IService) *Handler {
+	return &Handler{service: service}
+}
 
 // Get returns a single chainlink node by name
 // 1-get the node validated from ValidateNodeExist method
 // 2-marshall node to dto and format the response
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	node := c.Locals("node").(*chainlinkv1alpha1.Node)
+	sharedHandlers.SetETag(c, node)
+
 	return c.JSON(shared.NewResponse(new(chainlink.ChainlinkDto).FromChainlinkNode(node)))
 }
 
 // Create creates chainlink node from the given spec
-// 1-Todo validate request body and return validation error
+// 1-validate request body and return validation error
 // 2-call chain link service to create chainlink node
 // 2-marshall node to and format the response
-func Create(c *fiber.Ctx) error {
+func (handler *Handler) Create(c *fiber.Ctx) error {
 	chainlinkDto := new(chainlink.ChainlinkDto)
 	if err := c.BodyParser(chainlinkDto); err != nil {
 		badReqErr := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReqErr.Status).JSON(badReqErr)
+		return restErrors.Send(c, badReqErr)
+	}
+
+	if err := validation.Validate(chainlinkDto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
 	}
 
-	node, err := service.Create(chainlinkDto)
+	node, err := handler.service.Create(c.UserContext(), chainlinkDto)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	audit.Created(c, node)
+
 	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(chainlink.ChainlinkDto).FromChainlinkNode(node)))
 }
 
 // Update updates a single chainlink node by name from spec
-// 1-todo validate request body and return validation errors if exits
+// 1-validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
 // 3-call chainlink service to update node which returns *chainlinkv1alpha1.Node
 // 4-marshall node to node dto and format the response
-func Update(c *fiber.Ctx) error {
+func (handler *Handler) Update(c *fiber.Ctx) error {
 	dto := new(chainlink.ChainlinkDto)
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(err)
+		return restErrors.Send(c, badReq)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	node := c.Locals("node").(*chainlinkv1alpha1.Node)
 
-	node, err := service.Update(dto, node)
+	node, err := handler.service.Update(c.UserContext(), dto, node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	sharedHandlers.SetETag(c, node)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(chainlink.ChainlinkDto).FromChainlinkNode(node)))
 
 }
 
+// Patch patches a single chainlink resource by name from json merge patch or json patch document
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-apply the patch document to the node dto representation, removed fields are reset to the operator defaults
+// 3-validate the patched dto and return validation errors if exits
+// 4-call service to patch the node and format the response
+func (handler *Handler) Patch(c *fiber.Ctx) error {
+	node := c.Locals("node").(*chainlinkv1alpha1.Node)
+
+	dto := new(chainlink.ChainlinkDto).FromChainlinkNode(node)
+	if err := patch.Apply(c.Get(fiber.HeaderContentType), c.Body(), dto); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	node, err := handler.service.Patch(c.UserContext(), dto, node)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	sharedHandlers.SetETag(c, node)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(chainlink.ChainlinkDto).FromChainlinkNode(node)))
+}
+
 // List returns all chainlink nodes
-// 1-get the pagination qs default to 0
-// 2-call service to return node models
-// 3-make the pagination
-// 3-marshall nodes  to chainlink dto and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	// default page to 0
-	page, _ := strconv.Atoi(c.Query("page"))
-
-	nodeList, err := service.List(c.Query(namespaceKeyword, defaultNamespace))
+// watch=true qs streams their changes instead, see Watch
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return nodes matching the selectors
+// 3-marshall nodes to dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	if sharedHandlers.IsWatch(c) {
+		return handler.Watch(c)
+	}
+
+	listQuery, err := query.Parse(c, chainlink.ChainlinkDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	start, end := shared.Page(uint(len(nodeList.Items)), uint(page))
-	sort.Slice(nodeList.Items[:], func(i, j int) bool {
-		return nodeList.Items[j].CreationTimestamp.Before(&nodeList.Items[i].CreationTimestamp)
-	})
+	nodeList, err := handler.service.List(c.UserContext(), sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace)), listQuery.PageListOptions()...)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", len(nodeList.Items)))
+	dtos := new(chainlink.ChainlinkListDto).FromChainlinkNode(nodeList.Items)
+	listQuery.Apply(&dtos)
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(chainlink.ChainlinkListDto).FromChainlinkNode(nodeList.Items[start:end])))
+	return sharedHandlers.SendList(c, listQuery, dtos, nodeList.ListMeta)
+}
+
+// Watch streams chainlink nodes changes as server-sent events
+// 1-get the namespace qs default to default namespace
+// 2-call service to watch changes after the given resource version
+// 3-marshall changed chainlink nodes to dto
+func (handler *Handler) Watch(c *fiber.Ctx) error {
+	namespace := sharedHandlers.ListNamespace(utils.CopyString(c.Query(namespaceKeyword, defaultNamespace)))
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		return handler.service.Watch(ctx, namespace, resourceVersion)
+	}
+
+	return sharedHandlers.Watch(c, watchFunc, func(obj runtime.Object) interface{} {
+		return new(chainlink.ChainlinkDto).FromChainlinkNode(obj.(*chainlinkv1alpha1.Node))
+	})
 }
 
 // Delete a single chainlink node by name
 // 1-get node from locals which checked and assigned by ValidateNodeExist
 // 2-call chainlink service to delete the node
 // 3-return ok if deleted with no errors
-func Delete(c *fiber.Ctx) error {
+func (handler *Handler) Delete(c *fiber.Ctx) error {
 	node := c.Locals("node").(*chainlinkv1alpha1.Node)
 
-	err := service.Delete(node)
+	err := handler.service.Delete(c.UserContext(), node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
 
 // Count returns total number of nodes
-// 1-call chainlink service to get exiting node list
-// 2-create X-Total-Count header with the length
-// 3-return
-func Count(c *fiber.Ctx) error {
-	length, err := service.Count(c.Query(namespaceKeyword, defaultNamespace))
+// 1-parse the label selectors and filters qs
+// 2-call service to count nodes, or list and filter them if the qs has dto filters
+// 3-create X-Total-Count header with the length
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, chainlink.ChainlinkDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	namespace := sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace))
+
+	var length int
+	if listQuery.HasFilters() {
+		nodeList, err := handler.service.List(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+
+		dtos := new(chainlink.ChainlinkListDto).FromChainlinkNode(nodeList.Items)
+		listQuery.Apply(&dtos)
+		length = len(dtos)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+		length = *count
 	}
 
 	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", *length))
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
 
 	return c.SendStatus(http.StatusOK)
 }
@@ -133,17 +227,22 @@ func Count(c *fiber.Ctx) error {
 // 1-call chainlink service to check if node exits
 // 2-return 404 if it's not
 // 3-save the node to local with the key node to be used by the other handlers
-func ValidateNodeExist(c *fiber.Ctx) error {
+func (handler *Handler) ValidateNodeExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 	}
 
-	node, err := service.Get(nameSpacedName)
+	node, err := handler.service.Get(c.UserContext(), nameSpacedName)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, node); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	c.Locals("node", node)
+	audit.Before(c, node, new(chainlink.ChainlinkDto).FromChainlinkNode(node))
 	return c.Next()
 }
@@ -0,0 +1,55 @@
+// Package cluster handler is the representation layer for the k8s clusters managed by the api server
+// implements clusterService for listing and getting clusters
+package cluster
+
+import (
+	"github.com/gofiber/fiber/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/internal/cluster"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/query"
+	"github.com/kotalco/api/pkg/shared"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+)
+
+// Handler serves clusters api calls
+type Handler struct {
+	service cluster.IService
+}
+
+// NewHandler returns clusters handler using the given service
+func NewHandler(service cluster.IService) *Handler {
+	return &Handler{service: service}
+}
+
+// List returns all registered clusters with their kubernetes and kotal operator versions
+// 1-parse the filters like reachable=true and pagination qs
+// 2-call service to probe the registered clusters
+// 3-filter the clusters and send the requested page
+func (handler *Handler) List(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, cluster.ClusterDto{})
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	dtos, err := handler.service.List(c.UserContext())
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	listQuery.Apply(&dtos)
+
+	return sharedHandlers.SendList(c, listQuery, dtos, metav1.ListMeta{})
+}
+
+// Get returns the registered cluster by name with its kubernetes and kotal operator versions
+// unreachable cluster is returned with reachable false and the probe error
+func (handler *Handler) Get(c *fiber.Ctx) error {
+	dto, err := handler.service.Get(c.UserContext(), c.Params(sharedHandlers.ClusterParam))
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(dto))
+}
@@ -0,0 +1,130 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/internal/cluster"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// clustersClientset serves the reachable clusters version from test api server, other clusters can't be reached
+type clustersClientset struct {
+	server    *httptest.Server
+	reachable map[string]bool
+}
+
+func (service clustersClientset) Clientset(ctx context.Context) (kubernetes.Interface, error) {
+	name, _ := k8s.ClusterFromContext(ctx)
+	host := service.server.URL
+	if !service.reachable[name] {
+		// nothing listens on the port once the listener is closed
+		unreachable := httptest.NewServer(http.NotFoundHandler())
+		unreachable.Close()
+		host = unreachable.URL
+	}
+	return kubernetes.NewForConfig(&rest.Config{Host: host})
+}
+
+func (service clustersClientset) MetricsClientset(ctx context.Context) (metrics.Interface, error) {
+	return nil, fmt.Errorf("metrics server isn't available")
+}
+
+// newTestApp registers default and mainnet clusters, mainnet can't be reached
+func newTestApp(t *testing.T) *fiber.App {
+	k8s.UseClusters(k8s.DefaultClusterName, "mainnet")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		w.Write([]byte(`{"gitVersion":"v1.23.3"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	operator := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kotal-controller-manager", Namespace: "kotal"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "manager", Image: "kotalco/kotal:v0.1.0"}},
+		}}},
+	}
+	clientset := clustersClientset{server: server, reachable: map[string]bool{k8s.DefaultClusterName: true}}
+	handler := NewHandler(cluster.NewClusterService(fake.NewClientService(operator), clientset))
+
+	app := fiber.New()
+	app.Get("/clusters", handler.List)
+	app.Get("/clusters/:"+sharedHandlers.ClusterParam, handler.Get)
+	return app
+}
+
+func TestList(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/clusters", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+
+	dtos := cluster.ClusterListDto{}
+	handlertest.Decode(t, body, &dtos)
+	if assert.Len(t, dtos, 2) {
+		assert.Equal(t, k8s.DefaultClusterName, dtos[0].Name)
+		assert.True(t, dtos[0].Default)
+		assert.True(t, dtos[0].Reachable)
+		assert.Equal(t, "v1.23.3", dtos[0].KubernetesVersion)
+		assert.Equal(t, "v0.1.0", dtos[0].OperatorVersion)
+
+		// unreachable cluster is listed with the probe error
+		assert.Equal(t, "mainnet", dtos[1].Name)
+		assert.False(t, dtos[1].Reachable)
+		assert.NotEmpty(t, dtos[1].Error)
+	}
+
+	// reachable clusters filter
+	resp, body = handlertest.Request(t, app, http.MethodGet, "/clusters?reachable=true", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	dtos = cluster.ClusterListDto{}
+	handlertest.Decode(t, body, &dtos)
+	if assert.Len(t, dtos, 1) {
+		assert.Equal(t, k8s.DefaultClusterName, dtos[0].Name)
+	}
+}
+
+func TestGet(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/clusters/default", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	dto := cluster.ClusterDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, k8s.DefaultClusterName, dto.Name)
+	assert.True(t, dto.Reachable)
+	assert.Equal(t, "v1.23.3", dto.KubernetesVersion)
+	assert.Equal(t, "v0.1.0", dto.OperatorVersion)
+
+	// unreachable cluster is returned with the probe error
+	resp, body = handlertest.Request(t, app, http.MethodGet, "/clusters/mainnet", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	dto = cluster.ClusterDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "mainnet", dto.Name)
+	assert.False(t, dto.Default)
+	assert.False(t, dto.Reachable)
+	assert.Empty(t, dto.KubernetesVersion)
+	assert.NotEmpty(t, dto.Error)
+
+	resp, body = handlertest.Request(t, app, http.MethodGet, "/clusters/testnet", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, string(restErrors.CodeNotFound), body.Code)
+}
@@ -0,0 +1,25 @@
+// Package config handler is the representation layer for the api server effective configuration
+package config
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/shared"
+	"net/http"
+)
+
+// Handler serves the api server configuration
+type Handler struct {
+	config *configs.Config
+}
+
+// NewHandler returns config handler serving the given configuration
+func NewHandler(config *configs.Config) *Handler {
+	return &Handler{config: config}
+}
+
+// Get returns the effective configuration loaded from the defaults, config file, environment variables and flags
+// secrets like auth.jwtHMACSecret are written as REDACTED
+func (handler *Handler) Get(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(handler.config))
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	config := configs.Defaults()
+	config.Server.ReadTimeout = configs.Duration{Duration: 90 * time.Second}
+	config.CORS.AllowOrigins = []string{"https://app.kotal.co"}
+
+	app := fiber.New()
+	app.Get("/config", NewHandler(config).Get)
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/config", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body.Data), `"readTimeout":"1m30s"`)
+
+	served := configs.Config{}
+	handlertest.Decode(t, body, &served)
+	assert.Equal(t, *config, served)
+}
+
+func TestGetRedactsSecrets(t *testing.T) {
+	config := configs.Defaults()
+	config.Auth.JWTHMACSecret = "s3cr3t"
+
+	app := fiber.New()
+	app.Get("/config", NewHandler(config).Get)
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/config", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body.Data), `"jwtHMACSecret":"REDACTED"`)
+	assert.NotContains(t, string(body.Data), "s3cr3t")
+}
@@ -0,0 +1,157 @@
+// Package namespace handler is the representation layer for kotal workspaces namespaces
+// implements namespaceService for namespaces cruds
+package namespace
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/internal/core/namespace"
+	"github.com/kotalco/api/pkg/audit"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/query"
+	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
+	corev1 "k8s.io/api/core/v1"
+	"net/http"
+)
+
+const (
+	nameKeyword = "name"
+)
+
+// Handler serves workspace namespaces api calls
+type Handler struct {
+	service namespace.IService
+}
+
+// NewHandler returns workspace namespaces handler using the given service
+func NewHandler(service namespace.IService) *Handler {
+	return &Handler{service: service}
+}
+
+// Get gets a single workspace namespace by name
+// 1-get the namespace validated from ValidateNamespaceExist method
+// 2-marshall namespace model and format the reponse
+func (handler *Handler) Get(c *fiber.Ctx) error {
+	namespaceModel := c.Locals("namespace").(*corev1.Namespace)
+
+	sharedHandlers.SetETag(c, namespaceModel)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(namespace.NamespaceDto).FromCoreNamespace(namespaceModel)))
+}
+
+// List returns all workspace namespaces
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return namespaces matching the selectors
+// 3-marshall namespaces to dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, namespace.NamespaceDto{})
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	namespaces, err := handler.service.List(c.UserContext(), listQuery.PageListOptions()...)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	dtos := new(namespace.NamespaceListDto).FromCoreNamespace(namespaces.Items)
+	listQuery.Apply(&dtos)
+
+	return sharedHandlers.SendList(c, listQuery, dtos, namespaces.ListMeta)
+}
+
+// Create creates workspace namespace from spec
+// 1-creates dto from request
+// 2-call service to create the namespace labelled as kotal workspace
+// 3-marshall the model to the dto and format the response
+func (handler *Handler) Create(c *fiber.Ctx) error {
+	dto := new(namespace.NamespaceDto)
+	if err := c.BodyParser(dto); err != nil {
+		badReq := restErrors.NewBadRequestError("invalid request body")
+		return restErrors.Send(c, badReq)
+	}
+
+	if err := validation.Validate(dto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	namespaceModel, err := handler.service.Create(c.UserContext(), dto)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	audit.Created(c, namespaceModel)
+
+	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(namespace.NamespaceDto).FromCoreNamespace(namespaceModel)))
+}
+
+// Delete deletes workspace namespace by name with all the resources in it
+// 1-get the namespace validated from ValidateNamespaceExist method
+// 2-call service to make the delete action
+// 3-return the respective response
+func (handler *Handler) Delete(c *fiber.Ctx) error {
+	namespaceModel := c.Locals("namespace").(*corev1.Namespace)
+
+	if err := handler.service.Delete(c.UserContext(), namespaceModel); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Count returns total number of workspace namespaces
+// 1-parse the label selectors and filters qs
+// 2-call service to count namespaces, or list and filter them if the qs has dto filters
+// 3-create X-Total-Count header with the length
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, namespace.NamespaceDto{})
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	var length int
+	if listQuery.HasFilters() {
+		namespaces, err := handler.service.List(c.UserContext(), listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+
+		dtos := new(namespace.NamespaceListDto).FromCoreNamespace(namespaces.Items)
+		listQuery.Apply(&dtos)
+		length = len(dtos)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+		length = *count
+	}
+
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// ValidateNamespaceExist validate workspace namespace by name exist acts as a validation for all handlers the needs to find namespace by name
+// 1-call namespace service to check if the namespace exits and is kotal workspace
+// 2-return 404 if it's not
+// 3-save the namespace to local with the key namespace to be used by the other handlers
+func (handler *Handler) ValidateNamespaceExist(c *fiber.Ctx) error {
+	namespaceModel, err := handler.service.Get(c.UserContext(), c.Params(nameKeyword))
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, namespaceModel); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	c.Locals("namespace", namespaceModel)
+	audit.Before(c, namespaceModel, new(namespace.NamespaceDto).FromCoreNamespace(namespaceModel))
+
+	return c.Next()
+}
@@ -0,0 +1,95 @@
+package namespace
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/core/namespace"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// newTestApp returns app serving the namespaces routes using in memory k8s client holding workspaces by the given names
+func newTestApp(t *testing.T, names ...string) *fiber.App {
+	handler := NewHandler(namespace.NewNamespaceService(fake.NewClientService()))
+
+	app := fiber.New()
+	router := app.Group("/namespaces")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateNamespaceExist, handler.Get)
+	router.Delete("/:name", handler.ValidateNamespaceExist, handler.Delete)
+
+	for _, name := range names {
+		resp, _ := handlertest.Request(t, app, http.MethodPost, "/namespaces", map[string]string{"name": name})
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	return app
+}
+
+func TestCreate(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := handlertest.Request(t, app, http.MethodPost, "/namespaces", map[string]string{"name": "goerli"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto := namespace.NamespaceDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "goerli", dto.Name)
+
+	resp, _ = handlertest.Request(t, app, http.MethodPost, "/namespaces", map[string]string{"name": "goerli"})
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, _ = handlertest.Request(t, app, http.MethodPost, "/namespaces", map[string]string{"name": "Invalid Name"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGet(t *testing.T) {
+	app := newTestApp(t, "goerli")
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/namespaces/goerli", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dto := namespace.NamespaceDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "goerli", dto.Name)
+
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/namespaces/unknown", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestList(t *testing.T) {
+	app := newTestApp(t, "goerli", "mainnet")
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/namespaces?sort=name&order=asc", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+
+	dtos := []namespace.NamespaceDto{}
+	handlertest.Decode(t, body, &dtos)
+	if assert.Len(t, dtos, 2) {
+		assert.Equal(t, "goerli", dtos[0].Name)
+		assert.Equal(t, "mainnet", dtos[1].Name)
+	}
+}
+
+func TestCount(t *testing.T) {
+	app := newTestApp(t, "goerli", "mainnet")
+
+	resp, _ := handlertest.Request(t, app, http.MethodHead, "/namespaces", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+}
+
+func TestDelete(t *testing.T) {
+	app := newTestApp(t, "goerli")
+
+	resp, _ := handlertest.Request(t, app, http.MethodDelete, "/namespaces/goerli", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/namespaces/goerli", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -5,14 +5,16 @@ package secret
 import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/core/secret"
+	"github.com/kotalco/api/pkg/audit"
 	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"net/http"
-	"sort"
-	"strconv"
 )
 
 const (
@@ -21,65 +23,84 @@ const (
 	defaultNamespace = "default"
 )
 
-var service = secret.NewSecretService()
+// Handler serves secrets api calls
+type Handler struct {
+	service secret.IService
+}
+
+// NewHandler returns secrets handler using the given service
+func NewHandler(service secret.IService) *Handler {
+	return &Handler{service: service}
+}
 
 // Get gets a single  secret by name
 // 1-get the node validated from ValidateSecretExist method
 // 2-marshall secretModel and format the reponse
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	secretModel := c.Locals("secret").(*corev1.Secret)
 
-	return c.Status(http.StatusOK).JSON(new(secret.SecretDto).FromCoreSecret(secretModel))
+	sharedHandlers.SetETag(c, secretModel)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(secret.SecretDto).FromCoreSecret(secretModel)))
 }
 
 // List returns all k8s secrets
-// 1-get the pagination and type qs
-// 2-call service to return secret models
-// 3-paginate the list
-// 3-marshall secrets model to secrets dto and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	secretType := c.Query("type")
-	page, _ := strconv.Atoi(c.Query("page")) // default page to 0
-
-	secrets, err := service.List(c.Query(namespaceKeyword, defaultNamespace))
+// 1-parse the label selectors, filters, sorting and pagination qs, type qs filters secrets by key type
+// 2-call service to return secret models matching the selectors
+// 3-marshall secrets model to secrets dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, secret.SecretDto{})
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	secrets, err := handler.service.List(c.UserContext(), sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace)), listQuery.PageListOptions()...)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	start, end := shared.Page(uint(len(secrets.Items)), uint(page))
-	sort.Slice(secrets.Items[:], func(i, j int) bool {
-		return secrets.Items[j].CreationTimestamp.Before(&secrets.Items[i].CreationTimestamp)
-	})
+	secretListDto := toSecretsDto(secrets.Items)
+	listQuery.Apply(&secretListDto)
+
+	return sharedHandlers.SendList(c, listQuery, secretListDto, secrets.ListMeta)
+}
 
-	var secretListDto = make([]secret.SecretDto, 0)
-	for _, sec := range secrets.Items[start:end] {
-		keyType := sec.Labels["kotal.io/key-type"]
-		if keyType == "" || secretType != "" && keyType != secretType {
+// toSecretsDto marshalls secrets model to secrets dto skipping secrets without key type
+func toSecretsDto(secrets []corev1.Secret) secret.SecretsDto {
+	result := make(secret.SecretsDto, 0)
+	for _, sec := range secrets {
+		if sec.Labels["kotal.io/key-type"] == "" {
 			continue
 		}
-		secretListDto = append(secretListDto, *secret.SecretDto{}.FromCoreSecret(&sec))
+		result = append(result, *secret.SecretDto{}.FromCoreSecret(&sec))
 	}
-
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(secretListDto))
+	return result
 }
 
 // Create creates k8s secret from spec
 // 1-creates dto from request
 // 2-call service to create and save the secret model
 // 3-marshall the model to the dto and format the response
-func Create(c *fiber.Ctx) error {
+func (handler *Handler) Create(c *fiber.Ctx) error {
 
 	dto := new(secret.SecretDto)
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(err)
+		return restErrors.Send(c, badReq)
+	}
+
+	if err := validation.Validate(dto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
 	}
 
-	secretModel, err := service.Create(dto)
+	secretModel, err := handler.service.Create(c.UserContext(), dto)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	audit.Created(c, secretModel)
+
 	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(secret.SecretDto).FromCoreSecret(secretModel)))
 }
 
@@ -87,33 +108,59 @@ func Create(c *fiber.Ctx) error {
 // 1-check if secrets with this name exits
 // 2-call service to make the delete action
 // 3-return the respective response
-func Delete(c *fiber.Ctx) error {
+func (handler *Handler) Delete(c *fiber.Ctx) error {
 	secretModel := c.Locals("secret").(*corev1.Secret)
 
-	err := service.Delete(secretModel)
+	err := handler.service.Delete(c.UserContext(), secretModel)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
 
 // Update updates k8s secret by name from spec
-func Update(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+func (handler *Handler) Update(c *fiber.Ctx) error {
+	return restErrors.Send(c, restErrors.NewMethodNotAllowedError("secrets can't be updated"))
+}
+
+// Patch patches k8s secret by name from json merge patch or json patch document
+func (handler *Handler) Patch(c *fiber.Ctx) error {
+	return restErrors.Send(c, restErrors.NewMethodNotAllowedError("secrets can't be patched"))
 }
 
 // Count returns total number of secrets
-// 1-call secrets service to count secrets items
-// 2-set the X-Total-Count header with default to 0
-func Count(c *fiber.Ctx) error {
-	length, err := service.Count(c.Query(namespaceKeyword, defaultNamespace))
+// 1-parse the label selectors and filters qs
+// 2-call secrets service to count secrets items, or list and filter them if the qs has dto filters
+// 3-set the X-Total-Count header with default to 0
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, secret.SecretDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	namespace := sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace))
+
+	var length int
+	if listQuery.HasFilters() {
+		secrets, err := handler.service.List(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+
+		secretListDto := toSecretsDto(secrets.Items)
+		listQuery.Apply(&secretListDto)
+		length = len(secretListDto)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+		length = *count
 	}
 
 	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", &length))
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
 
 	return c.SendStatus(http.StatusOK)
 }
@@ -122,18 +169,23 @@ func Count(c *fiber.Ctx) error {
 // 1-call secrets service to check if secret exits
 // 2-return 404 if it's not
 // 3-save the secret to local with the key secret to be used by the other handlers
-func ValidateSecretExist(c *fiber.Ctx) error {
+func (handler *Handler) ValidateSecretExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 	}
 
-	secretModel, err := service.Get(nameSpacedName)
+	secretModel, err := handler.service.Get(c.UserContext(), nameSpacedName)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, secretModel); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	c.Locals("secret", secretModel)
+	audit.Before(c, secretModel, new(secret.SecretDto).FromCoreSecret(secretModel))
 
 	return c.Next()
 
@@ -1 +1,118 @@
 package secret
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/core/secret"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func newTestBody(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      name,
+		"namespace": "default",
+		"type":      "password",
+		"data":      map[string]string{"password": "secret"},
+	}
+}
+
+// newTestApp returns app serving the secrets routes using in memory k8s client holding secrets by the given names
+func newTestApp(t *testing.T, names ...string) *fiber.App {
+	handler := NewHandler(secret.NewSecretService(fake.NewClientService()))
+
+	app := fiber.New()
+	router := app.Group("/secrets")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateSecretExist, handler.Get)
+	router.Put("/:name", handler.ValidateSecretExist, handler.Update)
+	router.Delete("/:name", handler.ValidateSecretExist, handler.Delete)
+
+	for _, name := range names {
+		resp, _ := handlertest.Request(t, app, http.MethodPost, "/secrets", newTestBody(name))
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+
+	return app
+}
+
+func TestCreate(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := handlertest.Request(t, app, http.MethodPost, "/secrets", newTestBody("my-secret"))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto := secret.SecretDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "my-secret", dto.Name)
+	assert.Equal(t, "password", dto.Type)
+	assert.Empty(t, dto.Data)
+
+	resp, body = handlertest.Request(t, app, http.MethodPost, "/secrets", newTestBody("my-secret"))
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, "ALREADY_EXISTS", body.Code)
+
+	resp, _ = handlertest.Request(t, app, http.MethodPost, "/secrets", map[string]interface{}{"name": "my-secret"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGet(t *testing.T) {
+	app := newTestApp(t, "my-secret")
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/secrets/my-secret", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(fiber.HeaderETag))
+
+	dto := secret.SecretDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "my-secret", dto.Name)
+
+	resp, body = handlertest.Request(t, app, http.MethodGet, "/secrets/unknown", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "NOT_FOUND", body.Code)
+}
+
+func TestUpdate(t *testing.T) {
+	app := newTestApp(t, "my-secret")
+
+	// secrets are immutable
+	resp, _ := handlertest.Request(t, app, http.MethodPut, "/secrets/my-secret", newTestBody("my-secret"))
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestList(t *testing.T) {
+	app := newTestApp(t, "secret-1", "secret-2")
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/secrets?sort=name&order=asc", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+
+	dtos := []secret.SecretDto{}
+	handlertest.Decode(t, body, &dtos)
+	if assert.Len(t, dtos, 2) {
+		assert.Equal(t, "secret-1", dtos[0].Name)
+		assert.Equal(t, "secret-2", dtos[1].Name)
+	}
+}
+
+func TestCount(t *testing.T) {
+	app := newTestApp(t, "secret-1", "secret-2")
+
+	resp, _ := handlertest.Request(t, app, http.MethodHead, "/secrets", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+}
+
+func TestDelete(t *testing.T) {
+	app := newTestApp(t, "my-secret")
+
+	resp, _ := handlertest.Request(t, app, http.MethodDelete, "/secrets/my-secret", nil)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/secrets/my-secret", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -1,84 +1,107 @@
 package storage_class
 
 import (
-	"fmt"
 	"github.com/gofiber/fiber/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/core/storage_class"
+	"github.com/kotalco/api/pkg/audit"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
 	storagev1 "k8s.io/api/storage/v1"
 	"net/http"
 	"sort"
-	"strconv"
 )
 
 const (
 	nameKeyword = "name"
 )
 
-var service = storage_class.NewStorageClassService()
+// Handler serves storage classes api calls
+type Handler struct {
+	service storage_class.IService
+}
+
+// NewHandler returns storage classes handler using the given service
+func NewHandler(service storage_class.IService) *Handler {
+	return &Handler{service: service}
+}
 
 // Get gets a single k8s storage class
 // 1-get the node validated from ValidateStorageClassExist method
 // 2-marshall storageClass model and format the reponse
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	storageClass := c.Locals("storage_class").(*storagev1.StorageClass)
 
-	return c.Status(http.StatusOK).JSON(new(storage_class.StorageClassDto).FromCoreStorageClass(storageClass))
+	sharedHandlers.SetETag(c, storageClass)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(storage_class.StorageClassDto).FromCoreStorageClass(storageClass)))
 }
 
 // List returns all k8s storage classes
-// 1-get the pagination qs default to 0
-// 2-call service to return node models
-// 3-make the pagination
-// 3-marshall nodes  to storage class dto and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page")) // default page to 0
-
-	storageClassList, err := service.List()
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return storage classes matching the selectors
+// 3-marshall storage classes to storage class dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, storage_class.StorageClassDto{})
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	storageClassList, err := handler.service.List(c.UserContext(), listQuery.PageListOptions()...)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	start, end := shared.Page(uint(len(storageClassList.Items)), uint(page))
-	sort.Slice(storageClassList.Items[:], func(i, j int) bool {
-		return storageClassList.Items[j].CreationTimestamp.Before(&storageClassList.Items[i].CreationTimestamp)
-	})
+	// storage class dto has no creation time, so the newest first default order is kept by sorting the models
+	// continue pages are kept in k8s order
+	if !listQuery.Cursor() {
+		sort.Slice(storageClassList.Items[:], func(i, j int) bool {
+			return storageClassList.Items[j].CreationTimestamp.Before(&storageClassList.Items[i].CreationTimestamp)
+		})
+	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", len(storageClassList.Items)))
+	dtos := new(storage_class.StorageClassListDto).FromCoreSecret(storageClassList.Items)
+	listQuery.Apply(&dtos)
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(storage_class.StorageClassListDto).FromCoreSecret(storageClassList.Items[start:end])))
+	return sharedHandlers.SendList(c, listQuery, dtos, storageClassList.ListMeta)
 }
 
 // Create creates k8s storage class from spec
 //todo
-func Create(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+func (handler *Handler) Create(c *fiber.Ctx) error {
+	return restErrors.Send(c, restErrors.NewMethodNotAllowedError("storage classes can't be created"))
 }
 
 // Delete deletes k8s storage class by name
 //todo
-func Delete(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+func (handler *Handler) Delete(c *fiber.Ctx) error {
+	return restErrors.Send(c, restErrors.NewMethodNotAllowedError("storage classes can't be deleted"))
 }
 
 // Update updates k8s storage class by name from spec
 //todo
-func Update(c *fiber.Ctx) error {
-	return c.SendStatus(http.StatusMethodNotAllowed)
+func (handler *Handler) Update(c *fiber.Ctx) error {
+	return restErrors.Send(c, restErrors.NewMethodNotAllowedError("storage classes can't be updated"))
 }
 
 // ValidateStorageClassExist validate storage class by name exist acts as a validation for all handlers the needs to find storage class by name
 // 1-call storage class service to check if storage class exits
 // 2-return not found if it's not
 // 3-save the storage class to local with the key storage_class to be used by the other handlers
-func ValidateStorageClassExist(c *fiber.Ctx) error {
-	storageClass, err := service.Get(c.Params(nameKeyword))
+func (handler *Handler) ValidateStorageClassExist(c *fiber.Ctx) error {
+	storageClass, err := handler.service.Get(c.UserContext(), c.Params(nameKeyword))
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, storageClass); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	c.Locals("storage_class", storageClass)
+	audit.Before(c, storageClass, new(storage_class.StorageClassDto).FromCoreStorageClass(storageClass))
 
 	return c.Next()
 }
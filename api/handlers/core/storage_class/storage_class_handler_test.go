@@ -1 +1,80 @@
 package storage_class
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/core/storage_class"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+// newTestApp returns app serving the storage classes routes using in memory k8s client holding storage classes by the given names
+func newTestApp(names ...string) *fiber.App {
+	objs := []client.Object{}
+	for _, name := range names {
+		objs = append(objs, &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: "kubernetes.io/gce-pd",
+		})
+	}
+	handler := NewHandler(storage_class.NewStorageClassService(fake.NewClientService(objs...)))
+
+	app := fiber.New()
+	router := app.Group("/storageclasses")
+	router.Post("/", handler.Create)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateStorageClassExist, handler.Get)
+	router.Delete("/:name", handler.ValidateStorageClassExist, handler.Delete)
+
+	return app
+}
+
+func TestGet(t *testing.T) {
+	app := newTestApp("standard")
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/storageclasses/standard", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(fiber.HeaderETag))
+
+	dto := storage_class.StorageClassDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "standard", dto.Name)
+
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/storageclasses/unknown", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestList(t *testing.T) {
+	app := newTestApp("standard", "premium-rwo")
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/storageclasses?sort=name&order=asc", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+
+	dtos := []storage_class.StorageClassDto{}
+	handlertest.Decode(t, body, &dtos)
+	if assert.Len(t, dtos, 2) {
+		assert.Equal(t, "premium-rwo", dtos[0].Name)
+		assert.Equal(t, "standard", dtos[1].Name)
+		assert.Equal(t, "kubernetes.io/gce-pd", dtos[0].Provisioner)
+	}
+}
+
+func TestNotAllowed(t *testing.T) {
+	app := newTestApp("standard")
+
+	resp, _ := handlertest.Request(t, app, http.MethodPost, "/storageclasses", map[string]string{"name": "fast"})
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, _ = handlertest.Request(t, app, http.MethodDelete, "/storageclasses/standard", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// storage classes have no patch route
+	resp, _ = handlertest.Request(t, app, http.MethodPatch, "/storageclasses/standard", map[string]string{"provisioner": "fast"})
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,55 @@
+// Package diagnostics handler is the representation layer for the api server health and the clusters diagnostics
+// implements diagnosticsService for readiness and diagnostics checks
+package diagnostics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/internal/diagnostics"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/shared"
+	"net/http"
+)
+
+// statusOK is the status of healthy and ready api server
+const statusOK = "ok"
+
+// HealthDto is the liveness and readiness probes response
+type HealthDto struct {
+	Status string `json:"status"`
+}
+
+// Handler serves health and diagnostics api calls
+type Handler struct {
+	service diagnostics.IService
+}
+
+// NewHandler returns diagnostics handler using the given service
+func NewHandler(service diagnostics.IService) *Handler {
+	return &Handler{service: service}
+}
+
+// Healthz is the liveness probe, it returns ok as long as the api server serves calls
+func (handler *Handler) Healthz(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(HealthDto{Status: statusOK}))
+}
+
+// Readyz is the readiness probe, it returns 503 if the default cluster api server can't be reached
+// or the server is shutting down, so no more calls are routed to it
+func (handler *Handler) Readyz(c *fiber.Ctx) error {
+	if sharedHandlers.Draining(c) {
+		return restErrors.Send(c, restErrors.NewNotReadyError("api server is shutting down"))
+	}
+
+	if err := handler.service.Ready(c.UserContext()); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(HealthDto{Status: statusOK}))
+}
+
+// Get returns the diagnostics of the cluster selected by the call
+// failed checks don't fail the call, the diagnostics and the failed checks are marked as unhealthy
+func (handler *Handler) Get(c *fiber.Ctx) error {
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(handler.service.Diagnose(c.UserContext())))
+}
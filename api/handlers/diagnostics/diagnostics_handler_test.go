@@ -0,0 +1,94 @@
+package diagnostics
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/internal/diagnostics"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// diagnosticsServiceMock serves the given readiness error and diagnostics
+type diagnosticsServiceMock struct {
+	ready       *restErrors.RestErr
+	diagnostics *diagnostics.DiagnosticsDto
+}
+
+func (service diagnosticsServiceMock) Ready(ctx context.Context) *restErrors.RestErr {
+	return service.ready
+}
+
+func (service diagnosticsServiceMock) Diagnose(ctx context.Context) *diagnostics.DiagnosticsDto {
+	return service.diagnostics
+}
+
+func newTestApp(service diagnostics.IService, middlewares ...fiber.Handler) *fiber.App {
+	handler := NewHandler(service)
+	app := fiber.New()
+	for _, middleware := range middlewares {
+		app.Use(middleware)
+	}
+	app.Get("/healthz", handler.Healthz)
+	app.Get("/readyz", handler.Readyz)
+	app.Get("/diagnostics", handler.Get)
+	return app
+}
+
+func TestProbes(t *testing.T) {
+	app := newTestApp(diagnosticsServiceMock{})
+
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	health := HealthDto{}
+	handlertest.Decode(t, body, &health)
+	assert.Equal(t, statusOK, health.Status)
+
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the api server stays alive while the cluster isn't reachable
+	app = newTestApp(diagnosticsServiceMock{ready: restErrors.NewNotReadyError("kubernetes api server can't be reached")})
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, body = handlertest.Request(t, app, http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, string(restErrors.CodeNotReady), body.Code)
+
+	// draining server isn't ready, so no more calls are routed to it
+	drainer := server.NewDrainer()
+	app = newTestApp(diagnosticsServiceMock{}, sharedHandlers.Drain(drainer))
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Nil(t, drainer.Drain(context.Background()))
+	resp, body = handlertest.Request(t, app, http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, string(restErrors.CodeNotReady), body.Code)
+	resp, _ = handlertest.Request(t, app, http.MethodGet, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGet(t *testing.T) {
+	app := newTestApp(diagnosticsServiceMock{diagnostics: &diagnostics.DiagnosticsDto{
+		Healthy: false,
+		Checks: []diagnostics.CheckDto{
+			{Name: "kubernetes-api-server", Healthy: true},
+			{Name: "operator", Message: "kotal/kotal-controller-manager has 0/1 available replicas"},
+		},
+	}})
+
+	// unhealthy diagnostics don't fail the call
+	resp, body := handlertest.Request(t, app, http.MethodGet, "/diagnostics", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dto := diagnostics.DiagnosticsDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.False(t, dto.Healthy)
+	assert.Len(t, dto.Checks, 2)
+}
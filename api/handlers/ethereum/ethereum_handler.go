@@ -3,23 +3,27 @@
 package ethereum
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/gofiber/websocket/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/ethereum"
+	"github.com/kotalco/api/pkg/audit"
 	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/patch"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
 	"github.com/ybbus/jsonrpc/v2"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"math/big"
 	"net/http"
-	"os"
-	"sort"
-	"strconv"
 	"strings"
-	"time"
 )
 
 const (
@@ -28,155 +32,206 @@ const (
 	defaultNamespace = "default"
 )
 
-var service = ethereum.NewEthereumService()
+// Handler serves ethereum nodes api calls
+type Handler struct {
+	service   ethereum.IService
+	rpcClient *http.Client
+}
+
+// NewHandler returns ethereum nodes handler using the given service
+// rpcClient is the http client used to call the nodes JSON-RPC servers
+func NewHandler(service ethereum.IService, rpcClient *http.Client) *Handler {
+	return &Handler{service: service, rpcClient: rpcClient}
+}
 
 // Get returns a single ethereum node by name
 // 1-get the node validated from ValidateNodeExist method
 // 2-marshall node to dto and format the response
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	node := c.Locals("node").(*ethereumv1alpha1.Node)
 
+	sharedHandlers.SetETag(c, node)
+
 	return c.JSON(shared.NewResponse(new(ethereum.EthereumDto).FromEthereumNode(node)))
 }
 
 // Create creates ethereum node from the given spec
-// 1-Todo validate request body and return validation error
+// 1-validate request body and return validation error
 // 2-call chain link service to create ethereum node
 // 2-marshall node to dto and format the response
-func Create(c *fiber.Ctx) error {
+func (handler *Handler) Create(c *fiber.Ctx) error {
 	dto := new(ethereum.EthereumDto)
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
 	}
 
-	node, err := service.Create(dto)
+	if err := validation.Validate(dto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	node, err := handler.service.Create(c.UserContext(), dto)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	audit.Created(c, node)
+
 	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(ethereum.EthereumDto).FromEthereumNode(node)))
 }
 
 // Update updates a single ethereum node by name from spec
-// 1-todo validate request body and return validation errors if exits
+// 1-validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
 // 3-call ethereum service to update node which returns *ethereumv1alpha1.Node
 // 4-marshall node to node dto and format the response
-func Update(c *fiber.Ctx) error {
+func (handler *Handler) Update(c *fiber.Ctx) error {
 	dto := new(ethereum.EthereumDto)
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	node := c.Locals("node").(*ethereumv1alpha1.Node)
 
-	node, err := service.Update(dto, node)
+	node, err := handler.service.Update(c.UserContext(), dto, node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	sharedHandlers.SetETag(c, node)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(ethereum.EthereumDto).FromEthereumNode(node)))
+}
+
+// Patch patches a single ethereum resource by name from json merge patch or json patch document
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-apply the patch document to the node dto representation, removed fields are reset to the operator defaults
+// 3-validate the patched dto and return validation errors if exits
+// 4-call service to patch the node and format the response
+func (handler *Handler) Patch(c *fiber.Ctx) error {
+	node := c.Locals("node").(*ethereumv1alpha1.Node)
+
+	dto := new(ethereum.EthereumDto).FromEthereumNode(node)
+	if err := patch.Apply(c.Get(fiber.HeaderContentType), c.Body(), dto); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	node, err := handler.service.Patch(c.UserContext(), dto, node)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	sharedHandlers.SetETag(c, node)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(ethereum.EthereumDto).FromEthereumNode(node)))
 }
 
 // List returns all ethereum nodes
-// 1-get the pagination qs default to 0
-// 2-call service to return node models
-// 3-make the pagination
-// 3-marshall nodes  to ethereum dto and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page"))
+// watch=true qs streams their changes instead, see Watch
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return nodes matching the selectors
+// 3-marshall nodes to dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	if sharedHandlers.IsWatch(c) {
+		return handler.Watch(c)
+	}
 
-	nodes, err := service.List(c.Query(namespaceKeyword, defaultNamespace))
+	listQuery, err := query.Parse(c, ethereum.EthereumDto{})
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
 
+	nodes, err := handler.service.List(c.UserContext(), sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace)), listQuery.PageListOptions()...)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", len(nodes.Items)))
+	dtos := new(ethereum.EthereumListDto).FromEthereumNode(nodes.Items)
+	listQuery.Apply(&dtos)
 
-	start, end := shared.Page(uint(len(nodes.Items)), uint(page))
-	sort.Slice(nodes.Items[:], func(i, j int) bool {
-		return nodes.Items[j].CreationTimestamp.Before(&nodes.Items[i].CreationTimestamp)
-	})
+	return sharedHandlers.SendList(c, listQuery, dtos, nodes.ListMeta)
+}
+
+// Watch streams ethereum nodes changes as server-sent events
+// 1-get the namespace qs default to default namespace
+// 2-call service to watch changes after the given resource version
+// 3-marshall changed ethereum nodes to dto
+func (handler *Handler) Watch(c *fiber.Ctx) error {
+	namespace := sharedHandlers.ListNamespace(utils.CopyString(c.Query(namespaceKeyword, defaultNamespace)))
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		return handler.service.Watch(ctx, namespace, resourceVersion)
+	}
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(ethereum.EthereumListDto).FromEthereumNode(nodes.Items[start:end])))
+	return sharedHandlers.Watch(c, watchFunc, func(obj runtime.Object) interface{} {
+		return new(ethereum.EthereumDto).FromEthereumNode(obj.(*ethereumv1alpha1.Node))
+	})
 }
 
 // Delete a single ethereum node by name
 // 1-get node from locals which checked and assigned by ValidateNodeExist
 // 2-call ethereum service to delete the node
 // 3-return ok if deleted with no errors
-func Delete(c *fiber.Ctx) error {
+func (handler *Handler) Delete(c *fiber.Ctx) error {
 	node := c.Locals("node").(*ethereumv1alpha1.Node)
 
-	err := service.Delete(node)
+	err := handler.service.Delete(c.UserContext(), node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
 
 // Count returns total number of nodes
-// 1-call ethereum service to get exiting node list
-// 2-create X-Total-Count header with the length
-// 3-return
-func Count(c *fiber.Ctx) error {
-	length, err := service.Count(c.Query(namespaceKeyword, defaultNamespace))
+// 1-parse the label selectors and filters qs
+// 2-call service to count nodes, or list and filter them if the qs has dto filters
+// 3-create X-Total-Count header with the length
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, ethereum.EthereumDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", *length))
+	namespace := sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace))
 
-	return c.SendStatus(http.StatusOK)
-}
-
-func Stats(c *websocket.Conn) {
-	defer c.Close()
-
-	type Result struct {
-		Error string `json:"error,omitempty"`
-		// eth_syncing call
-		CurrentBlock uint `json:"currentBlock,omitempty"`
-		HighestBlock uint `json:"highestBlock,omitempty"`
-		// net_peerCount call
-		Peers uint `json:"peersCount,omitempty"`
-	}
-
-	// Mock serever
-	if os.Getenv("MOCK") == "true" {
-		var currentBlock, highestBlock, peersCount uint
-		for {
-			currentBlock += 3
-			highestBlock += 32
-			peersCount += 1
-
-			r := &Result{
-				CurrentBlock: currentBlock,
-				HighestBlock: highestBlock,
-				Peers:        peersCount,
-			}
-
-			var msg []byte
-
-			if peersCount > 20 {
-				peersCount = 0
-				r = &Result{
-					Error: "JSON-RPC server is not enabled",
-				}
-			}
+	var length int
+	if listQuery.HasFilters() {
+		nodes, err := handler.service.List(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
 
-			msg, _ = json.Marshal(r)
-			c.WriteMessage(websocket.TextMessage, []byte(msg))
-			time.Sleep(time.Second)
+		dtos := new(ethereum.EthereumListDto).FromEthereumNode(nodes.Items)
+		listQuery.Apply(&dtos)
+		length = len(dtos)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
 		}
+		length = *count
 	}
 
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// Stats streams the node stats polled every stats.statsInterval until the client disconnects or the server starts draining
+// JSON-RPC call errors are written to the websocket without closing it
+func (handler *Handler) Stats(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
 	nameSpacedName := types.NamespacedName{
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 		Name:      c.Params(nameKeyword),
@@ -184,28 +239,37 @@ func Stats(c *websocket.Conn) {
 
 	for {
 
-		node, err := service.Get(nameSpacedName)
+		node, err := handler.service.Get(ctx, nameSpacedName)
 
 		if err != nil {
-			c.WriteJSON(err)
-			return
+			return err
 		}
 
 		if !node.Spec.RPC {
-			c.WriteJSON(restErrors.NewBadRequestError("rpc is not enabled"))
-			return
+			return restErrors.NewRPCDisabledError("rpc is not enabled")
 		}
 
-		client := jsonrpc.NewClient(fmt.Sprintf("http://%s:%d", node.Name, node.Spec.RPCPort))
+		client := jsonrpc.NewClientWithOpts(fmt.Sprintf("http://%s:%d", node.Name, node.Spec.RPCPort), &jsonrpc.RPCClientOpts{HTTPClient: handler.rpcClient})
 
 		type SyncStatus struct {
 			CurrentBlock string `json:"currentBlock"`
 			HighestBlock string `json:"highestBlock"`
 		}
 
-		// sync status
+		// sync status, the result is false instead of the sync status if the node isn't syncing
 		syncStatus := SyncStatus{}
-		client.CallFor(&syncStatus, "eth_syncing")
+		response, callErr := client.Call("eth_syncing")
+		if callErr == nil && response.Error != nil {
+			callErr = response.Error
+		}
+		if callErr != nil {
+			sharedHandlers.WriteError(c, sharedHandlers.RPCError("ethereum", "eth_syncing", callErr))
+			if !sharedHandlers.Wait(ctx, sharedHandlers.StatsInterval()) {
+				return nil
+			}
+			continue
+		}
+		response.GetObject(&syncStatus)
 
 		current := new(big.Int)
 		current.SetString(strings.Replace(syncStatus.CurrentBlock, "0x", "", 1), 16)
@@ -215,7 +279,13 @@ func Stats(c *websocket.Conn) {
 
 		// peer count
 		var peerCount string
-		client.CallFor(&peerCount, "net_peerCount")
+		if callErr := client.CallFor(&peerCount, "net_peerCount"); callErr != nil {
+			sharedHandlers.WriteError(c, sharedHandlers.RPCError("ethereum", "net_peerCount", callErr))
+			if !sharedHandlers.Wait(ctx, sharedHandlers.StatsInterval()) {
+				return nil
+			}
+			continue
+		}
 
 		count := new(big.Int)
 		count.SetString(strings.Replace(peerCount, "0x", "", 1), 16)
@@ -226,7 +296,9 @@ func Stats(c *websocket.Conn) {
 			"peersCount":   count,
 		})
 
-		time.Sleep(time.Second)
+		if !sharedHandlers.Wait(ctx, sharedHandlers.StatsInterval()) {
+			return nil
+		}
 	}
 }
 
@@ -234,17 +306,22 @@ func Stats(c *websocket.Conn) {
 // 1-call ethereum service to check if node exits
 // 2-return 404 if it's not
 // 3-save the node to local with the key node to be used by the other handlers
-func ValidateNodeExist(c *fiber.Ctx) error {
+func (handler *Handler) ValidateNodeExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 	}
 
-	node, err := service.Get(nameSpacedName)
+	node, err := handler.service.Get(c.UserContext(), nameSpacedName)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, node); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	c.Locals("node", node)
+	audit.Before(c, node, new(ethereum.EthereumDto).FromEthereumNode(node))
 	return c.Next()
 }
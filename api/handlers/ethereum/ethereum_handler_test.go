@@ -1 +1,144 @@
 package ethereum
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/internal/ethereum"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var nodes = handlertest.Resource{
+	Path:   "/nodes",
+	NewApp: newTestApp,
+	Body: func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    name,
+			"network": "goerli",
+			"client":  "besu",
+		}
+	},
+}
+
+// newTestApp returns app serving the nodes routes using empty in memory k8s client
+func newTestApp(t *testing.T) *fiber.App {
+	handler := NewHandler(ethereum.NewEthereumService(fake.NewClientService()), http.DefaultClient)
+
+	app := fiber.New()
+	router := app.Group("/nodes")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateNodeExist, handler.Get)
+	router.Get("/:name/stats", websocket.New(sharedHandlers.Websocket(handler.Stats)))
+	router.Put("/:name", handler.ValidateNodeExist, handler.Update)
+	router.Patch("/:name", handler.ValidateNodeExist, handler.Patch)
+	router.Delete("/:name", handler.ValidateNodeExist, handler.Delete)
+
+	return app
+}
+
+func TestCRUD(t *testing.T) {
+	handlertest.CRUD(t, nodes)
+}
+
+func TestBootnodesAndStaticNodes(t *testing.T) {
+	app := newTestApp(t)
+	nodes.Seed(t, app, "my-node")
+
+	enode := "enode://2281549869465d98e90cebc45e1d6834a01465a990add7bcf07a49287e7e66b50ca27f9c70a46190cef7ad746dd5d5b6b9dfee0c9954104c8e9bd0d42758ec58@10.5.0.2:30300"
+	resp, body := handlertest.Request(t, app, http.MethodPut, "/nodes/my-node", map[string]interface{}{
+		"bootnodes":   []string{enode},
+		"staticNodes": []string{enode},
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dto := ethereum.EthereumDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, []string{enode}, *dto.Bootnodes)
+	assert.Equal(t, []string{enode}, *dto.StaticNodes)
+
+	// removed nodes are returned as empty lists
+	req := handlertest.NewRequest(t, http.MethodPatch, "/nodes/my-node", map[string]interface{}{"bootnodes": nil})
+	req.Header.Set(fiber.HeaderContentType, "application/merge-patch+json")
+	resp, body = handlertest.Send(t, app, req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dto = ethereum.EthereumDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Empty(t, *dto.Bootnodes)
+	assert.Equal(t, []string{enode}, *dto.StaticNodes)
+}
+
+func TestCrossFields(t *testing.T) {
+	app := newTestApp(t)
+
+	tests := []struct {
+		name  string
+		body  map[string]interface{}
+		field string
+	}{
+		{
+			name:  "miner without coinbase",
+			body:  map[string]interface{}{"client": "geth", "miner": true},
+			field: "coinbase",
+		},
+		{
+			name:  "coinbase without miner",
+			body:  map[string]interface{}{"client": "geth", "miner": false, "coinbase": "0x5A0b54D5dc17e0AadC383d2db43B0a0D3E029c4c"},
+			field: "miner",
+		},
+		{
+			name:  "besu import",
+			body:  map[string]interface{}{"client": "besu", "import": map[string]interface{}{"privateKeySecretName": "my-key", "passwordSecretName": "my-password"}},
+			field: "import",
+		},
+		{
+			name:  "nethermind graphql",
+			body:  map[string]interface{}{"client": "nethermind", "graphql": true},
+			field: "graphql",
+		},
+		{
+			name:  "besu light sync",
+			body:  map[string]interface{}{"client": "besu", "syncMode": "light"},
+			field: "syncMode",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.body["name"] = "my-node"
+			test.body["network"] = "goerli"
+
+			resp, body := handlertest.Request(t, app, http.MethodPost, "/nodes", test.body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, string(restErrors.CodeSpecInvalid), body.Code)
+			assert.Contains(t, body.Validations, test.field)
+		})
+	}
+
+	// geth supports light sync
+	resp, _ := handlertest.Request(t, app, http.MethodPost, "/nodes", map[string]interface{}{
+		"name":     "my-node",
+		"network":  "goerli",
+		"client":   "geth",
+		"syncMode": "light",
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestStatsRPCDisabled(t *testing.T) {
+	app := newTestApp(t)
+	nodes.Seed(t, app, "my-node")
+
+	resp, _ := handlertest.Request(t, app, http.MethodPut, "/nodes/my-node", map[string]interface{}{"rpc": false})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	restErr := handlertest.WebsocketError(t, app, "/nodes/my-node/stats")
+	assert.Equal(t, restErrors.CodeRPCDisabled, restErr.Code)
+}
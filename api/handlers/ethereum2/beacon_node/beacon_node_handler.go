@@ -1,16 +1,23 @@
 package beacon_node
 
 import (
+	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/ethereum2/beacon_node"
+	"github.com/kotalco/api/pkg/audit"
 	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/patch"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"net/http"
-	"sort"
-	"strconv"
 )
 
 const (
@@ -19,110 +26,198 @@ const (
 	defaultNamespace = "default"
 )
 
-var service = beacon_node.NewBeaconNodeService()
+// Handler serves ethereum2 beacon nodes api calls
+type Handler struct {
+	service beacon_node.IService
+}
+
+// NewHandler returns ethereum2 beacon nodes handler using the given service
+func NewHandler(service beacon_node.IService) *Handler {
+	return &Handler{service: service}
+}
 
 // Get gets a single ethereum 2.0 beacon node by name
 // 1-get the node validated from ValidateNodeExist method
 // 2-marshall node to dto and format the response
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	node := c.Locals("node").(*ethereum2v1alpha1.BeaconNode)
 
+	sharedHandlers.SetETag(c, node)
+
 	return c.JSON(shared.NewResponse(new(beacon_node.BeaconNodeDto).FromEthereum2BeaconNode(node)))
 }
 
 // List returns all ethereum 2.0 beacon nodes
-// 1-get the pagination qs default to 0
-// 2-call service to return node models
-// 3-make the pagination
-// 3-marshall nodes  to beacon node dto and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page"))
-
-	nodes, err := service.List(c.Query(namespaceKeyword, defaultNamespace))
+// watch=true qs streams their changes instead, see Watch
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return nodes matching the selectors
+// 3-marshall nodes to dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	if sharedHandlers.IsWatch(c) {
+		return handler.Watch(c)
+	}
+
+	listQuery, err := query.Parse(c, beacon_node.BeaconNodeDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", len(nodes.Items)))
+	nodes, err := handler.service.List(c.UserContext(), sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace)), listQuery.PageListOptions()...)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
 
-	start, end := shared.Page(uint(len(nodes.Items)), uint(page))
-	sort.Slice(nodes.Items[:], func(i, j int) bool {
-		return nodes.Items[j].CreationTimestamp.Before(&nodes.Items[i].CreationTimestamp)
-	})
+	dtos := new(beacon_node.BeaconNodeListDto).FromEthereum2BeaconNode(nodes.Items)
+	listQuery.Apply(&dtos)
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(beacon_node.BeaconNodeListDto).FromEthereum2BeaconNode(nodes.Items[start:end])))
+	return sharedHandlers.SendList(c, listQuery, dtos, nodes.ListMeta)
 }
 
 // Create creates ethereum 2.0 beacon node from spec
-// 1-Todo validate request body and return validation error
+// 1-validate request body and return validation error
 // 2-call beacon node service to create beacon node
 // 2-marshall node to dto and format the response
-func Create(c *fiber.Ctx) error {
+func (handler *Handler) Create(c *fiber.Ctx) error {
 	dto := new(beacon_node.BeaconNodeDto)
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(err)
+		return restErrors.Send(c, badReq)
 	}
 
-	node, err := service.Create(dto)
+	if err := validation.Validate(dto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	node, err := handler.service.Create(c.UserContext(), dto)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	audit.Created(c, node)
+
 	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(beacon_node.BeaconNodeDto).FromEthereum2BeaconNode(node)))
 }
 
+// Watch streams ethereum 2.0 beacon nodes changes as server-sent events
+// 1-get the namespace qs default to default namespace
+// 2-call service to watch changes after the given resource version
+// 3-marshall changed ethereum 2.0 beacon nodes to dto
+func (handler *Handler) Watch(c *fiber.Ctx) error {
+	namespace := sharedHandlers.ListNamespace(utils.CopyString(c.Query(namespaceKeyword, defaultNamespace)))
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		return handler.service.Watch(ctx, namespace, resourceVersion)
+	}
+
+	return sharedHandlers.Watch(c, watchFunc, func(obj runtime.Object) interface{} {
+		return new(beacon_node.BeaconNodeDto).FromEthereum2BeaconNode(obj.(*ethereum2v1alpha1.BeaconNode))
+	})
+}
+
 // Delete deletes ethereum 2.0 beacon node by name
 // 1-get node from locals which checked and assigned by ValidateNodeExist
 // 2-call beacon node service to delete the node
 // 3-return ok if deleted with no errors
-func Delete(c *fiber.Ctx) error {
+func (handler *Handler) Delete(c *fiber.Ctx) error {
 	node := c.Locals("node").(*ethereum2v1alpha1.BeaconNode)
 
-	err := service.Delete(node)
+	err := handler.service.Delete(c.UserContext(), node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
 
 // Update updates ethereum 2.0 beacon node by name from spec
-// 1-todo validate request body and return validation errors if exits
+// 1-validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
 // 3-call beacon node  service to update node which returns *ethereum2v1alpha1.BeaconNode
 // 4-marshall node to node dto and format the response
-func Update(c *fiber.Ctx) error {
+func (handler *Handler) Update(c *fiber.Ctx) error {
 	dto := new(beacon_node.BeaconNodeDto)
 
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid reqeust body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	beaconnode := c.Locals("node").(*ethereum2v1alpha1.BeaconNode)
 
-	beaconnode, err := service.Update(dto, beaconnode)
+	beaconnode, err := handler.service.Update(c.UserContext(), dto, beaconnode)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	sharedHandlers.SetETag(c, beaconnode)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(beacon_node.BeaconNodeDto).FromEthereum2BeaconNode(beaconnode)))
+}
+
+// Patch patches a single beacon_node resource by name from json merge patch or json patch document
+// 1-get beaconnode from locals which checked and assigned by ValidateNodeExist
+// 2-apply the patch document to the beaconnode dto representation, removed fields are reset to the operator defaults
+// 3-validate the patched dto and return validation errors if exits
+// 4-call service to patch the beaconnode and format the response
+func (handler *Handler) Patch(c *fiber.Ctx) error {
+	beaconnode := c.Locals("node").(*ethereum2v1alpha1.BeaconNode)
+
+	dto := new(beacon_node.BeaconNodeDto).FromEthereum2BeaconNode(beaconnode)
+	if err := patch.Apply(c.Get(fiber.HeaderContentType), c.Body(), dto); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	beaconnode, err := handler.service.Patch(c.UserContext(), dto, beaconnode)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	sharedHandlers.SetETag(c, beaconnode)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(beacon_node.BeaconNodeDto).FromEthereum2BeaconNode(beaconnode)))
 }
 
 // Count returns total number of beacon nodes
-// 1-call beacon node service to get exiting node list
-// 2-create X-Total-Count header with the length
-// 3-return
-func Count(c *fiber.Ctx) error {
-	length, err := service.Count(c.Query(namespaceKeyword, defaultNamespace))
+// 1-parse the label selectors and filters qs
+// 2-call service to count nodes, or list and filter them if the qs has dto filters
+// 3-create X-Total-Count header with the length
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, beacon_node.BeaconNodeDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	namespace := sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace))
+
+	var length int
+	if listQuery.HasFilters() {
+		nodes, err := handler.service.List(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+
+		dtos := new(beacon_node.BeaconNodeListDto).FromEthereum2BeaconNode(nodes.Items)
+		listQuery.Apply(&dtos)
+		length = len(dtos)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+		length = *count
 	}
 
 	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", *length))
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
 
 	return c.SendStatus(http.StatusOK)
 }
@@ -131,17 +226,22 @@ func Count(c *fiber.Ctx) error {
 // 1-call beacon node service to check if node exits
 // 2-return 404 if it's not
 // 3-save the node to local with the key node to be used by the other handlers
-func ValidateBeaconNodeExist(c *fiber.Ctx) error {
+func (handler *Handler) ValidateBeaconNodeExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 	}
 
-	node, err := service.Get(nameSpacedName)
+	node, err := handler.service.Get(c.UserContext(), nameSpacedName)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, node); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	c.Locals("node", node)
+	audit.Before(c, node, new(beacon_node.BeaconNodeDto).FromEthereum2BeaconNode(node))
 	return c.Next()
 }
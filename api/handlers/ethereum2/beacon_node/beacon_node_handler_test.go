@@ -1 +1,87 @@
 package beacon_node
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/ethereum2/beacon_node"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var beaconNodes = handlertest.Resource{
+	Path:   "/beaconnodes",
+	NewApp: newTestApp,
+	Body: func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    name,
+			"network": "mainnet",
+			"client":  "teku",
+		}
+	},
+}
+
+// newTestApp returns app serving the beacon nodes routes using empty in memory k8s client
+func newTestApp(t *testing.T) *fiber.App {
+	handler := NewHandler(beacon_node.NewBeaconNodeService(fake.NewClientService()))
+
+	app := fiber.New()
+	router := app.Group("/beaconnodes")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateBeaconNodeExist, handler.Get)
+	router.Put("/:name", handler.ValidateBeaconNodeExist, handler.Update)
+	router.Patch("/:name", handler.ValidateBeaconNodeExist, handler.Patch)
+	router.Delete("/:name", handler.ValidateBeaconNodeExist, handler.Delete)
+
+	return app
+}
+
+func TestCRUD(t *testing.T) {
+	handlertest.CRUD(t, beaconNodes)
+}
+
+func TestCrossFields(t *testing.T) {
+	app := newTestApp(t)
+
+	// prysm requires eth1 endpoints outside mainnet
+	resp, body := handlertest.Request(t, app, http.MethodPost, "/beaconnodes", map[string]interface{}{
+		"name":    "my-node",
+		"network": "goerli",
+		"client":  "prysm",
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body.Validations, "eth1Endpoints")
+
+	resp, body = handlertest.Request(t, app, http.MethodPost, "/beaconnodes", map[string]interface{}{
+		"name":          "my-node",
+		"network":       "goerli",
+		"client":        "prysm",
+		"eth1Endpoints": []string{"ftp://goerli-node:8545"},
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body.Validations, "eth1Endpoints[0]")
+
+	resp, body = handlertest.Request(t, app, http.MethodPost, "/beaconnodes", map[string]interface{}{
+		"name":          "my-node",
+		"network":       "goerli",
+		"client":        "prysm",
+		"eth1Endpoints": []string{"http://goerli-node:8545"},
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto := beacon_node.BeaconNodeDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, []string{"http://goerli-node:8545"}, *dto.Eth1Endpoints)
+	// prysm beacon node serves the validators over its rpc server
+	assert.True(t, *dto.RPC)
+
+	resp, _ = handlertest.Request(t, app, http.MethodPost, "/beaconnodes", map[string]interface{}{
+		"name":    "mainnet-node",
+		"network": "mainnet",
+		"client":  "prysm",
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
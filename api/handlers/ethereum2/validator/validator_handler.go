@@ -1,16 +1,23 @@
 package validator
 
 import (
+	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/ethereum2/validator"
+	"github.com/kotalco/api/pkg/audit"
 	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/patch"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"net/http"
-	"sort"
-	"strconv"
 )
 
 const (
@@ -19,111 +26,199 @@ const (
 	defaultNamespace = "default"
 )
 
-var service = validator.NewValidatorService()
+// Handler serves ethereum2 validators api calls
+type Handler struct {
+	service validator.IService
+}
+
+// NewHandler returns ethereum2 validators handler using the given service
+func NewHandler(service validator.IService) *Handler {
+	return &Handler{service: service}
+}
 
 // Get gets a single Ethereum 2.0 validator client by name
 // 1-get the node validated from ValidateNodeExist method
 // 2-marshall node to dto and format the response
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	validatorNode := c.Locals("validator").(*ethereum2v1alpha1.Validator)
 
+	sharedHandlers.SetETag(c, validatorNode)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(validator.ValidatorDto).FromEthereum2Validator(validatorNode)))
 }
 
 // List returns all Ethereum 2.0 validator clients
-// 1-get the pagination qs default to 0
-// 2-call service to return node models
-// 3-make the pagination
-// 3-marshall nodes  to validator dto and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page"))
-
-	validatorList, err := service.List(c.Query(namespaceKeyword, defaultNamespace))
+// watch=true qs streams their changes instead, see Watch
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return validators matching the selectors
+// 3-marshall validators to dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	if sharedHandlers.IsWatch(c) {
+		return handler.Watch(c)
+	}
+
+	listQuery, err := query.Parse(c, validator.ValidatorDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", len(validatorList.Items)))
+	validatorList, err := handler.service.List(c.UserContext(), sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace)), listQuery.PageListOptions()...)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
 
-	start, end := shared.Page(uint(len(validatorList.Items)), uint(page))
-	sort.Slice(validatorList.Items[:], func(i, j int) bool {
-		return validatorList.Items[j].CreationTimestamp.Before(&validatorList.Items[i].CreationTimestamp)
-	})
+	dtos := new(validator.ValidatorListDto).FromEthereum2Validator(validatorList.Items)
+	listQuery.Apply(&dtos)
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(validator.ValidatorListDto).FromEthereum2Validator(validatorList.Items[start:end])))
+	return sharedHandlers.SendList(c, listQuery, dtos, validatorList.ListMeta)
 }
 
 // Create creates Ethereum 2.0 validator client from spec
-// 1-Todo validate request body and return validation error
+// 1-validate request body and return validation error
 // 2-call validator  service to create validator model
 // 2-marshall node to dto and format the response
-func Create(c *fiber.Ctx) error {
+func (handler *Handler) Create(c *fiber.Ctx) error {
 	dto := new(validator.ValidatorDto)
 
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
 	}
 
-	validatorNode, err := service.Create(dto)
+	if err := validation.Validate(dto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	validatorNode, err := handler.service.Create(c.UserContext(), dto)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(validator.ValidatorDto).FromEthereum2Validator(validatorNode)))
+	audit.Created(c, validatorNode)
+
+	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(validator.ValidatorDto).FromEthereum2Validator(validatorNode)))
+}
+
+// Watch streams Ethereum 2.0 validator clients changes as server-sent events
+// 1-get the namespace qs default to default namespace
+// 2-call service to watch changes after the given resource version
+// 3-marshall changed Ethereum 2.0 validator clients to dto
+func (handler *Handler) Watch(c *fiber.Ctx) error {
+	namespace := sharedHandlers.ListNamespace(utils.CopyString(c.Query(namespaceKeyword, defaultNamespace)))
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		return handler.service.Watch(ctx, namespace, resourceVersion)
+	}
+
+	return sharedHandlers.Watch(c, watchFunc, func(obj runtime.Object) interface{} {
+		return new(validator.ValidatorDto).FromEthereum2Validator(obj.(*ethereum2v1alpha1.Validator))
+	})
 }
 
 // Delete deletes Ethereum 2.0 validator client by name
 // 1-get node from locals which checked and assigned by ValidateNodeExist
 // 2-call validator service to delete the node
 // 3-return ok if deleted with no errors
-func Delete(c *fiber.Ctx) error {
+func (handler *Handler) Delete(c *fiber.Ctx) error {
 	validator := c.Locals("validator").(*ethereum2v1alpha1.Validator)
 
-	err := service.Delete(validator)
+	err := handler.service.Delete(c.UserContext(), validator)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
 
 // Update updates Ethereum 2.0 validator client by name from spec
-// 1-todo validate request body and return validation errors if exits
+// 1-validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
 // 3-call validator service to update node which returns *ethereum2v1alpha1.Validator
 // 4-marshall node to node dto and format the response
-func Update(c *fiber.Ctx) error {
+func (handler *Handler) Update(c *fiber.Ctx) error {
 	dto := new(validator.ValidatorDto)
 
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(err)
+		return restErrors.Send(c, badReq)
 	}
 
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	validatorNode := c.Locals("validator").(*ethereum2v1alpha1.Validator)
+
+	validatorNode, err := handler.service.Update(c.UserContext(), dto, validatorNode)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	sharedHandlers.SetETag(c, validatorNode)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(validator.ValidatorDto).FromEthereum2Validator(validatorNode)))
+}
+
+// Patch patches a single validator resource by name from json merge patch or json patch document
+// 1-get validatorNode from locals which checked and assigned by ValidateNodeExist
+// 2-apply the patch document to the validatorNode dto representation, removed fields are reset to the operator defaults
+// 3-validate the patched dto and return validation errors if exits
+// 4-call service to patch the validatorNode and format the response
+func (handler *Handler) Patch(c *fiber.Ctx) error {
 	validatorNode := c.Locals("validator").(*ethereum2v1alpha1.Validator)
 
-	validatorNode, err := service.Update(dto, validatorNode)
+	dto := new(validator.ValidatorDto).FromEthereum2Validator(validatorNode)
+	if err := patch.Apply(c.Get(fiber.HeaderContentType), c.Body(), dto); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	validatorNode, err := handler.service.Patch(c.UserContext(), dto, validatorNode)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	sharedHandlers.SetETag(c, validatorNode)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(validator.ValidatorDto).FromEthereum2Validator(validatorNode)))
 }
 
 // Count returns total number of validators
-// 1-call validator service to get exiting node list
-// 2-create X-Total-Count header with the length
-// 3-return
-func Count(c *fiber.Ctx) error {
-	length, err := service.Count(c.Query(namespaceKeyword, defaultNamespace))
+// 1-parse the label selectors and filters qs
+// 2-call service to count validators, or list and filter them if the qs has dto filters
+// 3-create X-Total-Count header with the length
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, validator.ValidatorDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	namespace := sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace))
+
+	var length int
+	if listQuery.HasFilters() {
+		validatorList, err := handler.service.List(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+
+		dtos := new(validator.ValidatorListDto).FromEthereum2Validator(validatorList.Items)
+		listQuery.Apply(&dtos)
+		length = len(dtos)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+		length = *count
 	}
 
 	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", *length))
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
 
 	return c.SendStatus(http.StatusOK)
 }
@@ -132,18 +227,23 @@ func Count(c *fiber.Ctx) error {
 // 1-call validator service to check if node exits
 // 2-return 404 if it's not
 // 3-save the node to local with the key node to be used by the other handlers
-func ValidateValidatorExist(c *fiber.Ctx) error {
+func (handler *Handler) ValidateValidatorExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 	}
 
-	validator, err := service.Get(nameSpacedName)
+	validatorNode, err := handler.service.Get(c.UserContext(), nameSpacedName)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, validatorNode); err != nil {
+		return restErrors.Send(c, err)
 	}
 
-	c.Locals("validator", validator)
+	c.Locals("validator", validatorNode)
+	audit.Before(c, validatorNode, new(validator.ValidatorDto).FromEthereum2Validator(validatorNode))
 
 	return c.Next()
 
@@ -1 +1,81 @@
 package validator
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/ethereum2/validator"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var validators = handlertest.Resource{
+	Path:   "/validators",
+	NewApp: newTestApp,
+	Body: func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":      name,
+			"network":   "mainnet",
+			"client":    "teku",
+			"keystores": []map[string]string{{"secretName": "my-keystore"}},
+		}
+	},
+}
+
+// newTestApp returns app serving the validators routes using empty in memory k8s client
+func newTestApp(t *testing.T) *fiber.App {
+	handler := NewHandler(validator.NewValidatorService(fake.NewClientService()))
+
+	app := fiber.New()
+	router := app.Group("/validators")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateValidatorExist, handler.Get)
+	router.Put("/:name", handler.ValidateValidatorExist, handler.Update)
+	router.Patch("/:name", handler.ValidateValidatorExist, handler.Patch)
+	router.Delete("/:name", handler.ValidateValidatorExist, handler.Delete)
+
+	return app
+}
+
+func TestCRUD(t *testing.T) {
+	handlertest.CRUD(t, validators)
+}
+
+func TestCrossFields(t *testing.T) {
+	app := newTestApp(t)
+
+	body := validators.Body("my-validator")
+	delete(body, "keystores")
+	resp, resBody := handlertest.Request(t, app, http.MethodPost, "/validators", body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resBody.Validations, "keystores")
+
+	// prysm requires wallet password
+	body = validators.Body("my-validator")
+	body["client"] = "prysm"
+	resp, resBody = handlertest.Request(t, app, http.MethodPost, "/validators", body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resBody.Validations, "walletPasswordSecretName")
+
+	body["walletPasswordSecretName"] = "my-wallet-password"
+	resp, resBody = handlertest.Request(t, app, http.MethodPost, "/validators", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto := validator.ValidatorDto{}
+	handlertest.Decode(t, resBody, &dto)
+	assert.Equal(t, "my-wallet-password", dto.WalletPasswordSecretName)
+	assert.Equal(t, []validator.KeystoreDto{{SecretName: "my-keystore"}}, dto.Keystores)
+
+	// wallet password is used by prysm only
+	body = validators.Body("teku-validator")
+	body["walletPasswordSecretName"] = "my-wallet-password"
+	resp, resBody = handlertest.Request(t, app, http.MethodPost, "/validators", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto = validator.ValidatorDto{}
+	handlertest.Decode(t, resBody, &dto)
+	assert.Empty(t, dto.WalletPasswordSecretName)
+}
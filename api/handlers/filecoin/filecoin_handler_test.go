@@ -1 +1,78 @@
 package filecoin
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/filecoin"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var nodes = handlertest.Resource{
+	Path:   "/nodes",
+	NewApp: newTestApp,
+	Body: func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    name,
+			"network": "mainnet",
+		}
+	},
+}
+
+// newTestApp returns app serving the nodes routes using empty in memory k8s client
+func newTestApp(t *testing.T) *fiber.App {
+	handler := NewHandler(filecoin.NewFilecoinService(fake.NewClientService()))
+
+	app := fiber.New()
+	router := app.Group("/nodes")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateNodeExist, handler.Get)
+	router.Put("/:name", handler.ValidateNodeExist, handler.Update)
+	router.Patch("/:name", handler.ValidateNodeExist, handler.Patch)
+	router.Delete("/:name", handler.ValidateNodeExist, handler.Delete)
+
+	return app
+}
+
+func TestCRUD(t *testing.T) {
+	handlertest.CRUD(t, nodes)
+}
+
+func TestNetwork(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := handlertest.Request(t, app, http.MethodPost, "/nodes", map[string]interface{}{"name": "my-node", "network": "calibration"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto := filecoin.FilecoinDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "calibration", dto.Network)
+
+	resp, body = handlertest.Request(t, app, http.MethodPost, "/nodes", map[string]interface{}{"name": "my-node", "network": "goerli"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body.Validations, "network")
+}
+
+func TestIPFS(t *testing.T) {
+	app := newTestApp(t)
+	nodes.Seed(t, app, "my-node")
+
+	resp, body := handlertest.Request(t, app, http.MethodPut, "/nodes/my-node", map[string]interface{}{
+		"ipfsPeerEndpoint": "/dns4/my-peer/tcp/5001",
+		"ipfsOnlineMode":   true,
+		"ipfsForRetrieval": true,
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dto := filecoin.FilecoinDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "/dns4/my-peer/tcp/5001", dto.IPFSPeerEndpoint)
+	assert.True(t, *dto.IPFSOnlineMode)
+	assert.True(t, *dto.IPFSForRetrieval)
+	// network can't be changed
+	assert.Equal(t, "mainnet", dto.Network)
+}
@@ -0,0 +1,192 @@
+package handlertest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Resource is protocol resource served by the app returned from NewApp
+type Resource struct {
+	// Path is the resources collection path, e.g. /nodes
+	Path string
+	// NewApp returns app serving the resources collection routes using empty in memory k8s client
+	NewApp func(t *testing.T) *fiber.App
+	// Body returns valid create request body of resource by the given name
+	Body func(name string) map[string]interface{}
+}
+
+// Seed creates resources by the given names in the default namespace
+func (resource Resource) Seed(t *testing.T, app *fiber.App, names ...string) {
+	for _, name := range names {
+		resp, _ := Request(t, app, http.MethodPost, resource.Path, resource.Body(name))
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+}
+
+// CRUD runs the checks shared by all protocols resources handlers
+// create, get, update, patch, list, count, delete and watch of the resource
+func CRUD(t *testing.T, resource Resource) {
+	t.Run("Create", func(t *testing.T) {
+		app := resource.NewApp(t)
+
+		resp, body := Request(t, app, http.MethodPost, resource.Path, resource.Body("my-node"))
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		dto := map[string]interface{}{}
+		Decode(t, body, &dto)
+		assert.Equal(t, "my-node", dto["name"])
+		assert.Equal(t, "default", dto["namespace"])
+
+		resp, body = Request(t, app, http.MethodPost, resource.Path, resource.Body("my-node"))
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, "ALREADY_EXISTS", body.Code)
+
+		resp, body = Request(t, app, http.MethodPost, resource.Path, resource.Body("Invalid Name"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, body.Validations, "name")
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		app := resource.NewApp(t)
+		resource.Seed(t, app, "my-node")
+
+		resp, body := Request(t, app, http.MethodGet, resource.Path+"/my-node", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get(fiber.HeaderETag))
+
+		dto := map[string]interface{}{}
+		Decode(t, body, &dto)
+		assert.Equal(t, "my-node", dto["name"])
+
+		resp, body = Request(t, app, http.MethodGet, resource.Path+"/unknown", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "NODE_NOT_FOUND", body.Code)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		app := resource.NewApp(t)
+		resource.Seed(t, app, "my-node")
+
+		resp, body := Request(t, app, http.MethodPut, resource.Path+"/my-node", map[string]interface{}{"cpu": "2"})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		dto := map[string]interface{}{}
+		Decode(t, body, &dto)
+		assert.Equal(t, "2", dto["cpu"])
+
+		resp, body = Request(t, app, http.MethodPut, resource.Path+"/my-node", map[string]interface{}{"cpu": "two"})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, body.Validations, "cpu")
+	})
+
+	t.Run("Patch", func(t *testing.T) {
+		app := resource.NewApp(t)
+		resource.Seed(t, app, "my-node")
+
+		req := NewRequest(t, http.MethodPatch, resource.Path+"/my-node", map[string]interface{}{"memory": "4Gi"})
+		req.Header.Set(fiber.HeaderContentType, "application/merge-patch+json")
+		resp, body := Send(t, app, req)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		dto := map[string]interface{}{}
+		Decode(t, body, &dto)
+		assert.Equal(t, "4Gi", dto["memory"])
+	})
+
+	t.Run("List", func(t *testing.T) {
+		app := resource.NewApp(t)
+		resource.Seed(t, app, "node-2", "node-1")
+
+		other := resource.Body("node-3")
+		other["namespace"] = "kotal"
+		resp, _ := Request(t, app, http.MethodPost, resource.Path, other)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		resp, body := Request(t, app, http.MethodGet, resource.Path+"?sort=name&order=asc", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+
+		dtos := []map[string]interface{}{}
+		Decode(t, body, &dtos)
+		if assert.Len(t, dtos, 2) {
+			assert.Equal(t, "node-1", dtos[0]["name"])
+			assert.Equal(t, "node-2", dtos[1]["name"])
+		}
+
+		resp, _ = Request(t, app, http.MethodGet, resource.Path+"?namespace="+sharedHandlers.AllNamespaces, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "3", resp.Header.Get("X-Total-Count"))
+	})
+
+	t.Run("Count", func(t *testing.T) {
+		app := resource.NewApp(t)
+		resource.Seed(t, app, "node-1", "node-2")
+
+		resp, _ := Request(t, app, http.MethodHead, resource.Path, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		app := resource.NewApp(t)
+		resource.Seed(t, app, "my-node")
+
+		resp, _ := Request(t, app, http.MethodDelete, resource.Path+"/my-node", nil)
+		assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, _ = Request(t, app, http.MethodGet, resource.Path+"/my-node", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		app := resource.NewApp(t)
+		address := Listen(t, app)
+
+		// the stream is sent once its first event is written
+		events := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + address + resource.Path + "?watch=true")
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			assert.Equal(t, "text/event-stream", resp.Header.Get(fiber.HeaderContentType))
+
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+					events <- strings.TrimPrefix(line, "data: ")
+					return
+				}
+			}
+		}()
+
+		// resources are created until one is received, because resources created before the watch starts aren't sent
+		timeout := time.After(5 * time.Second)
+		for i := 0; ; i++ {
+			select {
+			case data := <-events:
+				event := struct {
+					Type   string                 `json:"type"`
+					Object map[string]interface{} `json:"object"`
+				}{}
+				assert.Nil(t, json.Unmarshal([]byte(data), &event))
+				assert.Equal(t, "ADDED", event.Type)
+				assert.Regexp(t, "^node-", event.Object["name"])
+				return
+			case <-timeout:
+				t.Fatal("watch event wasn't received")
+			case <-time.After(100 * time.Millisecond):
+				resource.Seed(t, app, fmt.Sprintf("node-%d", i))
+			}
+		}
+	})
+}
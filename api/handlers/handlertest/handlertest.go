@@ -0,0 +1,97 @@
+// Package handlertest provides helpers for testing the handlers using in memory k8s clients
+package handlertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Response is the decoded json body of handler response
+// Code and RequestID are set if the response is error, Validations are set if it's validation error
+type Response struct {
+	Data        json.RawMessage        `json:"data"`
+	Meta        map[string]interface{} `json:"meta"`
+	Code        string                 `json:"code"`
+	RequestID   string                 `json:"requestId"`
+	Validations map[string]string      `json:"validations"`
+}
+
+// Request sends request with the given json body to the app
+// returns the response and its decoded body, body is empty if the response isn't json
+func Request(t *testing.T, app *fiber.App, method, target string, body interface{}) (*http.Response, *Response) {
+	return Send(t, app, NewRequest(t, method, target, body))
+}
+
+// NewRequest returns request with the given json body
+func NewRequest(t *testing.T, method, target string, body interface{}) *http.Request {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		assert.Nil(t, err)
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	if body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	return req
+}
+
+// Send sends the request to the app and returns the response and its decoded body
+func Send(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, *Response) {
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+
+	decoded := &Response{}
+	if payload, _ := io.ReadAll(resp.Body); len(payload) != 0 && resp.Header.Get(fiber.HeaderContentType) == fiber.MIMEApplicationJSON {
+		assert.Nil(t, json.Unmarshal(payload, decoded))
+	}
+
+	return resp, decoded
+}
+
+// Decode decodes the response data into the given dto
+func Decode(t *testing.T, response *Response, dto interface{}) {
+	assert.Nil(t, json.Unmarshal(response.Data, dto))
+}
+
+// Listen serves the app on local port until the test ends and returns its address
+func Listen(t *testing.T, app *fiber.App) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(listener)
+	// the app isn't shut down, because shutdown waits for the streams to notice their clients have disconnected
+	t.Cleanup(func() { listener.Close() })
+
+	return listener.Addr().String()
+}
+
+// WebsocketError connects to the app websocket at the target and returns the error written as its first message
+func WebsocketError(t *testing.T, app *fiber.App, target string) *restErrors.RestErr {
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+Listen(t, app)+target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	restErr := &restErrors.RestErr{}
+	if err := conn.ReadJSON(restErr); err != nil {
+		t.Fatal(err)
+	}
+	return restErr
+}
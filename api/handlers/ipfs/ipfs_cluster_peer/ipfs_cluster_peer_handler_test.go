@@ -1 +1,78 @@
 package ipfs_cluster_peer
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/ipfs/ipfs_cluster_peer"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var clusterPeers = handlertest.Resource{
+	Path:   "/clusterpeers",
+	NewApp: newTestApp,
+	Body: func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":         name,
+			"peerEndpoint": "/dns4/my-peer/tcp/5001",
+		}
+	},
+}
+
+// newTestApp returns app serving the cluster peers routes using empty in memory k8s client
+func newTestApp(t *testing.T) *fiber.App {
+	handler := NewHandler(ipfs_cluster_peer.NewIpfsClusterPeerService(fake.NewClientService()))
+
+	app := fiber.New()
+	router := app.Group("/clusterpeers")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateClusterPeerExist, handler.Get)
+	router.Put("/:name", handler.ValidateClusterPeerExist, handler.Update)
+	router.Patch("/:name", handler.ValidateClusterPeerExist, handler.Patch)
+	router.Delete("/:name", handler.ValidateClusterPeerExist, handler.Delete)
+
+	return app
+}
+
+func TestCRUD(t *testing.T) {
+	handlertest.CRUD(t, clusterPeers)
+}
+
+func TestCrossFields(t *testing.T) {
+	app := newTestApp(t)
+
+	// id and private key are provided together
+	body := clusterPeers.Body("my-peer")
+	body["id"] = "12D3KooWBcEtY8GH4mNkri9kM3haeWhEXtQV7mi81ErWrqLYGuiq"
+	resp, resBody := handlertest.Request(t, app, http.MethodPost, "/clusterpeers", body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resBody.Validations, "privatekeySecretName")
+
+	body = clusterPeers.Body("my-peer")
+	body["privatekeySecretName"] = "my-cluster-key"
+	resp, resBody = handlertest.Request(t, app, http.MethodPost, "/clusterpeers", body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resBody.Validations, "id")
+
+	body["id"] = "12D3KooWBcEtY8GH4mNkri9kM3haeWhEXtQV7mi81ErWrqLYGuiq"
+	body["consensus"] = "raft"
+	resp, resBody = handlertest.Request(t, app, http.MethodPost, "/clusterpeers", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto := ipfs_cluster_peer.ClusterPeerDto{}
+	handlertest.Decode(t, resBody, &dto)
+	assert.Equal(t, "12D3KooWBcEtY8GH4mNkri9kM3haeWhEXtQV7mi81ErWrqLYGuiq", dto.ID)
+	assert.Equal(t, "my-cluster-key", dto.PrivatekeySecretName)
+	assert.Equal(t, "raft", dto.Consensus)
+	assert.Equal(t, "/dns4/my-peer/tcp/5001", dto.PeerEndpoint)
+
+	body = clusterPeers.Body("other-peer")
+	body["consensus"] = "paxos"
+	resp, resBody = handlertest.Request(t, app, http.MethodPost, "/clusterpeers", body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resBody.Validations, "consensus")
+}
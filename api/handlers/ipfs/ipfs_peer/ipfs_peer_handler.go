@@ -3,16 +3,23 @@
 package ipfs_peer
 
 import (
+	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/ipfs/ipfs_peer"
+	"github.com/kotalco/api/pkg/audit"
 	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/patch"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"net/http"
-	"sort"
-	"strconv"
 )
 
 const (
@@ -21,110 +28,198 @@ const (
 	defaultNamespace = "default"
 )
 
-var service = ipfs_peer.NewIpfsPeerService()
+// Handler serves ipfs peers api calls
+type Handler struct {
+	service ipfs_peer.IService
+}
+
+// NewHandler returns ipfs peers handler using the given service
+func NewHandler(service ipfs_peer.IService) *Handler {
+	return &Handler{service: service}
+}
 
 // Get gets a single IPFS peer by name
 // 1-get the node validated from ValidatePeerExist method
 // 2-marshall node to dto and format the response
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	peer := c.Locals("peer").(*ipfsv1alpha1.Peer)
 
+	sharedHandlers.SetETag(c, peer)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(ipfs_peer.PeerDto).FromIPFSPeer(peer)))
 }
 
 // List returns all IPFS peers
-// 1-get the pagination qs default to 0
-// 2-call service to return peers list
-// 3-make the pagination
-// 3-marshall peers to the dto struct and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page"))
-
-	peers, err := service.List(c.Query(namespaceKeyword, defaultNamespace))
+// watch=true qs streams their changes instead, see Watch
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return peers matching the selectors
+// 3-marshall peers to dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	if sharedHandlers.IsWatch(c) {
+		return handler.Watch(c)
+	}
+
+	listQuery, err := query.Parse(c, ipfs_peer.PeerDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", len(peers.Items)))
+	peers, err := handler.service.List(c.UserContext(), sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace)), listQuery.PageListOptions()...)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
 
-	start, end := shared.Page(uint(len(peers.Items)), uint(page))
-	sort.Slice(peers.Items[:], func(i, j int) bool {
-		return peers.Items[j].CreationTimestamp.Before(&peers.Items[i].CreationTimestamp)
-	})
+	dtos := new(ipfs_peer.PeerListDto).FromIPFSPeer(peers.Items)
+	listQuery.Apply(&dtos)
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(ipfs_peer.PeerListDto).FromIPFSPeer(peers.Items[start:end])))
+	return sharedHandlers.SendList(c, listQuery, dtos, peers.ListMeta)
 }
 
 // Create creates IPFS peer from spec
-// 1-Todo validate request body and return validation error
+// 1-validate request body and return validation error
 // 2-call  ipfs peer  service to create ipfs peer
 // 2-marshall node to dto and format the response
-func Create(c *fiber.Ctx) error {
+func (handler *Handler) Create(c *fiber.Ctx) error {
 	dto := new(ipfs_peer.PeerDto)
 
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
 	}
 
-	peer, err := service.Create(dto)
+	if err := validation.Validate(dto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	peer, err := handler.service.Create(c.UserContext(), dto)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	audit.Created(c, peer)
+
 	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(ipfs_peer.PeerDto).FromIPFSPeer(peer)))
 }
 
+// Watch streams IPFS peers changes as server-sent events
+// 1-get the namespace qs default to default namespace
+// 2-call service to watch changes after the given resource version
+// 3-marshall changed IPFS peers to dto
+func (handler *Handler) Watch(c *fiber.Ctx) error {
+	namespace := sharedHandlers.ListNamespace(utils.CopyString(c.Query(namespaceKeyword, defaultNamespace)))
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		return handler.service.Watch(ctx, namespace, resourceVersion)
+	}
+
+	return sharedHandlers.Watch(c, watchFunc, func(obj runtime.Object) interface{} {
+		return new(ipfs_peer.PeerDto).FromIPFSPeer(obj.(*ipfsv1alpha1.Peer))
+	})
+}
+
 // Delete deletes IPFS peer by name
 // 1-get node from locals which checked and assigned by ValidatePeerExist
 // 2-call service to delete the node
 // 3-return ok if deleted with no errors
-func Delete(c *fiber.Ctx) error {
+func (handler *Handler) Delete(c *fiber.Ctx) error {
 	peer := c.Locals("peer").(*ipfsv1alpha1.Peer)
 
-	err := service.Delete(peer)
+	err := handler.service.Delete(c.UserContext(), peer)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
 
 // Update updates IPFS peer by name from spec
-// 1-todo validate request body and return validation errors if exits
+// 1-validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidatePeerExist
 // 3-call ipfs peer  service to update node which returns *ipfsv1alpha1.Peer
 // 4-marshall node to node dto and format the response
-func Update(c *fiber.Ctx) error {
+func (handler *Handler) Update(c *fiber.Ctx) error {
 	dto := new(ipfs_peer.PeerDto)
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	peer := c.Locals("peer").(*ipfsv1alpha1.Peer)
 
-	peer, err := service.Update(dto, peer)
+	peer, err := handler.service.Update(c.UserContext(), dto, peer)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	sharedHandlers.SetETag(c, peer)
+
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(ipfs_peer.PeerDto).FromIPFSPeer(peer)))
+}
+
+// Patch patches a single ipfs_peer resource by name from json merge patch or json patch document
+// 1-get peer from locals which checked and assigned by ValidateNodeExist
+// 2-apply the patch document to the peer dto representation, removed fields are reset to the operator defaults
+// 3-validate the patched dto and return validation errors if exits
+// 4-call service to patch the peer and format the response
+func (handler *Handler) Patch(c *fiber.Ctx) error {
+	peer := c.Locals("peer").(*ipfsv1alpha1.Peer)
+
+	dto := new(ipfs_peer.PeerDto).FromIPFSPeer(peer)
+	if err := patch.Apply(c.Get(fiber.HeaderContentType), c.Body(), dto); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	peer, err := handler.service.Patch(c.UserContext(), dto, peer)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	sharedHandlers.SetETag(c, peer)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(ipfs_peer.PeerDto).FromIPFSPeer(peer)))
 }
 
 // Count returns total number of peers
-// 1-call  service to get length of exiting peers items
-// 2-create X-Total-Count header with the length
-// 3-return
-func Count(c *fiber.Ctx) error {
-	length, err := service.Count(c.Query(namespaceKeyword, defaultNamespace))
+// 1-parse the label selectors and filters qs
+// 2-call service to count peers, or list and filter them if the qs has dto filters
+// 3-create X-Total-Count header with the length
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, ipfs_peer.PeerDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	namespace := sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace))
+
+	var length int
+	if listQuery.HasFilters() {
+		peers, err := handler.service.List(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+
+		dtos := new(ipfs_peer.PeerListDto).FromIPFSPeer(peers.Items)
+		listQuery.Apply(&dtos)
+		length = len(dtos)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
+		length = *count
 	}
 
 	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", *length))
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
 
 	return c.SendStatus(http.StatusOK)
 }
@@ -133,18 +228,23 @@ func Count(c *fiber.Ctx) error {
 // 1-call service to check if node exits
 // 2-return 404 if it's not
 // 3-save the peer to local with the key peer to be used by the other handlers
-func ValidatePeerExist(c *fiber.Ctx) error {
+func (handler *Handler) ValidatePeerExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 	}
 
-	peer, err := service.Get(nameSpacedName)
+	peer, err := handler.service.Get(c.UserContext(), nameSpacedName)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, peer); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	c.Locals("peer", peer)
+	audit.Before(c, peer, new(ipfs_peer.PeerDto).FromIPFSPeer(peer))
 
 	return c.Next()
 }
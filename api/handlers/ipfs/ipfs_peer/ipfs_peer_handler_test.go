@@ -1 +1,73 @@
 package ipfs_peer
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	"github.com/kotalco/api/internal/ipfs/ipfs_peer"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var peers = handlertest.Resource{
+	Path:   "/peers",
+	NewApp: newTestApp,
+	Body: func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":         name,
+			"initProfiles": []string{"server"},
+		}
+	},
+}
+
+// newTestApp returns app serving the peers routes using empty in memory k8s client
+func newTestApp(t *testing.T) *fiber.App {
+	handler := NewHandler(ipfs_peer.NewIpfsPeerService(fake.NewClientService()))
+
+	app := fiber.New()
+	router := app.Group("/peers")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidatePeerExist, handler.Get)
+	router.Put("/:name", handler.ValidatePeerExist, handler.Update)
+	router.Patch("/:name", handler.ValidatePeerExist, handler.Patch)
+	router.Delete("/:name", handler.ValidatePeerExist, handler.Delete)
+
+	return app
+}
+
+func TestCRUD(t *testing.T) {
+	handlertest.CRUD(t, peers)
+}
+
+func TestProfiles(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := handlertest.Request(t, app, http.MethodPost, "/peers", map[string]interface{}{
+		"name":         "my-peer",
+		"initProfiles": []string{"server", "unknown"},
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body.Validations, "initProfiles[1]")
+
+	peers.Seed(t, app, "my-peer")
+
+	resp, body = handlertest.Request(t, app, http.MethodPut, "/peers/my-peer", map[string]interface{}{
+		"profiles": []string{"lowpower"},
+		"routing":  "dhtclient",
+	})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dto := ipfs_peer.PeerDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, []string{"lowpower"}, dto.Profiles)
+	assert.Equal(t, "dhtclient", dto.Routing)
+	// init profiles are applied once at peer creation
+	assert.Equal(t, []string{"server"}, dto.InitProfiles)
+
+	resp, body = handlertest.Request(t, app, http.MethodPut, "/peers/my-peer", map[string]interface{}{"routing": "unknown"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body.Validations, "routing")
+}
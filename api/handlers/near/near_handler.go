@@ -2,23 +2,26 @@ package near
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"github.com/gofiber/websocket/v2"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
 	"github.com/kotalco/api/internal/near"
+	"github.com/kotalco/api/pkg/audit"
 	restErrors "github.com/kotalco/api/pkg/errors"
 	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/patch"
+	"github.com/kotalco/api/pkg/query"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	nearv1alpha1 "github.com/kotalco/kotal/apis/near/v1alpha1"
 	"github.com/ybbus/jsonrpc/v2"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"net/http"
-	"os"
-	"sort"
-	"strconv"
-	"time"
 )
 
 const (
@@ -27,195 +30,235 @@ const (
 	defaultNamespace = "default"
 )
 
-var (
-	k8sClient = k8s.NewClientService()
-	service   = near.NewNearService()
-)
+// Handler serves NEAR nodes api calls
+type Handler struct {
+	service   near.IService
+	k8sClient k8s.K8sClientServiceInterface
+	rpcClient *http.Client
+}
+
+// NewHandler returns NEAR nodes handler using the given service and k8s client
+// rpcClient is the http client used to call the nodes JSON-RPC servers
+func NewHandler(service near.IService, k8sClient k8s.K8sClientServiceInterface, rpcClient *http.Client) *Handler {
+	return &Handler{service: service, k8sClient: k8sClient, rpcClient: rpcClient}
+}
 
 // Get gets a single NEAR node by name
 // 1-get the node validated from ValidateNodeExist method
 // 2-marshall node to dto and format the response
-func Get(c *fiber.Ctx) error {
+func (handler *Handler) Get(c *fiber.Ctx) error {
 	node := c.Locals("node").(*nearv1alpha1.Node)
 
+	sharedHandlers.SetETag(c, node)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(near.NearDto).FromNEARNode(node)))
 }
 
 // List returns all NEAR nodes
-// 1-get the pagination qs default to 0
-// 2-call service to return node models
-// 3-make the pagination
-// 3-marshall nodes  to near dto and format the response using NewResponse
-func List(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page"))
-
-	nodes, err := service.List(c.Query(namespaceKeyword, defaultNamespace))
+// watch=true qs streams their changes instead, see Watch
+// 1-parse the label selectors, filters, sorting and pagination qs
+// 2-call service to return nodes matching the selectors
+// 3-marshall nodes to dto then filter and sort them
+// 4-send the requested page with its pagination metadata
+func (handler *Handler) List(c *fiber.Ctx) error {
+	if sharedHandlers.IsWatch(c) {
+		return handler.Watch(c)
+	}
+
+	listQuery, err := query.Parse(c, near.NearDto{})
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", len(nodes.Items)))
+	nodes, err := handler.service.List(c.UserContext(), sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace)), listQuery.PageListOptions()...)
+	if err != nil {
+		return restErrors.Send(c, err)
+	}
 
-	start, end := shared.Page(uint(len(nodes.Items)), uint(page))
-	sort.Slice(nodes.Items[:], func(i, j int) bool {
-		return nodes.Items[j].CreationTimestamp.Before(&nodes.Items[i].CreationTimestamp)
-	})
+	dtos := new(near.NearListDto).FromNEARNode(nodes.Items)
+	listQuery.Apply(&dtos)
 
-	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(near.NearListDto).FromNEARNode(nodes.Items[start:end])))
+	return sharedHandlers.SendList(c, listQuery, dtos, nodes.ListMeta)
 }
 
 // Create creates NEAR node from spec
 // Create creates near node from spec
-// 1-Todo validate request body and return validation error
+// 1-validate request body and return validation error
 // 2-call near service to create near node
 // 2-marshall node to dto and format the response
-func Create(c *fiber.Ctx) error {
+func (handler *Handler) Create(c *fiber.Ctx) error {
 	dto := new(near.NearDto)
 
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
 	}
 
-	node, err := service.Create(dto)
+	if err := validation.Validate(dto, validation.Create); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	node, err := handler.service.Create(c.UserContext(), dto)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	audit.Created(c, node)
+
 	return c.Status(http.StatusCreated).JSON(shared.NewResponse(new(near.NearDto).FromNEARNode(node)))
 }
 
+// Watch streams NEAR nodes changes as server-sent events
+// 1-get the namespace qs default to default namespace
+// 2-call service to watch changes after the given resource version
+// 3-marshall changed NEAR nodes to dto
+func (handler *Handler) Watch(c *fiber.Ctx) error {
+	namespace := sharedHandlers.ListNamespace(utils.CopyString(c.Query(namespaceKeyword, defaultNamespace)))
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		return handler.service.Watch(ctx, namespace, resourceVersion)
+	}
+
+	return sharedHandlers.Watch(c, watchFunc, func(obj runtime.Object) interface{} {
+		return new(near.NearDto).FromNEARNode(obj.(*nearv1alpha1.Node))
+	})
+}
+
 // Delete deletes NEAR node by name
 // 1-get node from locals which checked and assigned by ValidateNodeExist
 // 2-call near service to delete the node
 // 3-return ok if deleted with no errors
-func Delete(c *fiber.Ctx) error {
+func (handler *Handler) Delete(c *fiber.Ctx) error {
 	node := c.Locals("node").(*nearv1alpha1.Node)
 
-	err := service.Delete(node)
+	err := handler.service.Delete(c.UserContext(), node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
 	return c.SendStatus(http.StatusNoContent)
 }
 
 // Update updates NEAR node by name from spec
-// 1-todo validate request body and return validation errors if exits
+// 1-validate request body and return validation errors if exits
 // 2-get node from locals which checked and assigned by ValidateNodeExist
 // 3-call near service to update node which returns *nearv1alpha1.Node
 // 4-marshall node to node dto and format the response
-func Update(c *fiber.Ctx) error {
+func (handler *Handler) Update(c *fiber.Ctx) error {
 	dto := new(near.NearDto)
 	if err := c.BodyParser(dto); err != nil {
 		badReq := restErrors.NewBadRequestError("invalid request body")
-		return c.Status(badReq.Status).JSON(badReq)
+		return restErrors.Send(c, badReq)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	node := c.Locals("node").(*nearv1alpha1.Node)
 
-	node, err := service.Update(dto, node)
+	node, err := handler.service.Update(c.UserContext(), dto, node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
+	sharedHandlers.SetETag(c, node)
+
 	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(near.NearDto).FromNEARNode(node)))
 }
 
-// Count returns total number of nodes
-// 1-call near service to get exiting node list
-// 2-create X-Total-Count header with the length
-// 3-return
-func Count(c *fiber.Ctx) error {
-	length, err := service.Count(c.Query(namespaceKeyword, defaultNamespace))
+// Patch patches a single near resource by name from json merge patch or json patch document
+// 1-get node from locals which checked and assigned by ValidateNodeExist
+// 2-apply the patch document to the node dto representation, removed fields are reset to the operator defaults
+// 3-validate the patched dto and return validation errors if exits
+// 4-call service to patch the node and format the response
+func (handler *Handler) Patch(c *fiber.Ctx) error {
+	node := c.Locals("node").(*nearv1alpha1.Node)
+
+	dto := new(near.NearDto).FromNEARNode(node)
+	if err := patch.Apply(c.Get(fiber.HeaderContentType), c.Body(), dto); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	if err := validation.Validate(dto, validation.Update); err != nil {
+		return restErrors.Send(c, err)
+	}
+
+	node, err := handler.service.Patch(c.UserContext(), dto, node)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
 	}
 
-	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
-	c.Set("X-Total-Count", fmt.Sprintf("%d", *length))
+	sharedHandlers.SetETag(c, node)
 
-	return c.SendStatus(http.StatusOK)
+	return c.Status(http.StatusOK).JSON(shared.NewResponse(new(near.NearDto).FromNEARNode(node)))
 }
 
-func Stats(c *websocket.Conn) {
-	defer c.Close()
-
-	type Result struct {
-		Error string `json:"error,omitempty"`
-		// network_info call
-		ActivePeersCount       uint `json:"activePeersCount,omitempty"`
-		MaxPeersCount          uint `json:"maxPeersCount,omitempty"`
-		SentBytesPerSecond     uint `json:"sentBytesPerSecond,omitempty"`
-		ReceivedBytesPerSecond uint `json:"receivedBytesPerSecond,omitempty"`
-		// status call
-		LatestBlockHeight   uint `json:"latestBlockHeight,omitempty"`
-		EarliestBlockHeight uint `json:"earliestBlockHeight,omitempty"`
-		Syncing             bool `json:"syncing,omitempty"`
+// Count returns total number of nodes
+// 1-parse the label selectors and filters qs
+// 2-call service to count nodes, or list and filter them if the qs has dto filters
+// 3-create X-Total-Count header with the length
+func (handler *Handler) Count(c *fiber.Ctx) error {
+	listQuery, err := query.Parse(c, near.NearDto{})
+	if err != nil {
+		return restErrors.Send(c, err)
 	}
 
-	// Mock serever
-	if os.Getenv("MOCK") == "true" {
-		var activePeersCount, sentBytesPerSecond, receivedBytesPerSecond, latestBlockHeight, earliestBlockHeight uint
-		for {
-			activePeersCount++
-			sentBytesPerSecond += 100
-			receivedBytesPerSecond += 100
-			latestBlockHeight += 36
-			earliestBlockHeight += 3
-
-			r := &Result{
-				ActivePeersCount:       activePeersCount,
-				MaxPeersCount:          40,
-				SentBytesPerSecond:     sentBytesPerSecond,
-				ReceivedBytesPerSecond: receivedBytesPerSecond,
-				LatestBlockHeight:      latestBlockHeight,
-				EarliestBlockHeight:    earliestBlockHeight,
-				Syncing:                true,
-			}
+	namespace := sharedHandlers.ListNamespace(c.Query(namespaceKeyword, defaultNamespace))
 
-			var msg []byte
-
-			if activePeersCount > 40 {
-				activePeersCount = 10
-				r = &Result{
-					Error: "rpc is not enabled",
-				}
-			}
+	var length int
+	if listQuery.HasFilters() {
+		nodes, err := handler.service.List(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
+		}
 
-			msg, _ = json.Marshal(r)
-			c.WriteMessage(websocket.TextMessage, []byte(msg))
-			time.Sleep(time.Second)
+		dtos := new(near.NearListDto).FromNEARNode(nodes.Items)
+		listQuery.Apply(&dtos)
+		length = len(dtos)
+	} else {
+		count, err := handler.service.Count(c.UserContext(), namespace, listQuery.ListOptions...)
+		if err != nil {
+			return restErrors.Send(c, err)
 		}
+		length = *count
 	}
 
+	c.Set("Access-Control-Expose-Headers", "X-Total-Count")
+	c.Set("X-Total-Count", fmt.Sprintf("%d", length))
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// Stats streams the node stats polled every stats.statsInterval until the client disconnects or the server starts draining
+// JSON-RPC call errors are written to the websocket without closing it
+func (handler *Handler) Stats(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
 	name := c.Params("name")
 	node := &nearv1alpha1.Node{}
 	key := types.NamespacedName{
-		Namespace: "default",
+		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 		Name:      name,
 	}
 
 	for {
 
-		err := k8sClient.Get(context.Background(), key, node)
+		err := handler.k8sClient.Get(ctx, key, node)
 		if errors.IsNotFound(err) {
-			c.WriteJSON(fiber.Map{
-				"error": fmt.Sprintf("node by name %s doesn't exist", name),
-			})
-			return
+			return restErrors.NewNodeNotFoundError(fmt.Sprintf("node by name %s doesn't exist", name))
+		}
+		if err != nil {
+			return restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", name)))
 		}
 
 		if !node.Spec.RPC {
-			c.WriteJSON(fiber.Map{
-				"error": "JSON-RPC server is not enabled",
-			})
-			time.Sleep(time.Second)
+			sharedHandlers.WriteError(c, restErrors.NewRPCDisabledError("JSON-RPC server is not enabled"))
+			if !sharedHandlers.Wait(ctx, sharedHandlers.StatsInterval()) {
+				return nil
+			}
 			continue
 		}
 
-		client := jsonrpc.NewClient(fmt.Sprintf("http://%s:%d", node.Name, node.Spec.RPCPort))
+		client := jsonrpc.NewClientWithOpts(fmt.Sprintf("http://%s:%d", node.Name, node.Spec.RPCPort), &jsonrpc.RPCClientOpts{HTTPClient: handler.rpcClient})
 
 		type NodeStatus struct {
 			SyncInfo struct {
@@ -229,7 +272,11 @@ func Stats(c *websocket.Conn) {
 		nodeStatus := &NodeStatus{}
 		err = client.CallFor(nodeStatus, "status")
 		if err != nil {
-			fmt.Println(err)
+			sharedHandlers.WriteError(c, sharedHandlers.RPCError("near", "status", err))
+			if !sharedHandlers.Wait(ctx, sharedHandlers.StatsInterval()) {
+				return nil
+			}
+			continue
 		}
 
 		type NetworkInfo struct {
@@ -243,7 +290,11 @@ func Stats(c *websocket.Conn) {
 		networkInfo := &NetworkInfo{}
 		err = client.CallFor(networkInfo, "network_info")
 		if err != nil {
-			fmt.Println(err)
+			sharedHandlers.WriteError(c, sharedHandlers.RPCError("near", "network_info", err))
+			if !sharedHandlers.Wait(ctx, sharedHandlers.StatsInterval()) {
+				return nil
+			}
+			continue
 		}
 
 		c.WriteJSON(fiber.Map{
@@ -256,7 +307,9 @@ func Stats(c *websocket.Conn) {
 			"syncing":                nodeStatus.SyncInfo.Syncing,
 		})
 
-		time.Sleep(time.Second)
+		if !sharedHandlers.Wait(ctx, sharedHandlers.StatsInterval()) {
+			return nil
+		}
 	}
 }
 
@@ -264,18 +317,23 @@ func Stats(c *websocket.Conn) {
 // 1-call near service to check if node exits
 // 2-return Not found if it's not
 // 3-save the node to local with the key node to be used by the other handlers
-func ValidateNodeExist(c *fiber.Ctx) error {
+func (handler *Handler) ValidateNodeExist(c *fiber.Ctx) error {
 	nameSpacedName := types.NamespacedName{
 		Name:      c.Params(nameKeyword),
 		Namespace: c.Query(namespaceKeyword, defaultNamespace),
 	}
 
-	node, err := service.Get(nameSpacedName)
+	node, err := handler.service.Get(c.UserContext(), nameSpacedName)
 	if err != nil {
-		return c.Status(err.Status).JSON(err)
+		return restErrors.Send(c, err)
+	}
+
+	if err := sharedHandlers.CheckIfMatch(c, node); err != nil {
+		return restErrors.Send(c, err)
 	}
 
 	c.Locals("node", node)
+	audit.Before(c, node, new(near.NearDto).FromNEARNode(node))
 
 	return c.Next()
 }
@@ -1 +1,88 @@
 package near
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/api/api/handlers/handlertest"
+	sharedHandlers "github.com/kotalco/api/api/handlers/shared"
+	"github.com/kotalco/api/internal/near"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+var nodes = handlertest.Resource{
+	Path:   "/nodes",
+	NewApp: newTestApp,
+	Body: func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"name":    name,
+			"network": "testnet",
+		}
+	},
+}
+
+// newTestApp returns app serving the nodes routes using empty in memory k8s client
+func newTestApp(t *testing.T) *fiber.App {
+	k8sClient := fake.NewClientService()
+	handler := NewHandler(near.NewNearService(k8sClient), k8sClient, http.DefaultClient)
+
+	app := fiber.New()
+	router := app.Group("/nodes")
+	router.Post("/", handler.Create)
+	router.Head("/", handler.Count)
+	router.Get("/", handler.List)
+	router.Get("/:name", handler.ValidateNodeExist, handler.Get)
+	router.Get("/:name/stats", websocket.New(sharedHandlers.Websocket(handler.Stats)))
+	router.Put("/:name", handler.ValidateNodeExist, handler.Update)
+	router.Patch("/:name", handler.ValidateNodeExist, handler.Patch)
+	router.Delete("/:name", handler.ValidateNodeExist, handler.Delete)
+
+	return app
+}
+
+func TestCRUD(t *testing.T) {
+	handlertest.CRUD(t, nodes)
+}
+
+func TestNetwork(t *testing.T) {
+	app := newTestApp(t)
+
+	resp, body := handlertest.Request(t, app, http.MethodPost, "/nodes", map[string]interface{}{"name": "my-node", "network": "mainnet", "archive": true})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	dto := near.NearDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, "mainnet", dto.Network)
+	assert.True(t, dto.Archive)
+
+	resp, body = handlertest.Request(t, app, http.MethodPost, "/nodes", map[string]interface{}{"name": "my-node", "network": "goerli"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, body.Validations, "network")
+}
+
+func TestBootnodes(t *testing.T) {
+	app := newTestApp(t)
+	nodes.Seed(t, app, "my-node")
+
+	bootnode := "ed25519:4k9csx6zMiXy4waUvRMPTkEtAS2RFKLVScocR5HwN53P@34.73.25.182:24567"
+	resp, body := handlertest.Request(t, app, http.MethodPut, "/nodes/my-node", map[string]interface{}{"bootnodes": []string{bootnode}})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dto := near.NearDto{}
+	handlertest.Decode(t, body, &dto)
+	assert.Equal(t, []string{bootnode}, *dto.Bootnodes)
+}
+
+func TestStatsRPCDisabled(t *testing.T) {
+	app := newTestApp(t)
+	nodes.Seed(t, app, "my-node")
+
+	resp, _ := handlertest.Request(t, app, http.MethodPut, "/nodes/my-node", map[string]interface{}{"rpc": false})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	restErr := handlertest.WebsocketError(t, app, "/nodes/my-node/stats")
+	assert.Equal(t, restErrors.CodeRPCDisabled, restErr.Code)
+}
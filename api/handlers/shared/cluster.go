@@ -0,0 +1,50 @@
+package shared
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/logger"
+)
+
+const (
+	// ClusterHeader is the header selecting the cluster of the request resources
+	ClusterHeader = "X-Kotal-Cluster"
+	// ClusterParam is the path parameter selecting the cluster of the request resources
+	// like /api/v1/clusters/:cluster/ethereum/nodes
+	ClusterParam = "cluster"
+)
+
+// Cluster selects the cluster of the request k8s calls using the cluster path parameter or header
+// requests which don't select a cluster are served by the default cluster
+// 1-return not found error if the cluster isn't registered
+// 2-save the cluster name to the request user context used by the services
+// 3-save the cluster name to locals to be used by the websocket handlers
+func Cluster(c *fiber.Ctx) error {
+	name := c.Params(ClusterParam)
+	if name == "" {
+		name = c.Get(ClusterHeader)
+	}
+	if name == "" {
+		return c.Next()
+	}
+	name = utils.CopyString(name)
+
+	_, ok, err := k8s.GetCluster(name)
+	if err != nil {
+		go logger.Error("K8S_CLUSTERS", err)
+		internalErr := restErrors.NewInternalServerError("can't load clusters")
+		return restErrors.Send(c, internalErr)
+	}
+	if !ok {
+		notFoundErr := restErrors.NewNotFoundError(fmt.Sprintf("cluster by name %s doesn't exist", name))
+		return restErrors.Send(c, notFoundErr)
+	}
+
+	c.SetUserContext(k8s.WithCluster(c.UserContext(), name))
+	c.Locals(k8s.ClusterLocalsKey, name)
+
+	return c.Next()
+}
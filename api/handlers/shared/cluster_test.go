@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: testnet
+clusters:
+  - name: testnet
+    cluster:
+      server: https://testnet.kotal.local
+contexts:
+  - name: testnet
+    context:
+      cluster: testnet
+`
+
+func TestCluster(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	assert.Nil(t, os.WriteFile(kubeconfig, []byte(testKubeConfig), 0600))
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBECONFIG", kubeconfig)
+	contexts := &configs.Get().Kubernetes.Contexts
+	defer func(value []string) { *contexts = value }(*contexts)
+	*contexts = []string{"testnet"}
+
+	app := fiber.New()
+	handler := func(c *fiber.Ctx) error {
+		name, _ := k8s.ClusterFromContext(c.UserContext())
+		return c.SendString(name)
+	}
+	app.Use(Cluster)
+	app.Get("/nodes", handler)
+	app.Group("/clusters/:"+ClusterParam, Cluster).Get("/nodes", handler)
+
+	testCases := []struct {
+		path    string
+		header  string
+		status  int
+		cluster string
+	}{
+		{"/nodes", "", http.StatusOK, ""},
+		{"/nodes", "testnet", http.StatusOK, "testnet"},
+		{"/nodes", "mainnet", http.StatusNotFound, ""},
+		{"/clusters/testnet/nodes", "", http.StatusOK, "testnet"},
+		{"/clusters/default/nodes", "", http.StatusOK, "default"},
+		{"/clusters/mainnet/nodes", "", http.StatusNotFound, ""},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+		if testCase.header != "" {
+			req.Header.Set(ClusterHeader, testCase.header)
+		}
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.status, resp.StatusCode, testCase.path)
+		if resp.StatusCode == http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, testCase.cluster, string(body), testCase.path)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package shared
+
+import (
+	"context"
+	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/api/pkg/k8s"
+	"k8s.io/client-go/rest"
+)
+
+// websocketContext returns context for k8s calls made by websocket handlers derived from the given parent
+// the context carries the caller impersonation and the selected cluster saved to locals by the middlewares
+func websocketContext(parent context.Context, c *websocket.Conn) context.Context {
+	ctx := parent
+	if cluster, ok := c.Locals(k8s.ClusterLocalsKey).(string); ok {
+		ctx = k8s.WithCluster(ctx, cluster)
+	}
+	if impersonation, ok := c.Locals(k8s.ImpersonationLocalsKey).(rest.ImpersonationConfig); ok {
+		ctx = k8s.WithImpersonation(ctx, impersonation)
+	}
+	return ctx
+}
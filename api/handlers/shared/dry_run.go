@@ -0,0 +1,34 @@
+package shared
+
+import (
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"strconv"
+)
+
+// DryRunQuery is the query parameter previewing create, update, patch and delete calls without persisting them
+const DryRunQuery = "dryRun"
+
+// DryRun marks the request k8s write calls as dry run if the dryRun query parameter is true
+// dry run calls return the resource defaulted and validated by the operator admission webhooks
+// 1-return bad request error if dryRun isn't a boolean
+// 2-save the dry run flag to the request user context used by the services
+func DryRun(c *fiber.Ctx) error {
+	value := c.Query(DryRunQuery)
+	if value == "" {
+		return c.Next()
+	}
+
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		badReq := restErrors.NewBadRequestError("dryRun must be true or false")
+		return restErrors.Send(c, badReq)
+	}
+
+	if dryRun {
+		c.SetUserContext(k8s.WithDryRun(c.UserContext()))
+	}
+
+	return c.Next()
+}
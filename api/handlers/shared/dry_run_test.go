@@ -0,0 +1,41 @@
+package shared
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	app := fiber.New()
+	app.Post("/", DryRun, func(c *fiber.Ctx) error {
+		return c.SendString(strconv.FormatBool(k8s.IsDryRun(c.UserContext())))
+	})
+
+	testCases := []struct {
+		query  string
+		status int
+		dryRun string
+	}{
+		{"", http.StatusOK, "false"},
+		{"?dryRun=true", http.StatusOK, "true"},
+		{"?dryRun=1", http.StatusOK, "true"},
+		{"?dryRun=false", http.StatusOK, "false"},
+		{"?dryRun=all", http.StatusBadRequest, ""},
+	}
+
+	for _, testCase := range testCases {
+		resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/"+testCase.query, nil))
+		assert.Nil(t, err)
+		assert.EqualValues(t, testCase.status, resp.StatusCode, testCase.query)
+		if testCase.status == http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, testCase.dryRun, string(body), testCase.query)
+		}
+	}
+}
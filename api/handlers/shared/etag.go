@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"strings"
+)
+
+// ETag returns the entity tag of the k8s object derived from its resource version
+func ETag(object metav1.Object) string {
+	return fmt.Sprintf("%q", object.GetResourceVersion())
+}
+
+// SetETag sets the ETag response header of the k8s object
+func SetETag(c *fiber.Ctx, object metav1.Object) {
+	c.Set("Access-Control-Expose-Headers", fiber.HeaderETag)
+	c.Set(fiber.HeaderETag, ETag(object))
+}
+
+// CheckIfMatch returns conflict error if the PUT, PATCH and DELETE request If-Match header doesn't match the k8s object ETag
+// requests without If-Match header are allowed to keep the api backward compatible
+// the object may be a stale informer cache copy, so DELETE matching a version is conditioned on it
+// using the request user context, and the api server rejects it if the object has been modified since
+func CheckIfMatch(c *fiber.Ctx, object metav1.Object) *restErrors.RestErr {
+	if c.Method() != http.MethodPut && c.Method() != http.MethodPatch && c.Method() != http.MethodDelete {
+		return nil
+	}
+
+	ifMatch := c.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return nil
+	}
+
+	etag := ETag(object)
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		// If-Match uses strong comparison, weak tags never match
+		if candidate == "*" {
+			return nil
+		}
+		if candidate == etag {
+			if c.Method() == http.MethodDelete {
+				c.SetUserContext(k8s.WithResourceVersion(c.UserContext(), object.GetResourceVersion()))
+			}
+			return nil
+		}
+	}
+
+	return restErrors.NewConflictError(fmt.Sprintf("%s has been modified, current version is %s", object.GetName(), etag))
+}
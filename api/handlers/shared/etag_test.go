@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckIfMatch(t *testing.T) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", ResourceVersion: "42"}}
+
+	app := fiber.New()
+	handler := func(c *fiber.Ctx) error {
+		if err := CheckIfMatch(c, secret); err != nil {
+			return c.Status(err.Status).JSON(err)
+		}
+		if resourceVersion, ok := k8s.ResourceVersionFromContext(c.UserContext()); ok {
+			c.Set("X-Delete-Precondition", resourceVersion)
+		}
+		SetETag(c, secret)
+		return c.SendStatus(http.StatusOK)
+	}
+	app.Get("/", handler)
+	app.Put("/", handler)
+	app.Patch("/", handler)
+	app.Delete("/", handler)
+
+	testCases := []struct {
+		method  string
+		ifMatch string
+		status  int
+		// precondition is the resource version the delete is conditioned on
+		precondition string
+	}{
+		{http.MethodGet, `"41"`, http.StatusOK, ""},
+		{http.MethodPut, "", http.StatusOK, ""},
+		{http.MethodPut, `"42"`, http.StatusOK, ""},
+		{http.MethodPut, `"41", "42"`, http.StatusOK, ""},
+		{http.MethodPut, "*", http.StatusOK, ""},
+		{http.MethodPut, `"41"`, http.StatusConflict, ""},
+		{http.MethodPatch, `"42"`, http.StatusOK, ""},
+		{http.MethodPatch, `"41"`, http.StatusConflict, ""},
+		{http.MethodDelete, `W/"42"`, http.StatusConflict, ""},
+		{http.MethodDelete, `"42"`, http.StatusOK, "42"},
+		{http.MethodDelete, "*", http.StatusOK, ""},
+		{http.MethodDelete, "", http.StatusOK, ""},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, "/", nil)
+		if testCase.ifMatch != "" {
+			req.Header.Set(fiber.HeaderIfMatch, testCase.ifMatch)
+		}
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		assert.EqualValues(t, testCase.status, resp.StatusCode, "%s If-Match: %s", testCase.method, testCase.ifMatch)
+		if resp.StatusCode == http.StatusOK {
+			assert.EqualValues(t, `"42"`, resp.Header.Get(fiber.HeaderETag))
+		}
+		assert.EqualValues(t, testCase.precondition, resp.Header.Get("X-Delete-Precondition"))
+	}
+}
@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/query"
+	"github.com/kotalco/api/pkg/shared"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// TotalCountHeader is the header carrying the number of resources matching the list query
+const TotalCountHeader = "X-Total-Count"
+
+// SendList sends the requested page of the filtered and sorted dtos with its pagination metadata
+// dtos must be slice of the listed resources dtos and listMeta is the k8s list metadata
+// page pagination slices the dtos and links to the first, prev, next and last pages
+// continue pagination sends all the dtos and links to the next page using k8s continue token
+// total count is sent in X-Total-Count header and meta total if it's known
+func SendList(c *fiber.Ctx, listQuery *query.Query, dtos interface{}, listMeta metav1.ListMeta) error {
+	items := reflect.ValueOf(dtos)
+	meta := shared.ListMeta{Limit: listQuery.Limit}
+	var links []string
+
+	if listQuery.Cursor() {
+		meta.Continue = listMeta.Continue
+		meta.Remaining = listMeta.RemainingItemCount
+		// remaining items count is the total count of the first page only, and isn't accurate if the page is filtered
+		if listQuery.Continue == "" && !listQuery.HasFilters() && (listMeta.RemainingItemCount != nil || listMeta.Continue == "") {
+			total := items.Len()
+			if listMeta.RemainingItemCount != nil {
+				total += int(*listMeta.RemainingItemCount)
+			}
+			meta.Total = &total
+		}
+		if listMeta.Continue != "" {
+			links = append(links, link(c, "next", query.ContinueQuery, listMeta.Continue))
+		}
+	} else {
+		total := items.Len()
+		page := listQuery.Page
+		start, end := shared.PageOfSize(uint(total), page, listQuery.Limit)
+		items = items.Slice(int(start), int(end))
+		meta.Total = &total
+		meta.Page = &page
+
+		lastPage := uint(0)
+		if total > 0 {
+			lastPage = uint(total-1) / listQuery.Limit
+		}
+		links = append(links, link(c, "first", query.PageQuery, "0"))
+		if page > 0 {
+			prevPage := page - 1
+			if prevPage > lastPage {
+				prevPage = lastPage
+			}
+			links = append(links, link(c, "prev", query.PageQuery, strconv.FormatUint(uint64(prevPage), 10)))
+		}
+		if page < lastPage {
+			links = append(links, link(c, "next", query.PageQuery, strconv.FormatUint(uint64(page+1), 10)))
+		}
+		links = append(links, link(c, "last", query.PageQuery, strconv.FormatUint(uint64(lastPage), 10)))
+	}
+
+	if meta.Total != nil {
+		c.Set(fiber.HeaderAccessControlExposeHeaders, TotalCountHeader+", "+fiber.HeaderLink)
+		c.Set(TotalCountHeader, strconv.Itoa(*meta.Total))
+	} else {
+		c.Set(fiber.HeaderAccessControlExposeHeaders, fiber.HeaderLink)
+	}
+
+	if len(links) != 0 {
+		c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+
+	return c.Status(http.StatusOK).JSON(shared.NewListResponse(items.Interface(), meta))
+}
+
+// link returns Link header value of the current request url with the query parameter set to the given value
+func link(c *fiber.Ctx, rel, key, value string) string {
+	values, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	values.Set(key, value)
+	return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, c.BaseURL(), c.Path(), values.Encode(), rel)
+}
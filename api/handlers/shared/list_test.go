@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/query"
+	"github.com/stretchr/testify/assert"
+	"io"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testListDto struct {
+	Name string `json:"name"`
+}
+
+type testListResponse struct {
+	Data []testListDto `json:"data"`
+	Meta struct {
+		Total     *int   `json:"total"`
+		Page      *uint  `json:"page"`
+		Limit     uint   `json:"limit"`
+		Continue  string `json:"continue"`
+		Remaining *int64 `json:"remaining"`
+	} `json:"meta"`
+}
+
+// sendTestList sends list of the given length and list metadata and returns the response
+func sendTestList(t *testing.T, rawQuery string, length int, listMeta metav1.ListMeta) (*http.Response, *testListResponse) {
+	app := fiber.New()
+	app.Get("/nodes", func(c *fiber.Ctx) error {
+		listQuery, err := query.Parse(c, testListDto{})
+		if err != nil {
+			return c.Status(err.Status).JSON(err)
+		}
+		dtos := make([]testListDto, length)
+		for i := range dtos {
+			dtos[i] = testListDto{Name: string(rune('a' + i))}
+		}
+		return SendList(c, listQuery, dtos, listMeta)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "http://kotal.local/nodes?"+rawQuery, nil))
+	assert.Nil(t, err)
+
+	list := &testListResponse{}
+	body, _ := io.ReadAll(resp.Body)
+	assert.Nil(t, json.Unmarshal(body, list))
+
+	return resp, list
+}
+
+func TestSendListPage(t *testing.T) {
+	resp, list := sendTestList(t, "page=1&limit=2", 5, metav1.ListMeta{})
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []testListDto{{"c"}, {"d"}}, list.Data)
+	assert.Equal(t, 5, *list.Meta.Total)
+	assert.EqualValues(t, 1, *list.Meta.Page)
+	assert.EqualValues(t, 2, list.Meta.Limit)
+	assert.Equal(t, "5", resp.Header.Get(TotalCountHeader))
+	assert.Equal(t, `<http://kotal.local/nodes?limit=2&page=0>; rel="first", `+
+		`<http://kotal.local/nodes?limit=2&page=0>; rel="prev", `+
+		`<http://kotal.local/nodes?limit=2&page=2>; rel="next", `+
+		`<http://kotal.local/nodes?limit=2&page=2>; rel="last"`, resp.Header.Get(fiber.HeaderLink))
+}
+
+func TestSendListDefaultPage(t *testing.T) {
+	resp, list := sendTestList(t, "", 12, metav1.ListMeta{})
+
+	assert.Len(t, list.Data, 10)
+	assert.Equal(t, 12, *list.Meta.Total)
+	assert.EqualValues(t, 0, *list.Meta.Page)
+	assert.EqualValues(t, 10, list.Meta.Limit)
+	assert.Equal(t, `<http://kotal.local/nodes?page=0>; rel="first", `+
+		`<http://kotal.local/nodes?page=1>; rel="next", `+
+		`<http://kotal.local/nodes?page=1>; rel="last"`, resp.Header.Get(fiber.HeaderLink))
+}
+
+func TestSendListContinue(t *testing.T) {
+	remaining := int64(3)
+	resp, list := sendTestList(t, "limit=2", 2, metav1.ListMeta{Continue: "token", RemainingItemCount: &remaining})
+
+	assert.Equal(t, []testListDto{{"a"}, {"b"}}, list.Data)
+	assert.Equal(t, 5, *list.Meta.Total)
+	assert.Nil(t, list.Meta.Page)
+	assert.Equal(t, "token", list.Meta.Continue)
+	assert.EqualValues(t, 3, *list.Meta.Remaining)
+	assert.Equal(t, "5", resp.Header.Get(TotalCountHeader))
+	assert.Equal(t, `<http://kotal.local/nodes?continue=token&limit=2>; rel="next"`, resp.Header.Get(fiber.HeaderLink))
+
+	// total count of next pages is unknown
+	resp, list = sendTestList(t, "limit=2&continue=token", 2, metav1.ListMeta{Continue: "next-token", RemainingItemCount: &remaining})
+
+	assert.Nil(t, list.Meta.Total)
+	assert.Empty(t, resp.Header.Get(TotalCountHeader))
+	assert.Equal(t, `<http://kotal.local/nodes?continue=next-token&limit=2>; rel="next"`, resp.Header.Get(fiber.HeaderLink))
+}
@@ -4,39 +4,32 @@ import (
 	"context"
 	"fmt"
 	"github.com/gofiber/websocket/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
 	"github.com/kotalco/api/pkg/k8s"
+	"io"
 	corev1 "k8s.io/api/core/v1"
-	"os"
+	"k8s.io/client-go/rest"
 	"time"
 )
 
 // Logger returns a websocket that emits logs from pod
-func Logger(c *websocket.Conn) {
-	defer c.Close()
-
-	if os.Getenv("MOCK") == "true" {
-		var i int
-		for {
-			i++
-			if i == 10 {
-				return
-			}
-			msg := fmt.Sprintf("%s \n", time.Now().Local())
-			c.WriteMessage(websocket.TextMessage, []byte(msg))
-			time.Sleep(time.Second)
-		}
-	}
-
+// the logs stream is closed by its context, so it stops once the client disconnects or the server starts draining
+// opening the stream has the get deadline, see openStream
+func (handler *PodHandler) Logger(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
 	podLogOptions := corev1.PodLogOptions{
 		Follow: true,
 	}
 
-	podLogRequest := k8s.Clientset().CoreV1().Pods(c.Query("namespace", "default")).GetLogs(fmt.Sprintf("%s-0", c.Params("name")), &podLogOptions)
-
-	stream, err := podLogRequest.Stream(context.TODO())
+	clientset, err := handler.clientset.Clientset(ctx)
 	if err != nil {
-		c.WriteMessage(websocket.TextMessage, []byte(err.Error()))
-		return
+		return restErrors.NewInternalServerError(err.Error())
+	}
+
+	podLogRequest := clientset.CoreV1().Pods(c.Query("namespace", "default")).GetLogs(fmt.Sprintf("%s-0", c.Params("name")), &podLogOptions)
+
+	stream, restErr := openStream(ctx, podLogRequest)
+	if restErr != nil {
+		return restErr
 	}
 	defer stream.Close()
 
@@ -44,13 +37,57 @@ func Logger(c *websocket.Conn) {
 		buf := make([]byte, 1024)
 		numBytes, err := stream.Read(buf)
 		if err != nil {
-			break
+			return nil
 		}
 
 		if numBytes == 0 {
 			continue
 		}
 
-		c.WriteMessage(websocket.TextMessage, buf[:numBytes])
+		if err := c.WriteMessage(websocket.TextMessage, buf[:numBytes]); err != nil {
+			return nil
+		}
+	}
+}
+
+// openStream opens the request stream within kubernetes.getTimeout deadline
+// the stream outlives the deadline, so the deadline cancels the stream context only if it isn't opened in time
+func openStream(ctx context.Context, request *rest.Request) (io.ReadCloser, *restErrors.RestErr) {
+	timeout := k8s.Timeout("get")
+	if timeout == 0 {
+		stream, err := request.Stream(ctx)
+		if err != nil {
+			return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("can't stream pod logs"))
+		}
+		return stream, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	deadline := time.AfterFunc(timeout, cancel)
+
+	stream, err := request.Stream(ctx)
+	if !deadline.Stop() {
+		if stream != nil {
+			stream.Close()
+		}
+		return nil, restErrors.NewGatewayTimeoutError(fmt.Sprintf("pod logs stream wasn't opened within %s", timeout))
+	}
+	if err != nil {
+		cancel()
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("can't stream pod logs"))
 	}
+
+	return &cancelStream{ReadCloser: stream, cancel: cancel}, nil
+}
+
+// cancelStream cancels the stream context once the stream is closed
+type cancelStream struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the stream and cancels its context
+func (stream *cancelStream) Close() error {
+	defer stream.cancel()
+	return stream.ReadCloser.Close()
 }
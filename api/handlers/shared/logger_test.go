@@ -0,0 +1,76 @@
+package shared
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/configs"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// roundTripper serves the rest client calls using the function
+type roundTripper func(req *http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return fn(req)
+}
+
+// logsRequest returns pod logs request served by the given function
+func logsRequest(t *testing.T, fn roundTripper) *rest.Request {
+	restClient, err := rest.RESTClientFor(&rest.Config{
+		Host:    "http://kubernetes",
+		APIPath: "/api",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &corev1.SchemeGroupVersion,
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		},
+		Transport: fn,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return restClient.Get().Namespace("default").Resource("pods").Name("my-node-0").SubResource("log")
+}
+
+func TestOpenStream(t *testing.T) {
+	getTimeout := &configs.Get().Kubernetes.GetTimeout.Duration
+	defer func(timeout time.Duration) { *getTimeout = timeout }(*getTimeout)
+	*getTimeout = 50 * time.Millisecond
+
+	// stream which isn't opened within the get deadline fails with timeout error
+	_, err := openStream(context.Background(), logsRequest(t, func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusGatewayTimeout, err.Status)
+		assert.Equal(t, restErrors.CodeTimeout, err.Code)
+	}
+
+	// opened stream outlives the get deadline
+	stream, err := openStream(context.Background(), logsRequest(t, func(req *http.Request) (*http.Response, error) {
+		reader, writer := io.Pipe()
+		go func() {
+			select {
+			case <-time.After(2 * *getTimeout):
+				writer.Write([]byte("block imported"))
+				writer.Close()
+			case <-req.Context().Done():
+				writer.CloseWithError(req.Context().Err())
+			}
+		}()
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"text/plain"}}, Body: reader, Request: req}, nil
+	}))
+	if assert.Nil(t, err) {
+		defer stream.Close()
+		logs, readErr := io.ReadAll(stream)
+		assert.Nil(t, readErr)
+		assert.Equal(t, "block imported", string(logs))
+	}
+}
@@ -0,0 +1,30 @@
+package shared
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/kotalco/api/pkg/authorization"
+	"github.com/kotalco/api/pkg/metrics"
+	"time"
+)
+
+// Metrics is middleware recording the api calls count and latency by route template, see metrics.ObserveRequest
+// protocol and resource are the permission group and resource saved by the route authorization middleware
+func Metrics(c *fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	status := c.Response().StatusCode()
+	if err != nil {
+		// the error is turned into response by the app error handler after the middlewares return
+		status = fiber.StatusInternalServerError
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		}
+	}
+
+	permission, _ := authorization.PermissionFromCtx(c)
+	metrics.ObserveRequest(utils.CopyString(c.Method()), c.Route().Path, permission.Group, permission.Resource, status, time.Since(start))
+
+	return err
+}
@@ -0,0 +1,40 @@
+package shared
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/authorization"
+	"github.com/kotalco/api/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// scrape returns the metrics in prometheus text format
+func scrape(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(recorder.Body)
+	assert.Nil(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	app := fiber.New()
+	app.Use(Metrics)
+	app.Get("/metrics-test/:name", authorization.For("filecoin", "nodes")("get"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	for _, target := range []string{"/metrics-test/my-node", "/metrics-test/your-node", "/unknown"} {
+		_, err := app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Nil(t, err)
+	}
+
+	body := scrape(t)
+	// calls are counted by route template
+	assert.Contains(t, body, `kotal_api_http_requests_total{method="GET",protocol="filecoin",resource="nodes",route="/metrics-test/:name",status="204"} 2`)
+	assert.Contains(t, body, `kotal_api_http_request_duration_seconds_count{method="GET",protocol="filecoin",resource="nodes",route="/metrics-test/:name"} 2`)
+	assert.Contains(t, body, `status="404"} 1`)
+}
@@ -0,0 +1,13 @@
+package shared
+
+// AllNamespaces is the namespace query parameter value selecting resources of all namespaces
+const AllNamespaces = "*"
+
+// ListNamespace returns the namespace resources are listed, counted and watched in
+// namespace=* qs lists resources of all namespaces which is selected by k8s empty namespace
+func ListNamespace(namespace string) string {
+	if namespace == AllNamespaces {
+		return ""
+	}
+	return namespace
+}
@@ -0,0 +1,25 @@
+package shared
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	restErrors "github.com/kotalco/api/pkg/errors"
+)
+
+// maxRequestIDLength is the max length of the request id sent by the caller, longer ids are replaced
+const maxRequestIDLength = 128
+
+// RequestID middleware tags the request with the caller X-Request-ID header or a generated id
+// the id is saved to locals, sent back in the X-Request-ID response header and in the errors body
+func RequestID(c *fiber.Ctx) error {
+	// the id is copied because websockets and streams outlive the handler
+	requestID := utils.CopyString(c.Get(fiber.HeaderXRequestID))
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		requestID = utils.UUIDv4()
+	}
+
+	c.Set(fiber.HeaderXRequestID, requestID)
+	c.Locals(restErrors.RequestIDLocalsKey, requestID)
+
+	return c.Next()
+}
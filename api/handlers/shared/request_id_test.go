@@ -0,0 +1,53 @@
+package shared
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID)
+	app.Get("/", func(c *fiber.Ctx) error {
+		return restErrors.Send(c, restErrors.NewNodeNotFoundError("node by name my-node doesn't exist"))
+	})
+
+	testCases := []struct {
+		header    string
+		generated bool
+	}{
+		{"", true},
+		{"my-request", false},
+		{strings.Repeat("x", maxRequestIDLength+1), true},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if testCase.header != "" {
+			req.Header.Set(fiber.HeaderXRequestID, testCase.header)
+		}
+
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		requestID := resp.Header.Get(fiber.HeaderXRequestID)
+		if testCase.generated {
+			assert.NotEmpty(t, requestID)
+			assert.NotEqual(t, testCase.header, requestID)
+		} else {
+			assert.Equal(t, testCase.header, requestID)
+		}
+
+		restErr := restErrors.RestErr{}
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&restErr))
+		assert.EqualValues(t, restErrors.CodeNodeNotFound, restErr.Code)
+		assert.Equal(t, requestID, restErr.RequestID)
+	}
+}
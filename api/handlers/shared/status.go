@@ -2,46 +2,31 @@ package shared
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/gofiber/websocket/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
 	"github.com/kotalco/api/pkg/k8s"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
-	"math/rand"
-	"os"
-	"time"
 )
 
-var (
-	k8sClient = k8s.NewClientService()
-)
+// PodHandler streams the nodes pods status and logs over websocket
+type PodHandler struct {
+	k8sClient k8s.K8sClientServiceInterface
+	clientset k8s.ClientsetServiceInterface
+}
+
+// NewPodHandler returns pods handler using the given k8s client and clientset
+func NewPodHandler(k8sClient k8s.K8sClientServiceInterface, clientset k8s.ClientsetServiceInterface) *PodHandler {
+	return &PodHandler{k8sClient: k8sClient, clientset: clientset}
+}
 
 // Status returns a websocket that emits logs from pod
 // Possible values are: NotFound, Pending, PodInitializing, ContainerCreating, Running, Error, Terminating
-func Status(c *websocket.Conn) {
-	defer c.Close()
-
-	if os.Getenv("MOCK") == "true" {
-		statuses := []string{
-			"NotFound",
-			"Pending",
-			"PodInitializing",
-			"ContainerCreating",
-			"Running",
-			"Error",
-			"Terminating",
-		}
-
-		for {
-			i := rand.Intn(len(statuses))
-			c.WriteMessage(websocket.TextMessage, []byte(statuses[i]))
-			time.Sleep(time.Second)
-		}
-	}
-
+// the status is polled every stats.statusInterval until the node is deleted, the client disconnects or the server starts draining
+func (handler *PodHandler) Status(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
 	sts := &appsv1.StatefulSet{}
 	stsKey := types.NamespacedName{
 		Namespace: c.Query("namespace", "default"),
@@ -55,20 +40,24 @@ func Status(c *websocket.Conn) {
 	}
 
 	for {
-		err := k8sClient.Get(context.Background(), stsKey, sts)
+		err := handler.k8sClient.Get(ctx, stsKey, sts)
 		stsNotFound := apierrors.IsNotFound(err)
 
-		err = k8sClient.Get(context.Background(), key, pod)
+		err = handler.k8sClient.Get(ctx, key, pod)
 		if err != nil {
-			if stsNotFound {
-				return
+			if ctx.Err() != nil {
+				return nil
+			} else if stsNotFound {
+				return nil
+			} else if apierrors.IsNotFound(err) {
+				c.WriteMessage(websocket.TextMessage, []byte("NotFound"))
 			} else {
-				if apierrors.IsNotFound(err) {
-					err = errors.New("NotFound")
-				}
-				c.WriteMessage(websocket.TextMessage, []byte(err.Error()))
-				continue
+				WriteError(c, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't get pod by name %s", key.Name))))
 			}
+			if !Wait(ctx, StatusInterval()) {
+				return nil
+			}
+			continue
 		}
 
 		phase := string(pod.Status.Phase)
@@ -83,7 +72,8 @@ func Status(c *websocket.Conn) {
 
 		c.WriteMessage(websocket.TextMessage, []byte(phase))
 
-		time.Sleep(1 * time.Second)
+		if !Wait(ctx, StatusInterval()) {
+			return nil
+		}
 	}
-
 }
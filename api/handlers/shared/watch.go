@@ -0,0 +1,181 @@
+package shared
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/server"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"strconv"
+	"time"
+)
+
+const (
+	// WatchQuery is the list query parameter streaming resources changes instead of listing them
+	WatchQuery = "watch"
+	// ResourceVersionQuery is the query parameter resuming the watch after the given resource version
+	ResourceVersionQuery = "resourceVersion"
+	// lastEventIDHeader is sent by the browsers event source on reconnection with the last received event id
+	lastEventIDHeader = "Last-Event-ID"
+	// heartbeatInterval is the interval of the comments keeping idle streams alive and detecting closed connections
+	heartbeatInterval = 15 * time.Second
+	// errorEvent is the event sent before closing the stream if the watch fails
+	errorEvent = "ERROR"
+)
+
+// WatchFunc starts watching resources changes after the given resource version
+type WatchFunc func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr)
+
+// WatchEvent is resource change event sent to the watch stream
+type WatchEvent struct {
+	Type            watch.EventType `json:"type"`
+	ResourceVersion string          `json:"resourceVersion"`
+	Object          interface{}     `json:"object,omitempty"`
+}
+
+// IsWatch returns true if the list request asks for resources changes using watch=true query parameter
+func IsWatch(c *fiber.Ctx) bool {
+	isWatch, _ := strconv.ParseBool(c.Query(WatchQuery))
+	return isWatch
+}
+
+// Watch streams resources changes as server-sent events until the client disconnects or the server starts draining
+// ADDED, MODIFIED and DELETED events carry the resource dto, BOOKMARK events carry only the resource version
+// every event id is its resource version, so dropped streams are resumed by event source Last-Event-ID header
+// 1-start watching after resourceVersion query parameter or Last-Event-ID header, or from the current state if both are empty
+// 2-return the watch error as json response if the watch can't be started
+// 3-stream events, restarting the watch if it's closed by the api server or its resource version is expired
+func Watch(c *fiber.Ctx, watchFunc WatchFunc, toDto func(obj runtime.Object) interface{}) error {
+	// request values are copied because the stream outlives the handler
+	resourceVersion := utils.CopyString(c.Get(lastEventIDHeader, c.Query(ResourceVersionQuery)))
+	requestID, _ := c.Locals(restErrors.RequestIDLocalsKey).(string)
+
+	// the watch is stopped by cancelling its context once the stream ends
+	ctx, cancel := context.WithCancel(c.UserContext())
+
+	watcher, err := watchFunc(ctx, resourceVersion)
+	if err != nil {
+		cancel()
+		return restErrors.Send(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// draining server ends the stream, and the client resumes it from another replica using Last-Event-ID header
+	drain, untrack := context.Background(), func() {}
+	if drainer, _ := c.Locals(drainerLocalsKey).(*server.Drainer); drainer != nil {
+		// watch opened once the server has started draining isn't tracked and ends right away
+		drain = drainer.Context()
+		untrack, _ = drainer.Track()
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer untrack()
+		defer cancel()
+		stream := watchStream{w: w, watchFunc: watchFunc, toDto: toDto, resourceVersion: resourceVersion, requestID: requestID, drain: drain}
+		stream.run(ctx, watcher)
+	})
+
+	return nil
+}
+
+// watchStream writes watch events to the server-sent events stream
+type watchStream struct {
+	w               *bufio.Writer
+	watchFunc       WatchFunc
+	toDto           func(obj runtime.Object) interface{}
+	resourceVersion string
+	// requestID tags the error event sent if the watch fails
+	requestID string
+	// drain is done once the server starts draining
+	drain context.Context
+}
+
+// run streams the watcher events until the client disconnects, the server starts draining or the watch can't be restarted
+func (stream *watchStream) run(ctx context.Context, watcher watch.Interface) {
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-stream.drain.Done():
+			watcher.Stop()
+			return
+		case <-heartbeat.C:
+			if !stream.write(": heartbeat\n\n") {
+				watcher.Stop()
+				return
+			}
+		case event, ok := <-watcher.ResultChan():
+			if ok && event.Type == watch.Error {
+				err := apiErrors.FromObject(event.Object)
+				if !apiErrors.IsResourceExpired(err) && !apiErrors.IsGone(err) {
+					watcher.Stop()
+					stream.writeError(restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to watch resources")))
+					return
+				}
+				// expired resource version can't be resumed, the watch is restarted from the current state
+				stream.resourceVersion = ""
+				ok = false
+			}
+
+			if !ok {
+				watcher.Stop()
+				var restErr *restErrors.RestErr
+				if watcher, restErr = stream.watchFunc(ctx, stream.resourceVersion); restErr != nil {
+					stream.writeError(restErr)
+					return
+				}
+				continue
+			}
+
+			if !stream.writeEvent(event) {
+				watcher.Stop()
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes the resource change event and returns false if the client has disconnected
+func (stream *watchStream) writeEvent(event watch.Event) bool {
+	object, err := meta.Accessor(event.Object)
+	if err != nil {
+		return true
+	}
+
+	stream.resourceVersion = object.GetResourceVersion()
+
+	watchEvent := WatchEvent{Type: event.Type, ResourceVersion: stream.resourceVersion}
+	if event.Type != watch.Bookmark {
+		watchEvent.Object = stream.toDto(event.Object)
+	}
+
+	data, _ := json.Marshal(watchEvent)
+
+	return stream.write(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", stream.resourceVersion, event.Type, data))
+}
+
+// writeError writes the error event sent before closing the stream
+func (stream *watchStream) writeError(err *restErrors.RestErr) {
+	data, _ := json.Marshal(err.WithRequestID(stream.requestID))
+	stream.write(fmt.Sprintf("event: %s\ndata: %s\n\n", errorEvent, data))
+}
+
+// write writes and flushes the message and returns false if the client has disconnected
+func (stream *watchStream) write(message string) bool {
+	if _, err := stream.w.WriteString(message); err != nil {
+		return false
+	}
+	return stream.w.Flush() == nil
+}
@@ -0,0 +1,127 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newWatchTestApp(watchFunc WatchFunc) *fiber.App {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if !IsWatch(c) {
+			return c.SendStatus(http.StatusOK)
+		}
+		return Watch(c, watchFunc, func(obj runtime.Object) interface{} {
+			return fiber.Map{"name": obj.(*corev1.Secret).Name}
+		})
+	})
+	return app
+}
+
+func secretVersion(name, resourceVersion string) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: resourceVersion}}
+}
+
+func TestWatch(t *testing.T) {
+	var resourceVersions []string
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		resourceVersions = append(resourceVersions, resourceVersion)
+		if len(resourceVersions) > 1 {
+			return nil, restErrors.NewInternalServerError("failed to watch secrets")
+		}
+
+		watcher := watch.NewFakeWithChanSize(4, false)
+		watcher.Add(secretVersion("my-secret", "11"))
+		watcher.Modify(secretVersion("my-secret", "12"))
+		watcher.Action(watch.Bookmark, secretVersion("", "13"))
+		watcher.Delete(secretVersion("my-secret", "14"))
+		watcher.Stop()
+		return watcher, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?watch=true&resourceVersion=9", nil)
+	req.Header.Set(lastEventIDHeader, "10")
+	resp, err := newWatchTestApp(watchFunc).Test(req)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get(fiber.HeaderContentType))
+
+	body, _ := io.ReadAll(resp.Body)
+	messages := strings.Split(strings.TrimSpace(string(body)), "\n\n")
+	assert.Len(t, messages, 5)
+
+	expected := []struct {
+		id        string
+		eventType watch.EventType
+		name      string
+	}{
+		{"11", watch.Added, "my-secret"},
+		{"12", watch.Modified, "my-secret"},
+		{"13", watch.Bookmark, ""},
+		{"14", watch.Deleted, "my-secret"},
+	}
+	for i, e := range expected {
+		lines := strings.Split(messages[i], "\n")
+		assert.Equal(t, "id: "+e.id, lines[0])
+		assert.Equal(t, "event: "+string(e.eventType), lines[1])
+
+		event := struct {
+			Type            watch.EventType   `json:"type"`
+			ResourceVersion string            `json:"resourceVersion"`
+			Object          map[string]string `json:"object"`
+		}{}
+		assert.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &event))
+		assert.Equal(t, e.eventType, event.Type)
+		assert.Equal(t, e.id, event.ResourceVersion)
+		assert.Equal(t, e.name, event.Object["name"])
+	}
+
+	// closed watch is resumed after the last received resource version
+	assert.Equal(t, []string{"10", "14"}, resourceVersions)
+	assert.True(t, strings.HasPrefix(messages[4], "event: ERROR\n"))
+}
+
+func TestWatchError(t *testing.T) {
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		return nil, restErrors.NewForbiddenError("can't watch secrets")
+	}
+
+	resp, err := newWatchTestApp(watchFunc).Test(httptest.NewRequest(http.MethodGet, "/?watch=true", nil))
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestWatchExpiredResourceVersion(t *testing.T) {
+	var resourceVersions []string
+
+	watchFunc := func(ctx context.Context, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+		resourceVersions = append(resourceVersions, resourceVersion)
+		if len(resourceVersions) > 1 {
+			return nil, restErrors.NewInternalServerError("failed to watch secrets")
+		}
+
+		watcher := watch.NewFakeWithChanSize(1, false)
+		watcher.Error(&metav1.Status{Status: metav1.StatusFailure, Code: http.StatusGone, Reason: metav1.StatusReasonExpired})
+		return watcher, nil
+	}
+
+	resp, err := newWatchTestApp(watchFunc).Test(httptest.NewRequest(http.MethodGet, "/?watch=true&resourceVersion=5", nil))
+	assert.Nil(t, err)
+	io.ReadAll(resp.Body)
+
+	// expired watch is restarted from the current state
+	assert.Equal(t, []string{"5", ""}, resourceVersions)
+}
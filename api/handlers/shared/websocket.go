@@ -0,0 +1,147 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/kotalco/api/pkg/authorization"
+	"github.com/kotalco/api/pkg/configs"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/metrics"
+	"github.com/kotalco/api/pkg/server"
+	"time"
+)
+
+const (
+	// drainerLocalsKey is the locals key of the server drainer saved by Drain middleware
+	drainerLocalsKey = "drainer"
+	// closeTimeout is the max duration to wait for writing the close frame and for the client close frame reply
+	closeTimeout = time.Second
+	// closeErrorCodeBase is added to the error status for the close code of failed websockets like 4404
+	closeErrorCodeBase = 4000
+	// drainingReason is the close reason of websockets closed because the server is shutting down
+	drainingReason = "server is shutting down"
+	// endedReason is the close reason of websockets whose stream has ended like logs of deleted pod
+	endedReason = "stream ended"
+)
+
+// WebsocketHandler streams messages to the websocket until the context is done or the stream ends
+// the context is done once the client disconnects or the server starts draining
+// returned error is written to the websocket before closing it
+type WebsocketHandler func(ctx context.Context, c *websocket.Conn) *restErrors.RestErr
+
+// Drain is middleware saving the server drainer to locals, so websockets and watches are closed when the server shuts down
+func Drain(drainer *server.Drainer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(drainerLocalsKey, drainer)
+		return c.Next()
+	}
+}
+
+// Draining returns true if the server has started draining
+func Draining(c *fiber.Ctx) bool {
+	drainer, _ := c.Locals(drainerLocalsKey).(*server.Drainer)
+	return drainer != nil && drainer.Draining()
+}
+
+// Websocket serves the websocket using the handler and closes it with close frame telling the client why
+// 1-count the websocket as open until the handler returns, see metrics.WebsocketOpened, it's closed right away with 1001 if the server is draining
+// 2-read the client messages in the background to reply to control frames and detect disconnection
+// 3-close with 1001 if the server is draining, 4000 + status and the error code if the handler fails, or 1000 if the stream ended
+func Websocket(handler WebsocketHandler) func(c *websocket.Conn) {
+	return func(c *websocket.Conn) {
+		permission, _ := c.Locals(authorization.PermissionKey).(authorization.Permission)
+		defer metrics.WebsocketOpened(permission.Group, permission.Verb)()
+
+		drain := context.Background()
+		if drainer, _ := c.Locals(drainerLocalsKey).(*server.Drainer); drainer != nil {
+			untrack, tracked := drainer.Track()
+			defer untrack()
+			// websockets opened once the server has started draining are closed right away, so the client reconnects to another replica
+			if !tracked {
+				closeWebsocket(c, websocket.CloseGoingAway, drainingReason)
+				return
+			}
+			drain = drainer.Context()
+		}
+
+		ctx, cancel := context.WithCancel(websocketContext(drain, c))
+		defer cancel()
+
+		disconnected := make(chan struct{})
+		go func() {
+			defer close(disconnected)
+			defer cancel()
+			for {
+				if _, _, err := c.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		err := handler(ctx, c)
+
+		select {
+		case <-disconnected:
+			return
+		default:
+		}
+
+		switch {
+		case drain.Err() != nil:
+			closeWebsocket(c, websocket.CloseGoingAway, drainingReason)
+		case err != nil:
+			WriteError(c, err)
+			closeWebsocket(c, closeErrorCodeBase+err.Status, string(err.Code))
+		default:
+			closeWebsocket(c, websocket.CloseNormalClosure, endedReason)
+		}
+
+		// the reader stops once the client replies with its close frame or the close timeout passes
+		// it must stop before returning, because the connection is released once the handler returns
+		c.SetReadDeadline(time.Now().Add(closeTimeout))
+		<-disconnected
+	}
+}
+
+// closeWebsocket sends close frame with the given code and reason
+func closeWebsocket(c *websocket.Conn, code int, reason string) {
+	c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(closeTimeout))
+}
+
+// Wait waits for the interval and returns false if the context is done before it passes
+func Wait(ctx context.Context, interval time.Duration) bool {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// WriteError writes the error to the websocket as json message tagged with the request id
+func WriteError(c *websocket.Conn, err *restErrors.RestErr) error {
+	requestID, _ := c.Locals(restErrors.RequestIDLocalsKey).(string)
+	return c.WriteJSON(err.WithRequestID(requestID))
+}
+
+// RPCError records the failed node JSON-RPC call of the given protocol and returns its upstream unavailable error
+func RPCError(protocol, method string, err error) *restErrors.RestErr {
+	metrics.RPCProbeFailed(protocol, method)
+	message := fmt.Sprintf("JSON-RPC call %s failed: %s", method, err)
+	return restErrors.NewUpstreamUnavailableError(message).WithDetails(map[string]interface{}{"method": method})
+}
+
+// StatsInterval returns the interval of polling nodes JSON-RPC servers for stats set by stats.statsInterval config
+func StatsInterval() time.Duration {
+	return configs.Get().Stats.StatsInterval.Duration
+}
+
+// StatusInterval returns the interval of polling nodes pods status set by stats.statusInterval config
+func StatusInterval() time.Duration {
+	return configs.Get().Stats.StatusInterval.Duration
+}
@@ -0,0 +1,148 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	wsclient "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+// serveWebsocket serves the handler websocket at /ws and returns its url
+func serveWebsocket(t *testing.T, drainer *server.Drainer, handler WebsocketHandler) string {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(Drain(drainer))
+	app.Get("/ws", websocket.New(Websocket(handler)))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(listener)
+	t.Cleanup(func() { app.Shutdown() })
+
+	return "ws://" + listener.Addr().String() + "/ws"
+}
+
+// dial connects to the websocket and returns its messages and close error once it's closed
+func dial(t *testing.T, url string) ([]string, *wsclient.CloseError) {
+	conn, _, err := wsclient.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var messages []string
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			closeErr := &wsclient.CloseError{}
+			if !errors.As(err, &closeErr) {
+				t.Fatalf("websocket isn't closed with close frame: %v", err)
+			}
+			return messages, closeErr
+		}
+		messages = append(messages, string(message))
+	}
+}
+
+func TestWebsocketClose(t *testing.T) {
+	// ended stream is closed normally
+	url := serveWebsocket(t, server.NewDrainer(), func(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
+		c.WriteMessage(websocket.TextMessage, []byte("Running"))
+		return nil
+	})
+	messages, closeErr := dial(t, url)
+	assert.Equal(t, []string{"Running"}, messages)
+	assert.Equal(t, websocket.CloseNormalClosure, closeErr.Code)
+	assert.Equal(t, endedReason, closeErr.Text)
+
+	// failed stream writes the error then closes with its status and code
+	url = serveWebsocket(t, nil, func(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
+		return restErrors.NewNotFoundError("node by name my-node doesn't exist")
+	})
+	messages, closeErr = dial(t, url)
+	if assert.Len(t, messages, 1) {
+		restErr := restErrors.RestErr{}
+		assert.Nil(t, json.Unmarshal([]byte(messages[0]), &restErr))
+		assert.Equal(t, restErrors.CodeNotFound, restErr.Code)
+	}
+	assert.Equal(t, 4404, closeErr.Code)
+	assert.Equal(t, string(restErrors.CodeNotFound), closeErr.Text)
+}
+
+func TestWebsocketDrain(t *testing.T) {
+	drainer := server.NewDrainer()
+	started := make(chan struct{})
+	url := serveWebsocket(t, drainer, func(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
+		close(started)
+		for Wait(ctx, 10*time.Millisecond) {
+			c.WriteMessage(websocket.TextMessage, []byte("Running"))
+		}
+		return nil
+	})
+
+	// drain returns once the websocket is closed
+	drained := make(chan error)
+	go func() {
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		drained <- drainer.Drain(ctx)
+	}()
+
+	_, closeErr := dial(t, url)
+	assert.Nil(t, <-drained)
+	assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+	assert.Equal(t, drainingReason, closeErr.Text)
+}
+
+func TestWebsocketDraining(t *testing.T) {
+	drainer := server.NewDrainer()
+	assert.Nil(t, drainer.Drain(context.Background()))
+
+	served := false
+	url := serveWebsocket(t, drainer, func(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
+		served = true
+		return nil
+	})
+
+	// websocket opened while the server is draining is closed right away without serving it
+	messages, closeErr := dial(t, url)
+	assert.Empty(t, messages)
+	assert.Equal(t, websocket.CloseGoingAway, closeErr.Code)
+	assert.Equal(t, drainingReason, closeErr.Text)
+	assert.False(t, served)
+}
+
+func TestWebsocketDisconnect(t *testing.T) {
+	drainer := server.NewDrainer()
+	stopped := make(chan struct{})
+	url := serveWebsocket(t, drainer, func(ctx context.Context, c *websocket.Conn) *restErrors.RestErr {
+		<-ctx.Done()
+		close(stopped)
+		return nil
+	})
+
+	conn, _, err := wsclient.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+
+	// handler context is done once the client disconnects, without draining the server
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler context isn't done after the client disconnected")
+	}
+	assert.False(t, drainer.Draining())
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	diagnosticsHandlers "github.com/kotalco/api/api/handlers/diagnostics"
+	"github.com/kotalco/api/api/handlers/shared"
+	chainlinkInternal "github.com/kotalco/api/internal/chainlink"
+	clusterInternal "github.com/kotalco/api/internal/cluster"
+	namespaceInternal "github.com/kotalco/api/internal/core/namespace"
+	secretInternal "github.com/kotalco/api/internal/core/secret"
+	storageClassInternal "github.com/kotalco/api/internal/core/storage_class"
+	diagnosticsInternal "github.com/kotalco/api/internal/diagnostics"
+	ethereumInternal "github.com/kotalco/api/internal/ethereum"
+	beaconNodeInternal "github.com/kotalco/api/internal/ethereum2/beacon_node"
+	validatorInternal "github.com/kotalco/api/internal/ethereum2/validator"
+	filecoinInternal "github.com/kotalco/api/internal/filecoin"
+	clusterPeerInternal "github.com/kotalco/api/internal/ipfs/ipfs_cluster_peer"
+	peerInternal "github.com/kotalco/api/internal/ipfs/ipfs_peer"
+	nearInternal "github.com/kotalco/api/internal/near"
+	polkadotInternal "github.com/kotalco/api/internal/polkadot"
+	"github.com/kotalco/api/pkg/audit"
+	"github.com/kotalco/api/pkg/configs"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/openapi"
+	"net/http"
+)
+
+// apiSpec describes the api resources and endpoints and their dtos
+// new resources and endpoints must be registered here to be documented in /api/v1/openapi.json
+var apiSpec = openapi.Generator{
+	Info: openapi.Info{
+		Title:       "Kotal API",
+		Description: "Deploy, manage and monitor blockchain nodes on Kubernetes",
+		Version:     "v1",
+	},
+	Resources: []openapi.Resource{
+		{Path: "/api/v1/chainlink/nodes", Tag: "chainlink", Name: "chainlink node", Dto: chainlinkInternal.ChainlinkDto{}, Watch: true},
+		{Path: "/api/v1/ethereum/nodes", Tag: "ethereum", Name: "ethereum node", Dto: ethereumInternal.EthereumDto{}, Watch: true},
+		{Path: "/api/v1/core/secrets", Tag: "core", Name: "secret", Dto: secretInternal.SecretDto{}},
+		{Path: "/api/v1/core/storageclasses", Tag: "core", Name: "storage class", Dto: storageClassInternal.StorageClassDto{}},
+		{Path: "/api/v1/core/namespaces", Tag: "core", Name: "namespace", Dto: namespaceInternal.NamespaceDto{}},
+		{Path: "/api/v1/ethereum2/beaconnodes", Tag: "ethereum2", Name: "beacon node", Dto: beaconNodeInternal.BeaconNodeDto{}, Watch: true},
+		{Path: "/api/v1/ethereum2/validators", Tag: "ethereum2", Name: "validator", Dto: validatorInternal.ValidatorDto{}, Watch: true},
+		{Path: "/api/v1/filecoin/nodes", Tag: "filecoin", Name: "filecoin node", Dto: filecoinInternal.FilecoinDto{}, Watch: true},
+		{Path: "/api/v1/ipfs/peers", Tag: "ipfs", Name: "ipfs peer", Dto: peerInternal.PeerDto{}, Watch: true},
+		{Path: "/api/v1/ipfs/clusterpeers", Tag: "ipfs", Name: "ipfs cluster peer", Dto: clusterPeerInternal.ClusterPeerDto{}, Watch: true},
+		{Path: "/api/v1/near/nodes", Tag: "near", Name: "near node", Dto: nearInternal.NearDto{}, Watch: true},
+		{Path: "/api/v1/polkadot/nodes", Tag: "polkadot", Name: "polkadot node", Dto: polkadotInternal.PolkadotDto{}, Watch: true},
+	},
+	// probes, clusters, diagnostics, config and audit log routes
+	Endpoints: []openapi.Endpoint{
+		{Method: http.MethodGet, Path: "/healthz", Tag: "health", Summary: "Liveness probe", Dto: diagnosticsHandlers.HealthDto{}, Public: true},
+		{Method: http.MethodGet, Path: "/readyz", Tag: "health", Summary: "Readiness probe, fails if the default cluster can't be reached or the server is shutting down", Dto: diagnosticsHandlers.HealthDto{}, Public: true},
+		{Method: http.MethodGet, Path: "/api/v1/clusters", Tag: "clusters", Summary: "List registered clusters", Dto: clusterInternal.ClusterDto{}, List: true},
+		{Method: http.MethodGet, Path: "/api/v1/clusters/:" + shared.ClusterParam, Tag: "clusters", Summary: "Get registered cluster", Dto: clusterInternal.ClusterDto{}, Parameters: []openapi.Parameter{{
+			Name:        shared.ClusterParam,
+			In:          "path",
+			Description: "registered cluster name",
+			Required:    true,
+			Schema:      &openapi.Schema{Type: "string"},
+		}}},
+		{Method: http.MethodGet, Path: "/api/v1/diagnostics", Tag: "clusters", Summary: "Diagnose cluster", Dto: diagnosticsInternal.DiagnosticsDto{}, Clustered: true},
+		{Method: http.MethodGet, Path: "/api/v1/config", Tag: "config", Summary: "Get effective api server configuration", Dto: configs.Config{}},
+		{Method: http.MethodGet, Path: "/api/v1/audit", Tag: "audit", Summary: "List audit log entries newest first", Dto: audit.Entry{}, List: true, Parameters: []openapi.Parameter{{
+			Name:        "namespace",
+			In:          "query",
+			Description: "namespace of the audited calls",
+			Schema:      &openapi.Schema{Type: "string"},
+		}, {
+			Name:        "since",
+			In:          "query",
+			Description: "RFC3339 timestamp of the oldest entry",
+			Schema:      &openapi.Schema{Type: "string", Format: "date-time"},
+		}}},
+	},
+	BasePath:    "/api/v1",
+	ClusterPath: "/api/v1/clusters/:" + shared.ClusterParam,
+	ErrorDto:    restErrors.RestErr{},
+}
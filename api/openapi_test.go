@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/audit"
+	"github.com/kotalco/api/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// publicDocsPaths are the spec and docs page paths which aren't documented in the spec itself
+var publicDocsPaths = map[string]bool{
+	"/api/v1/openapi.json": true,
+	"/api/v1/docs":         true,
+	"/api/v1/docs/:file":   true,
+}
+
+// newTestApp returns app serving all the routes including the audit log
+func newTestApp() *fiber.App {
+	deps := NewDependencies()
+	store := audit.NewMemoryStore(10)
+	deps.Auditor = audit.NewAuditor(store, store)
+
+	app := fiber.New()
+	MapUrl(app, deps)
+	return app
+}
+
+// TestSpecDocumentsAllRoutes fails if a route is added without being documented
+func TestSpecDocumentsAllRoutes(t *testing.T) {
+	app := newTestApp()
+	doc := apiSpec.Generate(openapi.Routes(app))
+
+	for _, route := range openapi.Routes(app) {
+		switch route.Method {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			// middlewares are registered to all methods stacks
+			continue
+		}
+
+		path := strings.TrimSuffix(route.Path, "/")
+		if publicDocsPaths[path] {
+			continue
+		}
+
+		specPath := path
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				specPath = strings.Replace(specPath, segment, "{"+strings.TrimPrefix(segment, ":")+"}", 1)
+			}
+		}
+
+		item, ok := doc.Paths[specPath]
+		if !assert.True(t, ok, "path %s is not documented", specPath) {
+			continue
+		}
+
+		if route.Method == fiber.MethodHead && autoHead(app, route) {
+			continue
+		}
+		assert.NotNil(t, item.Operation(route.Method), "%s %s is not documented", route.Method, specPath)
+	}
+}
+
+// autoHead returns true if the HEAD route is registered by fiber for GET route of the same path and handlers
+// explicit HEAD routes like counts have their own handlers, fiber appends the GET handlers to them
+func autoHead(app *fiber.App, head *fiber.Route) bool {
+	for _, route := range openapi.Routes(app) {
+		if route.Method != fiber.MethodGet || route.Path != head.Path || len(route.Handlers) != len(head.Handlers) {
+			continue
+		}
+
+		same := true
+		for i := range route.Handlers {
+			if reflect.ValueOf(route.Handlers[i]).Pointer() != reflect.ValueOf(head.Handlers[i]).Pointer() {
+				same = false
+				break
+			}
+		}
+		if same {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSpecDocumentsAllDtoFields fails if a dto field is missing from its schema
+func TestSpecDocumentsAllDtoFields(t *testing.T) {
+	doc := apiSpec.Generate(openapi.Routes(newTestApp()))
+
+	for _, resource := range apiSpec.Resources {
+		dtoType := reflect.TypeOf(resource.Dto)
+		schema, ok := doc.Components.Schemas[dtoType.Name()]
+		if !assert.True(t, ok, "%s schema is not documented", dtoType.Name()) {
+			continue
+		}
+		for _, name := range jsonFields(dtoType) {
+			assert.Contains(t, schema.Properties, name, "%s field %s is not documented", dtoType.Name(), name)
+		}
+	}
+
+	assert.Contains(t, doc.Components.Schemas, openapi.ErrorSchema)
+	list := doc.Paths["/api/v1/ethereum/nodes"].Get
+	assert.Contains(t, list.Responses["200"].Headers, openapi.TotalCountHeader)
+}
+
+func TestSpecHandler(t *testing.T) {
+	app := newTestApp()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	doc := openapi.Document{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&doc))
+	assert.EqualValues(t, openapi.Version, doc.OpenAPI)
+	assert.Contains(t, doc.Paths, "/api/v1/polkadot/nodes/{name}/stats")
+	assert.Contains(t, doc.Paths, "/api/v1/clusters/{cluster}/polkadot/nodes/{name}/stats")
+	assert.Contains(t, doc.Paths, "/healthz")
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+
+	// the docs page assets are served by the api
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/docs/swagger-ui-bundle.js", nil))
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get(fiber.HeaderContentType), "javascript")
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/docs/unknown.js", nil))
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// jsonFields returns json names of the struct fields flattening the embedded structs
+func jsonFields(t reflect.Type) []string {
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := openapi.JSONName(field)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			names = append(names, jsonFields(embedded)...)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -3,9 +3,14 @@ package api
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
+	auditHandlers "github.com/kotalco/api/api/handlers/audit"
 	"github.com/kotalco/api/api/handlers/chainlink"
+	"github.com/kotalco/api/api/handlers/cluster"
+	configHandlers "github.com/kotalco/api/api/handlers/config"
+	"github.com/kotalco/api/api/handlers/core/namespace"
 	"github.com/kotalco/api/api/handlers/core/secret"
 	"github.com/kotalco/api/api/handlers/core/storage_class"
+	diagnosticsHandlers "github.com/kotalco/api/api/handlers/diagnostics"
 	"github.com/kotalco/api/api/handlers/ethereum"
 	"github.com/kotalco/api/api/handlers/ethereum2/beacon_node"
 	"github.com/kotalco/api/api/handlers/ethereum2/validator"
@@ -15,144 +20,252 @@ import (
 	"github.com/kotalco/api/api/handlers/near"
 	"github.com/kotalco/api/api/handlers/polkadot"
 	"github.com/kotalco/api/api/handlers/shared"
+	chainlinkInternal "github.com/kotalco/api/internal/chainlink"
+	clusterInternal "github.com/kotalco/api/internal/cluster"
+	namespaceInternal "github.com/kotalco/api/internal/core/namespace"
+	secretInternal "github.com/kotalco/api/internal/core/secret"
+	storageClassInternal "github.com/kotalco/api/internal/core/storage_class"
+	diagnosticsInternal "github.com/kotalco/api/internal/diagnostics"
+	ethereumInternal "github.com/kotalco/api/internal/ethereum"
+	beaconNodeInternal "github.com/kotalco/api/internal/ethereum2/beacon_node"
+	validatorInternal "github.com/kotalco/api/internal/ethereum2/validator"
+	filecoinInternal "github.com/kotalco/api/internal/filecoin"
+	clusterPeerInternal "github.com/kotalco/api/internal/ipfs/ipfs_cluster_peer"
+	peerInternal "github.com/kotalco/api/internal/ipfs/ipfs_peer"
+	nearInternal "github.com/kotalco/api/internal/near"
+	polkadotInternal "github.com/kotalco/api/internal/polkadot"
+	"github.com/kotalco/api/pkg/authorization"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/openapi"
 )
 
 // MapUrl abstracted function to map and register all the url for the application
 // Used in the main.go
 // helps to keep all the endpoints' definition in one place
 // the only place to interact with handlers and middlewares
-func MapUrl(app *fiber.App, handlers ...fiber.Handler) {
+// services and handlers are created using the given dependencies
+// every route declares its permission using can(verb) which is enforced by the authorization pkg
+func MapUrl(app *fiber.App, deps Dependencies, handlers ...fiber.Handler) {
+	// every request is tagged with request id before the authentication middlewares, so their errors carry it too
+	app.Use(shared.RequestID)
+	// calls are counted by their route template and permission, see /metrics on the metrics port
+	app.Use(shared.Metrics)
+	// websockets and watches are closed once the server starts draining, and readiness probe fails
+	app.Use(shared.Drain(deps.Drainer))
+	// liveness and readiness probes are public and served by the default cluster
+	probes := diagnosticsHandlers.NewHandler(diagnosticsInternal.NewDiagnosticsService(deps.K8sClient, deps.Clientset, deps.Certificates))
+	app.Get("/healthz", probes.Healthz)
+	app.Get("/readyz", probes.Readyz)
 	// routing groups
 	api := app.Group("api")
 	v1 := api.Group("v1")
+	// api docs are public and registered before the authentication middlewares
+	v1.Get("/openapi.json", openapi.SpecHandler(app, apiSpec))
+	v1.Get("/docs", openapi.DocsHandler())
+	v1.Get("/docs/:file", openapi.DocsAssetsHandler())
+	// mutating calls are audited before the authentication middlewares, so rejected calls are recorded too
+	if deps.Auditor != nil {
+		v1.Use(deps.Auditor.Record)
+	}
 	for i := 0; i < len(handlers); i++ {
 		v1.Use(handlers[i])
 	}
+	v1.Use(shared.Cluster)
+	v1.Use(shared.DryRun)
+
+	mapResources(v1, deps)
+
+	//clusters group, resources of every registered cluster are served under /api/v1/clusters/:cluster
+	clusters := v1.Group("clusters")
+	clusterHandler := cluster.NewHandler(clusterInternal.NewClusterService(deps.K8sClient, deps.Clientset))
+	clusters.Get("/", authorization.For("core", "clusters")("list"), clusterHandler.List)
+	clusters.Get("/:"+shared.ClusterParam, authorization.For("core", "clusters")("get"), clusterHandler.Get)
+	mapResources(clusters.Group("/:"+shared.ClusterParam, shared.Cluster), deps)
+
+	//effective configuration of the api server
+	configHandler := configHandlers.NewHandler(configs.Get())
+	v1.Get("/config", authorization.For("core", "config")("get"), configHandler.Get)
+
+	//audit log of the mutating calls of all clusters
+	if deps.Auditor != nil {
+		auditHandler := auditHandlers.NewHandler(deps.Auditor.Store())
+		v1.Get("/audit", authorization.For("core", "audit")("list"), auditHandler.List)
+	}
+}
+
+// mapResources registers the resources routes to the router
+// resources are served by the cluster selected by the router path or the cluster header
+func mapResources(router fiber.Router, deps Dependencies) {
+	pods := shared.NewPodHandler(deps.K8sClient, deps.Clientset)
+	// diagnostics of the api server, kotal custom resources, operator and its webhooks and metrics server
+	diagnosticsHandler := diagnosticsHandlers.NewHandler(diagnosticsInternal.NewDiagnosticsService(deps.K8sClient, deps.Clientset, deps.Certificates))
+	router.Get("/diagnostics", authorization.For("core", "diagnostics")("get"), diagnosticsHandler.Get)
+
 	// chainlink group
-	chainlinkGroup := v1.Group("chainlink")
+	chainlinkGroup := router.Group("chainlink")
 	chainlinkNodes := chainlinkGroup.Group("nodes")
+	can := authorization.For("chainlink", "nodes")
+	chainlinkHandler := chainlink.NewHandler(chainlinkInternal.NewChainLinkService(deps.K8sClient))
 
-	chainlinkNodes.Post("/", chainlink.Create)
-	chainlinkNodes.Head("/", chainlink.Count)
-	chainlinkNodes.Get("/", chainlink.List)
-	chainlinkNodes.Get("/:name", chainlink.ValidateNodeExist, chainlink.Get)
-	chainlinkNodes.Get("/:name/logs", websocket.New(shared.Logger))
-	chainlinkNodes.Get("/:name/status", websocket.New(shared.Status))
-	chainlinkNodes.Put("/:name", chainlink.ValidateNodeExist, chainlink.Update)
-	chainlinkNodes.Delete("/:name", chainlink.ValidateNodeExist, chainlink.Delete)
+	chainlinkNodes.Post("/", can("create"), chainlinkHandler.Create)
+	chainlinkNodes.Head("/", can("list"), chainlinkHandler.Count)
+	chainlinkNodes.Get("/", can("list"), chainlinkHandler.List)
+	chainlinkNodes.Get("/:name", can("get"), chainlinkHandler.ValidateNodeExist, chainlinkHandler.Get)
+	chainlinkNodes.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	chainlinkNodes.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	chainlinkNodes.Put("/:name", can("update"), chainlinkHandler.ValidateNodeExist, chainlinkHandler.Update)
+	chainlinkNodes.Patch("/:name", can("update"), chainlinkHandler.ValidateNodeExist, chainlinkHandler.Patch)
+	chainlinkNodes.Delete("/:name", can("delete"), chainlinkHandler.ValidateNodeExist, chainlinkHandler.Delete)
 
 	//ethereum group
-	ethereumGroup := v1.Group("ethereum")
+	ethereumGroup := router.Group("ethereum")
 	ethereumNodes := ethereumGroup.Group("nodes")
-	ethereumNodes.Post("/", ethereum.Create)
-	ethereumNodes.Head("/", ethereum.Count)
-	ethereumNodes.Get("/", ethereum.List)
-	ethereumNodes.Get("/:name", ethereum.ValidateNodeExist, ethereum.Get)
-	ethereumNodes.Get("/:name/logs", websocket.New(shared.Logger))
-	ethereumNodes.Get("/:name/status", websocket.New(shared.Status))
-	ethereumNodes.Get("/:name/stats", websocket.New(ethereum.Stats))
-	ethereumNodes.Put("/:name", ethereum.ValidateNodeExist, ethereum.Update)
-	ethereumNodes.Delete("/:name", ethereum.ValidateNodeExist, ethereum.Delete)
+	can = authorization.For("ethereum", "nodes")
+	ethereumHandler := ethereum.NewHandler(ethereumInternal.NewEthereumService(deps.K8sClient), deps.RPCClient)
+	ethereumNodes.Post("/", can("create"), ethereumHandler.Create)
+	ethereumNodes.Head("/", can("list"), ethereumHandler.Count)
+	ethereumNodes.Get("/", can("list"), ethereumHandler.List)
+	ethereumNodes.Get("/:name", can("get"), ethereumHandler.ValidateNodeExist, ethereumHandler.Get)
+	ethereumNodes.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	ethereumNodes.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	ethereumNodes.Get("/:name/stats", can("stats"), websocket.New(shared.Websocket(ethereumHandler.Stats)))
+	ethereumNodes.Put("/:name", can("update"), ethereumHandler.ValidateNodeExist, ethereumHandler.Update)
+	ethereumNodes.Patch("/:name", can("update"), ethereumHandler.ValidateNodeExist, ethereumHandler.Patch)
+	ethereumNodes.Delete("/:name", can("delete"), ethereumHandler.ValidateNodeExist, ethereumHandler.Delete)
 
 	//core group
-	coreGroup := v1.Group("core")
+	coreGroup := router.Group("core")
 	//secret group
 	secrets := coreGroup.Group("secrets")
-	secrets.Post("/", secret.Create)
-	secrets.Head("/", secret.Count)
-	secrets.Get("/", secret.List)
-	secrets.Get("/:name", secret.ValidateSecretExist, secret.Get)
-	secrets.Put("/:name", secret.ValidateSecretExist, secret.Update)
-	secrets.Delete("/:name", secret.ValidateSecretExist, secret.Delete)
+	can = authorization.For("core", "secrets")
+	secretHandler := secret.NewHandler(secretInternal.NewSecretService(deps.K8sClient))
+	secrets.Post("/", can("create"), secretHandler.Create)
+	secrets.Head("/", can("list"), secretHandler.Count)
+	secrets.Get("/", can("list"), secretHandler.List)
+	secrets.Get("/:name", can("get"), secretHandler.ValidateSecretExist, secretHandler.Get)
+	secrets.Put("/:name", can("update"), secretHandler.ValidateSecretExist, secretHandler.Update)
+	secrets.Patch("/:name", can("update"), secretHandler.ValidateSecretExist, secretHandler.Patch)
+	secrets.Delete("/:name", can("delete"), secretHandler.ValidateSecretExist, secretHandler.Delete)
 	//storage class group
 	storageClasses := coreGroup.Group("storageclasses")
-	storageClasses.Post("/", storage_class.Create)
-	storageClasses.Get("/", storage_class.List)
-	storageClasses.Get("/:name", storage_class.ValidateStorageClassExist, storage_class.Get)
-	storageClasses.Put("/:name", storage_class.ValidateStorageClassExist, storage_class.Update)
-	storageClasses.Delete("/:name", storage_class.ValidateStorageClassExist, storage_class.Delete)
+	can = authorization.For("core", "storageclasses")
+	storageClassHandler := storage_class.NewHandler(storageClassInternal.NewStorageClassService(deps.K8sClient))
+	storageClasses.Post("/", can("create"), storageClassHandler.Create)
+	storageClasses.Get("/", can("list"), storageClassHandler.List)
+	storageClasses.Get("/:name", can("get"), storageClassHandler.ValidateStorageClassExist, storageClassHandler.Get)
+	storageClasses.Put("/:name", can("update"), storageClassHandler.ValidateStorageClassExist, storageClassHandler.Update)
+	storageClasses.Delete("/:name", can("delete"), storageClassHandler.ValidateStorageClassExist, storageClassHandler.Delete)
+	//namespace group, only namespaces labelled as kotal workspaces are managed
+	namespaces := coreGroup.Group("namespaces")
+	can = authorization.For("core", "namespaces")
+	namespaceHandler := namespace.NewHandler(namespaceInternal.NewNamespaceService(deps.K8sClient))
+	namespaces.Post("/", can("create"), namespaceHandler.Create)
+	namespaces.Head("/", can("list"), namespaceHandler.Count)
+	namespaces.Get("/", can("list"), namespaceHandler.List)
+	namespaces.Get("/:name", can("get"), namespaceHandler.ValidateNamespaceExist, namespaceHandler.Get)
+	namespaces.Delete("/:name", can("delete"), namespaceHandler.ValidateNamespaceExist, namespaceHandler.Delete)
 
 	//ethereum2 group
-	ethereum2 := v1.Group("ethereum2")
+	ethereum2 := router.Group("ethereum2")
 	//beaconnodes group
 	beaconnodesGroup := ethereum2.Group("beaconnodes")
-	beaconnodesGroup.Post("/", beacon_node.Create)
-	beaconnodesGroup.Head("/", beacon_node.Count)
-	beaconnodesGroup.Get("/", beacon_node.List)
-	beaconnodesGroup.Get("/:name", beacon_node.ValidateBeaconNodeExist, beacon_node.Get)
-	beaconnodesGroup.Get("/:name/logs", websocket.New(shared.Logger))
-	beaconnodesGroup.Get("/:name/status", websocket.New(shared.Status))
-	beaconnodesGroup.Put("/:name", beacon_node.ValidateBeaconNodeExist, beacon_node.Update)
-	beaconnodesGroup.Delete("/:name", beacon_node.ValidateBeaconNodeExist, beacon_node.Delete)
+	can = authorization.For("ethereum2", "beaconnodes")
+	beaconNodeHandler := beacon_node.NewHandler(beaconNodeInternal.NewBeaconNodeService(deps.K8sClient))
+	beaconnodesGroup.Post("/", can("create"), beaconNodeHandler.Create)
+	beaconnodesGroup.Head("/", can("list"), beaconNodeHandler.Count)
+	beaconnodesGroup.Get("/", can("list"), beaconNodeHandler.List)
+	beaconnodesGroup.Get("/:name", can("get"), beaconNodeHandler.ValidateBeaconNodeExist, beaconNodeHandler.Get)
+	beaconnodesGroup.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	beaconnodesGroup.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	beaconnodesGroup.Put("/:name", can("update"), beaconNodeHandler.ValidateBeaconNodeExist, beaconNodeHandler.Update)
+	beaconnodesGroup.Patch("/:name", can("update"), beaconNodeHandler.ValidateBeaconNodeExist, beaconNodeHandler.Patch)
+	beaconnodesGroup.Delete("/:name", can("delete"), beaconNodeHandler.ValidateBeaconNodeExist, beaconNodeHandler.Delete)
 	//validators group
 	validatorsGroup := ethereum2.Group("validators")
-	validatorsGroup.Post("/", validator.Create)
-	validatorsGroup.Head("/", validator.Count)
-	validatorsGroup.Get("/", validator.List)
-	validatorsGroup.Get("/:name", validator.ValidateValidatorExist, validator.Get)
-	validatorsGroup.Get("/:name/logs", websocket.New(shared.Logger))
-	validatorsGroup.Get("/:name/status", websocket.New(shared.Status))
-	validatorsGroup.Put("/:name", validator.ValidateValidatorExist, validator.Update)
-	validatorsGroup.Delete("/:name", validator.ValidateValidatorExist, validator.Delete)
+	can = authorization.For("ethereum2", "validators")
+	validatorHandler := validator.NewHandler(validatorInternal.NewValidatorService(deps.K8sClient))
+	validatorsGroup.Post("/", can("create"), validatorHandler.Create)
+	validatorsGroup.Head("/", can("list"), validatorHandler.Count)
+	validatorsGroup.Get("/", can("list"), validatorHandler.List)
+	validatorsGroup.Get("/:name", can("get"), validatorHandler.ValidateValidatorExist, validatorHandler.Get)
+	validatorsGroup.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	validatorsGroup.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	validatorsGroup.Put("/:name", can("update"), validatorHandler.ValidateValidatorExist, validatorHandler.Update)
+	validatorsGroup.Patch("/:name", can("update"), validatorHandler.ValidateValidatorExist, validatorHandler.Patch)
+	validatorsGroup.Delete("/:name", can("delete"), validatorHandler.ValidateValidatorExist, validatorHandler.Delete)
 
 	//filecoin group
-	filecoinGroup := v1.Group("filecoin")
+	filecoinGroup := router.Group("filecoin")
 	filecoinNodes := filecoinGroup.Group("nodes")
-	filecoinNodes.Post("/", filecoin.Create)
-	filecoinNodes.Head("/", filecoin.Count)
-	filecoinNodes.Get("/", filecoin.List)
-	filecoinNodes.Get("/:name", filecoin.ValidateNodeExist, filecoin.Get)
-	filecoinNodes.Get("/:name/logs", websocket.New(shared.Logger))
-	filecoinNodes.Get("/:name/status", websocket.New(shared.Status))
-	filecoinNodes.Put("/:name", filecoin.ValidateNodeExist, filecoin.Update)
-	filecoinNodes.Delete("/:name", filecoin.ValidateNodeExist, filecoin.Delete)
+	can = authorization.For("filecoin", "nodes")
+	filecoinHandler := filecoin.NewHandler(filecoinInternal.NewFilecoinService(deps.K8sClient))
+	filecoinNodes.Post("/", can("create"), filecoinHandler.Create)
+	filecoinNodes.Head("/", can("list"), filecoinHandler.Count)
+	filecoinNodes.Get("/", can("list"), filecoinHandler.List)
+	filecoinNodes.Get("/:name", can("get"), filecoinHandler.ValidateNodeExist, filecoinHandler.Get)
+	filecoinNodes.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	filecoinNodes.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	filecoinNodes.Put("/:name", can("update"), filecoinHandler.ValidateNodeExist, filecoinHandler.Update)
+	filecoinNodes.Patch("/:name", can("update"), filecoinHandler.ValidateNodeExist, filecoinHandler.Patch)
+	filecoinNodes.Delete("/:name", can("delete"), filecoinHandler.ValidateNodeExist, filecoinHandler.Delete)
 
 	//ipfs group
-	ipfsGroup := v1.Group("ipfs")
+	ipfsGroup := router.Group("ipfs")
 	//ipfs peer group
 	ipfsPeersGroup := ipfsGroup.Group("peers")
-	ipfsPeersGroup.Post("/", ipfs_peer.Create)
-	ipfsPeersGroup.Head("/", ipfs_peer.Count)
-	ipfsPeersGroup.Get("/", ipfs_peer.List)
-	ipfsPeersGroup.Get("/:name", ipfs_peer.ValidatePeerExist, ipfs_peer.Get)
-	ipfsPeersGroup.Get("/:name/logs", websocket.New(shared.Logger))
-	ipfsPeersGroup.Get("/:name/status", websocket.New(shared.Status))
-	ipfsPeersGroup.Put("/:name", ipfs_peer.ValidatePeerExist, ipfs_peer.Update)
-	ipfsPeersGroup.Delete("/:name", ipfs_peer.ValidatePeerExist, ipfs_peer.Delete)
+	can = authorization.For("ipfs", "peers")
+	peerHandler := ipfs_peer.NewHandler(peerInternal.NewIpfsPeerService(deps.K8sClient))
+	ipfsPeersGroup.Post("/", can("create"), peerHandler.Create)
+	ipfsPeersGroup.Head("/", can("list"), peerHandler.Count)
+	ipfsPeersGroup.Get("/", can("list"), peerHandler.List)
+	ipfsPeersGroup.Get("/:name", can("get"), peerHandler.ValidatePeerExist, peerHandler.Get)
+	ipfsPeersGroup.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	ipfsPeersGroup.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	ipfsPeersGroup.Put("/:name", can("update"), peerHandler.ValidatePeerExist, peerHandler.Update)
+	ipfsPeersGroup.Patch("/:name", can("update"), peerHandler.ValidatePeerExist, peerHandler.Patch)
+	ipfsPeersGroup.Delete("/:name", can("delete"), peerHandler.ValidatePeerExist, peerHandler.Delete)
 	//ipfs peer group
 	clusterpeersGroup := ipfsGroup.Group("clusterpeers")
-	clusterpeersGroup.Post("/", ipfs_cluster_peer.Create)
-	clusterpeersGroup.Head("/", ipfs_cluster_peer.Count)
-	clusterpeersGroup.Get("/", ipfs_cluster_peer.List)
-	clusterpeersGroup.Get("/:name", ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Get)
-	clusterpeersGroup.Get("/:name/logs", websocket.New(shared.Logger))
-	clusterpeersGroup.Get("/:name/status", websocket.New(shared.Status))
-	clusterpeersGroup.Put("/:name", ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Update)
-	clusterpeersGroup.Delete("/:name", ipfs_cluster_peer.ValidateClusterPeerExist, ipfs_cluster_peer.Delete)
+	can = authorization.For("ipfs", "clusterpeers")
+	clusterPeerHandler := ipfs_cluster_peer.NewHandler(clusterPeerInternal.NewIpfsClusterPeerService(deps.K8sClient))
+	clusterpeersGroup.Post("/", can("create"), clusterPeerHandler.Create)
+	clusterpeersGroup.Head("/", can("list"), clusterPeerHandler.Count)
+	clusterpeersGroup.Get("/", can("list"), clusterPeerHandler.List)
+	clusterpeersGroup.Get("/:name", can("get"), clusterPeerHandler.ValidateClusterPeerExist, clusterPeerHandler.Get)
+	clusterpeersGroup.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	clusterpeersGroup.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	clusterpeersGroup.Put("/:name", can("update"), clusterPeerHandler.ValidateClusterPeerExist, clusterPeerHandler.Update)
+	clusterpeersGroup.Patch("/:name", can("update"), clusterPeerHandler.ValidateClusterPeerExist, clusterPeerHandler.Patch)
+	clusterpeersGroup.Delete("/:name", can("delete"), clusterPeerHandler.ValidateClusterPeerExist, clusterPeerHandler.Delete)
 
 	//near group
-	nearGroup := v1.Group("near")
+	nearGroup := router.Group("near")
 	nearNodesGroup := nearGroup.Group("nodes")
-	nearNodesGroup.Post("/", near.Create)
-	nearNodesGroup.Head("/", near.Count)
-	nearNodesGroup.Get("/", near.List)
-	nearNodesGroup.Get("/:name", near.ValidateNodeExist, near.Get)
-	nearNodesGroup.Get("/:name/logs", websocket.New(shared.Logger))
-	nearNodesGroup.Get("/:name/status", websocket.New(shared.Status))
-	nearNodesGroup.Get("/:name/stats", websocket.New(near.Stats))
-	nearNodesGroup.Put("/:name", near.ValidateNodeExist, near.Update)
-	nearNodesGroup.Delete("/:name", near.ValidateNodeExist, near.Delete)
-
-	polkadotGroup := v1.Group("polkadot")
-	polkadotNodesGroup := polkadotGroup.Group("nodes")
-	polkadotNodesGroup.Post("/", polkadot.Create)
-	polkadotNodesGroup.Head("/", polkadot.Count)
-	polkadotNodesGroup.Get("/", polkadot.List)
-	polkadotNodesGroup.Get("/:name", polkadot.ValidateNodeExist, polkadot.Get)
-	polkadotNodesGroup.Get("/:name/logs", websocket.New(shared.Logger))
-	polkadotNodesGroup.Get("/:name/status", websocket.New(shared.Status))
-	polkadotNodesGroup.Get("/:name/stats", websocket.New(polkadot.Stats))
-	polkadotNodesGroup.Put("/:name", polkadot.ValidateNodeExist, polkadot.Update)
-	polkadotNodesGroup.Delete("/:name", polkadot.ValidateNodeExist, polkadot.Delete)
+	can = authorization.For("near", "nodes")
+	nearHandler := near.NewHandler(nearInternal.NewNearService(deps.K8sClient), deps.K8sClient, deps.RPCClient)
+	nearNodesGroup.Post("/", can("create"), nearHandler.Create)
+	nearNodesGroup.Head("/", can("list"), nearHandler.Count)
+	nearNodesGroup.Get("/", can("list"), nearHandler.List)
+	nearNodesGroup.Get("/:name", can("get"), nearHandler.ValidateNodeExist, nearHandler.Get)
+	nearNodesGroup.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	nearNodesGroup.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	nearNodesGroup.Get("/:name/stats", can("stats"), websocket.New(shared.Websocket(nearHandler.Stats)))
+	nearNodesGroup.Put("/:name", can("update"), nearHandler.ValidateNodeExist, nearHandler.Update)
+	nearNodesGroup.Patch("/:name", can("update"), nearHandler.ValidateNodeExist, nearHandler.Patch)
+	nearNodesGroup.Delete("/:name", can("delete"), nearHandler.ValidateNodeExist, nearHandler.Delete)
 
+	polkadotGroup := router.Group("polkadot")
+	polkadotNodesGroup := polkadotGroup.Group("nodes")
+	can = authorization.For("polkadot", "nodes")
+	polkadotHandler := polkadot.NewHandler(polkadotInternal.NewPolkadotService(deps.K8sClient), deps.K8sClient, deps.RPCClient)
+	polkadotNodesGroup.Post("/", can("create"), polkadotHandler.Create)
+	polkadotNodesGroup.Head("/", can("list"), polkadotHandler.Count)
+	polkadotNodesGroup.Get("/", can("list"), polkadotHandler.List)
+	polkadotNodesGroup.Get("/:name", can("get"), polkadotHandler.ValidateNodeExist, polkadotHandler.Get)
+	polkadotNodesGroup.Get("/:name/logs", can("logs"), websocket.New(shared.Websocket(pods.Logger)))
+	polkadotNodesGroup.Get("/:name/status", can("status"), websocket.New(shared.Websocket(pods.Status)))
+	polkadotNodesGroup.Get("/:name/stats", can("stats"), websocket.New(shared.Websocket(polkadotHandler.Stats)))
+	polkadotNodesGroup.Put("/:name", can("update"), polkadotHandler.ValidateNodeExist, polkadotHandler.Update)
+	polkadotNodesGroup.Patch("/:name", can("update"), polkadotHandler.ValidateNodeExist, polkadotHandler.Patch)
+	polkadotNodesGroup.Delete("/:name", can("delete"), polkadotHandler.ValidateNodeExist, polkadotHandler.Delete)
 }
@@ -0,0 +1,18 @@
+package api
+
+import (
+	"github.com/kotalco/api/pkg/validation"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// TestResourcesValidationRules fails if a dto declares unknown validation rule or unregistered enum
+func TestResourcesValidationRules(t *testing.T) {
+	for _, resource := range apiSpec.Resources {
+		dto := reflect.New(reflect.TypeOf(resource.Dto)).Interface()
+		assert.NotPanics(t, func() {
+			validation.Validate(dto, validation.Create)
+		}, "%T", resource.Dto)
+	}
+}
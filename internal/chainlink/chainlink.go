@@ -4,43 +4,46 @@ import (
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 type apiCredentials struct {
-	Email              string `json:"email"`
-	PasswordSecretName string `json:"passwordSecretName"`
+	Email              string `json:"email" validate:"required"`
+	PasswordSecretName string `json:"passwordSecretName" validate:"required,dns1123subdomain"`
 }
 
 type ChainlinkDto struct {
 	models.Time
 	k8s.MetaDataDto
-	EthereumChainId            uint            `json:"ethereumChainId"`
-	LinkContractAddress        string          `json:"linkContractAddress"`
-	EthereumWSEndpoint         string          `json:"ethereumWsEndpoint"`
-	DatabaseURL                string          `json:"databaseURL"`
-	EthereumHTTPEndpoints      []string        `json:"ethereumHttpEndpoints"`
-	KeystorePasswordSecretName string          `json:"keystorePasswordSecretName"`
-	APICredentials             *apiCredentials `json:"apiCredentials"`
+	EthereumChainId            uint            `json:"ethereumChainId" validate:"required"`
+	LinkContractAddress        string          `json:"linkContractAddress" validate:"required"`
+	EthereumWSEndpoint         string          `json:"ethereumWsEndpoint" validate:"required,url=ws|wss"`
+	DatabaseURL                string          `json:"databaseURL" validate:"required,url=postgres|postgresql"`
+	EthereumHTTPEndpoints      []string        `json:"ethereumHttpEndpoints" validate:"url=http|https"`
+	KeystorePasswordSecretName string          `json:"keystorePasswordSecretName" validate:"required,dns1123subdomain"`
+	APICredentials             *apiCredentials `json:"apiCredentials" validate:"required"`
 	CORSDomains                []string        `json:"corsDomains"`
-	CertSecretName             string          `json:"certSecretName"`
-	TLSPort                    uint            `json:"tlsPort"`
-	P2PPort                    uint            `json:"p2pPort"`
-	APIPort                    uint            `json:"apiPort"`
+	CertSecretName             string          `json:"certSecretName" validate:"dns1123subdomain"`
+	TLSPort                    uint            `json:"tlsPort" validate:"port"`
+	P2PPort                    uint            `json:"p2pPort" validate:"port"`
+	APIPort                    uint            `json:"apiPort" validate:"port"`
 	SecureCookies              *bool           `json:"secureCookies"`
-	Logging                    string          `json:"logging"`
-	CPU                        string          `json:"cpu"`
-	CPULimit                   string          `json:"cpuLimit"`
-	Memory                     string          `json:"memory"`
-	MemoryLimit                string          `json:"memoryLimit"`
-	Storage                    string          `json:"storage"`
-	StorageClass               *string         `json:"storageClass"`
+	Logging                    string          `json:"logging" validate:"enum=chainlinkLogging"`
+	CPU                        string          `json:"cpu" validate:"quantity"`
+	CPULimit                   string          `json:"cpuLimit" validate:"quantity"`
+	Memory                     string          `json:"memory" validate:"quantity"`
+	MemoryLimit                string          `json:"memoryLimit" validate:"quantity"`
+	Storage                    string          `json:"storage" validate:"quantity"`
+	StorageClass               *string         `json:"storageClass" validate:"dns1123subdomain"`
 }
 
 type ChainlinkListDto []ChainlinkDto
 
 func (dto ChainlinkDto) FromChainlinkNode(n *chainlinkv1alpha1.Node) *ChainlinkDto {
 	dto.Name = n.Name
+	dto.Namespace = n.Namespace
 	dto.Time = models.Time{CreatedAt: n.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.EthereumChainId = n.Spec.EthereumChainId
 	dto.LinkContractAddress = n.Spec.LinkContractAddress
@@ -76,3 +79,49 @@ func (nodes ChainlinkListDto) FromChainlinkNode(models []chainlinkv1alpha1.Node)
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("chainlinkLogging",
+		string(sharedAPI.DebugLogs),
+		string(sharedAPI.InfoLogs),
+		string(sharedAPI.WarnLogs),
+		string(sharedAPI.ErrorLogs),
+		string(sharedAPI.PanicLogs),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the chainlink node
+func (dto *ChainlinkDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToChainlinkNodeSpec replaces the node spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the node defaulting
+func (dto *ChainlinkDto) ToChainlinkNodeSpec(spec *chainlinkv1alpha1.NodeSpec) {
+	spec.EthereumChainId = dto.EthereumChainId
+	spec.LinkContractAddress = dto.LinkContractAddress
+	spec.EthereumWSEndpoint = dto.EthereumWSEndpoint
+	spec.DatabaseURL = dto.DatabaseURL
+	spec.EthereumHTTPEndpoints = dto.EthereumHTTPEndpoints
+	spec.KeystorePasswordSecretName = dto.KeystorePasswordSecretName
+	spec.APICredentials = chainlinkv1alpha1.APICredentials{}
+	if dto.APICredentials != nil {
+		spec.APICredentials.Email = dto.APICredentials.Email
+		spec.APICredentials.PasswordSecretName = dto.APICredentials.PasswordSecretName
+	}
+	spec.CORSDomains = dto.CORSDomains
+	spec.CertSecretName = dto.CertSecretName
+	spec.TLSPort = dto.TLSPort
+	spec.P2PPort = dto.P2PPort
+	spec.APIPort = dto.APIPort
+	spec.SecureCookies = dto.SecureCookies != nil && *dto.SecureCookies
+	spec.Logging = sharedAPI.VerbosityLevel(dto.Logging)
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
@@ -11,39 +11,40 @@ import (
 	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
 	sharedAPI "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type chainlinkService struct{}
+type chainlinkService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(types.NamespacedName) (*chainlinkv1alpha1.Node, *errors.RestErr)
-	Create(*ChainlinkDto) (*chainlinkv1alpha1.Node, *errors.RestErr)
-	Update(*ChainlinkDto, *chainlinkv1alpha1.Node) (*chainlinkv1alpha1.Node, *errors.RestErr)
-	List(namespace string) (*chainlinkv1alpha1.NodeList, *errors.RestErr)
-	Count(namespace string) (*int, *errors.RestErr)
-	Delete(node *chainlinkv1alpha1.Node) *errors.RestErr
+	Get(context.Context, types.NamespacedName) (*chainlinkv1alpha1.Node, *errors.RestErr)
+	Create(context.Context, *ChainlinkDto) (*chainlinkv1alpha1.Node, *errors.RestErr)
+	Update(context.Context, *ChainlinkDto, *chainlinkv1alpha1.Node) (*chainlinkv1alpha1.Node, *errors.RestErr)
+	Patch(context.Context, *ChainlinkDto, *chainlinkv1alpha1.Node) (*chainlinkv1alpha1.Node, *errors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*chainlinkv1alpha1.NodeList, *errors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr)
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr)
+	Delete(ctx context.Context, node *chainlinkv1alpha1.Node) *errors.RestErr
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewChainLinkService() IService {
-	return chainlinkService{}
+func NewChainLinkService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return chainlinkService{k8sClient: k8sClient}
 }
 
 // Get returns a single chainlink node by name
-func (service chainlinkService) Get(namespacedName types.NamespacedName) (*chainlinkv1alpha1.Node, *errors.RestErr) {
+func (service chainlinkService) Get(ctx context.Context, namespacedName types.NamespacedName) (*chainlinkv1alpha1.Node, *errors.RestErr) {
 	node := &chainlinkv1alpha1.Node{}
-	if err := k8sClient.Get(context.Background(), namespacedName, node); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, node); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, errors.NewNotFoundError(fmt.Sprintf("node by name %s doesn't exist", namespacedName.Name))
+			return nil, errors.NewNodeNotFoundError(fmt.Sprintf("node by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName.Name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName.Name)))
 	}
 
 	return node, nil
@@ -51,7 +52,7 @@ func (service chainlinkService) Get(namespacedName types.NamespacedName) (*chain
 }
 
 // Create creates chainlink node from the given spec
-func (service chainlinkService) Create(dto *ChainlinkDto) (*chainlinkv1alpha1.Node, *errors.RestErr) {
+func (service chainlinkService) Create(ctx context.Context, dto *ChainlinkDto) (*chainlinkv1alpha1.Node, *errors.RestErr) {
 	node := &chainlinkv1alpha1.Node{
 		ObjectMeta: dto.ObjectMetaFromMetadataDto(),
 		Spec: chainlinkv1alpha1.NodeSpec{
@@ -67,24 +68,20 @@ func (service chainlinkService) Create(dto *ChainlinkDto) (*chainlinkv1alpha1.No
 		},
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
-	}
-
-	err := k8sClient.Create(context.Background(), node)
+	err := service.k8sClient.Create(ctx, node)
 	if err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, errors.NewBadRequestError(fmt.Sprintf("node by name %s already exist", node.Name))
+			return nil, errors.NewAlreadyExistsError(fmt.Sprintf("node by name %s already exists", node.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, errors.NewInternalServerError("failed to create node")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to create node"))
 	}
 
 	return node, nil
 }
 
 // Update updates a single chainlink node by name from spec
-func (service chainlinkService) Update(dto *ChainlinkDto, node *chainlinkv1alpha1.Node) (*chainlinkv1alpha1.Node, *errors.RestErr) {
+func (service chainlinkService) Update(ctx context.Context, dto *ChainlinkDto, node *chainlinkv1alpha1.Node) (*chainlinkv1alpha1.Node, *errors.RestErr) {
 
 	if dto.EthereumWSEndpoint != "" {
 		node.Spec.EthereumWSEndpoint = dto.EthereumWSEndpoint
@@ -151,51 +148,76 @@ func (service chainlinkService) Update(dto *ChainlinkDto, node *chainlinkv1alpha
 		node.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
-	}
-
-	err := k8sClient.Update(context.Background(), node)
+	err := service.k8sClient.Update(ctx, node)
 	if err != nil {
 		go logger.Error(service.Update, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name)))
+	}
+
+	return node, nil
+}
+
+// Patch replaces node spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service chainlinkService) Patch(ctx context.Context, dto *ChainlinkDto, node *chainlinkv1alpha1.Node) (*chainlinkv1alpha1.Node, *errors.RestErr) {
+	dto.ToChainlinkNodeSpec(&node.Spec)
+
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't patch node by name %s", node.Name)))
 	}
 
 	return node, nil
 }
 
 // List returns all chainlink nodes
-func (service chainlinkService) List(namespace string) (*chainlinkv1alpha1.NodeList, *errors.RestErr) {
+func (service chainlinkService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*chainlinkv1alpha1.NodeList, *errors.RestErr) {
 	nodes := &chainlinkv1alpha1.NodeList{}
-	err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace))
+	err := service.k8sClient.List(ctx, nodes, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
 	if err != nil {
 		go logger.Error(service.List, err)
-		return nil, errors.NewInternalServerError("failed to get all nodes")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to get all nodes"))
 	}
 
 	return nodes, nil
 }
 
+// Watch watches nodes changes in the namespace starting after the given resource version
+// all existing nodes are sent as added events if the resource version is empty
+func (service chainlinkService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &chainlinkv1alpha1.NodeList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+	if err != nil {
+		go logger.Error(service.Watch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to watch nodes"))
+	}
+
+	return watcher, nil
+}
+
 // Count returns all nodes length
-func (service chainlinkService) Count(namespace string) (*int, *errors.RestErr) {
-	nodes := &chainlinkv1alpha1.NodeList{}
-	err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace))
+func (service chainlinkService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &chainlinkv1alpha1.NodeList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
 	if err != nil {
 		go logger.Error(service.Count, err)
-		return nil, errors.NewInternalServerError("failed to get all nodes")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to count nodes"))
 	}
 
-	length := len(nodes.Items)
 	return &length, nil
 }
 
 // Delete a single chainlink node by name
-func (service chainlinkService) Delete(node *chainlinkv1alpha1.Node) *errors.RestErr {
-	err := k8sClient.Delete(context.Background(), node)
+func (service chainlinkService) Delete(ctx context.Context, node *chainlinkv1alpha1.Node) *errors.RestErr {
+	err := service.k8sClient.Delete(ctx, node)
 
 	if err != nil {
 		go logger.Error(service.Delete, err)
-		return errors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name))
+		return errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name)))
 	}
 
 	return nil
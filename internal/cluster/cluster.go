@@ -0,0 +1,24 @@
+package cluster
+
+import (
+	"github.com/kotalco/api/pkg/k8s"
+)
+
+// ClusterDto is k8s cluster managed by the api server
+type ClusterDto struct {
+	Name              string `json:"name"`
+	Default           bool   `json:"default"`
+	Reachable         bool   `json:"reachable"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	OperatorVersion   string `json:"operatorVersion,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+type ClusterListDto []ClusterDto
+
+func (dto ClusterDto) FromCluster(cluster *k8s.Cluster) *ClusterDto {
+	dto.Name = cluster.Name
+	dto.Default = cluster.Default
+
+	return &dto
+}
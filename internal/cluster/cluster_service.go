@@ -0,0 +1,134 @@
+// Package cluster internal is the domain layer for the k8s clusters managed by the api server
+// probes the registered clusters for their kubernetes and kotal operator versions
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/version"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// probeTimeout is the max duration to wait for a cluster api server to respond
+	probeTimeout = 5 * time.Second
+	// operatorContainer is the kotal operator deployment container running the manager
+	operatorContainer = "manager"
+)
+
+type clusterService struct {
+	k8sClient k8s.K8sClientServiceInterface
+	clientset k8s.ClientsetServiceInterface
+}
+
+type IService interface {
+	List(ctx context.Context) (ClusterListDto, *errors.RestErr)
+	Get(ctx context.Context, name string) (*ClusterDto, *errors.RestErr)
+}
+
+func NewClusterService(k8sClient k8s.K8sClientServiceInterface, clientset k8s.ClientsetServiceInterface) IService {
+	return clusterService{k8sClient: k8sClient, clientset: clientset}
+}
+
+// List returns all registered clusters probed concurrently
+// unreachable clusters are listed with the probe error
+func (service clusterService) List(ctx context.Context) (ClusterListDto, *errors.RestErr) {
+	clusters, err := k8s.Clusters()
+	if err != nil {
+		go logger.Error(service.List, err)
+		return nil, errors.NewInternalServerError("can't load clusters")
+	}
+
+	dtos := make(ClusterListDto, len(clusters))
+	wg := sync.WaitGroup{}
+	for i, cluster := range clusters {
+		wg.Add(1)
+		go func(i int, cluster *k8s.Cluster) {
+			defer wg.Done()
+			dtos[i] = *service.probe(k8s.WithCluster(ctx, cluster.Name), cluster)
+		}(i, cluster)
+	}
+	wg.Wait()
+
+	return dtos, nil
+}
+
+// Get returns the registered cluster by name probed for its versions
+// unreachable cluster is returned with the probe error
+func (service clusterService) Get(ctx context.Context, name string) (*ClusterDto, *errors.RestErr) {
+	cluster, ok, err := k8s.GetCluster(name)
+	if err != nil {
+		go logger.Error(service.Get, err)
+		return nil, errors.NewInternalServerError("can't load clusters")
+	}
+	if !ok {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("cluster by name %s doesn't exist", name))
+	}
+
+	return service.probe(k8s.WithCluster(ctx, cluster.Name), cluster), nil
+}
+
+// probe returns the cluster dto with its kubernetes version and the deployed kotal operator version
+func (service clusterService) probe(ctx context.Context, cluster *k8s.Cluster) *ClusterDto {
+	dto := new(ClusterDto).FromCluster(cluster)
+
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	clientset, err := service.clientset.Clientset(ctx)
+	if err != nil {
+		dto.Error = err.Error()
+		return dto
+	}
+
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		dto.Error = err.Error()
+		return dto
+	}
+
+	info := version.Info{}
+	if err := json.Unmarshal(body, &info); err != nil {
+		dto.Error = err.Error()
+		return dto
+	}
+	dto.Reachable = true
+	dto.KubernetesVersion = info.GitVersion
+
+	deployment := &appsv1.Deployment{}
+	if err := service.k8sClient.Get(ctx, k8s.OperatorDeployment(), deployment); err != nil {
+		if !apiErrors.IsNotFound(err) {
+			dto.Error = fmt.Sprintf("can't get kotal operator deployment: %s", err)
+		}
+		return dto
+	}
+	dto.OperatorVersion = operatorVersion(deployment)
+
+	return dto
+}
+
+// operatorVersion returns the image tag or digest of the operator manager container
+func operatorVersion(deployment *appsv1.Deployment) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != operatorContainer {
+			continue
+		}
+		image := container.Image
+		if i := strings.LastIndex(image, "@"); i != -1 {
+			return image[i+1:]
+		}
+		if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+			return image[i+1:]
+		}
+		return "latest"
+	}
+	return ""
+}
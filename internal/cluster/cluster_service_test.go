@@ -0,0 +1,31 @@
+package cluster
+
+import (
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"testing"
+)
+
+func TestOperatorVersion(t *testing.T) {
+	testCases := []struct {
+		image   string
+		version string
+	}{
+		{"kotalco/kotal:v0.1.0", "v0.1.0"},
+		{"localhost:5000/kotalco/kotal:v0.1.0", "v0.1.0"},
+		{"localhost:5000/kotalco/kotal", "latest"},
+		{"kotalco/kotal@sha256:abc", "sha256:abc"},
+	}
+
+	for _, testCase := range testCases {
+		deployment := &appsv1.Deployment{}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{
+			{Name: "kube-rbac-proxy", Image: "gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0"},
+			{Name: operatorContainer, Image: testCase.image},
+		}
+		assert.Equal(t, testCase.version, operatorVersion(deployment), testCase.image)
+	}
+
+	assert.Empty(t, operatorVersion(&appsv1.Deployment{}))
+}
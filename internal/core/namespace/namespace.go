@@ -0,0 +1,32 @@
+package namespace
+
+import (
+	"github.com/kotalco/api/internal/models"
+	"github.com/kotalco/api/pkg/shared"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NamespaceDto is kotal workspace namespace
+type NamespaceDto struct {
+	models.Time
+	Name   string `json:"name" validate:"required,dns1123label"`
+	Status string `json:"status"`
+}
+
+type NamespaceListDto []NamespaceDto
+
+func (dto NamespaceDto) FromCoreNamespace(namespace *corev1.Namespace) *NamespaceDto {
+	dto.Name = namespace.Name
+	dto.Time = models.Time{CreatedAt: namespace.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
+	dto.Status = string(namespace.Status.Phase)
+
+	return &dto
+}
+
+func (namespaces NamespaceListDto) FromCoreNamespace(models []corev1.Namespace) NamespaceListDto {
+	result := make(NamespaceListDto, len(models))
+	for index, v := range models {
+		result[index] = *(NamespaceDto{}.FromCoreNamespace(&v))
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+// Package namespace internal is the domain layer for kotal workspaces namespaces
+// uses the k8 client to CRUD the namespaces labelled as kotal workspaces, other namespaces aren't found
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkspaceLabel is the label of the namespaces managed as kotal workspaces
+const WorkspaceLabel = "kotal.io/workspace"
+
+type namespaceService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
+
+type IService interface {
+	Get(ctx context.Context, name string) (*corev1.Namespace, *errors.RestErr)
+	Create(ctx context.Context, dto *NamespaceDto) (*corev1.Namespace, *errors.RestErr)
+	List(ctx context.Context, opts ...client.ListOption) (*corev1.NamespaceList, *errors.RestErr)
+	Delete(ctx context.Context, namespace *corev1.Namespace) *errors.RestErr
+	Count(ctx context.Context, opts ...client.ListOption) (*int, *errors.RestErr)
+}
+
+func NewNamespaceService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return namespaceService{k8sClient: k8sClient}
+}
+
+// Get returns a single workspace namespace by name
+func (service namespaceService) Get(ctx context.Context, name string) (*corev1.Namespace, *errors.RestErr) {
+	namespace := &corev1.Namespace{}
+
+	if err := service.k8sClient.Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return nil, errors.NewNotFoundError(fmt.Sprintf("namespace by name %s doesn't exist", name))
+		}
+		go logger.Error(service.Get, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't get namespace by name %s", name)))
+	}
+
+	// namespaces which aren't kotal workspaces like kube-system aren't managed by the api
+	if namespace.Labels[WorkspaceLabel] != "true" {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("namespace by name %s doesn't exist", name))
+	}
+
+	return namespace, nil
+}
+
+// Create creates a workspace namespace from the given spec
+func (service namespaceService) Create(ctx context.Context, dto *NamespaceDto) (*corev1.Namespace, *errors.RestErr) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: dto.Name,
+			Labels: map[string]string{
+				WorkspaceLabel:                 "true",
+				"app.kubernetes.io/created-by": "kotal-api",
+			},
+		},
+	}
+
+	if err := service.k8sClient.Create(ctx, namespace); err != nil {
+		if apiErrors.IsAlreadyExists(err) {
+			return nil, errors.NewAlreadyExistsError(fmt.Sprintf("namespace by name %s already exists", dto.Name))
+		}
+		go logger.Error(service.Create, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("error creating namespace"))
+	}
+
+	return namespace, nil
+}
+
+// List returns all workspace namespaces
+func (service namespaceService) List(ctx context.Context, opts ...client.ListOption) (*corev1.NamespaceList, *errors.RestErr) {
+	namespaces := &corev1.NamespaceList{}
+
+	if err := service.k8sClient.List(ctx, namespaces, append([]client.ListOption{client.MatchingLabels{WorkspaceLabel: "true"}}, opts...)...); err != nil {
+		go logger.Error(service.List, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to get all namespaces"))
+	}
+
+	return namespaces, nil
+}
+
+// Delete deletes a workspace namespace by name with all the nodes and secrets in it
+func (service namespaceService) Delete(ctx context.Context, namespace *corev1.Namespace) *errors.RestErr {
+	if err := service.k8sClient.Delete(ctx, namespace); err != nil {
+		go logger.Error(service.Delete, err)
+		return errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't delete namespace by name %s", namespace.Name)))
+	}
+
+	return nil
+}
+
+// Count returns total number of workspace namespaces
+func (service namespaceService) Count(ctx context.Context, opts ...client.ListOption) (*int, *errors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &corev1.NamespaceList{}, append([]client.ListOption{client.MatchingLabels{WorkspaceLabel: "true"}}, opts...)...)
+	if err != nil {
+		go logger.Error(service.Count, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to count namespaces"))
+	}
+
+	return &length, nil
+}
@@ -0,0 +1,85 @@
+package namespace
+
+import (
+	"context"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"testing"
+)
+
+// newTestService returns namespace service using in memory k8s client holding kube-system namespace
+// and workspace namespaces by the given names
+func newTestService(t *testing.T, names ...string) IService {
+	service := NewNamespaceService(fake.NewClientService(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-system"},
+	}))
+	for _, name := range names {
+		_, err := service.Create(context.Background(), &NamespaceDto{Name: name})
+		assert.Nil(t, err)
+	}
+	return service
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+
+	namespace, err := service.Create(ctx, &NamespaceDto{Name: "goerli"})
+	assert.Nil(t, err)
+	assert.Equal(t, "true", namespace.Labels[WorkspaceLabel])
+
+	_, err = service.Create(ctx, &NamespaceDto{Name: "goerli"})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Status)
+		assert.Equal(t, restErrors.CodeAlreadyExists, err.Code)
+	}
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, "goerli")
+
+	namespace, err := service.Get(ctx, "goerli")
+	assert.Nil(t, err)
+	assert.Equal(t, "goerli", namespace.Name)
+
+	for _, name := range []string{"kube-system", "unknown"} {
+		_, err = service.Get(ctx, name)
+		if assert.NotNil(t, err, name) {
+			assert.Equal(t, http.StatusNotFound, err.Status, name)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	service := newTestService(t, "goerli", "mainnet")
+
+	list, err := service.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, list.Items, 2)
+}
+
+func TestCount(t *testing.T) {
+	service := newTestService(t, "goerli", "mainnet")
+
+	count, err := service.Count(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, *count)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, "goerli")
+
+	namespace, _ := service.Get(ctx, "goerli")
+	assert.Nil(t, service.Delete(ctx, namespace))
+
+	_, err := service.Get(ctx, "goerli")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Status)
+	}
+}
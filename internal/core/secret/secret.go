@@ -10,7 +10,7 @@ import (
 type SecretDto struct {
 	models.Time
 	k8s.MetaDataDto
-	Type string            `json:"type"`
+	Type string            `json:"type" validate:"required"`
 	Data map[string]string `json:"data,omitempty"`
 }
 
@@ -18,6 +18,7 @@ type SecretsDto []SecretDto
 
 func (dto SecretDto) FromCoreSecret(s *corev1.Secret) *SecretDto {
 	dto.Name = s.Name
+	dto.Namespace = s.Namespace
 	dto.Time = models.Time{CreatedAt: s.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.Type = s.Labels["kotal.io/key-type"]
 
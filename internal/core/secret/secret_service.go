@@ -15,41 +15,39 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type secretService struct{}
+type secretService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(name types.NamespacedName) (*corev1.Secret, *errors.RestErr)
-	Create(*SecretDto) (*corev1.Secret, *errors.RestErr)
-	List(namespace string) (*corev1.SecretList, *errors.RestErr)
-	Delete(secret *corev1.Secret) *errors.RestErr
-	Count(namespace string) (*int, *errors.RestErr)
+	Get(ctx context.Context, name types.NamespacedName) (*corev1.Secret, *errors.RestErr)
+	Create(context.Context, *SecretDto) (*corev1.Secret, *errors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*corev1.SecretList, *errors.RestErr)
+	Delete(ctx context.Context, secret *corev1.Secret) *errors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewSecretService() IService {
-	return secretService{}
+func NewSecretService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return secretService{k8sClient: k8sClient}
 }
 
 // Get returns a single secret  by name
-func (service secretService) Get(namespacedName types.NamespacedName) (*corev1.Secret, *errors.RestErr) {
+func (service secretService) Get(ctx context.Context, namespacedName types.NamespacedName) (*corev1.Secret, *errors.RestErr) {
 	secret := &corev1.Secret{}
 
-	if err := k8sClient.Get(context.Background(), namespacedName, secret); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, secret); err != nil {
 		if apiErrors.IsNotFound(err) {
 			return nil, errors.NewNotFoundError(fmt.Sprintf("secret by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't get secret by name %s", namespacedName.Name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't get secret by name %s", namespacedName.Name)))
 	}
 
 	return secret, nil
 }
 
 // Create creates a secret from the given spec
-func (service secretService) Create(dto *SecretDto) (*corev1.Secret, *errors.RestErr) {
+func (service secretService) Create(ctx context.Context, dto *SecretDto) (*corev1.Secret, *errors.RestErr) {
 	t := true
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -64,45 +62,45 @@ func (service secretService) Create(dto *SecretDto) (*corev1.Secret, *errors.Res
 		Immutable:  &t,
 	}
 
-	if err := k8sClient.Create(context.Background(), secret); err != nil {
+	if err := service.k8sClient.Create(ctx, secret); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, errors.NewBadRequestError(fmt.Sprintf("secret by name %s already exist", dto.Name))
+			return nil, errors.NewAlreadyExistsError(fmt.Sprintf("secret by name %s already exists", dto.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, errors.NewInternalServerError("error creating secret")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("error creating secret"))
 	}
 	return secret, nil
 }
 
 // List returns all secrets
-func (service secretService) List(namespace string) (*corev1.SecretList, *errors.RestErr) {
+func (service secretService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*corev1.SecretList, *errors.RestErr) {
 	secrets := &corev1.SecretList{}
 
-	if err := k8sClient.List(context.Background(), secrets, client.InNamespace(namespace), client.HasLabels{"app.kubernetes.io/created-by"}); err != nil {
+	if err := service.k8sClient.List(ctx, secrets, append([]client.ListOption{client.InNamespace(namespace), client.HasLabels{"app.kubernetes.io/created-by"}}, opts...)...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, errors.NewInternalServerError("failed to get all secrets")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to get all secrets"))
 	}
 
 	return secrets, nil
 }
 
 // Delete a single secret node by name
-func (service secretService) Delete(secret *corev1.Secret) *errors.RestErr {
-	if err := k8sClient.Delete(context.Background(), secret); err != nil {
+func (service secretService) Delete(ctx context.Context, secret *corev1.Secret) *errors.RestErr {
+	if err := service.k8sClient.Delete(ctx, secret); err != nil {
 		go logger.Error(service.Delete, err)
-		return errors.NewInternalServerError(fmt.Sprintf("can't delete secret by name %s", secret.Name))
+		return errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't delete secret by name %s", secret.Name)))
 	}
 
 	return nil
 }
 
 // Delete a list of secrets
-func (service secretService) Count(namespace string) (*int, *errors.RestErr) {
-	secrets := &corev1.SecretList{}
-	if err := k8sClient.List(context.Background(), secrets, client.InNamespace(namespace), client.HasLabels{"kotal.io/key-type"}); err != nil {
+func (service secretService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &corev1.SecretList{}, append([]client.ListOption{client.InNamespace(namespace), client.HasLabels{"kotal.io/key-type"}}, opts...)...)
+	if err != nil {
 		go logger.Error(service.Count, err)
-		return nil, errors.NewInternalServerError("failed to get all secrets")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to count secrets"))
 	}
-	length := len(secrets.Items)
+
 	return &length, nil
 }
@@ -1 +1,97 @@
 package secret
+
+import (
+	"context"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"testing"
+)
+
+var testKey = types.NamespacedName{Namespace: "default", Name: "my-secret"}
+
+func newTestDto(name string) *SecretDto {
+	return &SecretDto{
+		MetaDataDto: k8s.MetaDataDto{Name: name, Namespace: "default"},
+		Type:        "password",
+		Data:        map[string]string{"password": "secret"},
+	}
+}
+
+// newTestService returns secret service using in memory k8s client holding secrets by the given names in the default namespace
+func newTestService(t *testing.T, names ...string) IService {
+	// secrets not created by the api are ignored
+	service := NewSecretService(fake.NewClientService(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-token", Namespace: "default"},
+	}))
+	for _, name := range names {
+		_, err := service.Create(context.Background(), newTestDto(name))
+		assert.Nil(t, err)
+	}
+	return service
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t)
+
+	secret, err := service.Create(ctx, newTestDto("my-secret"))
+	assert.Nil(t, err)
+	assert.Equal(t, "password", secret.Labels["kotal.io/key-type"])
+	assert.Equal(t, "secret", secret.StringData["password"])
+	assert.True(t, *secret.Immutable)
+
+	_, err = service.Create(ctx, newTestDto("my-secret"))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusConflict, err.Status)
+		assert.Equal(t, restErrors.CodeAlreadyExists, err.Code)
+	}
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, "my-secret")
+
+	secret, err := service.Get(ctx, testKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "my-secret", secret.Name)
+
+	_, err = service.Get(ctx, types.NamespacedName{Namespace: "default", Name: "unknown"})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Status)
+	}
+}
+
+func TestList(t *testing.T) {
+	service := newTestService(t, "secret-1", "secret-2")
+
+	list, err := service.List(context.Background(), "default")
+	assert.Nil(t, err)
+	assert.Len(t, list.Items, 2)
+}
+
+func TestCount(t *testing.T) {
+	service := newTestService(t, "secret-1", "secret-2")
+
+	count, err := service.Count(context.Background(), "default")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, *count)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService(t, "my-secret")
+
+	secret, _ := service.Get(ctx, testKey)
+	assert.Nil(t, service.Delete(ctx, secret))
+
+	_, err := service.Get(ctx, testKey)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Status)
+	}
+}
@@ -1,14 +1,16 @@
 package storage_class
 
 import (
+	"github.com/kotalco/api/pkg/validation"
+	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 )
 
 // StorageClass is Kubernetes storage class
 type StorageClassDto struct {
-	Name                 string `json:"name"`
-	Provisioner          string `json:"provisioner"`
-	ReclaimPolicy        string `json:"reclaimPolicy"`
+	Name                 string `json:"name" validate:"required,dns1123subdomain"`
+	Provisioner          string `json:"provisioner" validate:"required"`
+	ReclaimPolicy        string `json:"reclaimPolicy" validate:"enum=reclaimPolicy"`
 	AllowVolumeExpansion bool   `json:"allowVolumeExpansion"`
 }
 
@@ -17,7 +19,9 @@ type StorageClassListDto []StorageClassDto
 func (dto StorageClassDto) FromCoreStorageClass(sc *storagev1.StorageClass) *StorageClassDto {
 	dto.Name = sc.Name
 	dto.Provisioner = sc.Provisioner
-	dto.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	if sc.ReclaimPolicy != nil {
+		dto.ReclaimPolicy = string(*sc.ReclaimPolicy)
+	}
 	dto.AllowVolumeExpansion = sc.AllowVolumeExpansion != nil && *sc.AllowVolumeExpansion
 
 	return &dto
@@ -30,3 +34,10 @@ func (storageClassListDto StorageClassListDto) FromCoreSecret(list []storagev1.S
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("reclaimPolicy",
+		string(corev1.PersistentVolumeReclaimDelete),
+		string(corev1.PersistentVolumeReclaimRetain),
+	)
+}
@@ -14,39 +14,37 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type storageClassService struct{}
+type storageClassService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(name string) (*storagev1.StorageClass, *errors.RestErr)
-	Create(dto *StorageClassDto) (*storagev1.StorageClass, *errors.RestErr)
-	Update(*StorageClassDto, *storagev1.StorageClass) (*storagev1.StorageClass, *errors.RestErr)
-	List() (*storagev1.StorageClassList, *errors.RestErr)
-	Delete(*storagev1.StorageClass) *errors.RestErr
-	Count() (*int, *errors.RestErr)
+	Get(ctx context.Context, name string) (*storagev1.StorageClass, *errors.RestErr)
+	Create(ctx context.Context, dto *StorageClassDto) (*storagev1.StorageClass, *errors.RestErr)
+	Update(context.Context, *StorageClassDto, *storagev1.StorageClass) (*storagev1.StorageClass, *errors.RestErr)
+	List(ctx context.Context, opts ...client.ListOption) (*storagev1.StorageClassList, *errors.RestErr)
+	Delete(context.Context, *storagev1.StorageClass) *errors.RestErr
+	Count(ctx context.Context, opts ...client.ListOption) (*int, *errors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewStorageClassService() IService {
-	return storageClassService{}
+func NewStorageClassService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return storageClassService{k8sClient: k8sClient}
 }
 
 // Get returns a single storage class  by name
-func (service storageClassService) Get(name string) (*storagev1.StorageClass, *errors.RestErr) {
+func (service storageClassService) Get(ctx context.Context, name string) (*storagev1.StorageClass, *errors.RestErr) {
 	storageClass := &storagev1.StorageClass{}
+	// storage classes are cluster scoped
 	key := types.NamespacedName{
-		Name:      name,
-		Namespace: "default",
+		Name: name,
 	}
 
-	if err := k8sClient.Get(context.Background(), key, storageClass); err != nil {
+	if err := service.k8sClient.Get(ctx, key, storageClass); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, errors.NewNotFoundError(fmt.Sprintf("storage class by name %s doens't exit", name))
+			return nil, errors.NewNotFoundError(fmt.Sprintf("storage class by name %s doesn't exist", name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't get storage class by name %s", name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't get storage class by name %s", name)))
 	}
 
 	return storageClass, nil
@@ -54,23 +52,23 @@ func (service storageClassService) Get(name string) (*storagev1.StorageClass, *e
 
 // Create creates a storage class from the given spec
 //todo
-func (service storageClassService) Create(dto *StorageClassDto) (*storagev1.StorageClass, *errors.RestErr) {
+func (service storageClassService) Create(ctx context.Context, dto *StorageClassDto) (*storagev1.StorageClass, *errors.RestErr) {
 	return nil, nil
 }
 
 // Update creates a storage class from the given spec
 //todo
-func (service storageClassService) Update(dto *StorageClassDto, storageClass *storagev1.StorageClass) (*storagev1.StorageClass, *errors.RestErr) {
+func (service storageClassService) Update(ctx context.Context, dto *StorageClassDto, storageClass *storagev1.StorageClass) (*storagev1.StorageClass, *errors.RestErr) {
 	return nil, nil
 }
 
-// List returns all storage classes
-func (service storageClassService) List() (*storagev1.StorageClassList, *errors.RestErr) {
+// List returns all storage classes, storage classes are cluster scoped so they aren't filtered by namespace
+func (service storageClassService) List(ctx context.Context, opts ...client.ListOption) (*storagev1.StorageClassList, *errors.RestErr) {
 	storageClasses := &storagev1.StorageClassList{}
 
-	if err := k8sClient.List(context.Background(), storageClasses, client.InNamespace("default")); err != nil {
+	if err := service.k8sClient.List(ctx, storageClasses, opts...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, errors.NewInternalServerError("failed to get storage class list")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to get storage class list"))
 	}
 
 	return storageClasses, nil
@@ -78,12 +76,12 @@ func (service storageClassService) List() (*storagev1.StorageClassList, *errors.
 
 // Delete a single storage node by name
 //todo
-func (service storageClassService) Delete(storageClass *storagev1.StorageClass) *errors.RestErr {
+func (service storageClassService) Delete(ctx context.Context, storageClass *storagev1.StorageClass) *errors.RestErr {
 	return nil
 }
 
 // Count a list of storage classes
 //todo
-func (service storageClassService) Count() (*int, *errors.RestErr) {
+func (service storageClassService) Count(ctx context.Context, opts ...client.ListOption) (*int, *errors.RestErr) {
 	return nil, nil
 }
@@ -1 +1,46 @@
 package storage_class
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+// newTestService returns storage class service using in memory k8s client holding storage classes by the given names
+func newTestService(names ...string) IService {
+	objs := []client.Object{}
+	for _, name := range names {
+		objs = append(objs, &storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: "kubernetes.io/gce-pd",
+		})
+	}
+	return NewStorageClassService(fake.NewClientService(objs...))
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	service := newTestService("standard")
+
+	storageClass, err := service.Get(ctx, "standard")
+	assert.Nil(t, err)
+	assert.Equal(t, "kubernetes.io/gce-pd", storageClass.Provisioner)
+
+	_, err = service.Get(ctx, "unknown")
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusNotFound, err.Status)
+	}
+}
+
+func TestList(t *testing.T) {
+	service := newTestService("standard", "premium-rwo")
+
+	list, err := service.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, list.Items, 2)
+}
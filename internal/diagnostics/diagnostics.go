@@ -0,0 +1,27 @@
+package diagnostics
+
+import "time"
+
+// CheckDto is the result of single diagnostics check like kubernetes api server connectivity
+// ExpiresAt is the expiry of the checked certificate like the api server TLS certificate
+type CheckDto struct {
+	Name      string     `json:"name"`
+	Healthy   bool       `json:"healthy"`
+	Message   string     `json:"message,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// DiagnosticsDto is the diagnostics of the cluster selected by the call, it's healthy if all its checks are healthy
+type DiagnosticsDto struct {
+	Cluster string     `json:"cluster,omitempty"`
+	Healthy bool       `json:"healthy"`
+	Checks  []CheckDto `json:"checks"`
+}
+
+// add adds the check, the diagnostics become unhealthy if the check isn't healthy
+func (dto *DiagnosticsDto) add(check CheckDto) {
+	if !check.Healthy {
+		dto.Healthy = false
+	}
+	dto.Checks = append(dto.Checks, check)
+}
@@ -0,0 +1,381 @@
+// Package diagnostics internal is the domain layer for the api server readiness and the clusters diagnostics
+// checks the kubernetes api server, kotal custom resources, kotal operator and its admission webhooks and metrics server
+package diagnostics
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/kotalco/api/pkg/certs"
+	"github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/logger"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes"
+	"strings"
+	"time"
+)
+
+const (
+	// readyTimeout is the max duration to wait for the default cluster api server to respond to readiness probes
+	readyTimeout = 2 * time.Second
+	// diagnoseTimeout is the max duration to wait for all the diagnostics checks
+	diagnoseTimeout = 10 * time.Second
+	// certificateExpiryWarning is the remaining validity of the api server TLS certificate reported as unhealthy
+	certificateExpiryWarning = 7 * 24 * time.Hour
+
+	tlsCheck           = "tls"
+	apiServerCheck     = "kubernetes-api-server"
+	crdCheckPrefix     = "crd/"
+	operatorCheck      = "operator"
+	webhooksCheck      = "webhooks"
+	webhookCheckPrefix = "webhook/"
+	metricsServerCheck = "metrics-server"
+)
+
+type diagnosticsService struct {
+	k8sClient    k8s.K8sClientServiceInterface
+	clientset    k8s.ClientsetServiceInterface
+	certificates *certs.Reloader
+}
+
+type IService interface {
+	Ready(ctx context.Context) *errors.RestErr
+	Diagnose(ctx context.Context) *DiagnosticsDto
+}
+
+// NewDiagnosticsService returns diagnostics service of the clusters served by the given clients
+// certificates is the api server TLS certificate reloader, it's nil if the api is served over plain http
+func NewDiagnosticsService(k8sClient k8s.K8sClientServiceInterface, clientset k8s.ClientsetServiceInterface, certificates *certs.Reloader) IService {
+	return diagnosticsService{k8sClient: k8sClient, clientset: clientset, certificates: certificates}
+}
+
+// Ready returns not ready error if the kubernetes api server of the cluster selected by the context can't be reached
+func (service diagnosticsService) Ready(ctx context.Context) *errors.RestErr {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	if _, _, err := service.serverVersion(ctx); err != nil {
+		go logger.Error(service.Ready, err)
+		return errors.NewNotReadyError(fmt.Sprintf("kubernetes api server can't be reached: %s", err))
+	}
+
+	return nil
+}
+
+// Diagnose returns the diagnostics of the cluster selected by the context
+// 0-check the api server TLS certificate isn't about to expire if the api is served over TLS
+// 1-check the kubernetes api server is reachable, the other checks are skipped if it's not
+// 2-check every kotal custom resources group version is installed and serves all its kinds
+// 3-check kotal operator deployment has available replicas
+// 4-check kotal admission webhooks have CA bundle and their services have ready endpoints
+// 5-check metrics server serves pods metrics
+func (service diagnosticsService) Diagnose(ctx context.Context) *DiagnosticsDto {
+	ctx, cancel := context.WithTimeout(ctx, diagnoseTimeout)
+	defer cancel()
+
+	dto := &DiagnosticsDto{Healthy: true, Checks: []CheckDto{}}
+	dto.Cluster, _ = k8s.ClusterFromContext(ctx)
+
+	if service.certificates != nil {
+		dto.add(certificateCheck(service.certificates.Certificate(), time.Now()))
+	}
+
+	clientset, info, err := service.serverVersion(ctx)
+	if err != nil {
+		dto.add(CheckDto{Name: apiServerCheck, Message: err.Error()})
+		return dto
+	}
+	dto.add(CheckDto{Name: apiServerCheck, Healthy: true, Message: "kubernetes " + info.GitVersion})
+
+	for _, check := range service.crds(ctx, clientset) {
+		dto.add(check)
+	}
+	dto.add(service.operator(ctx))
+	for _, check := range service.webhooks(ctx) {
+		dto.add(check)
+	}
+	dto.add(service.metricsServer(ctx))
+
+	return dto
+}
+
+// serverVersion returns the clientset of the cluster selected by the context and its api server version
+func (service diagnosticsService) serverVersion(ctx context.Context) (kubernetes.Interface, *version.Info, error) {
+	clientset, err := service.clientset.Clientset(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := k8s.WithTimeout(ctx, "get")
+	defer cancel()
+
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := &version.Info{}
+	if err := json.Unmarshal(body, info); err != nil {
+		return nil, nil, err
+	}
+
+	return clientset, info, nil
+}
+
+// crds returns check for every kotal group version which is healthy if the api server serves all its kinds
+func (service diagnosticsService) crds(ctx context.Context, clientset kubernetes.Interface) []CheckDto {
+	checks := []CheckDto{}
+
+	for _, groupVersion := range k8s.KotalGroupVersions() {
+		check := CheckDto{Name: crdCheckPrefix + groupVersion.String()}
+		kinds := k8s.KotalKinds(groupVersion)
+
+		resources, err := serverResources(ctx, clientset, groupVersion)
+		if err != nil {
+			check.Message = fmt.Sprintf("%s isn't served: %s", groupVersion, err)
+			if apiErrors.IsNotFound(err) {
+				check.Message = fmt.Sprintf("%s custom resources aren't installed", groupVersion)
+			}
+			checks = append(checks, check)
+			continue
+		}
+
+		served := map[string]bool{}
+		for _, resource := range resources.APIResources {
+			served[resource.Kind] = true
+		}
+		missing := []string{}
+		for _, kind := range kinds {
+			if !served[kind] {
+				missing = append(missing, kind)
+			}
+		}
+
+		if len(missing) == 0 {
+			check.Healthy = true
+			check.Message = "serving " + strings.Join(kinds, ", ")
+		} else {
+			check.Message = fmt.Sprintf("%s aren't served", strings.Join(missing, ", "))
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// serverResources returns the resources served by the group version
+// the discovery client calls can't be cancelled, so the group version is requested using its rest client with the get deadline
+func serverResources(ctx context.Context, clientset kubernetes.Interface, groupVersion schema.GroupVersion) (*metav1.APIResourceList, error) {
+	ctx, cancel := k8s.WithTimeout(ctx, "get")
+	defer cancel()
+
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/apis", groupVersion.Group, groupVersion.Version).Do(ctx).Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	resources := &metav1.APIResourceList{}
+	if err := json.Unmarshal(body, resources); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// certificateCheck returns check which is healthy if the api server TLS certificate is valid for more than a week
+func certificateCheck(certificate *x509.Certificate, now time.Time) CheckDto {
+	expiresAt := certificate.NotAfter.UTC()
+	check := CheckDto{Name: tlsCheck, ExpiresAt: &expiresAt}
+	subject := certificate.Subject.CommonName
+	if subject == "" && len(certificate.DNSNames) != 0 {
+		subject = certificate.DNSNames[0]
+	}
+
+	switch remaining := expiresAt.Sub(now); {
+	case now.Before(certificate.NotBefore):
+		check.Message = fmt.Sprintf("certificate %s isn't valid before %s", subject, certificate.NotBefore.UTC().Format(time.RFC3339))
+	case remaining <= 0:
+		check.Message = fmt.Sprintf("certificate %s expired at %s", subject, expiresAt.Format(time.RFC3339))
+	default:
+		check.Healthy = remaining > certificateExpiryWarning
+		check.Message = fmt.Sprintf("certificate %s expires at %s in %d days", subject, expiresAt.Format(time.RFC3339), int(remaining.Hours()/24))
+	}
+
+	return check
+}
+
+// operator returns check which is healthy if kotal operator deployment has available replicas
+func (service diagnosticsService) operator(ctx context.Context) CheckDto {
+	check := CheckDto{Name: operatorCheck}
+	key := k8s.OperatorDeployment()
+
+	deployment := &appsv1.Deployment{}
+	if err := service.k8sClient.Get(ctx, key, deployment); err != nil {
+		check.Message = fmt.Sprintf("can't get kotal operator deployment %s: %s", key, err)
+		if apiErrors.IsNotFound(err) {
+			check.Message = fmt.Sprintf("kotal operator deployment %s doesn't exist", key)
+		}
+		return check
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	check.Healthy = deployment.Status.AvailableReplicas > 0
+	check.Message = fmt.Sprintf("%s has %d/%d available replicas", key, deployment.Status.AvailableReplicas, replicas)
+	return check
+}
+
+// webhook is kotal admission webhook of mutating or validating webhook configuration
+type webhook struct {
+	name         string
+	clientConfig admissionregistrationv1.WebhookClientConfig
+}
+
+// webhooks returns check for every webhook configuration having kotal webhooks
+// the check is healthy if all its webhooks have CA bundle and their services have ready endpoints
+func (service diagnosticsService) webhooks(ctx context.Context) []CheckDto {
+	configurations := map[string][]webhook{}
+	names := []string{}
+
+	mutating := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := service.k8sClient.List(ctx, mutating); err != nil {
+		return []CheckDto{{Name: webhooksCheck, Message: fmt.Sprintf("can't list mutating webhook configurations: %s", err)}}
+	}
+	for _, configuration := range mutating.Items {
+		for _, item := range configuration.Webhooks {
+			if isKotalWebhook(item.Rules) {
+				configurations[configuration.Name] = append(configurations[configuration.Name], webhook{item.Name, item.ClientConfig})
+			}
+		}
+	}
+
+	validating := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := service.k8sClient.List(ctx, validating); err != nil {
+		return []CheckDto{{Name: webhooksCheck, Message: fmt.Sprintf("can't list validating webhook configurations: %s", err)}}
+	}
+	for _, configuration := range validating.Items {
+		for _, item := range configuration.Webhooks {
+			if isKotalWebhook(item.Rules) {
+				configurations[configuration.Name] = append(configurations[configuration.Name], webhook{item.Name, item.ClientConfig})
+			}
+		}
+	}
+
+	if len(configurations) == 0 {
+		return []CheckDto{{Name: webhooksCheck, Message: "kotal admission webhooks aren't registered"}}
+	}
+
+	for _, configuration := range mutating.Items {
+		if _, ok := configurations[configuration.Name]; ok {
+			names = append(names, configuration.Name)
+		}
+	}
+	for _, configuration := range validating.Items {
+		if _, ok := configurations[configuration.Name]; ok {
+			names = append(names, configuration.Name)
+		}
+	}
+
+	readyServices := map[k8s.ObjectKey]error{}
+	checks := []CheckDto{}
+	for _, name := range names {
+		check := CheckDto{Name: webhookCheckPrefix + name, Healthy: true}
+		problems := []string{}
+
+		for _, item := range configurations[name] {
+			if len(item.clientConfig.CABundle) == 0 {
+				problems = append(problems, fmt.Sprintf("%s has no CA bundle", item.name))
+			}
+
+			serviceRef := item.clientConfig.Service
+			if serviceRef == nil {
+				continue
+			}
+			key := k8s.ObjectKey{Namespace: serviceRef.Namespace, Name: serviceRef.Name}
+			err, checked := readyServices[key]
+			if !checked {
+				err = service.serviceReady(ctx, key)
+				readyServices[key] = err
+			}
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s %s", item.name, err))
+			}
+		}
+
+		if len(problems) != 0 {
+			check.Healthy = false
+			check.Message = strings.Join(problems, ", ")
+		} else {
+			check.Message = fmt.Sprintf("%d webhooks ready", len(configurations[name]))
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// isKotalWebhook returns true if the webhook rules match kotal custom resources
+func isKotalWebhook(rules []admissionregistrationv1.RuleWithOperations) bool {
+	for _, rule := range rules {
+		for _, group := range rule.APIGroups {
+			if strings.HasSuffix(group, ".kotal.io") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// serviceReady returns error if the webhook service has no ready endpoints
+func (service diagnosticsService) serviceReady(ctx context.Context, key k8s.ObjectKey) error {
+	endpoints := &corev1.Endpoints{}
+	if err := service.k8sClient.Get(ctx, key, endpoints); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return fmt.Errorf("service %s doesn't exist", key)
+		}
+		return fmt.Errorf("can't get service %s endpoints: %s", key, err)
+	}
+
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) != 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("service %s has no ready endpoints", key)
+}
+
+// metricsServer returns check which is healthy if metrics server serves pods metrics
+func (service diagnosticsService) metricsServer(ctx context.Context) CheckDto {
+	check := CheckDto{Name: metricsServerCheck}
+
+	metricsClientset, err := service.clientset.MetricsClientset(ctx)
+	if err != nil {
+		check.Message = err.Error()
+		return check
+	}
+
+	ctx, cancel := k8s.WithTimeout(ctx, "list")
+	defer cancel()
+
+	_, err = metricsClientset.MetricsV1beta1().PodMetricses(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		check.Message = fmt.Sprintf("pods metrics aren't available: %s", err)
+		return check
+	}
+
+	check.Healthy = true
+	check.Message = "serving pods metrics"
+	return check
+}
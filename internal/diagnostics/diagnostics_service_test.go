@@ -0,0 +1,251 @@
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/kotalco/api/pkg/simulator"
+	"github.com/stretchr/testify/assert"
+	"io"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	restFake "k8s.io/client-go/rest/fake"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unreachableCluster is clientset service of cluster whose api server can't be reached
+type unreachableCluster struct{}
+
+func (unreachableCluster) Clientset(ctx context.Context) (kubernetes.Interface, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (unreachableCluster) MetricsClientset(ctx context.Context) (metrics.Interface, error) {
+	return nil, errors.New("connection refused")
+}
+
+// discoveryClientset is in memory clientset whose discovery rest client serves its resources lists
+type discoveryClientset struct {
+	*kubernetesFake.Clientset
+}
+
+func (clientset discoveryClientset) Discovery() discovery.DiscoveryInterface {
+	return discoveryClient{DiscoveryInterface: clientset.Clientset.Discovery(), resources: clientset.Resources}
+}
+
+type discoveryClient struct {
+	discovery.DiscoveryInterface
+	resources []*metav1.APIResourceList
+}
+
+// RESTClient returns rest client serving /apis/{group}/{version} from the resources lists
+func (client discoveryClient) RESTClient() rest.Interface {
+	return &restFake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Version: "v1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restFake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"application/json"}}
+			for _, resources := range client.resources {
+				if req.URL.Path == "/apis/"+resources.GroupVersion {
+					body, err := json.Marshal(resources)
+					if err != nil {
+						return nil, err
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(bytes.NewReader(body))}, nil
+				}
+			}
+			return &http.Response{StatusCode: http.StatusNotFound, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}),
+	}
+}
+
+// checks returns the diagnostics checks by name
+func checks(dto *DiagnosticsDto) map[string]CheckDto {
+	byName := map[string]CheckDto{}
+	for _, check := range dto.Checks {
+		byName[check.Name] = check
+	}
+	return byName
+}
+
+func TestDiagnoseHealthyCluster(t *testing.T) {
+	sim := simulator.New()
+	service := NewDiagnosticsService(sim.K8sClient(), sim.ClientsetService(), nil)
+
+	dto := service.Diagnose(k8s.WithCluster(context.Background(), "mainnet"))
+	assert.True(t, dto.Healthy, "%+v", dto.Checks)
+	assert.Equal(t, "mainnet", dto.Cluster)
+
+	byName := checks(dto)
+	assert.Contains(t, byName[apiServerCheck].Message, "v1.23.3")
+	for _, groupVersion := range k8s.KotalGroupVersions() {
+		assert.True(t, byName[crdCheckPrefix+groupVersion.String()].Healthy, groupVersion.String())
+	}
+	assert.True(t, byName[operatorCheck].Healthy)
+	assert.True(t, byName[webhookCheckPrefix+"kotal-mutating-webhook-configuration"].Healthy)
+	assert.True(t, byName[webhookCheckPrefix+"kotal-validating-webhook-configuration"].Healthy)
+	assert.True(t, byName[metricsServerCheck].Healthy)
+
+	assert.Nil(t, service.Ready(context.Background()))
+}
+
+func TestDiagnoseUnreachableCluster(t *testing.T) {
+	service := NewDiagnosticsService(fake.NewClientService(), unreachableCluster{}, nil)
+
+	dto := service.Diagnose(context.Background())
+	assert.False(t, dto.Healthy)
+	// other checks are skipped if the api server can't be reached
+	if assert.Len(t, dto.Checks, 1) {
+		assert.Equal(t, apiServerCheck, dto.Checks[0].Name)
+		assert.Contains(t, dto.Checks[0].Message, "connection refused")
+	}
+
+	err := service.Ready(context.Background())
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusServiceUnavailable, err.Status)
+	}
+}
+
+func TestDiagnoseUnhealthyOperator(t *testing.T) {
+	sim := simulator.New()
+	key := k8s.OperatorDeployment()
+	replicas := int32(2)
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+
+	objs := []client.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "kotal-validating-webhook-configuration"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name: "vnode.kb.io",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Name: "kotal-webhook-service", Namespace: "kotal"},
+				},
+				Rules: []admissionregistrationv1.RuleWithOperations{{
+					Rule: admissionregistrationv1.Rule{APIGroups: []string{"ethereum.kotal.io"}},
+				}},
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			}},
+		},
+		// webhooks of other operators aren't checked
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "cert-manager-webhook"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name: "webhook.cert-manager.io",
+				Rules: []admissionregistrationv1.RuleWithOperations{{
+					Rule: admissionregistrationv1.Rule{APIGroups: []string{"cert-manager.io"}},
+				}},
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			}},
+		},
+	}
+	service := NewDiagnosticsService(fake.NewClientService(objs...), sim.ClientsetService(), nil)
+
+	dto := service.Diagnose(context.Background())
+	assert.False(t, dto.Healthy)
+
+	byName := checks(dto)
+	assert.True(t, byName[apiServerCheck].Healthy)
+	assert.False(t, byName[operatorCheck].Healthy)
+	assert.Contains(t, byName[operatorCheck].Message, "0/2")
+
+	webhook := byName[webhookCheckPrefix+"kotal-validating-webhook-configuration"]
+	assert.False(t, webhook.Healthy)
+	assert.Contains(t, webhook.Message, "vnode.kb.io has no CA bundle")
+	assert.Contains(t, webhook.Message, "kotal/kotal-webhook-service doesn't exist")
+	assert.NotContains(t, byName, webhookCheckPrefix+"cert-manager-webhook")
+
+	// kotal operator isn't deployed
+	dto = NewDiagnosticsService(fake.NewClientService(), sim.ClientsetService(), nil).Diagnose(context.Background())
+	byName = checks(dto)
+	assert.Contains(t, byName[operatorCheck].Message, "doesn't exist")
+	assert.False(t, byName[webhooksCheck].Healthy)
+}
+
+func TestCRDs(t *testing.T) {
+	groupVersions := k8s.KotalGroupVersions()
+	if !assert.NotEmpty(t, groupVersions) {
+		return
+	}
+	served := groupVersions[0]
+	kinds := k8s.KotalKinds(served)
+	if !assert.NotEmpty(t, kinds) {
+		return
+	}
+
+	clientset := kubernetesFake.NewSimpleClientset()
+	clientset.Resources = []*metav1.APIResourceList{{
+		GroupVersion: served.String(),
+		// the first kind isn't served
+		APIResources: []metav1.APIResource{},
+	}}
+	for _, kind := range kinds[1:] {
+		clientset.Resources[0].APIResources = append(clientset.Resources[0].APIResources, metav1.APIResource{Kind: kind})
+	}
+
+	byName := map[string]CheckDto{}
+	for _, check := range (diagnosticsService{}).crds(context.Background(), discoveryClientset{clientset}) {
+		byName[check.Name] = check
+	}
+
+	assert.Len(t, byName, len(groupVersions))
+	partial := byName[crdCheckPrefix+served.String()]
+	assert.False(t, partial.Healthy)
+	assert.Equal(t, kinds[0]+" aren't served", partial.Message)
+	for _, groupVersion := range groupVersions[1:] {
+		assert.False(t, byName[crdCheckPrefix+groupVersion.String()].Healthy, groupVersion.String())
+	}
+}
+
+func TestCertificateCheck(t *testing.T) {
+	now := time.Now()
+	certificate := func(notBefore, notAfter time.Time) *x509.Certificate {
+		return &x509.Certificate{
+			Subject:   pkix.Name{CommonName: "api.kotal.io"},
+			NotBefore: notBefore,
+			NotAfter:  notAfter,
+		}
+	}
+
+	check := certificateCheck(certificate(now.Add(-time.Hour), now.Add(90*24*time.Hour+time.Minute)), now)
+	assert.True(t, check.Healthy)
+	assert.Contains(t, check.Message, "api.kotal.io expires at")
+	assert.Contains(t, check.Message, "in 90 days")
+	if assert.NotNil(t, check.ExpiresAt) {
+		assert.WithinDuration(t, now.Add(90*24*time.Hour), *check.ExpiresAt, time.Hour)
+	}
+
+	// certificates about to expire are reported before they expire
+	check = certificateCheck(certificate(now.Add(-time.Hour), now.Add(3*24*time.Hour)), now)
+	assert.False(t, check.Healthy)
+
+	check = certificateCheck(certificate(now.Add(-time.Hour), now.Add(-time.Minute)), now)
+	assert.False(t, check.Healthy)
+	assert.Contains(t, check.Message, "expired at")
+
+	check = certificateCheck(certificate(now.Add(time.Hour), now.Add(90*24*time.Hour)), now)
+	assert.False(t, check.Healthy)
+	assert.Contains(t, check.Message, "isn't valid before")
+}
@@ -1,54 +1,58 @@
 package ethereum
 
 import (
+	"fmt"
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 // ImportedAccount is account derived from private key
 type ImportedAccount struct {
-	PrivateKeySecretName string `json:"privateKeySecretName"`
-	PasswordSecretName   string `json:"passwordSecretName"`
+	PrivateKeySecretName string `json:"privateKeySecretName" validate:"dns1123subdomain"`
+	PasswordSecretName   string `json:"passwordSecretName" validate:"dns1123subdomain"`
 }
 
 // Node is Ethereum node
 type EthereumDto struct {
 	models.Time
 	k8s.MetaDataDto
-	Network                  string           `json:"network"`
-	Client                   string           `json:"client"`
-	Logging                  string           `json:"logging"`
-	NodePrivateKeySecretName string           `json:"nodePrivateKeySecretName"`
-	SyncMode                 string           `json:"syncMode"`
-	P2PPort                  uint             `json:"p2pPort"`
+	Network                  string           `json:"network" validate:"required,enum=ethereumNetwork"`
+	Client                   string           `json:"client" validate:"required,enum=ethereumClient"`
+	Logging                  string           `json:"logging" validate:"enum=ethereumLogging"`
+	NodePrivateKeySecretName string           `json:"nodePrivateKeySecretName" validate:"dns1123subdomain"`
+	SyncMode                 string           `json:"syncMode" validate:"enum=ethereumSyncMode"`
+	P2PPort                  uint             `json:"p2pPort" validate:"port"`
 	StaticNodes              *[]string        `json:"staticNodes"`
 	Bootnodes                *[]string        `json:"bootnodes"`
 	Miner                    *bool            `json:"miner"`
 	Coinbase                 string           `json:"coinbase"`
 	Import                   *ImportedAccount `json:"import"`
 	RPC                      *bool            `json:"rpc"`
-	RPCPort                  uint             `json:"rpcPort"`
-	RPCAPI                   []string         `json:"rpcAPI"`
+	RPCPort                  uint             `json:"rpcPort" validate:"port"`
+	RPCAPI                   []string         `json:"rpcAPI" validate:"enum=ethereumAPI"`
 	WS                       *bool            `json:"ws"`
-	WSPort                   uint             `json:"wsPort"`
-	WSAPI                    []string         `json:"wsAPI"`
+	WSPort                   uint             `json:"wsPort" validate:"port"`
+	WSAPI                    []string         `json:"wsAPI" validate:"enum=ethereumAPI"`
 	GraphQL                  *bool            `json:"graphql"`
-	GraphQLPort              uint             `json:"graphqlPort"`
+	GraphQLPort              uint             `json:"graphqlPort" validate:"port"`
 	Hosts                    []string         `json:"hosts"`
 	CORSDomains              []string         `json:"corsDomains"`
-	CPU                      string           `json:"cpu"`
-	CPULimit                 string           `json:"cpuLimit"`
-	Memory                   string           `json:"memory"`
-	MemoryLimit              string           `json:"memoryLimit"`
-	Storage                  string           `json:"storage"`
-	StorageClass             *string          `json:"storageClass"`
+	CPU                      string           `json:"cpu" validate:"quantity"`
+	CPULimit                 string           `json:"cpuLimit" validate:"quantity"`
+	Memory                   string           `json:"memory" validate:"quantity"`
+	MemoryLimit              string           `json:"memoryLimit" validate:"quantity"`
+	Storage                  string           `json:"storage" validate:"quantity"`
+	StorageClass             *string          `json:"storageClass" validate:"dns1123subdomain"`
 }
 type EthereumListDto []EthereumDto
 
 func (dto EthereumDto) FromEthereumNode(node *ethereumv1alpha1.Node) *EthereumDto {
 	dto.Name = node.Name
+	dto.Namespace = node.Namespace
 	dto.Time = models.Time{CreatedAt: node.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.Network = node.Spec.Network
 	dto.Client = string(node.Spec.Client)
@@ -114,3 +118,138 @@ func (nodes EthereumListDto) FromEthereumNode(models []ethereumv1alpha1.Node) Et
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("ethereumNetwork",
+		ethereumv1alpha1.MainNetwork,
+		ethereumv1alpha1.RopstenNetwork,
+		ethereumv1alpha1.RinkebyNetwork,
+		ethereumv1alpha1.GoerliNetwork,
+		ethereumv1alpha1.XDaiNetwork,
+		ethereumv1alpha1.KottiNetwork,
+		ethereumv1alpha1.ClassicNetwork,
+		ethereumv1alpha1.MordorNetwork,
+		ethereumv1alpha1.DevNetwork,
+	)
+	validation.RegisterEnum("ethereumClient",
+		string(ethereumv1alpha1.BesuClient),
+		string(ethereumv1alpha1.GethClient),
+		string(ethereumv1alpha1.NethermindClient),
+	)
+	validation.RegisterEnum("ethereumSyncMode",
+		string(ethereumv1alpha1.FastSynchronization),
+		string(ethereumv1alpha1.FullSynchronization),
+		string(ethereumv1alpha1.LightSynchronization),
+		string(ethereumv1alpha1.SnapSynchronization),
+	)
+	validation.RegisterEnum("ethereumLogging",
+		string(sharedAPI.NoLogs),
+		string(sharedAPI.FatalLogs),
+		string(sharedAPI.ErrorLogs),
+		string(sharedAPI.WarnLogs),
+		string(sharedAPI.InfoLogs),
+		string(sharedAPI.DebugLogs),
+		string(sharedAPI.TraceLogs),
+		string(sharedAPI.AllLogs),
+	)
+	validation.RegisterEnum("ethereumAPI",
+		string(ethereumv1alpha1.AdminAPI),
+		string(ethereumv1alpha1.CliqueAPI),
+		string(ethereumv1alpha1.DebugAPI),
+		string(ethereumv1alpha1.EEAAPI),
+		string(ethereumv1alpha1.ETHAPI),
+		string(ethereumv1alpha1.IBFTAPI),
+		string(ethereumv1alpha1.MinerAPI),
+		string(ethereumv1alpha1.NetworkAPI),
+		string(ethereumv1alpha1.PermissionAPI),
+		string(ethereumv1alpha1.PluginsAPI),
+		string(ethereumv1alpha1.PrivacyAPI),
+		string(ethereumv1alpha1.TransactionPoolAPI),
+		string(ethereumv1alpha1.Web3API),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the ethereum node
+func (dto *EthereumDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	miner := dto.Miner != nil && *dto.Miner
+	if miner && dto.Coinbase == "" && operation == validation.Create {
+		errs.Add("coinbase", "is required if miner is true")
+	}
+	if dto.Coinbase != "" && dto.Miner != nil && !*dto.Miner {
+		errs.Add("miner", "must be true if coinbase is provided")
+	}
+
+	client := ethereumv1alpha1.EthereumClient(dto.Client)
+	if client == ethereumv1alpha1.BesuClient && dto.Import != nil {
+		errs.Add("import", "is not supported by besu client")
+	}
+	if client == ethereumv1alpha1.NethermindClient && dto.GraphQL != nil && *dto.GraphQL {
+		errs.Add("graphql", "is not supported by nethermind client")
+	}
+
+	syncMode := ethereumv1alpha1.SynchronizationMode(dto.SyncMode)
+	if client != "" && client != ethereumv1alpha1.GethClient && (syncMode == ethereumv1alpha1.LightSynchronization || syncMode == ethereumv1alpha1.SnapSynchronization) {
+		errs.Add("syncMode", fmt.Sprintf("%s sync mode is supported by geth client only", syncMode))
+	}
+
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToEthereumNodeSpec replaces the node spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the node defaulting
+func (dto *EthereumDto) ToEthereumNodeSpec(spec *ethereumv1alpha1.NodeSpec) {
+	spec.Network = dto.Network
+	spec.Client = ethereumv1alpha1.EthereumClient(dto.Client)
+	spec.Logging = sharedAPI.VerbosityLevel(dto.Logging)
+	spec.NodePrivateKeySecretName = dto.NodePrivateKeySecretName
+	spec.SyncMode = ethereumv1alpha1.SynchronizationMode(dto.SyncMode)
+	spec.P2PPort = dto.P2PPort
+	spec.Miner = dto.Miner != nil && *dto.Miner
+	spec.Coinbase = ethereumv1alpha1.EthereumAddress(dto.Coinbase)
+	spec.Import = nil
+	if dto.Import != nil {
+		spec.Import = &ethereumv1alpha1.ImportedAccount{
+			PrivateKeySecretName: dto.Import.PrivateKeySecretName,
+			PasswordSecretName:   dto.Import.PasswordSecretName,
+		}
+	}
+	spec.RPC = dto.RPC != nil && *dto.RPC
+	spec.RPCPort = dto.RPCPort
+	spec.RPCAPI = toAPIs(dto.RPCAPI)
+	spec.WS = dto.WS != nil && *dto.WS
+	spec.WSPort = dto.WSPort
+	spec.WSAPI = toAPIs(dto.WSAPI)
+	spec.GraphQL = dto.GraphQL != nil && *dto.GraphQL
+	spec.GraphQLPort = dto.GraphQLPort
+	spec.Hosts = dto.Hosts
+	spec.CORSDomains = dto.CORSDomains
+	spec.Bootnodes = toEnodes(dto.Bootnodes)
+	spec.StaticNodes = toEnodes(dto.StaticNodes)
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
+
+func toAPIs(apis []string) []ethereumv1alpha1.API {
+	var result []ethereumv1alpha1.API
+	for _, api := range apis {
+		result = append(result, ethereumv1alpha1.API(api))
+	}
+	return result
+}
+
+func toEnodes(enodes *[]string) []ethereumv1alpha1.Enode {
+	if enodes == nil {
+		return nil
+	}
+	var result []ethereumv1alpha1.Enode
+	for _, enode := range *enodes {
+		result = append(result, ethereumv1alpha1.Enode(enode))
+	}
+	return result
+}
@@ -11,47 +11,48 @@ import (
 	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
 	sharedAPI "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type ethereumService struct{}
+type ethereumService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(types.NamespacedName) (*ethereumv1alpha1.Node, *errors.RestErr)
-	Create(*EthereumDto) (*ethereumv1alpha1.Node, *errors.RestErr)
-	Update(*EthereumDto, *ethereumv1alpha1.Node) (*ethereumv1alpha1.Node, *errors.RestErr)
-	List(namespace string) (*ethereumv1alpha1.NodeList, *errors.RestErr)
-	Delete(node *ethereumv1alpha1.Node) *errors.RestErr
-	Count(namespace string) (*int, *errors.RestErr)
+	Get(context.Context, types.NamespacedName) (*ethereumv1alpha1.Node, *errors.RestErr)
+	Create(context.Context, *EthereumDto) (*ethereumv1alpha1.Node, *errors.RestErr)
+	Update(context.Context, *EthereumDto, *ethereumv1alpha1.Node) (*ethereumv1alpha1.Node, *errors.RestErr)
+	Patch(context.Context, *EthereumDto, *ethereumv1alpha1.Node) (*ethereumv1alpha1.Node, *errors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*ethereumv1alpha1.NodeList, *errors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr)
+	Delete(ctx context.Context, node *ethereumv1alpha1.Node) *errors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewEthereumService() IService {
-	return ethereumService{}
+func NewEthereumService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return ethereumService{k8sClient: k8sClient}
 }
 
 // Get returns a single ethereum node by name
-func (service ethereumService) Get(namespacedName types.NamespacedName) (*ethereumv1alpha1.Node, *errors.RestErr) {
+func (service ethereumService) Get(ctx context.Context, namespacedName types.NamespacedName) (*ethereumv1alpha1.Node, *errors.RestErr) {
 	node := &ethereumv1alpha1.Node{}
 
-	if err := k8sClient.Get(context.Background(), namespacedName, node); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, node); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, errors.NewNotFoundError(fmt.Sprintf("node by name %s doesn't exist", namespacedName.Name))
+			return nil, errors.NewNodeNotFoundError(fmt.Sprintf("node by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName.Name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName.Name)))
 	}
 
 	return node, nil
 }
 
 // Create creates ethereum node from the given spec
-func (service ethereumService) Create(dto *EthereumDto) (*ethereumv1alpha1.Node, *errors.RestErr) {
+func (service ethereumService) Create(ctx context.Context, dto *EthereumDto) (*ethereumv1alpha1.Node, *errors.RestErr) {
 	node := &ethereumv1alpha1.Node{
 		ObjectMeta: dto.ObjectMetaFromMetadataDto(),
 		Spec: ethereumv1alpha1.NodeSpec{
@@ -65,24 +66,20 @@ func (service ethereumService) Create(dto *EthereumDto) (*ethereumv1alpha1.Node,
 		},
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
-	}
-
-	err := k8sClient.Create(context.Background(), node)
+	err := service.k8sClient.Create(ctx, node)
 	if err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, errors.NewBadRequestError(fmt.Sprintf("node by name %s already exist", node.Name))
+			return nil, errors.NewAlreadyExistsError(fmt.Sprintf("node by name %s already exists", node.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, errors.NewInternalServerError("failed to create node")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to create node"))
 	}
 
 	return node, nil
 }
 
 // Update updates a single ethereum node by name from spec
-func (service ethereumService) Update(dto *EthereumDto, node *ethereumv1alpha1.Node) (*ethereumv1alpha1.Node, *errors.RestErr) {
+func (service ethereumService) Update(ctx context.Context, dto *EthereumDto, node *ethereumv1alpha1.Node) (*ethereumv1alpha1.Node, *errors.RestErr) {
 
 	if dto.Logging != "" {
 		node.Spec.Logging = sharedAPI.VerbosityLevel(dto.Logging)
@@ -161,21 +158,14 @@ func (service ethereumService) Update(dto *EthereumDto, node *ethereumv1alpha1.N
 		node.Spec.CORSDomains = dto.CORSDomains
 	}
 
-	var bootnodes, staticNodes []ethereumv1alpha1.Enode
-
+	// omitted bootnodes and static nodes are left unchanged, empty list clears them
 	if dto.Bootnodes != nil {
-		for _, bootnode := range *dto.Bootnodes {
-			bootnodes = append(bootnodes, ethereumv1alpha1.Enode(bootnode))
-		}
+		node.Spec.Bootnodes = toEnodes(dto.Bootnodes)
 	}
-	node.Spec.Bootnodes = bootnodes
 
 	if dto.StaticNodes != nil {
-		for _, staticNode := range *dto.StaticNodes {
-			staticNodes = append(staticNodes, ethereumv1alpha1.Enode(staticNode))
-		}
+		node.Spec.StaticNodes = toEnodes(dto.StaticNodes)
 	}
-	node.Spec.StaticNodes = staticNodes
 
 	if dto.CPU != "" {
 		node.Spec.CPU = dto.CPU
@@ -193,50 +183,77 @@ func (service ethereumService) Update(dto *EthereumDto, node *ethereumv1alpha1.N
 		node.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
-	}
-
-	err := k8sClient.Update(context.Background(), node)
+	err := service.k8sClient.Update(ctx, node)
 	if err != nil {
 		go logger.Error(service.Update, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name)))
+	}
+
+	return node, nil
+}
+
+// Patch replaces node spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service ethereumService) Patch(ctx context.Context, dto *EthereumDto, node *ethereumv1alpha1.Node) (*ethereumv1alpha1.Node, *errors.RestErr) {
+	dto.ToEthereumNodeSpec(&node.Spec)
+
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't patch node by name %s", node.Name)))
 	}
 
 	return node, nil
 }
 
 // List returns all ethereum nodes
-func (service ethereumService) List(namespace string) (*ethereumv1alpha1.NodeList, *errors.RestErr) {
+func (service ethereumService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*ethereumv1alpha1.NodeList, *errors.RestErr) {
 	nodes := &ethereumv1alpha1.NodeList{}
 
-	err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace))
+	err := service.k8sClient.List(ctx, nodes, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
 	if err != nil {
 		go logger.Error(service.List, err)
-		return nil, errors.NewInternalServerError("failed to get all nodes")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to get all nodes"))
 	}
 
 	return nodes, nil
 }
 
+// Watch watches nodes changes in the namespace starting after the given resource version
+// all existing nodes are sent as added events if the resource version is empty
+func (service ethereumService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &ethereumv1alpha1.NodeList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+	if err != nil {
+		go logger.Error(service.Watch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to watch nodes"))
+	}
+
+	return watcher, nil
+}
+
 // Count returns the length of ethereum nodes
-func (service ethereumService) Count(namespace string) (*int, *errors.RestErr) {
-	nodes, err := service.List(namespace)
+func (service ethereumService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &ethereumv1alpha1.NodeList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
 	if err != nil {
-		return nil, err
+		go logger.Error(service.Count, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to count nodes"))
 	}
 
-	length := len(nodes.Items)
 	return &length, nil
 }
 
 // Delete a single ethereum node by name
-func (service ethereumService) Delete(node *ethereumv1alpha1.Node) *errors.RestErr {
-	err := k8sClient.Delete(context.Background(), node)
+func (service ethereumService) Delete(ctx context.Context, node *ethereumv1alpha1.Node) *errors.RestErr {
+	err := service.k8sClient.Delete(ctx, node)
 
 	if err != nil {
 		go logger.Error(service.Delete, err)
-		return errors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name))
+		return errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name)))
 	}
 
 	return nil
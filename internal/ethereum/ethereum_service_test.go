@@ -1 +1,110 @@
 package ethereum
+
+import (
+	"context"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	"net/http"
+	"net/url"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+const enode = "enode://2281549869465d98e90cebc45e1d6834a01465a990add7bcf07a49287e7e66b50ca27f9c70a46190cef7ad746dd5d5b6b9dfee0c9954104c8e9bd0d42758ec58@10.5.0.2:30300"
+
+// newTestNode creates geth node in the default namespace using in memory k8s client
+func newTestNode(t *testing.T) (IService, *ethereumv1alpha1.Node) {
+	service := NewEthereumService(fake.NewClientService())
+	storageClass := "standard"
+	node, err := service.Create(context.Background(), &EthereumDto{
+		MetaDataDto:              k8s.MetaDataDto{Name: "my-node"},
+		Network:                  "goerli",
+		Client:                   "geth",
+		NodePrivateKeySecretName: "my-node-key",
+		StorageClass:             &storageClass,
+	})
+	assert.Nil(t, err)
+	return service, node
+}
+
+func TestCreate(t *testing.T) {
+	_, node := newTestNode(t)
+
+	assert.Equal(t, "default", node.Namespace)
+	assert.Equal(t, "goerli", node.Spec.Network)
+	assert.Equal(t, ethereumv1alpha1.GethClient, node.Spec.Client)
+	assert.Equal(t, "my-node-key", node.Spec.NodePrivateKeySecretName)
+	assert.Equal(t, "standard", *node.Spec.StorageClass)
+	// JSON-RPC server is enabled for new nodes
+	assert.True(t, node.Spec.RPC)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	miner := true
+	rpc := false
+	node, err := service.Update(ctx, &EthereumDto{
+		Miner:     &miner,
+		Coinbase:  "0x5A0b54D5dc17e0AadC383d2db43B0a0D3E029c4c",
+		RPC:       &rpc,
+		RPCPort:   8599,
+		Bootnodes: &[]string{enode},
+	}, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.Miner)
+	assert.EqualValues(t, "0x5A0b54D5dc17e0AadC383d2db43B0a0D3E029c4c", node.Spec.Coinbase)
+	assert.False(t, node.Spec.RPC)
+	// rpc port is set only if the JSON-RPC server is enabled
+	assert.Zero(t, node.Spec.RPCPort)
+	assert.Equal(t, []ethereumv1alpha1.Enode{enode}, node.Spec.Bootnodes)
+
+	// omitted fields are left unchanged, empty list clears the nodes
+	node, err = service.Update(ctx, &EthereumDto{StaticNodes: &[]string{}}, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.Miner)
+	assert.Equal(t, []ethereumv1alpha1.Enode{enode}, node.Spec.Bootnodes)
+	assert.Empty(t, node.Spec.StaticNodes)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	dto := new(EthereumDto).FromEthereumNode(node)
+	dto.RPC = nil
+	dto.StaticNodes = &[]string{enode}
+	dto.RPCAPI = []string{"eth", "net"}
+
+	node, err := service.Patch(ctx, dto, node)
+	assert.Nil(t, err)
+	// removed fields are reset
+	assert.False(t, node.Spec.RPC)
+	assert.Equal(t, []ethereumv1alpha1.Enode{enode}, node.Spec.StaticNodes)
+	assert.Equal(t, []ethereumv1alpha1.API{ethereumv1alpha1.ETHAPI, ethereumv1alpha1.NetworkAPI}, node.Spec.RPCAPI)
+	assert.Equal(t, "my-node-key", node.Spec.NodePrivateKeySecretName)
+}
+
+// slowClient is k8s client whose get calls exceed their deadline
+type slowClient struct {
+	k8s.K8sClientServiceInterface
+}
+
+func (slowClient) Get(ctx context.Context, key k8s.ObjectKey, obj client.Object) error {
+	return &url.Error{Op: "Get", URL: "https://kubernetes.default", Err: context.DeadlineExceeded}
+}
+
+func TestGetTimeout(t *testing.T) {
+	service := NewEthereumService(slowClient{fake.NewClientService()})
+
+	_, err := service.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "my-node"})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, http.StatusGatewayTimeout, err.Status)
+		assert.Equal(t, restErrors.CodeTimeout, err.Code)
+	}
+}
@@ -4,36 +4,39 @@ import (
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 type BeaconNodeDto struct {
 	models.Time
 	k8s.MetaDataDto
-	Network string `json:"network"`
-	Client  string `json:"client"`
-	// todo: required only for prysm and network is not mainnet
-	Eth1Endpoints *[]string `json:"eth1Endpoints"`
+	Network string `json:"network" validate:"required"`
+	Client  string `json:"client" validate:"required,enum=ethereum2Client"`
+	// required only for prysm and network is not mainnet
+	Eth1Endpoints *[]string `json:"eth1Endpoints" validate:"url=http|https|ws|wss"`
 	REST          *bool     `json:"rest"`
 	RESTHost      string    `json:"restHost"`
-	RESTPort      uint      `json:"restPort"`
+	RESTPort      uint      `json:"restPort" validate:"port"`
 	RPC           *bool     `json:"rpc"`
 	RPCHost       string    `json:"rpcHost"`
-	RPCPort       uint      `json:"rpcPort"`
+	RPCPort       uint      `json:"rpcPort" validate:"port"`
 	GRPC          *bool     `json:"grpc"`
 	GRPCHost      string    `json:"grpcHost"`
-	GRPCPort      uint      `json:"grpcPort"`
-	CPU           string    `json:"cpu"`
-	CPULimit      string    `json:"cpuLimit"`
-	Memory        string    `json:"memory"`
-	MemoryLimit   string    `json:"memoryLimit"`
-	Storage       string    `json:"storage"`
-	StorageClass  *string   `json:"storageClass"`
+	GRPCPort      uint      `json:"grpcPort" validate:"port"`
+	CPU           string    `json:"cpu" validate:"quantity"`
+	CPULimit      string    `json:"cpuLimit" validate:"quantity"`
+	Memory        string    `json:"memory" validate:"quantity"`
+	MemoryLimit   string    `json:"memoryLimit" validate:"quantity"`
+	Storage       string    `json:"storage" validate:"quantity"`
+	StorageClass  *string   `json:"storageClass" validate:"dns1123subdomain"`
 }
 type BeaconNodeListDto []BeaconNodeDto
 
 func (dto BeaconNodeDto) FromEthereum2BeaconNode(node *ethereum2v1alpha1.BeaconNode) *BeaconNodeDto {
 	dto.Name = node.Name
+	dto.Namespace = node.Namespace
 	dto.Time = models.Time{CreatedAt: node.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.Network = node.Spec.Network
 	dto.Client = string(node.Spec.Client)
@@ -64,3 +67,53 @@ func (nodes BeaconNodeListDto) FromEthereum2BeaconNode(beaconnodeList []ethereum
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("ethereum2Client",
+		string(ethereum2v1alpha1.TekuClient),
+		string(ethereum2v1alpha1.PrysmClient),
+		string(ethereum2v1alpha1.LighthouseClient),
+		string(ethereum2v1alpha1.NimbusClient),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the beacon node
+func (dto *BeaconNodeDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	prysm := ethereum2v1alpha1.Ethereum2Client(dto.Client) == ethereum2v1alpha1.PrysmClient
+	noEndpoints := dto.Eth1Endpoints == nil || len(*dto.Eth1Endpoints) == 0
+	if prysm && dto.Network != "mainnet" && noEndpoints && operation == validation.Create {
+		errs.Add("eth1Endpoints", "is required if client is prysm and network is not mainnet")
+	}
+
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToEthereum2BeaconNodeSpec replaces the beacon node spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the beacon node defaulting
+func (dto *BeaconNodeDto) ToEthereum2BeaconNodeSpec(spec *ethereum2v1alpha1.BeaconNodeSpec) {
+	spec.Network = dto.Network
+	spec.Client = ethereum2v1alpha1.Ethereum2Client(dto.Client)
+	// dto endpoints may point to the spec endpoints, so they're read before the spec is reset
+	var endpoints []string
+	if dto.Eth1Endpoints != nil {
+		endpoints = *dto.Eth1Endpoints
+	}
+	spec.Eth1Endpoints = endpoints
+	spec.REST = dto.REST != nil && *dto.REST
+	spec.RESTHost = dto.RESTHost
+	spec.RESTPort = dto.RESTPort
+	spec.RPC = dto.RPC != nil && *dto.RPC
+	spec.RPCHost = dto.RPCHost
+	spec.RPCPort = dto.RPCPort
+	spec.GRPC = dto.GRPC != nil && *dto.GRPC
+	spec.GRPCHost = dto.GRPCHost
+	spec.GRPCPort = dto.GRPCPort
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
@@ -11,47 +11,48 @@ import (
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type beaconNodeService struct{}
+type beaconNodeService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(types.NamespacedName) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr)
-	Create(dto *BeaconNodeDto) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr)
-	Update(*BeaconNodeDto, *ethereum2v1alpha1.BeaconNode) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr)
-	List(namespace string) (*ethereum2v1alpha1.BeaconNodeList, *errors.RestErr)
-	Delete(node *ethereum2v1alpha1.BeaconNode) *errors.RestErr
-	Count(namespace string) (*int, *errors.RestErr)
+	Get(context.Context, types.NamespacedName) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr)
+	Create(ctx context.Context, dto *BeaconNodeDto) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr)
+	Update(context.Context, *BeaconNodeDto, *ethereum2v1alpha1.BeaconNode) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr)
+	Patch(context.Context, *BeaconNodeDto, *ethereum2v1alpha1.BeaconNode) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*ethereum2v1alpha1.BeaconNodeList, *errors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr)
+	Delete(ctx context.Context, node *ethereum2v1alpha1.BeaconNode) *errors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewBeaconNodeService() IService {
-	return beaconNodeService{}
+func NewBeaconNodeService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return beaconNodeService{k8sClient: k8sClient}
 }
 
 // Get gets a single ethereum 2.0 beacon node by name
-func (service beaconNodeService) Get(namespacedNamed types.NamespacedName) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr) {
+func (service beaconNodeService) Get(ctx context.Context, namespacedNamed types.NamespacedName) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr) {
 	node := &ethereum2v1alpha1.BeaconNode{}
 
-	if err := k8sClient.Get(context.Background(), namespacedNamed, node); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedNamed, node); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, errors.NewNotFoundError(fmt.Sprintf("beacon node by name %s doesn't exist", namespacedNamed.Name))
+			return nil, errors.NewNodeNotFoundError(fmt.Sprintf("beacon node by name %s doesn't exist", namespacedNamed.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't get beacon node by name %s", namespacedNamed.Name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't get beacon node by name %s", namespacedNamed.Name)))
 	}
 
 	return node, nil
 }
 
 // Create creates ethereum 2.0 beacon node from spec
-func (service beaconNodeService) Create(dto *BeaconNodeDto) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr) {
+func (service beaconNodeService) Create(ctx context.Context, dto *BeaconNodeDto) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr) {
 
 	var endpoints []string
 	if dto.Eth1Endpoints != nil {
@@ -73,23 +74,19 @@ func (service beaconNodeService) Create(dto *BeaconNodeDto) (*ethereum2v1alpha1.
 		},
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		beaconnode.Default()
-	}
-
-	if err := k8sClient.Create(context.Background(), beaconnode); err != nil {
+	if err := service.k8sClient.Create(ctx, beaconnode); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, errors.NewBadRequestError(fmt.Sprintf("beacon node by name %s already exist", dto.Name))
+			return nil, errors.NewAlreadyExistsError(fmt.Sprintf("beacon node by name %s already exists", dto.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, errors.NewInternalServerError("failed to create beacon node")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to create beacon node"))
 	}
 
 	return beaconnode, nil
 }
 
 // Update updates ethereum 2.0 beacon node by name from spec
-func (service beaconNodeService) Update(dto *BeaconNodeDto, node *ethereum2v1alpha1.BeaconNode) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr) {
+func (service beaconNodeService) Update(ctx context.Context, dto *BeaconNodeDto, node *ethereum2v1alpha1.BeaconNode) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr) {
 	endpoints := dto.Eth1Endpoints
 	if endpoints != nil {
 		// all clients can clear ethereum endpoints
@@ -156,49 +153,75 @@ func (service beaconNodeService) Update(dto *BeaconNodeDto, node *ethereum2v1alp
 		node.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Update, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't update node by name  %s", node.Name)))
 	}
 
-	if err := k8sClient.Update(context.Background(), node); err != nil {
-		go logger.Error(service.Update, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't update node by name  %s", node.Name))
+	return node, nil
+}
+
+// Patch replaces node spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service beaconNodeService) Patch(ctx context.Context, dto *BeaconNodeDto, node *ethereum2v1alpha1.BeaconNode) (*ethereum2v1alpha1.BeaconNode, *errors.RestErr) {
+	dto.ToEthereum2BeaconNodeSpec(&node.Spec)
+
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't patch node by name %s", node.Name)))
 	}
 
 	return node, nil
 }
 
 // List returns all ethereum 2.0 beacon nodes
-func (service beaconNodeService) List(namespace string) (*ethereum2v1alpha1.BeaconNodeList, *errors.RestErr) {
+func (service beaconNodeService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*ethereum2v1alpha1.BeaconNodeList, *errors.RestErr) {
 	nodes := &ethereum2v1alpha1.BeaconNodeList{}
 
-	if err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace)); err != nil {
+	if err := service.k8sClient.List(ctx, nodes, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, errors.NewInternalServerError("failed to get all beacon nodes")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to get all beacon nodes"))
 	}
 
 	return nodes, nil
 }
 
-// Count returns total number of beacon nodes
-func (service beaconNodeService) Count(namespace string) (*int, *errors.RestErr) {
-	nodes, err := service.List(namespace)
+// Watch watches beacon nodes changes in the namespace starting after the given resource version
+// all existing beacon nodes are sent as added events if the resource version is empty
+func (service beaconNodeService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &ethereum2v1alpha1.BeaconNodeList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
 	if err != nil {
-		return nil, err
+		go logger.Error(service.Watch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to watch beacon nodes"))
 	}
 
-	length := len(nodes.Items)
+	return watcher, nil
+}
+
+// Count returns total number of beacon nodes
+func (service beaconNodeService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &ethereum2v1alpha1.BeaconNodeList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
+	if err != nil {
+		go logger.Error(service.Count, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to count beacon nodes"))
+	}
 
 	return &length, nil
 }
 
 // Delete deletes ethereum 2.0 beacon node by name
-func (service beaconNodeService) Delete(node *ethereum2v1alpha1.BeaconNode) *errors.RestErr {
-	err := k8sClient.Delete(context.Background(), node)
+func (service beaconNodeService) Delete(ctx context.Context, node *ethereum2v1alpha1.BeaconNode) *errors.RestErr {
+	err := service.k8sClient.Delete(ctx, node)
 
 	if err != nil {
 		go logger.Error(service.Delete, err)
-		return errors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name))
+		return errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name)))
 	}
 
 	return nil
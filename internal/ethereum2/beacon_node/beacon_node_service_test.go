@@ -1 +1,84 @@
 package beacon_node
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newTestBeaconNode creates prysm goerli beacon node in the default namespace using in memory k8s client
+func newTestBeaconNode(t *testing.T) (IService, *ethereum2v1alpha1.BeaconNode) {
+	service := NewBeaconNodeService(fake.NewClientService())
+	node, err := service.Create(context.Background(), &BeaconNodeDto{
+		MetaDataDto:   k8s.MetaDataDto{Name: "my-node"},
+		Network:       "goerli",
+		Client:        "prysm",
+		Eth1Endpoints: &[]string{"http://goerli-node:8545"},
+	})
+	assert.Nil(t, err)
+	return service, node
+}
+
+func TestCreate(t *testing.T) {
+	_, node := newTestBeaconNode(t)
+
+	assert.Equal(t, "default", node.Namespace)
+	assert.Equal(t, "goerli", node.Spec.Network)
+	assert.Equal(t, ethereum2v1alpha1.PrysmClient, node.Spec.Client)
+	assert.Equal(t, []string{"http://goerli-node:8545"}, node.Spec.Eth1Endpoints)
+	// prysm beacon node serves the validators over its rpc server
+	assert.True(t, node.Spec.RPC)
+
+	service := NewBeaconNodeService(fake.NewClientService())
+	node, err := service.Create(context.Background(), &BeaconNodeDto{
+		MetaDataDto: k8s.MetaDataDto{Name: "my-node"},
+		Network:     "mainnet",
+		Client:      "teku",
+	})
+	assert.Nil(t, err)
+	assert.False(t, node.Spec.RPC)
+	assert.Empty(t, node.Spec.Eth1Endpoints)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestBeaconNode(t)
+
+	rest := true
+	node, err := service.Update(ctx, &BeaconNodeDto{REST: &rest, RESTPort: 5052}, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.REST)
+	assert.EqualValues(t, 5052, node.Spec.RESTPort)
+
+	// prysm can't clear eth1 endpoints outside mainnet
+	node, err = service.Update(ctx, &BeaconNodeDto{Eth1Endpoints: &[]string{}}, node)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"http://goerli-node:8545"}, node.Spec.Eth1Endpoints)
+	assert.True(t, node.Spec.REST)
+
+	node, err = service.Update(ctx, &BeaconNodeDto{Eth1Endpoints: &[]string{"https://goerli.infura.io/v3/project"}}, node)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"https://goerli.infura.io/v3/project"}, node.Spec.Eth1Endpoints)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestBeaconNode(t)
+
+	grpc := true
+	dto := new(BeaconNodeDto).FromEthereum2BeaconNode(node)
+	dto.GRPC = &grpc
+	dto.GRPCPort = 4000
+	dto.RPC = nil
+
+	node, err := service.Patch(ctx, dto, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.GRPC)
+	assert.EqualValues(t, 4000, node.Spec.GRPCPort)
+	// removed fields are reset
+	assert.False(t, node.Spec.RPC)
+	assert.Equal(t, []string{"http://goerli-node:8545"}, node.Spec.Eth1Endpoints)
+}
@@ -4,28 +4,30 @@ import (
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 type ValidatorDto struct {
 	models.Time
 	k8s.MetaDataDto
-	Network                  string        `json:"network"`
-	Client                   string        `json:"client"`
+	Network                  string        `json:"network" validate:"required"`
+	Client                   string        `json:"client" validate:"required,enum=ethereum2Client"`
 	Graffiti                 string        `json:"graffiti"`
 	BeaconEndpoints          []string      `json:"beaconEndpoints"`
-	WalletPasswordSecretName string        `json:"walletPasswordSecretName"`
-	Keystores                []KeystoreDto `json:"keystores"`
-	CPU                      string        `json:"cpu"`
-	CPULimit                 string        `json:"cpuLimit"`
-	Memory                   string        `json:"memory"`
-	MemoryLimit              string        `json:"memoryLimit"`
-	Storage                  string        `json:"storage"`
-	StorageClass             *string       `json:"storageClass"`
+	WalletPasswordSecretName string        `json:"walletPasswordSecretName" validate:"dns1123subdomain"`
+	Keystores                []KeystoreDto `json:"keystores" validate:"required"`
+	CPU                      string        `json:"cpu" validate:"quantity"`
+	CPULimit                 string        `json:"cpuLimit" validate:"quantity"`
+	Memory                   string        `json:"memory" validate:"quantity"`
+	MemoryLimit              string        `json:"memoryLimit" validate:"quantity"`
+	Storage                  string        `json:"storage" validate:"quantity"`
+	StorageClass             *string       `json:"storageClass" validate:"dns1123subdomain"`
 }
 
 type KeystoreDto struct {
-	SecretName string `json:"secretName"`
+	SecretName string `json:"secretName" validate:"required,dns1123subdomain"`
 }
 
 type ValidatorListDto []ValidatorDto
@@ -39,6 +41,7 @@ func (dto ValidatorDto) FromEthereum2Validator(validator *ethereum2v1alpha1.Vali
 	}
 
 	dto.Name = validator.Name
+	dto.Namespace = validator.Namespace
 	dto.Time = models.Time{CreatedAt: validator.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.Network = validator.Spec.Network
 	dto.Client = string(validator.Spec.Client)
@@ -63,3 +66,55 @@ func (validatorListDto ValidatorListDto) FromEthereum2Validator(validators []eth
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("ethereum2Client",
+		string(ethereum2v1alpha1.TekuClient),
+		string(ethereum2v1alpha1.PrysmClient),
+		string(ethereum2v1alpha1.LighthouseClient),
+		string(ethereum2v1alpha1.NimbusClient),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the validator
+func (dto *ValidatorDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	prysm := ethereum2v1alpha1.Ethereum2Client(dto.Client) == ethereum2v1alpha1.PrysmClient
+	if prysm && dto.WalletPasswordSecretName == "" && operation == validation.Create {
+		errs.Add("walletPasswordSecretName", "is required if client is prysm")
+	}
+
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToEthereum2ValidatorSpec replaces the validator spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the validator defaulting
+func (dto *ValidatorDto) ToEthereum2ValidatorSpec(spec *ethereum2v1alpha1.ValidatorSpec) {
+	// keystores public keys aren't represented by the dto
+	publicKeys := map[string]string{}
+	for _, keystore := range spec.Keystores {
+		publicKeys[keystore.SecretName] = keystore.PublicKey
+	}
+
+	keystores := []ethereum2v1alpha1.Keystore{}
+	for _, keystore := range dto.Keystores {
+		keystores = append(keystores, ethereum2v1alpha1.Keystore{
+			SecretName: keystore.SecretName,
+			PublicKey:  publicKeys[keystore.SecretName],
+		})
+	}
+
+	spec.Network = dto.Network
+	spec.Client = ethereum2v1alpha1.Ethereum2Client(dto.Client)
+	spec.Graffiti = dto.Graffiti
+	spec.BeaconEndpoints = dto.BeaconEndpoints
+	spec.WalletPasswordSecret = dto.WalletPasswordSecretName
+	spec.Keystores = keystores
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
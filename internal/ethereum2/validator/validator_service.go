@@ -9,47 +9,48 @@ import (
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type validatorService struct{}
+type validatorService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(types.NamespacedName) (*ethereum2v1alpha1.Validator, *errors.RestErr)
-	Create(dto *ValidatorDto) (*ethereum2v1alpha1.Validator, *errors.RestErr)
-	Update(*ValidatorDto, *ethereum2v1alpha1.Validator) (*ethereum2v1alpha1.Validator, *errors.RestErr)
-	List(namespace string) (*ethereum2v1alpha1.ValidatorList, *errors.RestErr)
-	Delete(node *ethereum2v1alpha1.Validator) *errors.RestErr
-	Count(namespace string) (*int, *errors.RestErr)
+	Get(context.Context, types.NamespacedName) (*ethereum2v1alpha1.Validator, *errors.RestErr)
+	Create(ctx context.Context, dto *ValidatorDto) (*ethereum2v1alpha1.Validator, *errors.RestErr)
+	Update(context.Context, *ValidatorDto, *ethereum2v1alpha1.Validator) (*ethereum2v1alpha1.Validator, *errors.RestErr)
+	Patch(context.Context, *ValidatorDto, *ethereum2v1alpha1.Validator) (*ethereum2v1alpha1.Validator, *errors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*ethereum2v1alpha1.ValidatorList, *errors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr)
+	Delete(ctx context.Context, node *ethereum2v1alpha1.Validator) *errors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewValidatorService() IService {
-	return validatorService{}
+func NewValidatorService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return validatorService{k8sClient: k8sClient}
 }
 
 // Get gets a single ethereum 2.0 beacon node by name
-func (service validatorService) Get(namespacedName types.NamespacedName) (*ethereum2v1alpha1.Validator, *errors.RestErr) {
+func (service validatorService) Get(ctx context.Context, namespacedName types.NamespacedName) (*ethereum2v1alpha1.Validator, *errors.RestErr) {
 
 	validator := &ethereum2v1alpha1.Validator{}
 
-	if err := k8sClient.Get(context.Background(), namespacedName, validator); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, validator); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, errors.NewNotFoundError(fmt.Sprintf("validator by name %s doesn't exit", namespacedName.Name))
+			return nil, errors.NewNodeNotFoundError(fmt.Sprintf("validator by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't get a validator by name %s", namespacedName.Name))
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't get a validator by name %s", namespacedName.Name)))
 	}
 	return validator, nil
 }
 
 // Create creates ethereum 2.0 beacon node from spec
-func (service validatorService) Create(dto *ValidatorDto) (*ethereum2v1alpha1.Validator, *errors.RestErr) {
+func (service validatorService) Create(ctx context.Context, dto *ValidatorDto) (*ethereum2v1alpha1.Validator, *errors.RestErr) {
 	keystores := []ethereum2v1alpha1.Keystore{}
 	for _, keystore := range dto.Keystores {
 		keystores = append(keystores, ethereum2v1alpha1.Keystore{
@@ -79,23 +80,19 @@ func (service validatorService) Create(dto *ValidatorDto) (*ethereum2v1alpha1.Va
 		validator.Spec.BeaconEndpoints = []string{}
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		validator.Default()
-	}
-
-	if err := k8sClient.Create(context.Background(), validator); err != nil {
+	if err := service.k8sClient.Create(ctx, validator); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, errors.NewNotFoundError(fmt.Sprintf("validator by name %s already exits", validator.Name))
+			return nil, errors.NewAlreadyExistsError(fmt.Sprintf("validator by name %s already exists", validator.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, errors.NewInternalServerError("failed to create validator")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to create validator"))
 	}
 
 	return validator, nil
 }
 
 // Update updates ethereum 2.0 beacon node by name from spec
-func (service validatorService) Update(dto *ValidatorDto, validator *ethereum2v1alpha1.Validator) (*ethereum2v1alpha1.Validator, *errors.RestErr) {
+func (service validatorService) Update(ctx context.Context, dto *ValidatorDto, validator *ethereum2v1alpha1.Validator) (*ethereum2v1alpha1.Validator, *errors.RestErr) {
 	if dto.WalletPasswordSecretName != "" {
 		validator.Spec.WalletPasswordSecret = dto.WalletPasswordSecretName
 	}
@@ -134,49 +131,73 @@ func (service validatorService) Update(dto *ValidatorDto, validator *ethereum2v1
 		validator.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		validator.Default()
+	if err := service.k8sClient.Update(ctx, validator); err != nil {
+		go logger.Error(service.Update, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't update validator by name %s", validator.Name)))
 	}
 
-	if err := k8sClient.Update(context.Background(), validator); err != nil {
-		go logger.Error(service.Update, err)
-		return nil, errors.NewInternalServerError(fmt.Sprintf("can't update validator by name %s", validator.Name))
+	return validator, nil
+}
+
+// Patch replaces validator spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service validatorService) Patch(ctx context.Context, dto *ValidatorDto, validator *ethereum2v1alpha1.Validator) (*ethereum2v1alpha1.Validator, *errors.RestErr) {
+	dto.ToEthereum2ValidatorSpec(&validator.Spec)
+
+	if err := service.k8sClient.Update(ctx, validator); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't patch validator by name %s", validator.Name)))
 	}
 
 	return validator, nil
 }
 
 // List returns all ethereum 2.0 beacon nodes
-func (service validatorService) List(namespace string) (*ethereum2v1alpha1.ValidatorList, *errors.RestErr) {
+func (service validatorService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*ethereum2v1alpha1.ValidatorList, *errors.RestErr) {
 	validators := &ethereum2v1alpha1.ValidatorList{}
 
-	if err := k8sClient.List(context.Background(), validators, client.InNamespace(namespace)); err != nil {
+	if err := service.k8sClient.List(ctx, validators, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, errors.NewInternalServerError("failed to get all validators")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to get all validators"))
 	}
 
 	return validators, nil
 }
 
-// Count returns total number of beacon nodes
-func (service validatorService) Count(namespace string) (*int, *errors.RestErr) {
-	validators := &ethereum2v1alpha1.ValidatorList{}
+// Watch watches validators changes in the namespace starting after the given resource version
+// all existing validators are sent as added events if the resource version is empty
+func (service validatorService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *errors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &ethereum2v1alpha1.ValidatorList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+	if err != nil {
+		go logger.Error(service.Watch, err)
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("failed to watch validators"))
+	}
 
-	if err := k8sClient.List(context.Background(), validators, client.InNamespace(namespace)); err != nil {
+	return watcher, nil
+}
+
+// Count returns total number of beacon nodes
+func (service validatorService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *errors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &ethereum2v1alpha1.ValidatorList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
+	if err != nil {
 		go logger.Error(service.Count, err)
-		return nil, errors.NewInternalServerError("error counting validators")
+		return nil, errors.FromK8sError(err, errors.NewInternalServerError("error counting validators"))
 	}
 
-	length := len(validators.Items)
-
 	return &length, nil
 }
 
 // Delete deletes ethereum 2.0 beacon node by name
-func (service validatorService) Delete(validator *ethereum2v1alpha1.Validator) *errors.RestErr {
-	if err := k8sClient.Delete(context.Background(), validator); err != nil {
+func (service validatorService) Delete(ctx context.Context, validator *ethereum2v1alpha1.Validator) *errors.RestErr {
+	if err := service.k8sClient.Delete(ctx, validator); err != nil {
 		go logger.Error(service.Delete, err)
-		return errors.NewBadRequestError(fmt.Sprintf("can't delete validator by name %s", validator.Name))
+		return errors.FromK8sError(err, errors.NewInternalServerError(fmt.Sprintf("can't delete validator by name %s", validator.Name)))
 	}
 
 	return nil
@@ -1 +1,80 @@
 package validator
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newTestValidator creates validator in the default namespace by the given client using in memory k8s client
+func newTestValidator(t *testing.T, client string) (IService, *ethereum2v1alpha1.Validator) {
+	service := NewValidatorService(fake.NewClientService())
+	validator, err := service.Create(context.Background(), &ValidatorDto{
+		MetaDataDto:              k8s.MetaDataDto{Name: "my-validator"},
+		Network:                  "mainnet",
+		Client:                   client,
+		WalletPasswordSecretName: "my-wallet-password",
+		Keystores:                []KeystoreDto{{SecretName: "my-keystore"}},
+	})
+	assert.Nil(t, err)
+	return service, validator
+}
+
+func TestCreate(t *testing.T) {
+	_, validator := newTestValidator(t, "prysm")
+
+	assert.Equal(t, "default", validator.Namespace)
+	assert.Equal(t, ethereum2v1alpha1.PrysmClient, validator.Spec.Client)
+	assert.Equal(t, []ethereum2v1alpha1.Keystore{{SecretName: "my-keystore"}}, validator.Spec.Keystores)
+	assert.Equal(t, "my-wallet-password", validator.Spec.WalletPasswordSecret)
+	assert.Equal(t, []string{}, validator.Spec.BeaconEndpoints)
+
+	// wallet password is used by prysm only
+	_, validator = newTestValidator(t, "teku")
+	assert.Empty(t, validator.Spec.WalletPasswordSecret)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, validator := newTestValidator(t, "teku")
+
+	validator, err := service.Update(ctx, &ValidatorDto{
+		Graffiti:        "Validated by Kotal",
+		BeaconEndpoints: []string{"http://my-beacon-node:5052"},
+		Keystores:       []KeystoreDto{{SecretName: "my-keystore"}, {SecretName: "other-keystore"}},
+	}, validator)
+	assert.Nil(t, err)
+	assert.Equal(t, "Validated by Kotal", validator.Spec.Graffiti)
+	assert.Equal(t, []string{"http://my-beacon-node:5052"}, validator.Spec.BeaconEndpoints)
+	assert.Len(t, validator.Spec.Keystores, 2)
+
+	// omitted fields are left unchanged
+	validator, err = service.Update(ctx, &ValidatorDto{CPU: "2"}, validator)
+	assert.Nil(t, err)
+	assert.Equal(t, "Validated by Kotal", validator.Spec.Graffiti)
+	assert.Len(t, validator.Spec.Keystores, 2)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, validator := newTestValidator(t, "prysm")
+
+	// keystores public keys are kept
+	validator.Spec.Keystores[0].PublicKey = "0x83e5"
+
+	dto := new(ValidatorDto).FromEthereum2Validator(validator)
+	dto.Keystores = append(dto.Keystores, KeystoreDto{SecretName: "other-keystore"})
+	dto.WalletPasswordSecretName = ""
+
+	validator, err := service.Patch(ctx, dto, validator)
+	assert.Nil(t, err)
+	assert.Equal(t, []ethereum2v1alpha1.Keystore{
+		{SecretName: "my-keystore", PublicKey: "0x83e5"},
+		{SecretName: "other-keystore"},
+	}, validator.Spec.Keystores)
+	// removed fields are reset
+	assert.Empty(t, validator.Spec.WalletPasswordSecret)
+}
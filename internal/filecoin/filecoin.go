@@ -4,30 +4,32 @@ import (
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	filecoinv1alpha1 "github.com/kotalco/kotal/apis/filecoin/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 // Node is Filecoin node
 type FilecoinDto struct {
 	models.Time
 	k8s.MetaDataDto
-	Network            string  `json:"network"`
+	Network            string  `json:"network" validate:"required,enum=filecoinNetwork"`
 	API                *bool   `json:"api"`
-	APIPort            uint    `json:"apiPort"`
+	APIPort            uint    `json:"apiPort" validate:"port"`
 	APIHost            string  `json:"apiHost"`
 	APIRequestTimeout  uint    `json:"apiRequestTimeout"`
 	DisableMetadataLog *bool   `json:"disableMetadataLog"`
-	P2PPort            uint    `json:"p2pPort"`
+	P2PPort            uint    `json:"p2pPort" validate:"port"`
 	P2PHost            string  `json:"p2pHost"`
 	IPFSPeerEndpoint   string  `json:"ipfsPeerEndpoint"`
 	IPFSOnlineMode     *bool   `json:"ipfsOnlineMode"`
 	IPFSForRetrieval   *bool   `json:"ipfsForRetrieval"`
-	CPU                string  `json:"cpu"`
-	CPULimit           string  `json:"cpuLimit"`
-	Memory             string  `json:"memory"`
-	MemoryLimit        string  `json:"memoryLimit"`
-	Storage            string  `json:"storage"`
-	StorageClass       *string `json:"storageClass"`
+	CPU                string  `json:"cpu" validate:"quantity"`
+	CPULimit           string  `json:"cpuLimit" validate:"quantity"`
+	Memory             string  `json:"memory" validate:"quantity"`
+	MemoryLimit        string  `json:"memoryLimit" validate:"quantity"`
+	Storage            string  `json:"storage" validate:"quantity"`
+	StorageClass       *string `json:"storageClass" validate:"dns1123subdomain"`
 }
 
 type FilecoinListDto []FilecoinDto
@@ -36,6 +38,7 @@ type FilecoinListDto []FilecoinDto
 func (dto FilecoinDto) FromFilecoinNode(node *filecoinv1alpha1.Node) *FilecoinDto {
 
 	dto.Name = node.Name
+	dto.Namespace = node.Namespace
 	dto.Time = models.Time{CreatedAt: node.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.Network = string(node.Spec.Network)
 	dto.API = &node.Spec.API
@@ -66,3 +69,39 @@ func (filecoinListDto FilecoinListDto) FromFilecoinNode(nodes []filecoinv1alpha1
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("filecoinNetwork",
+		string(filecoinv1alpha1.MainNetwork),
+		string(filecoinv1alpha1.CalibrationNetwork),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the filecoin node
+func (dto *FilecoinDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToFilecoinNodeSpec replaces the node spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the node defaulting
+func (dto *FilecoinDto) ToFilecoinNodeSpec(spec *filecoinv1alpha1.NodeSpec) {
+	spec.Network = filecoinv1alpha1.FilecoinNetwork(dto.Network)
+	spec.API = dto.API != nil && *dto.API
+	spec.APIPort = dto.APIPort
+	spec.APIHost = dto.APIHost
+	spec.APIRequestTimeout = dto.APIRequestTimeout
+	spec.DisableMetadataLog = dto.DisableMetadataLog != nil && *dto.DisableMetadataLog
+	spec.P2PPort = dto.P2PPort
+	spec.P2PHost = dto.P2PHost
+	spec.IPFSPeerEndpoint = dto.IPFSPeerEndpoint
+	spec.IPFSOnlineMode = dto.IPFSOnlineMode != nil && *dto.IPFSOnlineMode
+	spec.IPFSForRetrieval = dto.IPFSForRetrieval != nil && *dto.IPFSForRetrieval
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
@@ -9,47 +9,48 @@ import (
 	filecoinv1alpha1 "github.com/kotalco/kotal/apis/filecoin/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type filecoinService struct{}
+type filecoinService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(types.NamespacedName) (*filecoinv1alpha1.Node, *restErrors.RestErr)
-	Create(dto *FilecoinDto) (*filecoinv1alpha1.Node, *restErrors.RestErr)
-	Update(*FilecoinDto, *filecoinv1alpha1.Node) (*filecoinv1alpha1.Node, *restErrors.RestErr)
-	List(namespace string) (*filecoinv1alpha1.NodeList, *restErrors.RestErr)
-	Delete(node *filecoinv1alpha1.Node) *restErrors.RestErr
-	Count(namespace string) (*int, *restErrors.RestErr)
+	Get(context.Context, types.NamespacedName) (*filecoinv1alpha1.Node, *restErrors.RestErr)
+	Create(ctx context.Context, dto *FilecoinDto) (*filecoinv1alpha1.Node, *restErrors.RestErr)
+	Update(context.Context, *FilecoinDto, *filecoinv1alpha1.Node) (*filecoinv1alpha1.Node, *restErrors.RestErr)
+	Patch(context.Context, *FilecoinDto, *filecoinv1alpha1.Node) (*filecoinv1alpha1.Node, *restErrors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*filecoinv1alpha1.NodeList, *restErrors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr)
+	Delete(ctx context.Context, node *filecoinv1alpha1.Node) *restErrors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewFilecoinService() IService {
-	return filecoinService{}
+func NewFilecoinService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return filecoinService{k8sClient: k8sClient}
 }
 
 // Get gets a single filecoin node by name
-func (service filecoinService) Get(namespacedName types.NamespacedName) (*filecoinv1alpha1.Node, *restErrors.RestErr) {
+func (service filecoinService) Get(ctx context.Context, namespacedName types.NamespacedName) (*filecoinv1alpha1.Node, *restErrors.RestErr) {
 	node := &filecoinv1alpha1.Node{}
 
-	if err := k8sClient.Get(context.Background(), namespacedName, node); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, node); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, restErrors.NewNotFoundError(fmt.Sprintf("node by name %s doesn't exit", namespacedName.Name))
+			return nil, restErrors.NewNodeNotFoundError(fmt.Sprintf("node by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName.Name))
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName.Name)))
 	}
 
 	return node, nil
 }
 
 // Create creates filecoin node from spec
-func (service filecoinService) Create(dto *FilecoinDto) (*filecoinv1alpha1.Node, *restErrors.RestErr) {
+func (service filecoinService) Create(ctx context.Context, dto *FilecoinDto) (*filecoinv1alpha1.Node, *restErrors.RestErr) {
 	node := &filecoinv1alpha1.Node{
 		ObjectMeta: dto.ObjectMetaFromMetadataDto(),
 		Spec: filecoinv1alpha1.NodeSpec{
@@ -60,23 +61,19 @@ func (service filecoinService) Create(dto *FilecoinDto) (*filecoinv1alpha1.Node,
 		},
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
-	}
-
-	if err := k8sClient.Create(context.Background(), node); err != nil {
+	if err := service.k8sClient.Create(ctx, node); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, restErrors.NewBadRequestError(fmt.Sprintf("node by name %s already exits", dto))
+			return nil, restErrors.NewAlreadyExistsError(fmt.Sprintf("node by name %s already exists", dto.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, restErrors.NewInternalServerError("failed to create node")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to create node"))
 	}
 
 	return node, nil
 }
 
 // Update updates filecoin node by name from spec
-func (service filecoinService) Update(dto *FilecoinDto, node *filecoinv1alpha1.Node) (*filecoinv1alpha1.Node, *restErrors.RestErr) {
+func (service filecoinService) Update(ctx context.Context, dto *FilecoinDto, node *filecoinv1alpha1.Node) (*filecoinv1alpha1.Node, *restErrors.RestErr) {
 	if dto.API != nil {
 		node.Spec.API = *dto.API
 	}
@@ -133,46 +130,71 @@ func (service filecoinService) Update(dto *FilecoinDto, node *filecoinv1alpha1.N
 		node.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Update, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name)))
 	}
 
-	if err := k8sClient.Update(context.Background(), node); err != nil {
-		go logger.Error(service.Update, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name))
+	return node, nil
+}
+
+// Patch replaces node spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service filecoinService) Patch(ctx context.Context, dto *FilecoinDto, node *filecoinv1alpha1.Node) (*filecoinv1alpha1.Node, *restErrors.RestErr) {
+	dto.ToFilecoinNodeSpec(&node.Spec)
+
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't patch node by name %s", node.Name)))
 	}
 
 	return node, nil
 }
 
 // List returns all filecoin nodes
-func (service filecoinService) List(namespace string) (*filecoinv1alpha1.NodeList, *restErrors.RestErr) {
+func (service filecoinService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*filecoinv1alpha1.NodeList, *restErrors.RestErr) {
 	nodes := &filecoinv1alpha1.NodeList{}
-	if err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace)); err != nil {
+	if err := service.k8sClient.List(ctx, nodes, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, restErrors.NewInternalServerError("failed to get all nodes")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to get all nodes"))
 	}
 	return nodes, nil
 }
 
-// Count returns total number of filecoin nodes
-func (service filecoinService) Count(namespace string) (*int, *restErrors.RestErr) {
+// Watch watches nodes changes in the namespace starting after the given resource version
+// all existing nodes are sent as added events if the resource version is empty
+func (service filecoinService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &filecoinv1alpha1.NodeList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+	if err != nil {
+		go logger.Error(service.Watch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to watch nodes"))
+	}
 
-	nodes := &filecoinv1alpha1.NodeList{}
-	if err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace)); err != nil {
+	return watcher, nil
+}
+
+// Count returns total number of filecoin nodes
+func (service filecoinService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &filecoinv1alpha1.NodeList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
+	if err != nil {
 		go logger.Error(service.Count, err)
-		return nil, restErrors.NewInternalServerError("failed to count filecoin nodes")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to count filecoin nodes"))
 	}
 
-	length := len(nodes.Items)
 	return &length, nil
 }
 
 // Delete deletes ethereum 2.0 filecoin node by name
-func (service filecoinService) Delete(node *filecoinv1alpha1.Node) *restErrors.RestErr {
-	if err := k8sClient.Delete(context.Background(), node); err != nil {
+func (service filecoinService) Delete(ctx context.Context, node *filecoinv1alpha1.Node) *restErrors.RestErr {
+	if err := service.k8sClient.Delete(ctx, node); err != nil {
 		go logger.Error(service.Delete, err)
-		return restErrors.NewInternalServerError(fmt.Sprintf("can't delte node by name %s", node.Name))
+		return restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't delte node by name %s", node.Name)))
 	}
 	return nil
 }
@@ -1 +1,76 @@
 package filecoin
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	filecoinv1alpha1 "github.com/kotalco/kotal/apis/filecoin/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newTestNode creates calibration node in the default namespace using in memory k8s client
+func newTestNode(t *testing.T) (IService, *filecoinv1alpha1.Node) {
+	service := NewFilecoinService(fake.NewClientService())
+	node, err := service.Create(context.Background(), &FilecoinDto{
+		MetaDataDto: k8s.MetaDataDto{Name: "my-node"},
+		Network:     "calibration",
+	})
+	assert.Nil(t, err)
+	return service, node
+}
+
+func TestCreate(t *testing.T) {
+	_, node := newTestNode(t)
+
+	assert.Equal(t, "default", node.Namespace)
+	assert.Equal(t, filecoinv1alpha1.CalibrationNetwork, node.Spec.Network)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	api := true
+	node, err := service.Update(ctx, &FilecoinDto{
+		Network:          "mainnet",
+		API:              &api,
+		APIPort:          1235,
+		IPFSPeerEndpoint: "/dns4/my-peer/tcp/5001",
+	}, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.API)
+	assert.EqualValues(t, 1235, node.Spec.APIPort)
+	assert.Equal(t, "/dns4/my-peer/tcp/5001", node.Spec.IPFSPeerEndpoint)
+	// network can't be changed
+	assert.Equal(t, filecoinv1alpha1.CalibrationNetwork, node.Spec.Network)
+
+	// omitted fields are left unchanged
+	node, err = service.Update(ctx, &FilecoinDto{CPU: "2"}, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.API)
+	assert.Equal(t, "/dns4/my-peer/tcp/5001", node.Spec.IPFSPeerEndpoint)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	online := true
+	dto := new(FilecoinDto).FromFilecoinNode(node)
+	dto.IPFSOnlineMode = &online
+	dto.IPFSPeerEndpoint = "/dns4/my-peer/tcp/5001"
+
+	node, err := service.Patch(ctx, dto, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.IPFSOnlineMode)
+	assert.Equal(t, "/dns4/my-peer/tcp/5001", node.Spec.IPFSPeerEndpoint)
+
+	// removed fields are reset
+	dto = new(FilecoinDto).FromFilecoinNode(node)
+	dto.IPFSOnlineMode = nil
+	node, err = service.Patch(ctx, dto, node)
+	assert.Nil(t, err)
+	assert.False(t, node.Spec.IPFSOnlineMode)
+	assert.Equal(t, filecoinv1alpha1.CalibrationNetwork, node.Spec.Network)
+}
@@ -4,30 +4,33 @@ import (
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 type ClusterPeerDto struct {
 	models.Time
 	k8s.MetaDataDto
 	ID                   string   `json:"id"`
-	PrivatekeySecretName string   `json:"privatekeySecretName"`
+	PrivatekeySecretName string   `json:"privatekeySecretName" validate:"dns1123subdomain"`
 	TrustedPeers         []string `json:"trustedPeers"`
 	BootstrapPeers       []string `json:"bootstrapPeers"`
-	Consensus            string   `json:"consensus"`
-	ClusterSecretName    string   `json:"clusterSecretName"`
+	Consensus            string   `json:"consensus" validate:"enum=ipfsConsensus"`
+	ClusterSecretName    string   `json:"clusterSecretName" validate:"dns1123subdomain"`
 	PeerEndpoint         string   `json:"peerEndpoint"`
-	CPU                  string   `json:"cpu"`
-	CPULimit             string   `json:"cpuLimit"`
-	Memory               string   `json:"memory"`
-	MemoryLimit          string   `json:"memoryLimit"`
-	Storage              string   `json:"storage"`
-	StorageClass         *string  `json:"storageClass"`
+	CPU                  string   `json:"cpu" validate:"quantity"`
+	CPULimit             string   `json:"cpuLimit" validate:"quantity"`
+	Memory               string   `json:"memory" validate:"quantity"`
+	MemoryLimit          string   `json:"memoryLimit" validate:"quantity"`
+	Storage              string   `json:"storage" validate:"quantity"`
+	StorageClass         *string  `json:"storageClass" validate:"dns1123subdomain"`
 }
 type ClusterPeerListDto []ClusterPeerDto
 
 func (dto ClusterPeerDto) FromIPFSClusterPeer(peer *ipfsv1alpha1.ClusterPeer) *ClusterPeerDto {
 	dto.Name = peer.Name
+	dto.Namespace = peer.Namespace
 	dto.Time = models.Time{CreatedAt: peer.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.ID = peer.Spec.ID
 	dto.PrivatekeySecretName = peer.Spec.PrivateKeySecretName
@@ -53,3 +56,42 @@ func (clusterPeerListDto ClusterPeerListDto) FromIPFSClusterPeer(peers []ipfsv1a
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("ipfsConsensus",
+		string(ipfsv1alpha1.CRDT),
+		string(ipfsv1alpha1.Raft),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the ipfs cluster peer
+func (dto *ClusterPeerDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	if dto.ID != "" && dto.PrivatekeySecretName == "" {
+		errs.Add("privatekeySecretName", "is required if id is provided")
+	}
+	if dto.PrivatekeySecretName != "" && dto.ID == "" {
+		errs.Add("id", "is required if privatekeySecretName is provided")
+	}
+
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToIPFSClusterPeerSpec replaces the cluster peer spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the cluster peer defaulting
+func (dto *ClusterPeerDto) ToIPFSClusterPeerSpec(spec *ipfsv1alpha1.ClusterPeerSpec) {
+	spec.ID = dto.ID
+	spec.PrivateKeySecretName = dto.PrivatekeySecretName
+	spec.TrustedPeers = dto.TrustedPeers
+	spec.BootstrapPeers = dto.BootstrapPeers
+	spec.Consensus = ipfsv1alpha1.ConsensusAlgorithm(dto.Consensus)
+	spec.ClusterSecretName = dto.ClusterSecretName
+	spec.PeerEndpoint = dto.PeerEndpoint
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
@@ -9,47 +9,48 @@ import (
 	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type ipfsClusterPeerService struct{}
+type ipfsClusterPeerService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(name types.NamespacedName) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr)
-	Create(dto *ClusterPeerDto) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr)
-	Update(*ClusterPeerDto, *ipfsv1alpha1.ClusterPeer) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr)
-	List(namespace string) (*ipfsv1alpha1.ClusterPeerList, *restErrors.RestErr)
-	Delete(node *ipfsv1alpha1.ClusterPeer) *restErrors.RestErr
-	Count(namespace string) (*int, *restErrors.RestErr)
+	Get(ctx context.Context, name types.NamespacedName) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr)
+	Create(ctx context.Context, dto *ClusterPeerDto) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr)
+	Update(context.Context, *ClusterPeerDto, *ipfsv1alpha1.ClusterPeer) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr)
+	Patch(context.Context, *ClusterPeerDto, *ipfsv1alpha1.ClusterPeer) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*ipfsv1alpha1.ClusterPeerList, *restErrors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr)
+	Delete(ctx context.Context, node *ipfsv1alpha1.ClusterPeer) *restErrors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewIpfsClusterPeerService() IService {
-	return ipfsClusterPeerService{}
+func NewIpfsClusterPeerService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return ipfsClusterPeerService{k8sClient: k8sClient}
 }
 
 // Get gets a single IPFS peer by name
-func (service ipfsClusterPeerService) Get(namespacedName types.NamespacedName) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr) {
+func (service ipfsClusterPeerService) Get(ctx context.Context, namespacedName types.NamespacedName) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr) {
 	peer := &ipfsv1alpha1.ClusterPeer{}
 
-	if err := k8sClient.Get(context.Background(), namespacedName, peer); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, peer); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, restErrors.NewNotFoundError(fmt.Sprintf("cluster peer by name %s doesn't exit", namespacedName.Name))
+			return nil, restErrors.NewNodeNotFoundError(fmt.Sprintf("cluster peer by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't get cluster peer by name %s", peer.Name))
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't get cluster peer by name %s", peer.Name)))
 	}
 
 	return peer, nil
 }
 
 // Create creates IPFS peer from spec
-func (service ipfsClusterPeerService) Create(dto *ClusterPeerDto) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr) {
+func (service ipfsClusterPeerService) Create(ctx context.Context, dto *ClusterPeerDto) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr) {
 
 	peer := &ipfsv1alpha1.ClusterPeer{
 		ObjectMeta: dto.ObjectMetaFromMetadataDto(),
@@ -104,23 +105,19 @@ func (service ipfsClusterPeerService) Create(dto *ClusterPeerDto) (*ipfsv1alpha1
 		peer.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		peer.Default()
-	}
-
-	if err := k8sClient.Create(context.Background(), peer); err != nil {
+	if err := service.k8sClient.Create(ctx, peer); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, restErrors.NewBadRequestError(fmt.Sprintf("cluster peer by name %s already exits", peer.Name))
+			return nil, restErrors.NewAlreadyExistsError(fmt.Sprintf("cluster peer by name %s already exists", peer.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, restErrors.NewInternalServerError("failed to create cluster peer")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to create cluster peer"))
 	}
 
 	return peer, nil
 }
 
 // Update updates IPFS peer by name from spec
-func (service ipfsClusterPeerService) Update(dto *ClusterPeerDto, peer *ipfsv1alpha1.ClusterPeer) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr) {
+func (service ipfsClusterPeerService) Update(ctx context.Context, dto *ClusterPeerDto, peer *ipfsv1alpha1.ClusterPeer) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr) {
 	if dto.PeerEndpoint != "" {
 		peer.Spec.PeerEndpoint = dto.PeerEndpoint
 	}
@@ -145,47 +142,72 @@ func (service ipfsClusterPeerService) Update(dto *ClusterPeerDto, peer *ipfsv1al
 		peer.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		peer.Default()
+	if err := service.k8sClient.Update(ctx, peer); err != nil {
+		go logger.Error(service.Update, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't update cluster peer by name %s", peer.Name)))
 	}
 
-	if err := k8sClient.Update(context.Background(), peer); err != nil {
-		go logger.Error(service.Update, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't update cluster peer by name %s", peer.Name))
+	return peer, nil
+}
+
+// Patch replaces cluster peer spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service ipfsClusterPeerService) Patch(ctx context.Context, dto *ClusterPeerDto, peer *ipfsv1alpha1.ClusterPeer) (*ipfsv1alpha1.ClusterPeer, *restErrors.RestErr) {
+	dto.ToIPFSClusterPeerSpec(&peer.Spec)
+
+	if err := service.k8sClient.Update(ctx, peer); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't patch cluster peer by name %s", peer.Name)))
 	}
 
 	return peer, nil
 }
 
 // List returns all IPFS peers
-func (service ipfsClusterPeerService) List(namespace string) (*ipfsv1alpha1.ClusterPeerList, *restErrors.RestErr) {
+func (service ipfsClusterPeerService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*ipfsv1alpha1.ClusterPeerList, *restErrors.RestErr) {
 	peers := &ipfsv1alpha1.ClusterPeerList{}
-	if err := k8sClient.List(context.Background(), peers, client.InNamespace(namespace)); err != nil {
+	if err := service.k8sClient.List(ctx, peers, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, restErrors.NewInternalServerError("failed to get all peers")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to get all peers"))
 	}
 
 	return peers, nil
 }
 
+// Watch watches cluster peers changes in the namespace starting after the given resource version
+// all existing cluster peers are sent as added events if the resource version is empty
+func (service ipfsClusterPeerService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &ipfsv1alpha1.ClusterPeerList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+	if err != nil {
+		go logger.Error(service.Watch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to watch cluster peers"))
+	}
+
+	return watcher, nil
+}
+
 // Count returns total number of IPFS peers
-func (service ipfsClusterPeerService) Count(namespace string) (*int, *restErrors.RestErr) {
-	peers := &ipfsv1alpha1.ClusterPeerList{}
-	if err := k8sClient.List(context.Background(), peers, client.InNamespace(namespace)); err != nil {
+func (service ipfsClusterPeerService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &ipfsv1alpha1.ClusterPeerList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
+	if err != nil {
 		go logger.Error(service.Count, err)
-		return nil, restErrors.NewInternalServerError("failed to count all cluster perrs")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to count all cluster perrs"))
 	}
 
-	length := len(peers.Items)
-
 	return &length, nil
 }
 
 // Delete deletes ethereum 2.0 IPFS peer by name
-func (service ipfsClusterPeerService) Delete(peer *ipfsv1alpha1.ClusterPeer) *restErrors.RestErr {
-	if err := k8sClient.Delete(context.Background(), peer); err != nil {
+func (service ipfsClusterPeerService) Delete(ctx context.Context, peer *ipfsv1alpha1.ClusterPeer) *restErrors.RestErr {
+	if err := service.k8sClient.Delete(ctx, peer); err != nil {
 		go logger.Error(service.Delete, err)
-		return restErrors.NewInternalServerError(fmt.Sprintf("can't delete cluster peer by name %s", peer.Name))
+		return restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't delete cluster peer by name %s", peer.Name)))
 	}
 
 	return nil
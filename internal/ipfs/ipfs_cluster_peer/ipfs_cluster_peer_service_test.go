@@ -1 +1,73 @@
 package ipfs_cluster_peer
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const peerID = "12D3KooWBcEtY8GH4mNkri9kM3haeWhEXtQV7mi81ErWrqLYGuiq"
+
+// newTestClusterPeer creates raft cluster peer in the default namespace using in memory k8s client
+func newTestClusterPeer(t *testing.T) (IService, *ipfsv1alpha1.ClusterPeer) {
+	service := NewIpfsClusterPeerService(fake.NewClientService())
+	peer, err := service.Create(context.Background(), &ClusterPeerDto{
+		MetaDataDto:          k8s.MetaDataDto{Name: "my-peer"},
+		ID:                   peerID,
+		PrivatekeySecretName: "my-cluster-key",
+		Consensus:            "raft",
+		TrustedPeers:         []string{peerID},
+		ClusterSecretName:    "my-cluster-secret",
+		PeerEndpoint:         "/dns4/my-peer/tcp/5001",
+	})
+	assert.Nil(t, err)
+	return service, peer
+}
+
+func TestCreate(t *testing.T) {
+	_, peer := newTestClusterPeer(t)
+
+	assert.Equal(t, "default", peer.Namespace)
+	assert.Equal(t, peerID, peer.Spec.ID)
+	assert.Equal(t, "my-cluster-key", peer.Spec.PrivateKeySecretName)
+	assert.Equal(t, ipfsv1alpha1.Raft, peer.Spec.Consensus)
+	assert.Equal(t, []string{peerID}, peer.Spec.TrustedPeers)
+	assert.Equal(t, "my-cluster-secret", peer.Spec.ClusterSecretName)
+	assert.Equal(t, "/dns4/my-peer/tcp/5001", peer.Spec.PeerEndpoint)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, peer := newTestClusterPeer(t)
+
+	bootstrapPeer := "/dns4/other-peer/tcp/9096/p2p/" + peerID
+	peer, err := service.Update(ctx, &ClusterPeerDto{
+		Consensus:      "crdt",
+		PeerEndpoint:   "/dns4/other-peer/tcp/5001",
+		BootstrapPeers: []string{bootstrapPeer},
+	}, peer)
+	assert.Nil(t, err)
+	assert.Equal(t, "/dns4/other-peer/tcp/5001", peer.Spec.PeerEndpoint)
+	assert.Equal(t, []string{bootstrapPeer}, peer.Spec.BootstrapPeers)
+	// consensus can't be changed
+	assert.Equal(t, ipfsv1alpha1.Raft, peer.Spec.Consensus)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, peer := newTestClusterPeer(t)
+
+	dto := new(ClusterPeerDto).FromIPFSClusterPeer(peer)
+	dto.TrustedPeers = nil
+	dto.PeerEndpoint = "/dns4/other-peer/tcp/5001"
+
+	peer, err := service.Patch(ctx, dto, peer)
+	assert.Nil(t, err)
+	assert.Equal(t, "/dns4/other-peer/tcp/5001", peer.Spec.PeerEndpoint)
+	// removed fields are reset
+	assert.Empty(t, peer.Spec.TrustedPeers)
+	assert.Equal(t, peerID, peer.Spec.ID)
+}
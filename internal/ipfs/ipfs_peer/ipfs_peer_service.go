@@ -9,47 +9,48 @@ import (
 	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type ipfsPeerService struct{}
+type ipfsPeerService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(name types.NamespacedName) (*ipfsv1alpha1.Peer, *restErrors.RestErr)
-	Create(dto *PeerDto) (*ipfsv1alpha1.Peer, *restErrors.RestErr)
-	Update(*PeerDto, *ipfsv1alpha1.Peer) (*ipfsv1alpha1.Peer, *restErrors.RestErr)
-	List(namespace string) (*ipfsv1alpha1.PeerList, *restErrors.RestErr)
-	Delete(node *ipfsv1alpha1.Peer) *restErrors.RestErr
-	Count(namespace string) (*int, *restErrors.RestErr)
+	Get(ctx context.Context, name types.NamespacedName) (*ipfsv1alpha1.Peer, *restErrors.RestErr)
+	Create(ctx context.Context, dto *PeerDto) (*ipfsv1alpha1.Peer, *restErrors.RestErr)
+	Update(context.Context, *PeerDto, *ipfsv1alpha1.Peer) (*ipfsv1alpha1.Peer, *restErrors.RestErr)
+	Patch(context.Context, *PeerDto, *ipfsv1alpha1.Peer) (*ipfsv1alpha1.Peer, *restErrors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*ipfsv1alpha1.PeerList, *restErrors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr)
+	Delete(ctx context.Context, node *ipfsv1alpha1.Peer) *restErrors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewIpfsPeerService() IService {
-	return ipfsPeerService{}
+func NewIpfsPeerService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return ipfsPeerService{k8sClient: k8sClient}
 }
 
 // Get gets a single IPFS peer by name
-func (service ipfsPeerService) Get(namespacedName types.NamespacedName) (*ipfsv1alpha1.Peer, *restErrors.RestErr) {
+func (service ipfsPeerService) Get(ctx context.Context, namespacedName types.NamespacedName) (*ipfsv1alpha1.Peer, *restErrors.RestErr) {
 	peer := &ipfsv1alpha1.Peer{}
 
-	if err := k8sClient.Get(context.Background(), namespacedName, peer); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, peer); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, restErrors.NewBadRequestError(fmt.Sprintf("peer by name %s doesn't exit", namespacedName.Name))
+			return nil, restErrors.NewNodeNotFoundError(fmt.Sprintf("peer by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't get peer by name %s", peer.Name))
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't get peer by name %s", peer.Name)))
 	}
 
 	return peer, nil
 }
 
 // Create creates IPFS peer from spec
-func (service ipfsPeerService) Create(dto *PeerDto) (*ipfsv1alpha1.Peer, *restErrors.RestErr) {
+func (service ipfsPeerService) Create(ctx context.Context, dto *PeerDto) (*ipfsv1alpha1.Peer, *restErrors.RestErr) {
 	var initProfiles []ipfsv1alpha1.Profile
 	for _, profile := range dto.InitProfiles {
 		initProfiles = append(initProfiles, ipfsv1alpha1.Profile(profile))
@@ -65,23 +66,19 @@ func (service ipfsPeerService) Create(dto *PeerDto) (*ipfsv1alpha1.Peer, *restEr
 		},
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		peer.Default()
-	}
-
-	if err := k8sClient.Create(context.Background(), peer); err != nil {
+	if err := service.k8sClient.Create(ctx, peer); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, restErrors.NewNotFoundError(fmt.Sprintf("peer by name %s already exits", dto.Name))
+			return nil, restErrors.NewAlreadyExistsError(fmt.Sprintf("peer by name %s already exists", dto.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, restErrors.NewInternalServerError("failed to create peer")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to create peer"))
 	}
 
 	return peer, nil
 }
 
 // Update updates IPFS peer by name from spec
-func (service ipfsPeerService) Update(dto *PeerDto, peer *ipfsv1alpha1.Peer) (*ipfsv1alpha1.Peer, *restErrors.RestErr) {
+func (service ipfsPeerService) Update(ctx context.Context, dto *PeerDto, peer *ipfsv1alpha1.Peer) (*ipfsv1alpha1.Peer, *restErrors.RestErr) {
 	if dto.APIPort != 0 {
 		peer.Spec.APIPort = dto.APIPort
 	}
@@ -126,48 +123,72 @@ func (service ipfsPeerService) Update(dto *PeerDto, peer *ipfsv1alpha1.Peer) (*i
 		peer.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		peer.Default()
+	if err := service.k8sClient.Update(ctx, peer); err != nil {
+		go logger.Error(service.Update, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't update peer by name %s", peer.Name)))
 	}
 
-	if err := k8sClient.Update(context.Background(), peer); err != nil {
-		go logger.Error(service.Update, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't update peer by name %s", peer.Name))
+	return peer, nil
+}
+
+// Patch replaces peer spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service ipfsPeerService) Patch(ctx context.Context, dto *PeerDto, peer *ipfsv1alpha1.Peer) (*ipfsv1alpha1.Peer, *restErrors.RestErr) {
+	dto.ToIPFSPeerSpec(&peer.Spec)
+
+	if err := service.k8sClient.Update(ctx, peer); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't patch peer by name %s", peer.Name)))
 	}
 
 	return peer, nil
 }
 
 // List returns all IPFS peers
-func (service ipfsPeerService) List(namespace string) (*ipfsv1alpha1.PeerList, *restErrors.RestErr) {
+func (service ipfsPeerService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*ipfsv1alpha1.PeerList, *restErrors.RestErr) {
 	peers := &ipfsv1alpha1.PeerList{}
-	if err := k8sClient.List(context.Background(), peers, client.InNamespace(namespace)); err != nil {
+	if err := service.k8sClient.List(ctx, peers, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, restErrors.NewInternalServerError("failed to get all peers")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to get all peers"))
 	}
 
 	return peers, nil
 }
 
-// Count returns total number of IPFS peers
-func (service ipfsPeerService) Count(namespace string) (*int, *restErrors.RestErr) {
-	peers := &ipfsv1alpha1.PeerList{}
+// Watch watches peers changes in the namespace starting after the given resource version
+// all existing peers are sent as added events if the resource version is empty
+func (service ipfsPeerService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &ipfsv1alpha1.PeerList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+	if err != nil {
+		go logger.Error(service.Watch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to watch peers"))
+	}
 
-	if err := k8sClient.List(context.Background(), peers, client.InNamespace(namespace)); err != nil {
+	return watcher, nil
+}
+
+// Count returns total number of IPFS peers
+func (service ipfsPeerService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &ipfsv1alpha1.PeerList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
+	if err != nil {
 		go logger.Error(service.Count, err)
-		return nil, restErrors.NewInternalServerError("failed to count all peers")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to count all peers"))
 	}
 
-	length := len(peers.Items)
-
 	return &length, nil
 }
 
 // Delete deletes ethereum 2.0 IPFS peer by name
-func (service ipfsPeerService) Delete(peer *ipfsv1alpha1.Peer) *restErrors.RestErr {
-	if err := k8sClient.Delete(context.Background(), peer); err != nil {
+func (service ipfsPeerService) Delete(ctx context.Context, peer *ipfsv1alpha1.Peer) *restErrors.RestErr {
+	if err := service.k8sClient.Delete(ctx, peer); err != nil {
 		go logger.Error(service.Delete, err)
-		return restErrors.NewInternalServerError(fmt.Sprintf("can't delete peer by name %s", peer.Name))
+		return restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't delete peer by name %s", peer.Name)))
 	}
 
 	return nil
@@ -1 +1,68 @@
 package ipfs_peer
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newTestPeer creates server profile peer in the default namespace using in memory k8s client
+func newTestPeer(t *testing.T) (IService, *ipfsv1alpha1.Peer) {
+	service := NewIpfsPeerService(fake.NewClientService())
+	peer, err := service.Create(context.Background(), &PeerDto{
+		MetaDataDto:  k8s.MetaDataDto{Name: "my-peer"},
+		InitProfiles: []string{"server"},
+	})
+	assert.Nil(t, err)
+	return service, peer
+}
+
+func TestCreate(t *testing.T) {
+	_, peer := newTestPeer(t)
+
+	assert.Equal(t, "default", peer.Namespace)
+	assert.Equal(t, []ipfsv1alpha1.Profile{ipfsv1alpha1.ServerProfile}, peer.Spec.InitProfiles)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, peer := newTestPeer(t)
+
+	peer, err := service.Update(ctx, &PeerDto{
+		InitProfiles: []string{"test"},
+		Profiles:     []string{"lowpower"},
+		Routing:      "dhtclient",
+		APIPort:      5002,
+	}, peer)
+	assert.Nil(t, err)
+	assert.Equal(t, []ipfsv1alpha1.Profile{ipfsv1alpha1.LowPowerProfile}, peer.Spec.Profiles)
+	assert.Equal(t, ipfsv1alpha1.DHTClientRouting, peer.Spec.Routing)
+	assert.EqualValues(t, 5002, peer.Spec.APIPort)
+	// init profiles are applied once at peer creation
+	assert.Equal(t, []ipfsv1alpha1.Profile{ipfsv1alpha1.ServerProfile}, peer.Spec.InitProfiles)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, peer := newTestPeer(t)
+
+	dto := new(PeerDto).FromIPFSPeer(peer)
+	dto.Profiles = []string{"lowpower"}
+	dto.GatewayPort = 8081
+
+	peer, err := service.Patch(ctx, dto, peer)
+	assert.Nil(t, err)
+	assert.Equal(t, []ipfsv1alpha1.Profile{ipfsv1alpha1.LowPowerProfile}, peer.Spec.Profiles)
+	assert.EqualValues(t, 8081, peer.Spec.GatewayPort)
+
+	// removed fields are reset
+	dto = new(PeerDto).FromIPFSPeer(peer)
+	dto.Profiles = nil
+	peer, err = service.Patch(ctx, dto, peer)
+	assert.Nil(t, err)
+	assert.Empty(t, peer.Spec.Profiles)
+	assert.Equal(t, []ipfsv1alpha1.Profile{ipfsv1alpha1.ServerProfile}, peer.Spec.InitProfiles)
+}
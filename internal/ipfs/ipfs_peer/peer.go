@@ -4,7 +4,9 @@ import (
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 // Peer is IPFS peer
@@ -12,19 +14,19 @@ import (
 type PeerDto struct {
 	models.Time
 	k8s.MetaDataDto
-	InitProfiles []string `json:"initProfiles"`
-	APIPort      uint     `json:"apiPort"`
+	InitProfiles []string `json:"initProfiles" validate:"enum=ipfsProfile"`
+	APIPort      uint     `json:"apiPort" validate:"port"`
 	APIHost      string   `json:"apiHost"`
-	GatewayPort  uint     `json:"gatewayPort"`
+	GatewayPort  uint     `json:"gatewayPort" validate:"port"`
 	GatewayHost  string   `json:"gatewayHost"`
-	Routing      string   `json:"routing"`
-	Profiles     []string `json:"profiles"`
-	CPU          string   `json:"cpu"`
-	CPULimit     string   `json:"cpuLimit"`
-	Memory       string   `json:"memory"`
-	MemoryLimit  string   `json:"memoryLimit"`
-	Storage      string   `json:"storage"`
-	StorageClass *string  `json:"storageClass"`
+	Routing      string   `json:"routing" validate:"enum=ipfsRouting"`
+	Profiles     []string `json:"profiles" validate:"enum=ipfsProfile"`
+	CPU          string   `json:"cpu" validate:"quantity"`
+	CPULimit     string   `json:"cpuLimit" validate:"quantity"`
+	Memory       string   `json:"memory" validate:"quantity"`
+	MemoryLimit  string   `json:"memoryLimit" validate:"quantity"`
+	Storage      string   `json:"storage" validate:"quantity"`
+	StorageClass *string  `json:"storageClass" validate:"dns1123subdomain"`
 }
 
 type PeerListDto []PeerDto
@@ -44,6 +46,7 @@ func (dto PeerDto) FromIPFSPeer(peer *ipfsv1alpha1.Peer) *PeerDto {
 	}
 
 	dto.Name = peer.Name
+	dto.Namespace = peer.Namespace
 	dto.Time = models.Time{CreatedAt: peer.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.APIPort = peer.Spec.APIPort
 	dto.APIHost = peer.Spec.APIHost
@@ -69,3 +72,56 @@ func (peerListDto PeerListDto) FromIPFSPeer(peers []ipfsv1alpha1.Peer) PeerListD
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("ipfsProfile",
+		string(ipfsv1alpha1.ServerProfile),
+		string(ipfsv1alpha1.RandomPortsProfile),
+		string(ipfsv1alpha1.DefaultDatastoreProfile),
+		string(ipfsv1alpha1.LocalDiscoveryProfile),
+		string(ipfsv1alpha1.TestProfile),
+		string(ipfsv1alpha1.DefaultNetworkingProfile),
+		string(ipfsv1alpha1.FlatFSProfile),
+		string(ipfsv1alpha1.BadgerDSProfile),
+		string(ipfsv1alpha1.LowPowerProfile),
+	)
+	validation.RegisterEnum("ipfsRouting",
+		string(ipfsv1alpha1.NoneRouting),
+		string(ipfsv1alpha1.DHTRouting),
+		string(ipfsv1alpha1.DHTClientRouting),
+		string(ipfsv1alpha1.DHTServerRouting),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the ipfs peer
+func (dto *PeerDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToIPFSPeerSpec replaces the peer spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the peer defaulting
+func (dto *PeerDto) ToIPFSPeerSpec(spec *ipfsv1alpha1.PeerSpec) {
+	var profiles, initProfiles []ipfsv1alpha1.Profile
+	for _, profile := range dto.Profiles {
+		profiles = append(profiles, ipfsv1alpha1.Profile(profile))
+	}
+	for _, profile := range dto.InitProfiles {
+		initProfiles = append(initProfiles, ipfsv1alpha1.Profile(profile))
+	}
+
+	spec.InitProfiles = initProfiles
+	spec.Profiles = profiles
+	spec.APIPort = dto.APIPort
+	spec.APIHost = dto.APIHost
+	spec.GatewayPort = dto.GatewayPort
+	spec.GatewayHost = dto.GatewayHost
+	spec.Routing = ipfsv1alpha1.RoutingMechanism(dto.Routing)
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
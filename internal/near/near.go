@@ -4,33 +4,35 @@ import (
 	"github.com/kotalco/api/internal/models"
 	"github.com/kotalco/api/pkg/k8s"
 	"github.com/kotalco/api/pkg/shared"
+	"github.com/kotalco/api/pkg/validation"
 	nearv1alpha1 "github.com/kotalco/kotal/apis/near/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 // NearDto is NEAR node
 type NearDto struct {
 	models.Time
 	k8s.MetaDataDto
-	Network                  string    `json:"network"`
+	Network                  string    `json:"network" validate:"required,enum=nearNetwork"`
 	Archive                  bool      `json:"archive"`
-	NodePrivateKeySecretName string    `json:"nodePrivateKeySecretName"`
-	ValidatorSecretName      string    `json:"validatorSecretName"`
+	NodePrivateKeySecretName string    `json:"nodePrivateKeySecretName" validate:"dns1123subdomain"`
+	ValidatorSecretName      string    `json:"validatorSecretName" validate:"dns1123subdomain"`
 	MinPeers                 uint      `json:"minPeers"`
-	P2PPort                  uint      `json:"p2pPort"`
+	P2PPort                  uint      `json:"p2pPort" validate:"port"`
 	P2PHost                  string    `json:"p2pHost"`
 	RPC                      *bool     `json:"rpc"`
-	RPCPort                  uint      `json:"rpcPort"`
+	RPCPort                  uint      `json:"rpcPort" validate:"port"`
 	RPCHost                  string    `json:"rpcHost"`
-	PrometheusPort           uint      `json:"prometheusPort"`
+	PrometheusPort           uint      `json:"prometheusPort" validate:"port"`
 	PrometheusHost           string    `json:"prometheusHost"`
-	TelemetryURL             string    `json:"telemetryURL"`
+	TelemetryURL             string    `json:"telemetryURL" validate:"url=http|https"`
 	Bootnodes                *[]string `json:"bootnodes"`
-	CPU                      string    `json:"cpu"`
-	CPULimit                 string    `json:"cpuLimit"`
-	Memory                   string    `json:"memory"`
-	MemoryLimit              string    `json:"memoryLimit"`
-	Storage                  string    `json:"storage"`
-	StorageClass             *string   `json:"storageClass"`
+	CPU                      string    `json:"cpu" validate:"quantity"`
+	CPULimit                 string    `json:"cpuLimit" validate:"quantity"`
+	Memory                   string    `json:"memory" validate:"quantity"`
+	MemoryLimit              string    `json:"memoryLimit" validate:"quantity"`
+	Storage                  string    `json:"storage" validate:"quantity"`
+	StorageClass             *string   `json:"storageClass" validate:"dns1123subdomain"`
 }
 
 type NearListDto []NearDto
@@ -38,6 +40,7 @@ type NearListDto []NearDto
 // FromNEARNode creates node model from NEAR node
 func (dto NearDto) FromNEARNode(node *nearv1alpha1.Node) *NearDto {
 	dto.Name = node.Name
+	dto.Namespace = node.Namespace
 	dto.Time = models.Time{CreatedAt: node.CreationTimestamp.UTC().Format(shared.JavascriptISOString)}
 	dto.Network = string(node.Spec.Network)
 	dto.Archive = node.Spec.Archive
@@ -70,3 +73,45 @@ func (listDto NearListDto) FromNEARNode(nodes []nearv1alpha1.Node) NearListDto {
 	}
 	return result
 }
+
+func init() {
+	// the operator declares near networks as kubebuilder enum without go constants
+	validation.RegisterEnum("nearNetwork", "mainnet", "testnet", "betanet")
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the near node
+func (dto *NearDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToNEARNodeSpec replaces the node spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the node defaulting
+func (dto *NearDto) ToNEARNodeSpec(spec *nearv1alpha1.NodeSpec) {
+	spec.Network = dto.Network
+	spec.Archive = dto.Archive
+	spec.NodePrivateKeySecretName = dto.NodePrivateKeySecretName
+	spec.ValidatorSecretName = dto.ValidatorSecretName
+	spec.MinPeers = dto.MinPeers
+	spec.P2PPort = dto.P2PPort
+	spec.P2PHost = dto.P2PHost
+	spec.RPC = dto.RPC != nil && *dto.RPC
+	spec.RPCPort = dto.RPCPort
+	spec.RPCHost = dto.RPCHost
+	spec.PrometheusPort = dto.PrometheusPort
+	spec.PrometheusHost = dto.PrometheusHost
+	spec.TelemetryURL = dto.TelemetryURL
+	// dto bootnodes may point to the spec bootnodes, so they're read before the spec is reset
+	var bootnodes []string
+	if dto.Bootnodes != nil {
+		bootnodes = *dto.Bootnodes
+	}
+	spec.Bootnodes = bootnodes
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
@@ -9,47 +9,48 @@ import (
 	nearv1alpha1 "github.com/kotalco/kotal/apis/near/v1alpha1"
 	sharedAPIs "github.com/kotalco/kotal/apis/shared"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"os"
+	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-type nearService struct{}
+type nearService struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
 
 type IService interface {
-	Get(types.NamespacedName) (*nearv1alpha1.Node, *restErrors.RestErr)
-	Create(dto *NearDto) (*nearv1alpha1.Node, *restErrors.RestErr)
-	Update(*NearDto, *nearv1alpha1.Node) (*nearv1alpha1.Node, *restErrors.RestErr)
-	List(namespace string) (*nearv1alpha1.NodeList, *restErrors.RestErr)
-	Delete(node *nearv1alpha1.Node) *restErrors.RestErr
-	Count(namespace string) (*int, *restErrors.RestErr)
+	Get(context.Context, types.NamespacedName) (*nearv1alpha1.Node, *restErrors.RestErr)
+	Create(ctx context.Context, dto *NearDto) (*nearv1alpha1.Node, *restErrors.RestErr)
+	Update(context.Context, *NearDto, *nearv1alpha1.Node) (*nearv1alpha1.Node, *restErrors.RestErr)
+	Patch(context.Context, *NearDto, *nearv1alpha1.Node) (*nearv1alpha1.Node, *restErrors.RestErr)
+	List(ctx context.Context, namespace string, opts ...client.ListOption) (*nearv1alpha1.NodeList, *restErrors.RestErr)
+	Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr)
+	Delete(ctx context.Context, node *nearv1alpha1.Node) *restErrors.RestErr
+	Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr)
 }
 
-var (
-	k8sClient = k8s.NewClientService()
-)
-
-func NewNearService() IService {
-	return nearService{}
+func NewNearService(k8sClient k8s.K8sClientServiceInterface) IService {
+	return nearService{k8sClient: k8sClient}
 }
 
 // Get gets a single filecoin node by name
-func (service nearService) Get(namespacedName types.NamespacedName) (*nearv1alpha1.Node, *restErrors.RestErr) {
+func (service nearService) Get(ctx context.Context, namespacedName types.NamespacedName) (*nearv1alpha1.Node, *restErrors.RestErr) {
 	node := &nearv1alpha1.Node{}
 
-	if err := k8sClient.Get(context.Background(), namespacedName, node); err != nil {
+	if err := service.k8sClient.Get(ctx, namespacedName, node); err != nil {
 		if apiErrors.IsNotFound(err) {
-			return nil, restErrors.NewBadRequestError(fmt.Sprintf("node by name %s doesn't exit", namespacedName))
+			return nil, restErrors.NewNodeNotFoundError(fmt.Sprintf("node by name %s doesn't exist", namespacedName.Name))
 		}
 		go logger.Error(service.Get, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName))
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't get node by name %s", namespacedName)))
 	}
 
 	return node, nil
 }
 
 // Create creates filecoin node from spec
-func (service nearService) Create(dto *NearDto) (*nearv1alpha1.Node, *restErrors.RestErr) {
+func (service nearService) Create(ctx context.Context, dto *NearDto) (*nearv1alpha1.Node, *restErrors.RestErr) {
 	node := &nearv1alpha1.Node{
 		ObjectMeta: dto.ObjectMetaFromMetadataDto(),
 		Spec: nearv1alpha1.NodeSpec{
@@ -62,23 +63,19 @@ func (service nearService) Create(dto *NearDto) (*nearv1alpha1.Node, *restErrors
 		},
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
-	}
-
-	if err := k8sClient.Create(context.Background(), node); err != nil {
+	if err := service.k8sClient.Create(ctx, node); err != nil {
 		if apiErrors.IsAlreadyExists(err) {
-			return nil, restErrors.NewNotFoundError(fmt.Sprintf("node by name %s already exits", node.Name))
+			return nil, restErrors.NewAlreadyExistsError(fmt.Sprintf("node by name %s already exists", node.Name))
 		}
 		go logger.Error(service.Create, err)
-		return nil, restErrors.NewInternalServerError("failed to create node")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to create node"))
 	}
 
 	return node, nil
 }
 
 // Update updates filecoin node by name from spec
-func (service nearService) Update(dto *NearDto, node *nearv1alpha1.Node) (*nearv1alpha1.Node, *restErrors.RestErr) {
+func (service nearService) Update(ctx context.Context, dto *NearDto, node *nearv1alpha1.Node) (*nearv1alpha1.Node, *restErrors.RestErr) {
 
 	if dto.NodePrivateKeySecretName != "" {
 		node.Spec.NodePrivateKeySecretName = dto.NodePrivateKeySecretName
@@ -144,47 +141,72 @@ func (service nearService) Update(dto *NearDto, node *nearv1alpha1.Node) (*nearv
 		node.Spec.Storage = dto.Storage
 	}
 
-	if os.Getenv("MOCK") == "true" {
-		node.Default()
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Update, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name)))
 	}
 
-	if err := k8sClient.Update(context.Background(), node); err != nil {
-		go logger.Error(service.Update, err)
-		return nil, restErrors.NewInternalServerError(fmt.Sprintf("can't update node by name %s", node.Name))
+	return node, nil
+}
+
+// Patch replaces node spec by name from the patched dto
+// fields reset by the patch are defaulted by the operator mutating webhook on update
+func (service nearService) Patch(ctx context.Context, dto *NearDto, node *nearv1alpha1.Node) (*nearv1alpha1.Node, *restErrors.RestErr) {
+	dto.ToNEARNodeSpec(&node.Spec)
+
+	if err := service.k8sClient.Update(ctx, node); err != nil {
+		go logger.Error(service.Patch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't patch node by name %s", node.Name)))
 	}
 
 	return node, nil
 }
 
 // List returns all filecoin nodes
-func (service nearService) List(namespace string) (*nearv1alpha1.NodeList, *restErrors.RestErr) {
+func (service nearService) List(ctx context.Context, namespace string, opts ...client.ListOption) (*nearv1alpha1.NodeList, *restErrors.RestErr) {
 	nodes := &nearv1alpha1.NodeList{}
-	if err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace)); err != nil {
+	if err := service.k8sClient.List(ctx, nodes, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...); err != nil {
 		go logger.Error(service.List, err)
-		return nil, restErrors.NewInternalServerError("failed to get all nodes")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to get all nodes"))
 	}
 
 	return nodes, nil
 }
 
+// Watch watches nodes changes in the namespace starting after the given resource version
+// all existing nodes are sent as added events if the resource version is empty
+func (service nearService) Watch(ctx context.Context, namespace string, resourceVersion string) (watch.Interface, *restErrors.RestErr) {
+	watcher, err := service.k8sClient.Watch(ctx, &nearv1alpha1.NodeList{}, &client.ListOptions{
+		Namespace: namespace,
+		Raw: &metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		},
+	})
+	if err != nil {
+		go logger.Error(service.Watch, err)
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to watch nodes"))
+	}
+
+	return watcher, nil
+}
+
 // Count returns total number of filecoin nodes
-func (service nearService) Count(namespace string) (*int, *restErrors.RestErr) {
-	nodes := &nearv1alpha1.NodeList{}
-	if err := k8sClient.List(context.Background(), nodes, client.InNamespace(namespace)); err != nil {
+func (service nearService) Count(ctx context.Context, namespace string, opts ...client.ListOption) (*int, *restErrors.RestErr) {
+	length, err := k8s.Count(ctx, service.k8sClient, &nearv1alpha1.NodeList{}, append([]client.ListOption{client.InNamespace(namespace)}, opts...)...)
+	if err != nil {
 		go logger.Error(service.Count, err)
-		return nil, restErrors.NewInternalServerError("failed to count all nodes")
+		return nil, restErrors.FromK8sError(err, restErrors.NewInternalServerError("failed to count all nodes"))
 	}
 
-	length := len(nodes.Items)
-
 	return &length, nil
 }
 
 // Delete deletes ethereum 2.0 filecoin node by name
-func (service nearService) Delete(node *nearv1alpha1.Node) *restErrors.RestErr {
-	if err := k8sClient.Delete(context.Background(), node); err != nil {
+func (service nearService) Delete(ctx context.Context, node *nearv1alpha1.Node) *restErrors.RestErr {
+	if err := service.k8sClient.Delete(ctx, node); err != nil {
 		go logger.Error(service.Delete, err)
-		return restErrors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name))
+		return restErrors.FromK8sError(err, restErrors.NewInternalServerError(fmt.Sprintf("can't delete node by name %s", node.Name)))
 	}
 
 	return nil
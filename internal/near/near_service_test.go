@@ -1 +1,82 @@
 package near
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	nearv1alpha1 "github.com/kotalco/kotal/apis/near/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const bootnode = "ed25519:4k9csx6zMiXy4waUvRMPTkEtAS2RFKLVScocR5HwN53P@34.73.25.182:24567"
+
+// newTestNode creates archive testnet node in the default namespace using in memory k8s client
+func newTestNode(t *testing.T) (IService, *nearv1alpha1.Node) {
+	service := NewNearService(fake.NewClientService())
+	node, err := service.Create(context.Background(), &NearDto{
+		MetaDataDto: k8s.MetaDataDto{Name: "my-node"},
+		Network:     "testnet",
+		Archive:     true,
+	})
+	assert.Nil(t, err)
+	return service, node
+}
+
+func TestCreate(t *testing.T) {
+	_, node := newTestNode(t)
+
+	assert.Equal(t, "default", node.Namespace)
+	assert.Equal(t, "testnet", node.Spec.Network)
+	assert.True(t, node.Spec.Archive)
+	// JSON-RPC server is enabled for new nodes
+	assert.True(t, node.Spec.RPC)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	node, err := service.Update(ctx, &NearDto{
+		MinPeers:  10,
+		RPCPort:   3031,
+		Bootnodes: &[]string{bootnode},
+	}, node)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 10, node.Spec.MinPeers)
+	assert.EqualValues(t, 3031, node.Spec.RPCPort)
+	assert.Equal(t, []string{bootnode}, node.Spec.Bootnodes)
+
+	// rpc port is set only if the JSON-RPC server is enabled
+	rpc := false
+	node, err = service.Update(ctx, &NearDto{RPC: &rpc, RPCPort: 3032}, node)
+	assert.Nil(t, err)
+	assert.False(t, node.Spec.RPC)
+	assert.EqualValues(t, 3031, node.Spec.RPCPort)
+	assert.Equal(t, []string{bootnode}, node.Spec.Bootnodes)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	node.Spec.Bootnodes = []string{bootnode}
+	dto := new(NearDto).FromNEARNode(node)
+	dto.ValidatorSecretName = "my-validator-key"
+
+	node, err := service.Patch(ctx, dto, node)
+	assert.Nil(t, err)
+	assert.Equal(t, "my-validator-key", node.Spec.ValidatorSecretName)
+	// fields missing from the patch are kept
+	assert.Equal(t, []string{bootnode}, node.Spec.Bootnodes)
+
+	// removed fields are reset
+	dto = new(NearDto).FromNEARNode(node)
+	dto.Bootnodes = nil
+	dto.RPC = nil
+	node, err = service.Patch(ctx, dto, node)
+	assert.Nil(t, err)
+	assert.Empty(t, node.Spec.Bootnodes)
+	assert.False(t, node.Spec.RPC)
+	assert.True(t, node.Spec.Archive)
+}
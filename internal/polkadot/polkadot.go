@@ -2,40 +2,43 @@ package polkadot
 
 import (
 	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/validation"
 	polkadotv1alpha1 "github.com/kotalco/kotal/apis/polkadot/v1alpha1"
+	sharedAPI "github.com/kotalco/kotal/apis/shared"
 )
 
 type PolkadotDto struct {
 	k8s.MetaDataDto
-	Network                  string   `json:"network"`
-	NodePrivateKeySecretName string   `json:"nodePrivateKeySecretName"`
+	Network                  string   `json:"network" validate:"required"`
+	NodePrivateKeySecretName string   `json:"nodePrivateKeySecretName" validate:"dns1123subdomain"`
 	Validator                *bool    `json:"validator"`
-	SyncMode                 string   `json:"syncMode"`
-	P2PPort                  uint     `json:"p2pPort"`
+	SyncMode                 string   `json:"syncMode" validate:"enum=polkadotSyncMode"`
+	P2PPort                  uint     `json:"p2pPort" validate:"port"`
 	Pruning                  *bool    `json:"pruning"`
 	RetainedBlocks           uint     `json:"retainedBlocks"`
-	Logging                  string   `json:"logging"`
+	Logging                  string   `json:"logging" validate:"enum=polkadotLogging"`
 	Telemetry                *bool    `json:"telemetry"`
 	TelemetryURL             string   `json:"telemetryURL"`
 	Prometheus               *bool    `json:"prometheus"`
-	PrometheusPort           uint     `json:"prometheusPort"`
+	PrometheusPort           uint     `json:"prometheusPort" validate:"port"`
 	RPC                      *bool    `json:"rpc"`
-	RPCPort                  uint     `json:"rpcPort"`
+	RPCPort                  uint     `json:"rpcPort" validate:"port"`
 	WS                       *bool    `json:"ws"`
-	WSPort                   uint     `json:"wsPort"`
+	WSPort                   uint     `json:"wsPort" validate:"port"`
 	CORSDomains              []string `json:"corsDomains"`
-	CPU                      string   `json:"cpu"`
-	CPULimit                 string   `json:"cpuLimit"`
-	Memory                   string   `json:"memory"`
-	MemoryLimit              string   `json:"memoryLimit"`
-	Storage                  string   `json:"storage"`
-	StorageClass             *string  `json:"storageClass"`
+	CPU                      string   `json:"cpu" validate:"quantity"`
+	CPULimit                 string   `json:"cpuLimit" validate:"quantity"`
+	Memory                   string   `json:"memory" validate:"quantity"`
+	MemoryLimit              string   `json:"memoryLimit" validate:"quantity"`
+	Storage                  string   `json:"storage" validate:"quantity"`
+	StorageClass             *string  `json:"storageClass" validate:"dns1123subdomain"`
 }
 
 type PolkadotListDto []PolkadotDto
 
 func (dto PolkadotDto) FromPolkadotNode(node *polkadotv1alpha1.Node) *PolkadotDto {
 	dto.Name = node.Name
+	dto.Namespace = node.Namespace
 	dto.Network = node.Spec.Network
 	dto.NodePrivateKeySecretName = node.Spec.NodePrivateKeySecretName
 	dto.Validator = &node.Spec.Validator
@@ -70,3 +73,64 @@ func (listDto PolkadotListDto) FromPolkadotNode(nodes []polkadotv1alpha1.Node) P
 	}
 	return result
 }
+
+func init() {
+	validation.RegisterEnum("polkadotSyncMode",
+		string(polkadotv1alpha1.FastSynchronization),
+		string(polkadotv1alpha1.FullSynchronization),
+	)
+	validation.RegisterEnum("polkadotLogging",
+		string(sharedAPI.ErrorLogs),
+		string(sharedAPI.WarnLogs),
+		string(sharedAPI.InfoLogs),
+		string(sharedAPI.DebugLogs),
+		string(sharedAPI.TraceLogs),
+	)
+}
+
+// ValidateCrossFields validates the rules spanning multiple fields of the polkadot node
+func (dto *PolkadotDto) ValidateCrossFields(operation validation.Operation, errs validation.Errors) {
+	if dto.Validator != nil && *dto.Validator {
+		if dto.RPC != nil && *dto.RPC {
+			errs.Add("rpc", "must be false if node is validator")
+		}
+		if dto.WS != nil && *dto.WS {
+			errs.Add("ws", "must be false if node is validator")
+		}
+		if dto.Pruning != nil && *dto.Pruning {
+			errs.Add("pruning", "must be false if node is validator")
+		}
+	}
+
+	validation.ValidateResources(errs, dto.CPU, dto.CPULimit, dto.Memory, dto.MemoryLimit)
+}
+
+// ToPolkadotNodeSpec replaces the node spec fields represented by the dto
+// zero valued fields are reset to the operator defaults by the node defaulting
+func (dto *PolkadotDto) ToPolkadotNodeSpec(spec *polkadotv1alpha1.NodeSpec) {
+	spec.Network = dto.Network
+	spec.NodePrivateKeySecretName = dto.NodePrivateKeySecretName
+	spec.Validator = dto.Validator != nil && *dto.Validator
+	spec.SyncMode = polkadotv1alpha1.SynchronizationMode(dto.SyncMode)
+	spec.P2PPort = dto.P2PPort
+	spec.Pruning = dto.Pruning
+	spec.RetainedBlocks = dto.RetainedBlocks
+	spec.Logging = sharedAPI.VerbosityLevel(dto.Logging)
+	spec.Telemetry = dto.Telemetry != nil && *dto.Telemetry
+	spec.TelemetryURL = dto.TelemetryURL
+	spec.Prometheus = dto.Prometheus != nil && *dto.Prometheus
+	spec.PrometheusPort = dto.PrometheusPort
+	spec.RPC = dto.RPC != nil && *dto.RPC
+	spec.RPCPort = dto.RPCPort
+	spec.WS = dto.WS != nil && *dto.WS
+	spec.WSPort = dto.WSPort
+	spec.CORSDomains = dto.CORSDomains
+	spec.Resources = sharedAPI.Resources{
+		CPU:          dto.CPU,
+		CPULimit:     dto.CPULimit,
+		Memory:       dto.Memory,
+		MemoryLimit:  dto.MemoryLimit,
+		Storage:      dto.Storage,
+		StorageClass: dto.StorageClass,
+	}
+}
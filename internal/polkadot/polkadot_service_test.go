@@ -1 +1,75 @@
 package polkadot
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	polkadotv1alpha1 "github.com/kotalco/kotal/apis/polkadot/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newTestNode creates kusama node in the default namespace using in memory k8s client
+func newTestNode(t *testing.T) (IService, *polkadotv1alpha1.Node) {
+	service := NewPolkadotService(fake.NewClientService())
+	node, err := service.Create(context.Background(), &PolkadotDto{
+		MetaDataDto: k8s.MetaDataDto{Name: "my-node"},
+		Network:     "kusama",
+	})
+	assert.Nil(t, err)
+	return service, node
+}
+
+func TestCreate(t *testing.T) {
+	_, node := newTestNode(t)
+
+	assert.Equal(t, "default", node.Namespace)
+	assert.Equal(t, "kusama", node.Spec.Network)
+	// JSON-RPC server is enabled for new nodes
+	assert.True(t, node.Spec.RPC)
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	validator := true
+	disabled := false
+	node, err := service.Update(ctx, &PolkadotDto{
+		Validator: &validator,
+		RPC:       &disabled,
+		Pruning:   &disabled,
+		SyncMode:  "fast",
+	}, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.Validator)
+	assert.False(t, node.Spec.RPC)
+	assert.False(t, *node.Spec.Pruning)
+	assert.EqualValues(t, "fast", node.Spec.SyncMode)
+
+	// omitted fields are left unchanged
+	node, err = service.Update(ctx, &PolkadotDto{P2PPort: 30334}, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.Validator)
+	assert.False(t, *node.Spec.Pruning)
+	assert.EqualValues(t, 30334, node.Spec.P2PPort)
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	service, node := newTestNode(t)
+
+	telemetry := true
+	dto := new(PolkadotDto).FromPolkadotNode(node)
+	dto.Telemetry = &telemetry
+	dto.TelemetryURL = "wss://telemetry.polkadot.io/submit/ 0"
+	dto.RPC = nil
+
+	node, err := service.Patch(ctx, dto, node)
+	assert.Nil(t, err)
+	assert.True(t, node.Spec.Telemetry)
+	assert.Equal(t, "wss://telemetry.polkadot.io/submit/ 0", node.Spec.TelemetryURL)
+	// removed fields are reset
+	assert.False(t, node.Spec.RPC)
+	assert.Equal(t, "kusama", node.Spec.Network)
+}
@@ -1,24 +1,116 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/kotalco/api/api"
+	"github.com/kotalco/api/pkg/audit"
+	"github.com/kotalco/api/pkg/auth"
+	"github.com/kotalco/api/pkg/authorization"
+	"github.com/kotalco/api/pkg/certs"
 	"github.com/kotalco/api/pkg/configs"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	kotalLogger "github.com/kotalco/api/pkg/logger"
+	"github.com/kotalco/api/pkg/metrics"
 	"github.com/kotalco/api/pkg/server"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"log"
+	"net/http"
+	"os"
+	"strings"
 )
 
 func main() {
+	// configuration is loaded before anything else, so all the packages use the effective configuration
+	apiConfig, err := configs.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("can't load config: %v", err)
+	}
+	if err := kotalLogger.Configure(apiConfig.Log.Level, apiConfig.Log.Output); err != nil {
+		log.Fatalf("can't configure logger: %v", err)
+	}
+
 	config := configs.FiberConfig()
 	app := fiber.New(config)
 
-	app.Use(logger.New())
-	app.Use(recover.New())
-	app.Use(cors.New())
-	api.MapUrl(app)
+	// request id is saved to locals by the request id middleware registered by api.MapUrl
+	app.Use(logger.New(logger.Config{
+		Format: "[${time}] ${locals:" + restErrors.RequestIDLocalsKey + "} ${status} - ${latency} ${method} ${path}\n",
+	}))
+	app.Use(configs.Recover)
+	app.Use(cors.New(cors.Config{AllowOrigins: strings.Join(apiConfig.CORS.AllowOrigins, ",")}))
+
+	verifiers, err := auth.VerifiersFromConfig(context.Background())
+	if err != nil {
+		log.Fatalf("can't create authentication verifiers: %v", err)
+	}
+
+	// callers presenting client certificates verified by the TLS server are authenticated without bearer tokens
+	clientCertificates := apiConfig.TLS.ClientAuth != configs.ClientAuthNone
+	authenticated := len(verifiers) != 0 || clientCertificates
+
+	var middlewares []fiber.Handler
+	if !authenticated {
+		log.Println("WARNING: no authentication verifier is configured, api is accessible without authentication ...")
+	} else {
+		middlewares = append(middlewares, auth.Authenticate(verifiers...))
+	}
+
+	if auth.ImpersonationEnabled() {
+		if !authenticated {
+			log.Fatalf("k8s impersonation requires at least one authentication verifier or client certificates")
+		}
+		middlewares = append(middlewares, auth.Impersonate())
+	}
+
+	authorizer, err := authorization.AuthorizerFromConfig(context.Background())
+	if err != nil {
+		log.Fatalf("can't create authorizer: %v", err)
+	}
+	if authorizer == nil {
+		log.Println("WARNING: no authorization policy is configured, authenticated callers can perform any action ...")
+	}
+	authorization.SetAuthorizer(authorizer)
+
+	deps := api.NewDependencies()
+	deps.Auditor, err = audit.AuditorFromConfig(deps.K8sClient)
+	if err != nil {
+		log.Fatalf("can't create auditor: %v", err)
+	}
+
+	deps.Certificates, err = certs.ReloaderFromConfig(context.Background(), apiConfig.TLS, deps.K8sClient)
+	if err != nil {
+		log.Fatalf("can't load TLS certificate: %v", err)
+	}
+	var tlsConfig *tls.Config
+	if deps.Certificates != nil {
+		go deps.Certificates.Watch(context.Background(), apiConfig.TLS.ReloadInterval.Duration)
+		tlsConfig = deps.Certificates.TLSConfig()
+	}
+
+	api.MapUrl(app, deps, middlewares...)
+
+	metricsServer := metrics.NewServer()
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("can't serve metrics: %v", err)
+		}
+	}()
+
+	server.StartServerWithGracefulShutdown(app, tlsConfig, deps.Drainer)
 
-	server.StartServerWithGracefulShutdown(app)
+	// metrics are served until the api server is drained, so the shutdown can be observed
+	ctx, cancel := context.WithTimeout(context.Background(), apiConfig.Server.DrainTimeout.Duration)
+	defer cancel()
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		log.Printf("can't shut metrics server down: %v", err)
+	}
 }
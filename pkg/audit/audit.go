@@ -0,0 +1,170 @@
+// Package audit records every mutating api call with its caller, target resource, dto diff and result
+// entries are written to the sinks like the logger, rotating json lines file and k8s events on the target resources
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/kotalco/api/pkg/auth"
+	"github.com/kotalco/api/pkg/authorization"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/logger"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"time"
+)
+
+const (
+	// beforeKey is the locals key of the resource dto before the call
+	beforeKey = "audit.before"
+	// targetKey is the locals key of the k8s object the call acted on
+	targetKey = "audit.target"
+	// anonymous is the principal of unauthenticated calls
+	anonymous = "system:anonymous"
+	// defaultNamespace is the namespace of namespaced resources if the namespace qs is missing
+	defaultNamespace = "default"
+)
+
+// mutatingMethods are the audited http methods
+var mutatingMethods = map[string]bool{
+	fiber.MethodPost:   true,
+	fiber.MethodPut:    true,
+	fiber.MethodPatch:  true,
+	fiber.MethodDelete: true,
+}
+
+// Sink writes audit entries
+// target is the k8s object the call acted on, it's nil if the call failed before the object is read or created
+type Sink interface {
+	Write(ctx context.Context, entry *Entry, target client.Object) error
+}
+
+// Store returns the recorded audit entries newest first
+type Store interface {
+	Entries() ([]Entry, error)
+}
+
+// Auditor records the mutating api calls to its sinks and serves them from its store
+type Auditor struct {
+	store Store
+	sinks []Sink
+}
+
+// NewAuditor returns auditor writing entries to the given sinks, and serving them from the given store
+func NewAuditor(store Store, sinks ...Sink) *Auditor {
+	return &Auditor{store: store, sinks: sinks}
+}
+
+// Store returns the store serving the recorded entries
+func (auditor *Auditor) Store() Store {
+	return auditor.store
+}
+
+// Record is middleware recording the mutating calls after they're served
+// it's registered before the authentication middlewares, so rejected calls are recorded too
+// 1-skip the read only calls
+// 2-serve the call and measure its latency
+// 3-build the entry from the principal, permission, cluster and resource saved to locals by the other handlers
+// 4-diff the resource dto saved by Before with the response dto
+// 5-write the entry to all sinks, sinks errors are logged and never fail the call
+func (auditor *Auditor) Record(c *fiber.Ctx) error {
+	if !mutatingMethods[c.Method()] {
+		return c.Next()
+	}
+
+	start := time.Now()
+	err := c.Next()
+
+	entry := &Entry{
+		Time:      start.UTC(),
+		Principal: anonymous,
+		SourceIP:  c.IP(),
+		Method:    c.Method(),
+		Path:      utils.CopyString(c.Path()),
+		Name:      utils.CopyString(c.Params("name")),
+		Namespace: utils.CopyString(c.Query("namespace", defaultNamespace)),
+		DryRun:    k8s.IsDryRun(c.UserContext()),
+		Status:    c.Response().StatusCode(),
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		// the error is turned into response by the app error handler after the middlewares return
+		entry.Status = http.StatusInternalServerError
+		entry.Error = string(restErrors.CodeInternal)
+	}
+
+	if requestID, ok := c.Locals(restErrors.RequestIDLocalsKey).(string); ok {
+		entry.RequestID = requestID
+	}
+	if principal := auth.PrincipalFromCtx(c); principal != nil {
+		entry.Principal = principal.Name
+		entry.Groups = principal.Groups
+	}
+	if permission, ok := authorization.PermissionFromCtx(c); ok {
+		entry.Group = permission.Group
+		entry.Resource = permission.Resource
+		entry.Verb = permission.Verb
+	}
+	cluster, _ := k8s.ClusterFromContext(c.UserContext())
+	entry.Cluster = cluster
+
+	target, _ := c.Locals(targetKey).(client.Object)
+	if target != nil {
+		entry.Name = target.GetName()
+		entry.Namespace = target.GetNamespace()
+	}
+
+	body := responseBody{}
+	if len(c.Response().Body()) != 0 {
+		json.Unmarshal(c.Response().Body(), &body)
+	}
+	if entry.Status >= http.StatusBadRequest && entry.Error == "" {
+		entry.Error = body.Code
+	}
+
+	// failed calls change nothing, deleted resources have no response dto
+	if entry.Status < http.StatusBadRequest {
+		var after interface{}
+		if len(body.Data) != 0 {
+			after = body.Data
+		}
+		entry.Diff = Diff(entry.Resource, c.Locals(beforeKey), after)
+	}
+
+	ctx := context.Background()
+	if cluster != "" {
+		ctx = k8s.WithCluster(ctx, cluster)
+	}
+	for _, sink := range auditor.sinks {
+		if sinkErr := sink.Write(ctx, entry, target); sinkErr != nil {
+			go logger.Error("AUDIT_SINK", sinkErr)
+		}
+	}
+
+	return err
+}
+
+// responseBody is the part of the json response used by the audit entries
+type responseBody struct {
+	Data json.RawMessage `json:"data"`
+	Code string          `json:"code"`
+}
+
+// Before saves the resource and its dto before the mutating call to be recorded by Record
+// it's called by the handlers validating the resource exists, read only calls are ignored
+// the dto is saved as json value because dtos fields may point to the resource fields updated by the call
+func Before(c *fiber.Ctx, obj client.Object, dto interface{}) {
+	if !mutatingMethods[c.Method()] {
+		return
+	}
+	c.Locals(beforeKey, toJSONValue(dto))
+	c.Locals(targetKey, obj)
+}
+
+// Created saves the resource created by the call to be recorded by Record
+func Created(c *fiber.Ctx, obj client.Object) {
+	c.Locals(targetKey, obj)
+}
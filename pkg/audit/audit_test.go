@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/auth"
+	"github.com/kotalco/api/pkg/authorization"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/kotalco/api/pkg/shared"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDto struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Type      string            `json:"type,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+	Replicas  uint              `json:"replicas,omitempty"`
+}
+
+func TestDiff(t *testing.T) {
+	before := testDto{Name: "my-secret", Namespace: "default", Replicas: 1, Data: map[string]string{"key": "old"}}
+	after := testDto{Name: "my-secret", Namespace: "default", Type: "password", Data: map[string]string{"key": "new"}}
+
+	changes := Diff("secrets", before, after)
+	assert.Equal(t, []Change{
+		{Field: "data.key", Before: redacted, After: redacted},
+		{Field: "replicas", Before: float64(1)},
+		{Field: "type", After: "password"},
+	}, changes)
+
+	// data of other resources isn't redacted
+	changes = Diff("nodes", before, after)
+	assert.Equal(t, "old", changes[0].Before)
+
+	// all fields are changes of created resources
+	changes = Diff("nodes", nil, after)
+	assert.Len(t, changes, 4)
+	for _, change := range changes {
+		assert.Nil(t, change.Before)
+	}
+
+	assert.Empty(t, Diff("nodes", before, before))
+}
+
+// newTestApp returns app auditing secrets routes to the given sinks
+func newTestApp(sinks ...Sink) *fiber.App {
+	auditor := NewAuditor(nil, sinks...)
+	can := authorization.For("core", "secrets")
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(restErrors.RequestIDLocalsKey, "my-request")
+		c.Locals(auth.PrincipalKey, &auth.Principal{Name: "alice", Groups: []string{"admins"}})
+		return c.Next()
+	})
+	app.Use(auditor.Record)
+
+	validate := func(c *fiber.Ctx) error {
+		if c.Params("name") != "my-secret" {
+			return restErrors.Send(c, restErrors.NewNotFoundError("secret not found"))
+		}
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "default"}}
+		Before(c, secret, testDto{Name: "my-secret", Namespace: "default", Data: map[string]string{"key": "old"}})
+		return c.Next()
+	}
+
+	app.Get("/secrets/:name", can("get"), validate, func(c *fiber.Ctx) error {
+		return c.JSON(shared.NewResponse(testDto{Name: "my-secret"}))
+	})
+	app.Put("/secrets/:name", can("update"), validate, func(c *fiber.Ctx) error {
+		return c.JSON(shared.NewResponse(testDto{Name: "my-secret", Namespace: "default", Type: "password", Data: map[string]string{"key": "new"}}))
+	})
+	app.Delete("/secrets/:name", can("delete"), validate, func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	return app
+}
+
+func TestRecord(t *testing.T) {
+	store := NewMemoryStore(2)
+	app := newTestApp(store)
+
+	_, err := app.Test(httptest.NewRequest(http.MethodGet, "/secrets/my-secret", nil))
+	assert.Nil(t, err)
+	entries, _ := store.Entries()
+	assert.Empty(t, entries)
+
+	_, err = app.Test(httptest.NewRequest(http.MethodPut, "/secrets/my-secret", strings.NewReader("{}")))
+	assert.Nil(t, err)
+	entries, _ = store.Entries()
+	if assert.Len(t, entries, 1) {
+		entry := entries[0]
+		assert.Equal(t, "my-request", entry.RequestID)
+		assert.Equal(t, "alice", entry.Principal)
+		assert.Equal(t, []string{"admins"}, entry.Groups)
+		assert.NotEmpty(t, entry.SourceIP)
+		assert.Equal(t, http.MethodPut, entry.Method)
+		assert.Equal(t, "/secrets/my-secret", entry.Path)
+		assert.Equal(t, "core", entry.Group)
+		assert.Equal(t, "secrets", entry.Resource)
+		assert.Equal(t, "update", entry.Verb)
+		assert.Equal(t, "my-secret", entry.Name)
+		assert.Equal(t, "default", entry.Namespace)
+		assert.Equal(t, http.StatusOK, entry.Status)
+		assert.Empty(t, entry.Error)
+		assert.Equal(t, []Change{
+			{Field: "data.key", Before: redacted, After: redacted},
+			{Field: "type", After: "password"},
+		}, entry.Diff)
+	}
+
+	_, err = app.Test(httptest.NewRequest(http.MethodDelete, "/secrets/unknown", nil))
+	assert.Nil(t, err)
+	entries, _ = store.Entries()
+	if assert.Len(t, entries, 2) {
+		entry := entries[0]
+		assert.Equal(t, "unknown", entry.Name)
+		assert.Equal(t, "delete", entry.Verb)
+		assert.Equal(t, http.StatusNotFound, entry.Status)
+		assert.Equal(t, string(restErrors.CodeNotFound), entry.Error)
+		assert.Empty(t, entry.Diff)
+	}
+
+	// the oldest entry is overwritten once the store is full
+	_, err = app.Test(httptest.NewRequest(http.MethodDelete, "/secrets/my-secret", nil))
+	assert.Nil(t, err)
+	entries, _ = store.Entries()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, http.StatusNoContent, entries[0].Status)
+		assert.Len(t, entries[0].Diff, 3)
+		assert.Equal(t, http.StatusNotFound, entries[1].Status)
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	k8sClient := fake.NewClientService()
+	app := newTestApp(NewEventSink(k8sClient))
+
+	_, err := app.Test(httptest.NewRequest(http.MethodDelete, "/secrets/my-secret", nil))
+	assert.Nil(t, err)
+	// calls with no target object have no events
+	_, err = app.Test(httptest.NewRequest(http.MethodDelete, "/secrets/unknown", nil))
+	assert.Nil(t, err)
+
+	events := &corev1.EventList{}
+	assert.Eventually(t, func() bool {
+		assert.Nil(t, k8sClient.List(context.Background(), events))
+		return len(events.Items) != 0
+	}, time.Second, 10*time.Millisecond)
+
+	if assert.Len(t, events.Items, 1) {
+		event := events.Items[0]
+		assert.Equal(t, "default", event.Namespace)
+		assert.Equal(t, "Secret", event.InvolvedObject.Kind)
+		assert.Equal(t, "v1", event.InvolvedObject.APIVersion)
+		assert.Equal(t, "my-secret", event.InvolvedObject.Name)
+		assert.Equal(t, "Deleted", event.Reason)
+		assert.Equal(t, corev1.EventTypeNormal, event.Type)
+		assert.Equal(t, eventSource, event.Source.Component)
+		assert.Contains(t, event.Message, "by alice")
+		assert.Contains(t, event.Message, "my-request")
+	}
+}
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/k8s"
+)
+
+// memoryStoreSize is the number of the last entries kept in memory if audit.file isn't set
+const memoryStoreSize = 1000
+
+// AuditorFromConfig creates auditor writing entries to the sinks enabled by audit config
+// entries are always written to the logger
+// audit.file writes the entries to rotating json lines file which serves the audit queries
+// otherwise the last entries are kept in memory
+// audit.maxSize is the max file size in megabytes before it's rotated
+// audit.maxBackups is the max number of rotated files to keep
+// audit.events creates k8s events on the target resources using the given client
+func AuditorFromConfig(k8sClient k8s.K8sClientServiceInterface) (*Auditor, error) {
+	config := configs.Get().Audit
+	sinks := []Sink{LoggerSink{}}
+
+	var store Store
+	if config.File != "" {
+		fileSink, err := NewFileSink(config.File, int64(config.MaxSize)*1024*1024, int(config.MaxBackups))
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+		store = fileSink
+	} else {
+		memoryStore := NewMemoryStore(memoryStoreSize)
+		sinks = append(sinks, memoryStore)
+		store = memoryStore
+	}
+
+	if config.Events {
+		sinks = append(sinks, NewEventSink(k8sClient))
+	}
+
+	return NewAuditor(store, sinks...), nil
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redacted replaces the values of redacted fields
+const redacted = "[REDACTED]"
+
+// redactedFields are the dto fields whose values never reach the audit log keyed by the resource
+// like secrets data which holds private keys and passwords
+var redactedFields = map[string][]string{
+	"secrets": {"data"},
+}
+
+// Entry is the audit record of mutating api call
+type Entry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId,omitempty"`
+	// Principal is the authenticated caller name, system:anonymous if authentication is disabled or failed
+	Principal string   `json:"principal"`
+	Groups    []string `json:"groups,omitempty"`
+	SourceIP  string   `json:"sourceIP"`
+	Method    string   `json:"method"`
+	Path      string   `json:"path"`
+	Cluster   string   `json:"cluster,omitempty"`
+	// Group, Resource and Verb are the permission required by the route like ethereum, nodes and delete
+	Group     string `json:"group,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Verb      string `json:"verb,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	DryRun    bool   `json:"dryRun"`
+	// Diff is the changes between the resource dto before and after the call, secret data is redacted
+	Diff []Change `json:"diff,omitempty"`
+	// Status is the response status code, and Error is the error code of failed calls
+	Status    int     `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// Change is dto field changed by the api call, Before is omitted for created fields and After for removed ones
+type Change struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff returns the changed fields between the before and after dtos sorted by field path
+// nested objects fields are flattened to their dot separated json path like resources.cpu
+// nil before or after dto means the resource is created or deleted, so all the other dto fields are changes
+func Diff(resource string, before, after interface{}) []Change {
+	beforeFields, afterFields := map[string]interface{}{}, map[string]interface{}{}
+	flatten("", toJSONValue(before), beforeFields)
+	flatten("", toJSONValue(after), afterFields)
+
+	changes := []Change{}
+	for field, beforeValue := range beforeFields {
+		if afterValue, ok := afterFields[field]; !ok || !reflect.DeepEqual(beforeValue, afterValue) {
+			changes = append(changes, Change{Field: field, Before: beforeValue, After: afterFields[field]})
+		}
+	}
+	for field, afterValue := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes = append(changes, Change{Field: field, After: afterValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	for i := range changes {
+		if isRedacted(resource, changes[i].Field) {
+			if changes[i].Before != nil {
+				changes[i].Before = redacted
+			}
+			if changes[i].After != nil {
+				changes[i].After = redacted
+			}
+		}
+	}
+
+	return changes
+}
+
+// toJSONValue returns the dto as decoded json value, maps are returned as map[string]interface{}
+func toJSONValue(dto interface{}) interface{} {
+	if dto == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(dto)
+	if err != nil {
+		return nil
+	}
+
+	var value interface{}
+	json.Unmarshal(data, &value)
+	return value
+}
+
+// flatten saves the json value leaves keyed by their dot separated path, arrays are leaves
+// empty values are skipped because omitted and empty dto fields are equivalent
+func flatten(path string, value interface{}, fields map[string]interface{}) {
+	switch typed := value.(type) {
+	case nil:
+	case map[string]interface{}:
+		for key, nested := range typed {
+			if path != "" {
+				key = path + "." + key
+			}
+			flatten(key, nested, fields)
+		}
+	case []interface{}:
+		if path != "" && len(typed) != 0 {
+			fields[path] = typed
+		}
+	default:
+		if path != "" && !reflect.ValueOf(typed).IsZero() {
+			fields[path] = typed
+		}
+	}
+}
+
+// isRedacted returns true if the resource field or its parent is redacted
+func isRedacted(resource, field string) bool {
+	for _, redactedField := range redactedFields[resource] {
+		if field == redactedField || strings.HasPrefix(field, redactedField+".") {
+			return true
+		}
+	}
+	return false
+}
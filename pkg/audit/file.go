@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+)
+
+// maxFileEntries is the max number of entries read from the audit files
+const maxFileEntries = 10000
+
+// FileSink writes the entries as json lines to file, the file is rotated once it reaches its max size
+// rotated files are renamed to path.1, path.2 ... up to the max backups, path.1 being the newest
+type FileSink struct {
+	lock       sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileSink returns sink appending to the file at the given path, the file is created if it doesn't exist
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	sink := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// open opens the file for appending and saves its current size
+func (sink *FileSink) open() error {
+	file, err := os.OpenFile(sink.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	sink.file, sink.size = file, info.Size()
+	return nil
+}
+
+// Write appends the entry to the file, rotating it first if the entry doesn't fit
+func (sink *FileSink) Write(_ context.Context, entry *Entry, _ client.Object) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	if sink.size > 0 && sink.size+int64(len(line)) > sink.maxSize {
+		if err := sink.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := sink.file.Write(line)
+	sink.size += int64(n)
+	return err
+}
+
+// rotate shifts the backups, renames the file to the newest backup and opens new file
+// the oldest backup is removed if the backups exceed the max backups
+func (sink *FileSink) rotate() error {
+	if err := sink.file.Close(); err != nil {
+		return err
+	}
+
+	if sink.maxBackups == 0 {
+		if err := os.Remove(sink.path); err != nil {
+			return err
+		}
+		return sink.open()
+	}
+
+	for i := sink.maxBackups - 1; i > 0; i-- {
+		err := os.Rename(sink.backup(i), sink.backup(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(sink.path, sink.backup(1)); err != nil {
+		return err
+	}
+
+	return sink.open()
+}
+
+// backup returns the path of the i-th backup
+func (sink *FileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", sink.path, i)
+}
+
+// Entries returns the entries of the file and its backups newest first up to the max file entries
+// lines which aren't valid entries are skipped
+func (sink *FileSink) Entries() ([]Entry, error) {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	entries := []Entry{}
+	paths := []string{sink.path}
+	for i := 1; i <= sink.maxBackups; i++ {
+		paths = append(paths, sink.backup(i))
+	}
+
+	for _, path := range paths {
+		fileEntries, err := readEntries(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				break
+			}
+			return nil, err
+		}
+
+		for i := len(fileEntries) - 1; i >= 0 && len(entries) < maxFileEntries; i-- {
+			entries = append(entries, fileEntries[i])
+		}
+		if len(entries) == maxFileEntries {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+// readEntries returns the entries of the json lines file oldest first
+func readEntries(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Close closes the file
+func (sink *FileSink) Close() error {
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+
+	return sink.file.Close()
+}
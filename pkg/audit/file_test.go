@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	newTestEntry := func(i int) *Entry {
+		return &Entry{Name: fmt.Sprintf("node-%d", i), Method: "DELETE", Status: 204}
+	}
+
+	// every entry line is 147 bytes, so every file holds 2 entries
+	sink, err := NewFileSink(path, 300, 2)
+	assert.Nil(t, err)
+
+	for i := 0; i < 7; i++ {
+		assert.Nil(t, sink.Write(context.Background(), newTestEntry(i), nil))
+	}
+	assert.Nil(t, sink.Close())
+
+	for _, name := range []string{"audit.log", "audit.log.1", "audit.log.2"} {
+		_, err := os.Stat(filepath.Join(filepath.Dir(path), name))
+		assert.Nil(t, err, name)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	// reopened files are appended to
+	sink, err = NewFileSink(path, 300, 2)
+	assert.Nil(t, err)
+	defer sink.Close()
+	assert.Nil(t, sink.Write(context.Background(), newTestEntry(7), nil))
+
+	entries, err := sink.Entries()
+	assert.Nil(t, err)
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	assert.Equal(t, []string{"node-7", "node-6", "node-5", "node-4", "node-3", "node-2"}, names)
+}
@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/logger"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sync"
+)
+
+// eventSource is the component reported by the audit events
+const eventSource = "kotal-api"
+
+// eventReasons are the audit events reasons of the succeeded and failed calls keyed by the http method
+var eventReasons = map[string][2]string{
+	http.MethodPost:   {"Created", "CreateFailed"},
+	http.MethodPut:    {"Updated", "UpdateFailed"},
+	http.MethodPatch:  {"Patched", "PatchFailed"},
+	http.MethodDelete: {"Deleted", "DeleteFailed"},
+}
+
+// scheme resolves the group, version and kind of the audited objects
+var scheme = k8s.NewScheme()
+
+// LoggerSink writes the entries to the api logger
+type LoggerSink struct{}
+
+// Write logs the entry as info message with the entry fields under the audit key
+func (LoggerSink) Write(_ context.Context, entry *Entry, _ client.Object) error {
+	logger.Info("AUDIT", zap.Any("audit", entry))
+	return nil
+}
+
+// MemoryStore keeps the last entries in memory, it's the entries store if the audit file isn't configured
+type MemoryStore struct {
+	lock    sync.RWMutex
+	entries []Entry
+	size    int
+	next    int
+}
+
+// NewMemoryStore returns store keeping the last size entries
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{size: size}
+}
+
+// Write saves the entry, overwriting the oldest one if the store is full
+func (store *MemoryStore) Write(_ context.Context, entry *Entry, _ client.Object) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	if len(store.entries) < store.size {
+		store.entries = append(store.entries, *entry)
+	} else {
+		store.entries[store.next] = *entry
+	}
+	store.next = (store.next + 1) % store.size
+
+	return nil
+}
+
+// Entries returns the saved entries newest first
+func (store *MemoryStore) Entries() ([]Entry, error) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+
+	entries := make([]Entry, 0, len(store.entries))
+	for i := 1; i <= len(store.entries); i++ {
+		entries = append(entries, store.entries[(store.next-i+len(store.entries))%len(store.entries)])
+	}
+
+	return entries, nil
+}
+
+// EventSink creates k8s event on the object the call acted on
+// so the call shows up in kubectl describe of the resource
+type EventSink struct {
+	k8sClient k8s.K8sClientServiceInterface
+}
+
+// NewEventSink returns sink creating the events using the given client
+func NewEventSink(k8sClient k8s.K8sClientServiceInterface) *EventSink {
+	return &EventSink{k8sClient: k8sClient}
+}
+
+// Write creates the event in the background, dry run calls and calls with no target object are skipped
+// cluster wide objects events are created in the default namespace like the k8s controllers do
+func (sink *EventSink) Write(ctx context.Context, entry *Entry, target client.Object) error {
+	if target == nil || entry.DryRun {
+		return nil
+	}
+
+	gvk, err := apiutil.GVKForObject(target, scheme)
+	if err != nil {
+		return err
+	}
+
+	namespace := target.GetNamespace()
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	reason, eventType := eventReasons[entry.Method][0], corev1.EventTypeNormal
+	if entry.Status >= http.StatusBadRequest {
+		reason, eventType = eventReasons[entry.Method][1], corev1.EventTypeWarning
+	}
+
+	message := fmt.Sprintf("%s %s by %s, status %d", entry.Method, entry.Path, entry.Principal, entry.Status)
+	if entry.RequestID != "" {
+		message += ", request id " + entry.RequestID
+	}
+
+	timestamp := metav1.NewTime(entry.Time)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", target.GetName(), entry.Time.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Name:       target.GetName(),
+			Namespace:  target.GetNamespace(),
+			UID:        target.GetUID(),
+		},
+		Reason:              reason,
+		Message:             message,
+		Type:                eventType,
+		Source:              corev1.EventSource{Component: eventSource},
+		ReportingController: eventSource,
+		FirstTimestamp:      timestamp,
+		LastTimestamp:       timestamp,
+		Count:               1,
+	}
+
+	go func() {
+		if err := sink.k8sClient.Create(ctx, event); err != nil {
+			go logger.Error("AUDIT_EVENT", err)
+		}
+	}()
+
+	return nil
+}
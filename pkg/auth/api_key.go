@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+const (
+	apiKeyMethod = "api-key"
+	// APIKeysGroup is the group every api key principal belongs to
+	APIKeysGroup = "kotal:api-keys"
+	// apiKeysReloadInterval is the minimum time between two secret reloads
+	apiKeysReloadInterval = 30 * time.Second
+)
+
+// apiKeyVerifier verifies static api keys loaded from a k8s secret
+// every secret data key is a principal name and its value is the api key
+type apiKeyVerifier struct {
+	reader   client.Reader
+	secret   types.NamespacedName
+	lock     sync.RWMutex
+	keys     map[string]string
+	loadedAt time.Time
+}
+
+// NewAPIKeyVerifier creates api key verifier backed by the given secret
+// keys are loaded once and reloaded on unknown keys so keys can be rotated without restarts
+func NewAPIKeyVerifier(ctx context.Context, reader client.Reader, secret types.NamespacedName) (Verifier, error) {
+	verifier := &apiKeyVerifier{
+		reader: reader,
+		secret: secret,
+	}
+	if err := verifier.load(ctx); err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+// NewStaticAPIKeyVerifier creates api key verifier from principal name to api key map
+func NewStaticAPIKeyVerifier(keys map[string]string) Verifier {
+	return &apiKeyVerifier{keys: keys}
+}
+
+// load reads api keys from the secret
+func (verifier *apiKeyVerifier) load(ctx context.Context) error {
+	secret := &corev1.Secret{}
+	if err := verifier.reader.Get(ctx, verifier.secret, secret); err != nil {
+		return err
+	}
+
+	keys := map[string]string{}
+	for name, key := range secret.Data {
+		keys[name] = string(key)
+	}
+	for name, key := range secret.StringData {
+		keys[name] = key
+	}
+
+	verifier.lock.Lock()
+	defer verifier.lock.Unlock()
+	verifier.keys = keys
+	verifier.loadedAt = time.Now()
+
+	return nil
+}
+
+// lookup returns the principal name of the given api key
+func (verifier *apiKeyVerifier) lookup(token string) (string, bool) {
+	verifier.lock.RLock()
+	defer verifier.lock.RUnlock()
+
+	for name, key := range verifier.keys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Verify returns the principal owning the api key
+func (verifier *apiKeyVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	name, ok := verifier.lookup(token)
+
+	if !ok && verifier.reader != nil {
+		verifier.lock.RLock()
+		stale := time.Since(verifier.loadedAt) > apiKeysReloadInterval
+		verifier.lock.RUnlock()
+
+		if stale {
+			if err := verifier.load(ctx); err != nil {
+				return nil, err
+			}
+			name, ok = verifier.lookup(token)
+		}
+	}
+
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{
+		Name:   name,
+		Groups: []string{APIKeysGroup},
+		Method: apiKeyMethod,
+	}, nil
+}
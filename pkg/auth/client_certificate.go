@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/x509"
+	"github.com/gofiber/fiber/v2"
+)
+
+const clientCertificateMethod = "client-certificate"
+
+// clientCertificatePrincipal returns the principal of the client certificate verified by the TLS server
+// principal name is the certificate common name and its groups are the certificate organizations like kubernetes
+// returns nil if the call isn't made over TLS or the client certificate isn't verified
+func clientCertificatePrincipal(c *fiber.Ctx) *Principal {
+	state := c.Context().TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return principalOf(state.VerifiedChains[0][0])
+}
+
+// principalOf returns the principal of the client certificate, or nil if it has no common name
+func principalOf(certificate *x509.Certificate) *Principal {
+	if certificate.Subject.CommonName == "" {
+		return nil
+	}
+
+	return &Principal{
+		Name:   certificate.Subject.CommonName,
+		Groups: certificate.Subject.Organization,
+		Method: clientCertificateMethod,
+	}
+}
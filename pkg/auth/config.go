@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/k8s"
+)
+
+// VerifiersFromConfig creates the token verifiers enabled by auth config
+// auth.apiKeysSecret enables api keys loaded from secret in the form of namespace/name
+// auth.jwtHMACSecret and auth.jwtJWKSFile enable HS256 and RS256 json web tokens
+// auth.tokenReview enables k8s token review
+// returns empty list if no verifier is enabled
+func VerifiersFromConfig(ctx context.Context) ([]Verifier, error) {
+	config := configs.Get().Auth
+	verifiers := []Verifier{}
+
+	if config.APIKeysSecret != "" {
+		verifier, err := NewAPIKeyVerifier(ctx, k8s.NewClientService(), k8s.NamespacedNameFromString(config.APIKeysSecret))
+		if err != nil {
+			return nil, err
+		}
+		verifiers = append(verifiers, verifier)
+	}
+
+	if config.JWTHMACSecret != "" || config.JWTJWKSFile != "" {
+		options := JWTOptions{
+			HMACSecret:    []byte(config.JWTHMACSecret.Value()),
+			Issuer:        config.JWTIssuer,
+			Audience:      config.JWTAudience,
+			UsernameClaim: config.JWTUsernameClaim,
+			GroupsClaim:   config.JWTGroupsClaim,
+		}
+		if config.JWTJWKSFile != "" {
+			keys, err := LoadJWKSFile(config.JWTJWKSFile)
+			if err != nil {
+				return nil, err
+			}
+			options.Keys = keys
+		}
+		verifiers = append(verifiers, NewJWTVerifier(options))
+	}
+
+	if config.TokenReview {
+		verifiers = append(verifiers, NewTokenReviewVerifier(k8s.Clientset(), config.TokenReviewAudiences...))
+	}
+
+	return verifiers, nil
+}
+
+// ImpersonationEnabled returns true if kubernetes.impersonation is set
+// k8s calls are made as the authenticated principal instead of the api service account
+func ImpersonationEnabled() bool {
+	return configs.Get().Kubernetes.Impersonation
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/k8s"
+	"k8s.io/client-go/rest"
+)
+
+// Impersonate returns middleware that forwards the authenticated principal to k8s using impersonation
+// 1-get the principal saved by the Authenticate middleware
+// 2-save impersonation config to the request user context used by the services
+// 3-save impersonation config to locals to be used by the websocket handlers
+func Impersonate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := PrincipalFromCtx(c)
+		if principal == nil {
+			return c.Next()
+		}
+
+		impersonation := rest.ImpersonationConfig{
+			UserName: principal.Name,
+			Groups:   principal.Groups,
+		}
+
+		c.SetUserContext(k8s.WithImpersonation(c.UserContext(), impersonation))
+		c.Locals(k8s.ImpersonationLocalsKey, impersonation)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestImpersonate(t *testing.T) {
+	app := fiber.New()
+	app.Use(Authenticate(NewStaticAPIKeyVerifier(map[string]string{"ci": "ci-key"})))
+	app.Use(Impersonate())
+	app.Get("/", func(c *fiber.Ctx) error {
+		impersonation, ok := k8s.ImpersonationFromContext(c.UserContext())
+		assert.True(t, ok)
+		assert.EqualValues(t, "ci", impersonation.UserName)
+		assert.EqualValues(t, []string{APIKeysGroup}, impersonation.Groups)
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer ci-key")
+	resp, err := app.Test(req)
+	assert.Nil(t, err)
+	assert.EqualValues(t, http.StatusOK, resp.StatusCode)
+}
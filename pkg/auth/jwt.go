@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	jwtMethod = "jwt"
+	// jwtLeeway is the allowed clock skew when checking exp and nbf claims
+	jwtLeeway = time.Minute
+)
+
+// JWTOptions configures the jwt verifier
+type JWTOptions struct {
+	// HMACSecret verifies HS256 signed tokens
+	HMACSecret []byte
+	// Keys verifies RS256 signed tokens by key id, usually loaded from a jwks file
+	Keys map[string]*rsa.PublicKey
+	// Issuer if set must match the iss claim
+	Issuer string
+	// Audience if set must be one of the aud claim values
+	Audience string
+	// UsernameClaim is the claim used as principal name, defaults to sub
+	UsernameClaim string
+	// GroupsClaim is the claim used as principal groups, defaults to groups
+	GroupsClaim string
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// jwtVerifier verifies HS256 and RS256 json web tokens
+type jwtVerifier struct {
+	options JWTOptions
+	now     func() time.Time
+}
+
+// NewJWTVerifier creates json web token verifier
+func NewJWTVerifier(options JWTOptions) Verifier {
+	if options.UsernameClaim == "" {
+		options.UsernameClaim = "sub"
+	}
+	if options.GroupsClaim == "" {
+		options.GroupsClaim = "groups"
+	}
+	return &jwtVerifier{
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Verify checks the token signature and claims and returns the principal it identifies
+func (verifier *jwtVerifier) Verify(_ context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	header := jwtHeader{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := verifier.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := verifier.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+
+	name, _ := claims[verifier.options.UsernameClaim].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, verifier.options.UsernameClaim)
+	}
+
+	return &Principal{
+		Name:   name,
+		Groups: stringsClaim(claims[verifier.options.GroupsClaim]),
+		Method: jwtMethod,
+	}, nil
+}
+
+// verifySignature verifies the signed content using the algorithm from the token header
+func (verifier *jwtVerifier) verifySignature(header jwtHeader, signed string, signature []byte) error {
+	switch header.Algorithm {
+	case "HS256":
+		if len(verifier.options.HMACSecret) == 0 {
+			return ErrInvalidToken
+		}
+		mac := hmac.New(sha256.New, verifier.options.HMACSecret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrInvalidToken
+		}
+	case "RS256":
+		key := verifier.rsaKey(header.KeyID)
+		if key == nil {
+			return ErrInvalidToken
+		}
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return ErrInvalidToken
+		}
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidToken, header.Algorithm)
+	}
+
+	return nil
+}
+
+// rsaKey returns the rsa public key by key id
+// tokens without key id can be verified only if there's a single key
+func (verifier *jwtVerifier) rsaKey(kid string) *rsa.PublicKey {
+	if key, ok := verifier.options.Keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(verifier.options.Keys) == 1 {
+		for _, key := range verifier.options.Keys {
+			return key
+		}
+	}
+	return nil
+}
+
+// verifyClaims checks token expiry, issuer and audience
+// tokens without exp claim are rejected, so leaked tokens can't be used forever
+func (verifier *jwtVerifier) verifyClaims(claims map[string]interface{}) error {
+	now := verifier.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("%w: token has no expiry", ErrInvalidToken)
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok {
+		if now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+			return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+		}
+	}
+
+	if issuer := verifier.options.Issuer; issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != issuer {
+			return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+		}
+	}
+
+	if audience := verifier.options.Audience; audience != "" {
+		found := false
+		for _, aud := range stringsClaim(claims["aud"]) {
+			if aud == audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+		}
+	}
+
+	return nil
+}
+
+// decodeSegment decodes base64 url encoded json token segment
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringsClaim returns claim values for claims that can be a string or list of strings
+func stringsClaim(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := []string{}
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	assert.Nil(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func hs256Token(t *testing.T, secret []byte, claims map[string]interface{}) string {
+	signed := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func rs256Token(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.Nil(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifierHS256(t *testing.T) {
+	secret := []byte("s3cr3t")
+	verifier := NewJWTVerifier(JWTOptions{HMACSecret: secret, Issuer: "kotal", Audience: "api"})
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	token := hs256Token(t, secret, map[string]interface{}{
+		"sub":    "alice",
+		"iss":    "kotal",
+		"aud":    []string{"dashboard", "api"},
+		"exp":    exp,
+		"groups": []string{"admins"},
+	})
+	principal, err := verifier.Verify(context.Background(), token)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "alice", principal.Name)
+	assert.EqualValues(t, []string{"admins"}, principal.Groups)
+	assert.EqualValues(t, jwtMethod, principal.Method)
+
+	testCases := map[string]string{
+		"wrong secret":    hs256Token(t, []byte("wrong"), map[string]interface{}{"sub": "alice", "iss": "kotal", "aud": "api", "exp": exp}),
+		"expired":         hs256Token(t, secret, map[string]interface{}{"sub": "alice", "iss": "kotal", "aud": "api", "exp": float64(time.Now().Add(-time.Hour).Unix())}),
+		"missing expiry":  hs256Token(t, secret, map[string]interface{}{"sub": "alice", "iss": "kotal", "aud": "api"}),
+		"wrong issuer":    hs256Token(t, secret, map[string]interface{}{"sub": "alice", "iss": "other", "aud": "api", "exp": exp}),
+		"wrong audience":  hs256Token(t, secret, map[string]interface{}{"sub": "alice", "iss": "kotal", "aud": "other", "exp": exp}),
+		"missing subject": hs256Token(t, secret, map[string]interface{}{"iss": "kotal", "aud": "api", "exp": exp}),
+		"malformed":       "not.a-token",
+	}
+
+	for name, token := range testCases {
+		_, err := verifier.Verify(context.Background(), token)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestJWTVerifierRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"key-1","use":"sig","n":"%s","e":"%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	)
+	keys, err := ParseJWKS([]byte(jwks))
+	assert.Nil(t, err)
+
+	verifier := NewJWTVerifier(JWTOptions{Keys: keys})
+	claims := map[string]interface{}{"sub": "bob", "exp": float64(time.Now().Add(time.Hour).Unix())}
+
+	principal, err := verifier.Verify(context.Background(), rs256Token(t, key, "key-1", claims))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "bob", principal.Name)
+
+	_, err = verifier.Verify(context.Background(), rs256Token(t, key, "key-2", claims))
+	assert.NotNil(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	_, err = verifier.Verify(context.Background(), rs256Token(t, otherKey, "key-1", claims))
+	assert.NotNil(t, err)
+}
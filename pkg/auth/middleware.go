@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"strings"
+)
+
+const (
+	bearerPrefix = "bearer "
+	// accessTokenQuery is used by websocket clients which can't set the authorization header
+	accessTokenQuery = "access_token"
+)
+
+// Authenticate returns middleware that authenticates requests using client certificates or bearer tokens
+// 1-authenticate callers presenting client certificate verified by the TLS server using its subject
+// 2-extract bearer token from authorization header or access_token query string
+// 3-verify the token using the given verifiers in order
+// 4-return unauthorized if no verifier accepted the token
+// 5-save the principal to locals with the key principal to be used by the other handlers
+func Authenticate(verifiers ...Verifier) fiber.Handler {
+	verifier := chain(verifiers)
+
+	return func(c *fiber.Ctx) error {
+		if principal := clientCertificatePrincipal(c); principal != nil {
+			c.Locals(PrincipalKey, principal)
+			return c.Next()
+		}
+
+		token := bearerToken(c)
+		if token == "" {
+			return unauthorized(c, "missing bearer token")
+		}
+
+		principal, err := verifier.Verify(c.UserContext(), token)
+		if err != nil {
+			return unauthorized(c, "invalid bearer token")
+		}
+
+		c.Locals(PrincipalKey, principal)
+
+		return c.Next()
+	}
+}
+
+// bearerToken returns the bearer token from authorization header or access_token query string
+func bearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) > len(bearerPrefix) && strings.ToLower(header[:len(bearerPrefix)]) == bearerPrefix {
+		return strings.TrimSpace(header[len(bearerPrefix):])
+	}
+	return c.Query(accessTokenQuery)
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	unAuthorizedErr := restErrors.NewUnAuthorizedError(message)
+	c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	return restErrors.Send(c, unAuthorizedErr)
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	app := fiber.New()
+	app.Use(Authenticate(NewStaticAPIKeyVerifier(map[string]string{"ci": "ci-key"})))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(PrincipalFromCtx(c).Name)
+	})
+
+	testCases := []struct {
+		name   string
+		header string
+		query  string
+		status int
+	}{
+		{"missing token", "", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer wrong-key", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic ci-key", "", http.StatusUnauthorized},
+		{"valid header token", "Bearer ci-key", "", http.StatusOK},
+		{"valid query token", "", "?access_token=ci-key", http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/"+testCase.query, nil)
+		if testCase.header != "" {
+			req.Header.Set(fiber.HeaderAuthorization, testCase.header)
+		}
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		assert.EqualValues(t, testCase.status, resp.StatusCode, testCase.name)
+	}
+}
@@ -0,0 +1,28 @@
+// Package auth authenticates api callers using bearer tokens
+// tokens are checked against a chain of pluggable verifiers (api keys, jwt, k8s token review)
+package auth
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// PrincipalKey is the fiber locals key holding the authenticated principal
+const PrincipalKey = "principal"
+
+// Principal is the authenticated identity behind a request
+type Principal struct {
+	Name   string   `json:"name"`
+	Groups []string `json:"groups,omitempty"`
+	// Method is the verifier that authenticated the principal
+	Method string `json:"method"`
+}
+
+// PrincipalFromCtx returns the authenticated principal stored by the Authenticate middleware
+// returns nil if the request wasn't authenticated
+func PrincipalFromCtx(c *fiber.Ctx) *Principal {
+	principal, ok := c.Locals(PrincipalKey).(*Principal)
+	if !ok {
+		return nil
+	}
+	return principal
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const tokenReviewMethod = "token-review"
+
+// tokenReviewVerifier verifies k8s tokens (service account tokens, oidc tokens ... etc) using TokenReview api
+type tokenReviewVerifier struct {
+	clientset kubernetes.Interface
+	audiences []string
+}
+
+// NewTokenReviewVerifier creates verifier that delegates token verification to k8s api server
+func NewTokenReviewVerifier(clientset kubernetes.Interface, audiences ...string) Verifier {
+	return &tokenReviewVerifier{
+		clientset: clientset,
+		audiences: audiences,
+	}
+}
+
+// Verify creates token review and returns the authenticated k8s user
+// the token review has the write deadline, so unresponsive api server doesn't hang the authentication
+func (verifier *tokenReviewVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	review := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token:     token,
+			Audiences: verifier.audiences,
+		},
+	}
+
+	ctx, cancel := k8s.WithTimeout(ctx, "create")
+	defer cancel()
+
+	review, err := verifier.clientset.AuthenticationV1().TokenReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !review.Status.Authenticated {
+		return nil, ErrInvalidToken
+	}
+
+	return &Principal{
+		Name:   review.Status.User.Username,
+		Groups: review.Status.User.Groups,
+		Method: tokenReviewMethod,
+	}, nil
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrInvalidToken is returned by verifiers when the token isn't recognized or can't be trusted
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier verifies bearer tokens and resolves them to a principal
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// chain tries verifiers in order and returns the first principal resolved
+type chain []Verifier
+
+// Verify returns the principal resolved by the first verifier accepting the token
+func (verifiers chain) Verify(ctx context.Context, token string) (*Principal, error) {
+	for _, verifier := range verifiers {
+		principal, err := verifier.Verify(ctx, token)
+		if err == nil {
+			return principal, nil
+		}
+	}
+	return nil, ErrInvalidToken
+}
@@ -0,0 +1,84 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/api/pkg/auth"
+	"github.com/kotalco/api/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Authorizer authorizes principals to perform verbs on protocol resources
+type Authorizer interface {
+	Authorize(principal *auth.Principal, permission Permission, namespace string) bool
+}
+
+// Loader loads policy from its source
+// version changes when the policy source changes
+type Loader interface {
+	Load(ctx context.Context) (policy *Policy, version string, err error)
+}
+
+// PolicyAuthorizer authorizes using the latest policy loaded by its loader
+type PolicyAuthorizer struct {
+	loader  Loader
+	lock    sync.RWMutex
+	policy  *Policy
+	version string
+}
+
+// NewAuthorizer creates authorizer from the policy loaded by the given loader
+func NewAuthorizer(ctx context.Context, loader Loader) (*PolicyAuthorizer, error) {
+	authorizer := &PolicyAuthorizer{loader: loader}
+	if err := authorizer.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return authorizer, nil
+}
+
+// Authorize returns true if the current policy allows the principal permission in namespace
+func (authorizer *PolicyAuthorizer) Authorize(principal *auth.Principal, permission Permission, namespace string) bool {
+	authorizer.lock.RLock()
+	defer authorizer.lock.RUnlock()
+
+	return authorizer.policy.Allows(principal, permission, namespace)
+}
+
+// Reload loads the policy again and replaces the current one if its source has changed
+// invalid policies are rejected and the current policy is kept
+func (authorizer *PolicyAuthorizer) Reload(ctx context.Context) error {
+	policy, version, err := authorizer.loader.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("can't load authorization policy: %w", err)
+	}
+
+	authorizer.lock.Lock()
+	defer authorizer.lock.Unlock()
+
+	if authorizer.policy != nil && version == authorizer.version {
+		return nil
+	}
+
+	authorizer.policy = policy
+	authorizer.version = version
+
+	return nil
+}
+
+// Watch reloads the policy every interval until the context is done
+func (authorizer *PolicyAuthorizer) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := authorizer.Reload(ctx); err != nil {
+				go logger.Error("AUTHORIZATION_POLICY_RELOAD", err)
+			}
+		}
+	}
+}
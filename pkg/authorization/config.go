@@ -0,0 +1,34 @@
+package authorization
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/k8s"
+)
+
+// AuthorizerFromConfig creates policy authorizer enabled by authorization config and keeps reloading its policy
+// authorization.policyFile loads policy from local yaml file
+// authorization.policyConfigMap loads policy from configmap in the form of namespace/name
+// authorization.policyReloadInterval is the policy reload interval
+// returns nil if authorization is not enabled
+func AuthorizerFromConfig(ctx context.Context) (Authorizer, error) {
+	config := configs.Get().Authorization
+	var loader Loader
+
+	if config.PolicyFile != "" {
+		loader = NewFileLoader(config.PolicyFile)
+	} else if config.PolicyConfigMap != "" {
+		loader = NewConfigMapLoader(k8s.NewClientService(), k8s.NamespacedNameFromString(config.PolicyConfigMap))
+	} else {
+		return nil, nil
+	}
+
+	authorizer, err := NewAuthorizer(ctx, loader)
+	if err != nil {
+		return nil, err
+	}
+
+	go authorizer.Watch(ctx, config.PolicyReloadInterval.Duration)
+
+	return authorizer, nil
+}
@@ -0,0 +1,77 @@
+package authorization
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PolicyConfigMapKey is the configmap data key holding the policy
+const PolicyConfigMapKey = "policy.yaml"
+
+// fileLoader loads policy from local yaml file
+type fileLoader struct {
+	path string
+}
+
+// NewFileLoader creates loader that reads policy from local yaml file
+func NewFileLoader(path string) Loader {
+	return fileLoader{path: path}
+}
+
+// Load reads the policy file, version is the file modification time
+func (loader fileLoader) Load(_ context.Context) (*Policy, string, error) {
+	info, err := os.Stat(loader.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(loader.path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return policy, info.ModTime().String(), nil
+}
+
+// configMapLoader loads policy from configmap
+type configMapLoader struct {
+	reader    client.Reader
+	configMap types.NamespacedName
+}
+
+// NewConfigMapLoader creates loader that reads policy from policy.yaml key of configmap
+func NewConfigMapLoader(reader client.Reader, configMap types.NamespacedName) Loader {
+	return configMapLoader{
+		reader:    reader,
+		configMap: configMap,
+	}
+}
+
+// Load reads the policy configmap, version is the configmap resource version
+func (loader configMapLoader) Load(ctx context.Context) (*Policy, string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := loader.reader.Get(ctx, loader.configMap, configMap); err != nil {
+		return nil, "", err
+	}
+
+	data, ok := configMap.Data[PolicyConfigMapKey]
+	if !ok {
+		return nil, "", fmt.Errorf("configmap %s has no %s key", loader.configMap, PolicyConfigMapKey)
+	}
+
+	policy, err := ParsePolicy([]byte(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return policy, configMap.ResourceVersion, nil
+}
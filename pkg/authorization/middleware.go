@@ -0,0 +1,163 @@
+package authorization
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/auth"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/k8s"
+	"sync"
+)
+
+const (
+	namespaceKeyword = "namespace"
+	defaultNamespace = "default"
+	// PermissionKey is the fiber locals key holding the permission required by the route
+	PermissionKey = "permission"
+)
+
+// clusterScoped are the group resources which don't belong to a namespace, like storage classes
+var clusterScoped = map[string]bool{
+	"core/storageclasses": true,
+	"core/clusters":       true,
+	"core/config":         true,
+	"core/audit":          true,
+	"core/diagnostics":    true,
+}
+
+// anonymous is the principal of unauthenticated requests
+var anonymous = &auth.Principal{
+	Name:   "system:anonymous",
+	Groups: []string{"system:unauthenticated"},
+}
+
+var (
+	authorizerLock = &sync.RWMutex{}
+	authorizer     Authorizer
+)
+
+// SetAuthorizer sets the authorizer used by Require middlewares
+// nil authorizer disables authorization
+func SetAuthorizer(a Authorizer) {
+	authorizerLock.Lock()
+	defer authorizerLock.Unlock()
+	authorizer = a
+}
+
+func currentAuthorizer() Authorizer {
+	authorizerLock.RLock()
+	defer authorizerLock.RUnlock()
+	return authorizer
+}
+
+// Require returns middleware that authorizes the request principal to perform verb on group resource
+// the permission is saved to locals even if authorization is disabled, so the audit log knows the route resource
+// 1-pass if authorization is disabled
+// 2-get the principal saved by the authentication middleware or anonymous principal
+// 3-return forbidden if the principal isn't allowed in the namespace the request acts on of the selected cluster
+func Require(group, resource, verb string) fiber.Handler {
+	permission := Permission{
+		Group:    group,
+		Resource: resource,
+		Verb:     verb,
+	}
+
+	return func(c *fiber.Ctx) error {
+		authorizer := currentAuthorizer()
+		if authorizer == nil {
+			c.Locals(PermissionKey, permission)
+			return c.Next()
+		}
+
+		requested := permission
+		requested.Cluster = requestCluster(c)
+		c.Locals(PermissionKey, requested)
+
+		principal := auth.PrincipalFromCtx(c)
+		if principal == nil {
+			principal = anonymous
+		}
+
+		namespace := requestNamespace(c, requested)
+		if !authorizer.Authorize(principal, requested, namespace) {
+			scope := fmt.Sprintf("namespace %s of cluster %s", namespace, requested.Cluster)
+			if namespace == "" {
+				scope = fmt.Sprintf("cluster %s", requested.Cluster)
+			}
+			forbiddenErr := restErrors.NewForbiddenError(fmt.Sprintf("%s can't %s %s/%s in %s", principal.Name, verb, group, resource, scope))
+			return restErrors.Send(c, forbiddenErr)
+		}
+
+		return c.Next()
+	}
+}
+
+// requestCluster returns the cluster selected by the request saved to locals by the cluster middleware
+// requests which don't select a cluster are served by the default cluster
+func requestCluster(c *fiber.Ctx) string {
+	if cluster, ok := c.Locals(k8s.ClusterLocalsKey).(string); ok {
+		return cluster
+	}
+
+	clusters, err := k8s.Clusters()
+	if err == nil {
+		for _, cluster := range clusters {
+			if cluster.Default {
+				return cluster.Name
+			}
+		}
+	}
+	return k8s.DefaultClusterName
+}
+
+// requestNamespace returns the namespace the request acts on
+// 1-cluster scoped resources like storage classes don't act on a namespace, empty namespace is returned
+// 2-namespaces act on themselves, the :name param or the name in the create body
+// 3-creates write to the namespace in the body, or the default namespace if it's empty, see k8s.MetaDataDto
+// 4-other requests act on the namespace query parameter, or the default namespace
+func requestNamespace(c *fiber.Ctx, permission Permission) string {
+	if clusterScoped[permission.Group+"/"+permission.Resource] {
+		return ""
+	}
+
+	body := struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}{}
+	if permission.Verb == "create" {
+		// invalid bodies are rejected by the handlers
+		json.Unmarshal(c.Body(), &body)
+	}
+
+	if permission.Group == "core" && permission.Resource == "namespaces" {
+		if name := c.Params("name"); name != "" {
+			return name
+		}
+		if body.Name != "" {
+			return body.Name
+		}
+	} else if permission.Verb == "create" {
+		if body.Namespace != "" {
+			return body.Namespace
+		}
+		return defaultNamespace
+	}
+
+	return c.Query(namespaceKeyword, defaultNamespace)
+}
+
+// For returns Require middleware factory for group resource
+// used by the route table to declare each route permission by its verb
+func For(group, resource string) func(verb string) fiber.Handler {
+	return func(verb string) fiber.Handler {
+		return Require(group, resource, verb)
+	}
+}
+
+// PermissionFromCtx returns the permission required by the route saved by the Require middleware
+// returns false if the route doesn't declare its permission
+func PermissionFromCtx(c *fiber.Ctx) (Permission, bool) {
+	permission, ok := c.Locals(PermissionKey).(Permission)
+	return permission, ok
+}
@@ -0,0 +1,118 @@
+// Package authorization decides if authenticated principals can perform verbs on protocol resources
+// decisions are made using role based policies loaded from configmap or local yaml file
+package authorization
+
+import (
+	"github.com/kotalco/api/pkg/auth"
+	"sigs.k8s.io/yaml"
+)
+
+// Wildcard matches any group, resource, verb, namespace, cluster, user or principal group
+const Wildcard = "*"
+
+// Permission is a verb on a protocol resource in a cluster
+// like create ethereum2 validators or list ipfs peers in mainnet cluster
+// Cluster is the cluster selected by the request, it's empty in the permission declared by the route
+type Permission struct {
+	Group    string
+	Resource string
+	Verb     string
+	Cluster  string
+}
+
+// Policy is the set of roles and who they are bound to
+type Policy struct {
+	Roles    []Role    `json:"roles"`
+	Bindings []Binding `json:"bindings"`
+}
+
+// Role is a named set of rules
+type Role struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// Rule allows verbs on resources of protocol groups in namespaces of clusters
+// empty namespaces list matches all namespaces, and empty clusters list matches all clusters
+// cluster scoped resources like storage classes are allowed only by rules without namespaces list
+type Rule struct {
+	Groups     []string `json:"groups"`
+	Resources  []string `json:"resources"`
+	Verbs      []string `json:"verbs"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Clusters   []string `json:"clusters,omitempty"`
+}
+
+// Binding grants role to users and principal groups
+type Binding struct {
+	Role   string   `json:"role"`
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// ParsePolicy parses yaml or json policy
+func ParsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Allows returns true if any role bound to the principal allows the permission in the namespace of the permission cluster
+func (policy *Policy) Allows(principal *auth.Principal, permission Permission, namespace string) bool {
+	roles := map[string]Role{}
+	for _, role := range policy.Roles {
+		roles[role.Name] = role
+	}
+
+	for _, binding := range policy.Bindings {
+		if !binding.matches(principal) {
+			continue
+		}
+		role, ok := roles[binding.Role]
+		if !ok {
+			continue
+		}
+		for _, rule := range role.Rules {
+			if rule.allows(permission, namespace) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matches returns true if the binding subjects include the principal or one of its groups
+func (binding Binding) matches(principal *auth.Principal) bool {
+	if contains(binding.Users, principal.Name) {
+		return true
+	}
+	for _, group := range principal.Groups {
+		if contains(binding.Groups, group) {
+			return true
+		}
+	}
+	return contains(binding.Groups, Wildcard)
+}
+
+// allows returns true if the rule allows the permission in the namespace of the permission cluster
+// empty namespace is the namespace of cluster scoped resources
+func (rule Rule) allows(permission Permission, namespace string) bool {
+	return contains(rule.Groups, permission.Group) &&
+		contains(rule.Resources, permission.Resource) &&
+		contains(rule.Verbs, permission.Verb) &&
+		(len(rule.Namespaces) == 0 || (namespace != "" && contains(rule.Namespaces, namespace))) &&
+		(len(rule.Clusters) == 0 || contains(rule.Clusters, permission.Cluster))
+}
+
+// contains returns true if values contain the value or the wildcard
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value || v == Wildcard {
+			return true
+		}
+	}
+	return false
+}
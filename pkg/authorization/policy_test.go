@@ -0,0 +1,194 @@
+package authorization
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/auth"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+roles:
+  - name: admin
+    rules:
+      - groups: ["*"]
+        resources: ["*"]
+        verbs: ["*"]
+  - name: ipfs-viewer
+    rules:
+      - groups: ["ipfs"]
+        resources: ["peers"]
+        verbs: ["list", "get"]
+  - name: goerli-validators
+    rules:
+      - groups: ["ethereum2"]
+        resources: ["validators"]
+        verbs: ["delete"]
+        namespaces: ["goerli"]
+  - name: goerli-nodes
+    rules:
+      - groups: ["ethereum", "core"]
+        resources: ["nodes", "namespaces"]
+        verbs: ["create", "delete"]
+        namespaces: ["goerli"]
+  - name: testnet-nodes
+    rules:
+      - groups: ["ethereum"]
+        resources: ["nodes"]
+        verbs: ["delete"]
+        clusters: ["testnet"]
+  - name: goerli-storage
+    rules:
+      - groups: ["core"]
+        resources: ["storageclasses"]
+        verbs: ["list"]
+        namespaces: ["goerli"]
+  - name: storage-viewer
+    rules:
+      - groups: ["core"]
+        resources: ["storageclasses"]
+        verbs: ["list"]
+bindings:
+  - role: admin
+    groups: ["admins"]
+  - role: ipfs-viewer
+    users: ["bob"]
+  - role: goerli-validators
+    users: ["alice"]
+  - role: goerli-nodes
+    users: ["dave"]
+  - role: testnet-nodes
+    users: ["erin"]
+  - role: goerli-storage
+    users: ["frank"]
+  - role: storage-viewer
+    users: ["grace"]
+`
+
+func TestPolicyAllows(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	assert.Nil(t, err)
+
+	admin := &auth.Principal{Name: "carol", Groups: []string{"admins"}}
+	bob := &auth.Principal{Name: "bob"}
+	alice := &auth.Principal{Name: "alice"}
+	erin := &auth.Principal{Name: "erin"}
+	frank := &auth.Principal{Name: "frank"}
+	grace := &auth.Principal{Name: "grace"}
+
+	testCases := []struct {
+		principal  *auth.Principal
+		permission Permission
+		namespace  string
+		allowed    bool
+	}{
+		{admin, Permission{Group: "core", Resource: "secrets", Verb: "get"}, "default", true},
+		{bob, Permission{Group: "ipfs", Resource: "peers", Verb: "list"}, "default", true},
+		{bob, Permission{Group: "ipfs", Resource: "peers", Verb: "delete"}, "default", false},
+		{bob, Permission{Group: "ipfs", Resource: "clusterpeers", Verb: "list"}, "default", false},
+		{alice, Permission{Group: "ethereum2", Resource: "validators", Verb: "delete"}, "goerli", true},
+		{alice, Permission{Group: "ethereum2", Resource: "validators", Verb: "delete"}, "mainnet", false},
+		{alice, Permission{Group: "core", Resource: "secrets", Verb: "get"}, "goerli", false},
+		{erin, Permission{Group: "ethereum", Resource: "nodes", Verb: "delete", Cluster: "testnet"}, "default", true},
+		{erin, Permission{Group: "ethereum", Resource: "nodes", Verb: "delete", Cluster: "mainnet"}, "default", false},
+		// cluster scoped resources are allowed only by rules which aren't limited to namespaces
+		{frank, Permission{Group: "core", Resource: "storageclasses", Verb: "list"}, "", false},
+		{grace, Permission{Group: "core", Resource: "storageclasses", Verb: "list"}, "", true},
+		{admin, Permission{Group: "core", Resource: "storageclasses", Verb: "list"}, "", true},
+	}
+
+	for _, testCase := range testCases {
+		allowed := policy.Allows(testCase.principal, testCase.permission, testCase.namespace)
+		assert.EqualValues(t, testCase.allowed, allowed, "%s %v in %s", testCase.principal.Name, testCase.permission, testCase.namespace)
+	}
+}
+
+func TestParsePolicyRejectsUnknownFields(t *testing.T) {
+	_, err := ParsePolicy([]byte("roles:\n  - name: admin\n    rulez: []\n"))
+	assert.NotNil(t, err)
+}
+
+func TestRequire(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	assert.Nil(t, err)
+
+	SetAuthorizer(staticAuthorizer{policy})
+	defer SetAuthorizer(nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals(auth.PrincipalKey, &auth.Principal{Name: c.Get("X-User")})
+		return c.Next()
+	})
+	app.Delete("/validators", Require("ethereum2", "validators", "delete"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+	app.Post("/nodes", Require("ethereum", "nodes", "create"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+	app.Post("/namespaces", Require("core", "namespaces", "create"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+	app.Delete("/namespaces/:name", Require("core", "namespaces", "delete"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+	// the cluster is saved to locals by the cluster middleware
+	selectCluster := func(c *fiber.Ctx) error {
+		c.Locals(k8s.ClusterLocalsKey, c.Params("cluster"))
+		return c.Next()
+	}
+	app.Get("/storageclasses", Require("core", "storageclasses", "list"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+	app.Delete("/clusters/:cluster/nodes", selectCluster, Require("ethereum", "nodes", "delete"), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	testCases := []struct {
+		user   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"alice", http.MethodDelete, "/validators?namespace=goerli", "", http.StatusNoContent},
+		{"alice", http.MethodDelete, "/validators", "", http.StatusForbidden},
+		{"bob", http.MethodDelete, "/validators?namespace=goerli", "", http.StatusForbidden},
+		// creates are authorized in the namespace of the body they're written to
+		{"dave", http.MethodPost, "/nodes?namespace=goerli", `{"name":"my-node","namespace":"goerli"}`, http.StatusCreated},
+		{"dave", http.MethodPost, "/nodes?namespace=goerli", `{"name":"my-node","namespace":"mainnet"}`, http.StatusForbidden},
+		{"dave", http.MethodPost, "/nodes?namespace=goerli", `{"name":"my-node"}`, http.StatusForbidden},
+		// namespaces are authorized in themselves
+		{"dave", http.MethodPost, "/namespaces?namespace=goerli", `{"name":"mainnet"}`, http.StatusForbidden},
+		{"dave", http.MethodPost, "/namespaces", `{"name":"goerli"}`, http.StatusCreated},
+		{"dave", http.MethodDelete, "/namespaces/mainnet?namespace=goerli", "", http.StatusForbidden},
+		{"dave", http.MethodDelete, "/namespaces/goerli", "", http.StatusNoContent},
+		// cluster rules allow the verb only in the selected clusters
+		{"erin", http.MethodDelete, "/clusters/testnet/nodes", "", http.StatusNoContent},
+		{"erin", http.MethodDelete, "/clusters/mainnet/nodes", "", http.StatusForbidden},
+		// cluster scoped resources ignore the namespace query parameter
+		{"frank", http.MethodGet, "/storageclasses?namespace=goerli", "", http.StatusForbidden},
+		{"grace", http.MethodGet, "/storageclasses?namespace=goerli", "", http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		req := httptest.NewRequest(testCase.method, testCase.target, strings.NewReader(testCase.body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set("X-User", testCase.user)
+		resp, err := app.Test(req)
+		assert.Nil(t, err)
+		assert.EqualValues(t, testCase.status, resp.StatusCode, "%s %s %s", testCase.user, testCase.method, testCase.target)
+	}
+}
+
+type staticAuthorizer struct {
+	policy *Policy
+}
+
+func (a staticAuthorizer) Authorize(principal *auth.Principal, permission Permission, namespace string) bool {
+	return a.policy.Allows(principal, permission, namespace)
+}
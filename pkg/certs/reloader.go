@@ -0,0 +1,167 @@
+// Package certs serves the api server TLS certificate loaded from PEM files or kubernetes TLS secret
+// the certificate and the client CA bundle are reloaded when they change, so they can be rotated without restarts
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/logger"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+	"time"
+)
+
+// clientAuthTypes maps the configured client certificates modes to their TLS client auth types
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	configs.ClientAuthNone:     tls.NoClientCert,
+	configs.ClientAuthOptional: tls.VerifyClientCertIfGiven,
+	configs.ClientAuthRequire:  tls.RequireAndVerifyClientCert,
+}
+
+// Reloader holds the served certificate and client CA bundle, and reloads them if their source changes
+type Reloader struct {
+	source       Source
+	clientCAFile string
+	clientAuth   tls.ClientAuthType
+
+	lock        sync.RWMutex
+	certPEM     []byte
+	keyPEM      []byte
+	clientCAPEM []byte
+	certificate *tls.Certificate
+	leaf        *x509.Certificate
+	clientCAs   *x509.CertPool
+}
+
+// NewReloader returns reloader serving the certificate of the given source
+// client certificates are verified using the CA bundle file if client auth isn't tls.NoClientCert
+// the certificate is loaded right away, error is returned if it can't be loaded
+func NewReloader(ctx context.Context, source Source, clientCAFile string, clientAuth tls.ClientAuthType) (*Reloader, error) {
+	reloader := &Reloader{
+		source:       source,
+		clientCAFile: clientCAFile,
+		clientAuth:   clientAuth,
+	}
+	if err := reloader.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// ReloaderFromConfig returns reloader of the configured cert and key files or TLS secret read using the given reader
+// returns nil reloader if TLS isn't enabled
+func ReloaderFromConfig(ctx context.Context, config configs.TLSConfig, reader client.Reader) (*Reloader, error) {
+	if !config.Enabled() {
+		return nil, nil
+	}
+
+	var source Source
+	if config.Secret != "" {
+		source = NewSecretSource(reader, k8s.NamespacedNameFromString(config.Secret))
+	} else {
+		source = NewFileSource(config.CertFile, config.KeyFile)
+	}
+
+	return NewReloader(ctx, source, config.ClientCAFile, clientAuthTypes[config.ClientAuth])
+}
+
+// Reload loads the certificate and the client CA bundle, and replaces the served ones if they've changed
+// served certificate isn't replaced if the loaded one is invalid
+func (reloader *Reloader) Reload(ctx context.Context) error {
+	certPEM, keyPEM, err := reloader.source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("can't load certificate from %s: %s", reloader.source, err)
+	}
+
+	var clientCAPEM []byte
+	if reloader.clientAuth != tls.NoClientCert {
+		if clientCAPEM, err = os.ReadFile(reloader.clientCAFile); err != nil {
+			return fmt.Errorf("can't read client CA bundle: %s", err)
+		}
+	}
+
+	reloader.lock.RLock()
+	unchanged := bytes.Equal(certPEM, reloader.certPEM) && bytes.Equal(keyPEM, reloader.keyPEM) && bytes.Equal(clientCAPEM, reloader.clientCAPEM)
+	reloader.lock.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid certificate from %s: %s", reloader.source, err)
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("invalid certificate from %s: %s", reloader.source, err)
+	}
+
+	var clientCAs *x509.CertPool
+	if clientCAPEM != nil {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(clientCAPEM) {
+			return errors.New("client CA bundle has no valid PEM encoded certificate")
+		}
+	}
+
+	reloader.lock.Lock()
+	defer reloader.lock.Unlock()
+	reloader.certPEM = certPEM
+	reloader.keyPEM = keyPEM
+	reloader.clientCAPEM = clientCAPEM
+	reloader.certificate = &certificate
+	reloader.leaf = leaf
+	reloader.clientCAs = clientCAs
+
+	return nil
+}
+
+// Watch reloads the certificate every interval until the context is done
+// reload errors are logged, and the last valid certificate is served
+func (reloader *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := reloader.Reload(ctx); err != nil {
+				go logger.Error("TLS_CERTIFICATE_RELOAD", err)
+			}
+		}
+	}
+}
+
+// Certificate returns the served certificate
+func (reloader *Reloader) Certificate() *x509.Certificate {
+	reloader.lock.RLock()
+	defer reloader.lock.RUnlock()
+	return reloader.leaf
+}
+
+// TLSConfig returns TLS config serving the last loaded certificate and verifying clients with the last loaded CA bundle
+func (reloader *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			reloader.lock.RLock()
+			defer reloader.lock.RUnlock()
+
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*reloader.certificate},
+				ClientAuth:   reloader.clientAuth,
+				ClientCAs:    reloader.clientCAs,
+			}, nil
+		},
+	}
+}
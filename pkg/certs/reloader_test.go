@@ -0,0 +1,223 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/auth"
+	"github.com/kotalco/api/pkg/k8s"
+	"github.com/kotalco/api/pkg/k8s/fake"
+	"github.com/stretchr/testify/assert"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA issues test certificates
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kotal-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, _ := x509.ParseCertificate(der)
+
+	return &testCA{
+		certificate: certificate,
+		key:         key,
+		pem:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns PEM encoded certificate and key of the given subject valid for localhost
+func (ca *testCA) issue(t *testing.T, subject pkix.Name, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(12 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: "api.kotal.local"}, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+
+	reloader, err := NewReloader(context.Background(), NewFileSource(certFile, keyFile), "", tls.NoClientCert)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "api.kotal.local", reloader.Certificate().Subject.CommonName)
+
+	// rotated certificate is served after reload
+	certPEM, keyPEM = ca.issue(t, pkix.Name{CommonName: "api.kotal.io"}, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	assert.Nil(t, reloader.Reload(context.Background()))
+	assert.Equal(t, "api.kotal.io", reloader.Certificate().Subject.CommonName)
+
+	// invalid certificate doesn't replace the served certificate
+	writeFile(t, keyFile, []byte("not a key"))
+	assert.NotNil(t, reloader.Reload(context.Background()))
+	assert.Equal(t, "api.kotal.io", reloader.Certificate().Subject.CommonName)
+
+	_, err = NewReloader(context.Background(), NewFileSource(filepath.Join(dir, "missing.crt"), keyFile), "", tls.NoClientCert)
+	assert.NotNil(t, err)
+}
+
+func TestSecretReload(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: "api.kotal.local"}, x509.ExtKeyUsageServerAuth)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kotal-api-tls", Namespace: "kotal"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}
+	client := fake.NewClientService(secret)
+	key := k8s.ObjectKey{Namespace: "kotal", Name: "kotal-api-tls"}
+
+	reloader, err := NewReloader(context.Background(), NewSecretSource(client, key), "", tls.NoClientCert)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "api.kotal.local", reloader.Certificate().Subject.CommonName)
+
+	certPEM, keyPEM = ca.issue(t, pkix.Name{CommonName: "api.kotal.io"}, x509.ExtKeyUsageServerAuth)
+	secret.Data = map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM}
+	assert.Nil(t, client.Update(context.Background(), secret))
+	assert.Nil(t, reloader.Reload(context.Background()))
+	assert.Equal(t, "api.kotal.io", reloader.Certificate().Subject.CommonName)
+
+	_, err = NewReloader(context.Background(), NewSecretSource(client, k8s.ObjectKey{Namespace: "kotal", Name: "missing"}), "", tls.NoClientCert)
+	assert.NotNil(t, err)
+}
+
+// TestMutualTLS serves app authenticating callers by their client certificates
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile, clientCAFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: "localhost"}, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, certPEM)
+	writeFile(t, keyFile, keyPEM)
+	writeFile(t, clientCAFile, ca.pem)
+
+	reloader, err := NewReloader(context.Background(), NewFileSource(certFile, keyFile), clientCAFile, tls.VerifyClientCertIfGiven)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(auth.Authenticate(auth.NewStaticAPIKeyVerifier(map[string]string{"ci": "ci-key"})))
+	app.Get("/", func(c *fiber.Ctx) error {
+		principal := auth.PrincipalFromCtx(c)
+		return c.JSON(principal)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(tls.NewListener(listener, reloader.TLSConfig()))
+	t.Cleanup(func() { app.Shutdown() })
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	call := func(certificates []tls.Certificate, token string) (int, string) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certificates}}}
+		req, _ := http.NewRequest(http.MethodGet, "https://"+listener.Addr().String(), nil)
+		if token != "" {
+			req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, err.Error()
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	clientCertPEM, clientKeyPEM := ca.issue(t, pkix.Name{CommonName: "ci-bot", Organization: []string{"kotal:bots"}}, x509.ExtKeyUsageClientAuth)
+	clientCertificate, err := tls.X509KeyPair(clientCertPEM, clientKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, body := call([]tls.Certificate{clientCertificate}, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"name":"ci-bot","groups":["kotal:bots"],"method":"client-certificate"}`, body)
+
+	// callers without client certificates use bearer tokens
+	status, _ = call(nil, "ci-key")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = call(nil, "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// client certificates issued by unknown CA are rejected
+	otherCertPEM, otherKeyPEM := newTestCA(t).issue(t, pkix.Name{CommonName: "intruder"}, x509.ExtKeyUsageClientAuth)
+	otherCertificate, _ := tls.X509KeyPair(otherCertPEM, otherKeyPEM)
+	status, _ = call([]tls.Certificate{otherCertificate}, "")
+	assert.Zero(t, status)
+}
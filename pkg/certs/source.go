@@ -0,0 +1,73 @@
+package certs
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/api/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Source loads PEM encoded certificate chain and private key
+type Source interface {
+	Load(ctx context.Context) (certPEM, keyPEM []byte, err error)
+	String() string
+}
+
+// fileSource loads the certificate and key from files, like mounted secrets volumes
+type fileSource struct {
+	certFile string
+	keyFile  string
+}
+
+// NewFileSource returns source loading the certificate and key from the given files
+func NewFileSource(certFile, keyFile string) Source {
+	return fileSource{certFile: certFile, keyFile: keyFile}
+}
+
+// Load reads the certificate and key files
+func (source fileSource) Load(ctx context.Context) ([]byte, []byte, error) {
+	certPEM, err := os.ReadFile(source.certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(source.keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certPEM, keyPEM, nil
+}
+
+func (source fileSource) String() string {
+	return fmt.Sprintf("files %s and %s", source.certFile, source.keyFile)
+}
+
+// secretSource loads the certificate and key from kubernetes.io/tls secret
+type secretSource struct {
+	reader client.Reader
+	key    k8s.ObjectKey
+}
+
+// NewSecretSource returns source loading the certificate and key from the given kubernetes.io/tls secret
+func NewSecretSource(reader client.Reader, key k8s.ObjectKey) Source {
+	return secretSource{reader: reader, key: key}
+}
+
+// Load reads tls.crt and tls.key of the secret
+func (source secretSource) Load(ctx context.Context) ([]byte, []byte, error) {
+	secret := &corev1.Secret{}
+	if err := source.reader.Get(ctx, source.key, secret); err != nil {
+		return nil, nil, err
+	}
+
+	certPEM, keyPEM := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+	if len(certPEM) == 0 || len(keyPEM) == 0 {
+		return nil, nil, fmt.Errorf("secret has no %s or %s", corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+	return certPEM, keyPEM, nil
+}
+
+func (source secretSource) String() string {
+	return fmt.Sprintf("secret %s", source.key)
+}
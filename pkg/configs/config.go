@@ -0,0 +1,550 @@
+package configs
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// envConfigFile is the config file path if -config flag isn't given
+	envConfigFile = "KOTAL_API_CONFIG"
+	// configFlag is the command line flag of the config file path
+	configFlag = "config"
+)
+
+// deprecatedEnvs are the deprecated environment variables by the environment variable replacing them
+// they're read only if the replacing environment variable isn't set
+var deprecatedEnvs = map[string]string{
+	"SIMULATOR": "MOCK",
+}
+
+// Config is the api server configuration
+// every field is loaded from the defaults, config file, environment variable and command line flag in increasing precedence
+// it's served by /api/v1/config, secrets are written as REDACTED
+type Config struct {
+	// Environment is the deployment environment like development or production
+	Environment string `json:"environment" env:"ENVIRONMENT" flag:"environment"`
+	// Simulator serves the k8s calls from in memory cluster instead of the registered clusters
+	Simulator     bool                `json:"simulator" env:"SIMULATOR" flag:"simulator"`
+	Server        ServerConfig        `json:"server"`
+	Log           LogConfig           `json:"log"`
+	CORS          CORSConfig          `json:"cors"`
+	Kubernetes    KubernetesConfig    `json:"kubernetes"`
+	Auth          AuthConfig          `json:"auth"`
+	Authorization AuthorizationConfig `json:"authorization"`
+	Audit         AuditConfig         `json:"audit"`
+	Pagination    PaginationConfig    `json:"pagination"`
+	Stats         StatsConfig         `json:"stats"`
+	TLS           TLSConfig           `json:"tls"`
+}
+
+// ServerConfig is the api and metrics http servers configuration
+type ServerConfig struct {
+	// Port is the api server address like :5000
+	Port string `json:"port" env:"KOTAL_API_SERVER_PORT" flag:"port"`
+	// MetricsPort is the metrics server address like :9090
+	MetricsPort string `json:"metricsPort" env:"KOTAL_API_METRICS_PORT" flag:"metrics-port"`
+	// ReadTimeout is the max duration for reading the request, zero means no timeout
+	ReadTimeout Duration `json:"readTimeout" env:"SERVER_READ_TIMEOUT" flag:"read-timeout"`
+	// WriteTimeout is the max duration before timing out writing the response, zero means no timeout
+	WriteTimeout Duration `json:"writeTimeout" env:"SERVER_WRITE_TIMEOUT" flag:"write-timeout"`
+	// IdleTimeout is the max duration to wait for the next request on keep-alive connections, zero means read timeout
+	IdleTimeout Duration `json:"idleTimeout" env:"SERVER_IDLE_TIMEOUT" flag:"idle-timeout"`
+	// DrainTimeout is the max duration to wait for websockets, watches and in-flight calls on shutdown
+	// it should be less than the pod termination grace period, so the server exits before it's killed
+	DrainTimeout Duration `json:"drainTimeout" env:"SERVER_DRAIN_TIMEOUT" flag:"drain-timeout"`
+}
+
+// LogConfig is the api server logger configuration
+type LogConfig struct {
+	// Level is debug, info or error
+	Level string `json:"level" env:"LOG_LEVEL" flag:"log-level"`
+	// Output is stdout, stderr or file path
+	Output string `json:"output" env:"LOG_OUTPUT" flag:"log-output"`
+}
+
+// CORSConfig is the cross-origin resource sharing configuration
+type CORSConfig struct {
+	// AllowOrigins are the origins allowed to call the api, * allows all origins
+	AllowOrigins []string `json:"allowOrigins" env:"CORS_ALLOW_ORIGINS" flag:"cors-allow-origins"`
+}
+
+// KubernetesConfig selects the kubeconfig of the default cluster if the api server isn't running in k8s cluster
+type KubernetesConfig struct {
+	// Kubeconfig is the kubeconfig file path or list of paths, kubectl default is used if it's empty
+	Kubeconfig string `json:"kubeconfig" env:"KUBECONFIG" flag:"kubeconfig"`
+	// Context is the kubeconfig context of the default cluster, current context is used if it's empty
+	Context string `json:"context" env:"KUBE_CONTEXT" flag:"kube-context"`
+	// GetTimeout is the deadline of getting single resource, zero means no deadline
+	GetTimeout Duration `json:"getTimeout" env:"KUBE_GET_TIMEOUT" flag:"kube-get-timeout"`
+	// ListTimeout is the deadline of listing resources, zero means no deadline
+	ListTimeout Duration `json:"listTimeout" env:"KUBE_LIST_TIMEOUT" flag:"kube-list-timeout"`
+	// WriteTimeout is the deadline of creating, updating, patching and deleting resources, zero means no deadline
+	WriteTimeout Duration `json:"writeTimeout" env:"KUBE_WRITE_TIMEOUT" flag:"kube-write-timeout"`
+	// ClusterName is the name of the default cluster
+	ClusterName string `json:"clusterName" env:"KUBE_CLUSTER_NAME" flag:"kube-cluster-name"`
+	// Contexts are the kubeconfig contexts registered as additional clusters, * registers all of them
+	Contexts []string `json:"contexts" env:"KUBE_CONTEXTS" flag:"kube-contexts"`
+	// ClustersSecret is secret in the form of namespace/name holding additional clusters kubeconfigs by cluster name
+	ClustersSecret string `json:"clustersSecret" env:"KUBE_CLUSTERS_SECRET" flag:"kube-clusters-secret"`
+	// Impersonation makes the k8s calls as the authenticated caller instead of the api service account
+	Impersonation bool `json:"impersonation" env:"KUBE_IMPERSONATION" flag:"kube-impersonation"`
+	// OperatorDeployment is kotal operator deployment in the form of namespace/name
+	OperatorDeployment string `json:"operatorDeployment" env:"KOTAL_OPERATOR_DEPLOYMENT" flag:"operator-deployment"`
+}
+
+// AuthConfig enables the callers authentication methods, calls aren't authenticated if none is enabled
+type AuthConfig struct {
+	// APIKeysSecret is secret in the form of namespace/name holding the api keys
+	APIKeysSecret string `json:"apiKeysSecret" env:"AUTH_API_KEYS_SECRET" flag:"auth-api-keys-secret"`
+	// JWTHMACSecret is the HS256 json web tokens signing secret
+	JWTHMACSecret Secret `json:"jwtHMACSecret" env:"AUTH_JWT_HMAC_SECRET" flag:"auth-jwt-hmac-secret"`
+	// JWTJWKSFile is the JSON web key set file path of RS256 json web tokens public keys
+	JWTJWKSFile string `json:"jwtJWKSFile" env:"AUTH_JWT_JWKS_FILE" flag:"auth-jwt-jwks-file"`
+	// JWTIssuer and JWTAudience are the required json web tokens iss and aud claims if they're set
+	JWTIssuer   string `json:"jwtIssuer" env:"AUTH_JWT_ISSUER" flag:"auth-jwt-issuer"`
+	JWTAudience string `json:"jwtAudience" env:"AUTH_JWT_AUDIENCE" flag:"auth-jwt-audience"`
+	// JWTUsernameClaim and JWTGroupsClaim are the json web tokens claims of the caller name and groups
+	JWTUsernameClaim string `json:"jwtUsernameClaim" env:"AUTH_JWT_USERNAME_CLAIM" flag:"auth-jwt-username-claim"`
+	JWTGroupsClaim   string `json:"jwtGroupsClaim" env:"AUTH_JWT_GROUPS_CLAIM" flag:"auth-jwt-groups-claim"`
+	// TokenReview verifies kubernetes tokens using TokenReview api of the default cluster
+	TokenReview bool `json:"tokenReview" env:"AUTH_TOKEN_REVIEW" flag:"auth-token-review"`
+	// TokenReviewAudiences are the audiences the reviewed tokens must be issued for
+	TokenReviewAudiences []string `json:"tokenReviewAudiences" env:"AUTH_TOKEN_REVIEW_AUDIENCES" flag:"auth-token-review-audiences"`
+}
+
+// AuthorizationConfig loads the authorization policy, calls aren't authorized if no policy is set
+type AuthorizationConfig struct {
+	// PolicyFile is the yaml policy file path
+	PolicyFile string `json:"policyFile" env:"AUTHORIZATION_POLICY_FILE" flag:"authorization-policy-file"`
+	// PolicyConfigMap is configmap in the form of namespace/name holding the policy, it's ignored if policy file is set
+	PolicyConfigMap string `json:"policyConfigMap" env:"AUTHORIZATION_POLICY_CONFIGMAP" flag:"authorization-policy-configmap"`
+	// PolicyReloadInterval is the interval of reloading the policy
+	PolicyReloadInterval Duration `json:"policyReloadInterval" env:"AUTHORIZATION_POLICY_RELOAD_INTERVAL" flag:"authorization-policy-reload-interval"`
+}
+
+// AuditConfig is the audit log sinks configuration
+type AuditConfig struct {
+	// File is the rotating json lines file path serving the audit queries, the last entries are kept in memory if it's empty
+	File string `json:"file" env:"AUDIT_LOG_FILE" flag:"audit-log-file"`
+	// MaxSize is the max file size in megabytes before it's rotated
+	MaxSize uint `json:"maxSize" env:"AUDIT_LOG_MAX_SIZE" flag:"audit-log-max-size"`
+	// MaxBackups is the max number of rotated files to keep
+	MaxBackups uint `json:"maxBackups" env:"AUDIT_LOG_MAX_BACKUPS" flag:"audit-log-max-backups"`
+	// Events creates k8s events on the audited resources
+	Events bool `json:"events" env:"AUDIT_EVENTS" flag:"audit-events"`
+}
+
+// PaginationConfig is the list calls page sizes
+type PaginationConfig struct {
+	// DefaultLimit is the page size if limit query parameter isn't given
+	DefaultLimit uint `json:"defaultLimit" env:"PAGE_SIZE" flag:"page-size"`
+	// MaxLimit is the max page size which can be requested using limit query parameter
+	MaxLimit uint `json:"maxLimit" env:"MAX_PAGE_SIZE" flag:"max-page-size"`
+}
+
+// StatsConfig is the polling intervals of the websockets streaming nodes status and stats
+type StatsConfig struct {
+	// StatusInterval is the interval of polling node pod status
+	StatusInterval Duration `json:"statusInterval" env:"STATUS_INTERVAL" flag:"status-interval"`
+	// StatsInterval is the interval of polling node JSON-RPC server for its stats
+	StatsInterval Duration `json:"statsInterval" env:"STATS_INTERVAL" flag:"stats-interval"`
+	// RPCTimeout is the deadline of every node JSON-RPC call, zero means no deadline
+	RPCTimeout Duration `json:"rpcTimeout" env:"RPC_TIMEOUT" flag:"rpc-timeout"`
+}
+
+// TLS client certificates modes
+const (
+	// ClientAuthNone ignores client certificates
+	ClientAuthNone = "none"
+	// ClientAuthOptional verifies client certificates if they're given
+	ClientAuthOptional = "optional"
+	// ClientAuthRequire rejects connections without valid client certificate
+	ClientAuthRequire = "require"
+)
+
+// TLSConfig is the api server TLS configuration, api is served over plain http if it's not enabled
+// certificate is loaded from the cert and key files or from kubernetes TLS secret, and reloaded when it changes
+type TLSConfig struct {
+	// CertFile is the PEM encoded certificate file path
+	CertFile string `json:"certFile" env:"TLS_CERT_FILE" flag:"tls-cert-file"`
+	// KeyFile is the PEM encoded private key file path
+	KeyFile string `json:"keyFile" env:"TLS_KEY_FILE" flag:"tls-key-file"`
+	// Secret is kubernetes.io/tls secret in the form of namespace/name in the default cluster
+	Secret string `json:"secret" env:"TLS_SECRET" flag:"tls-secret"`
+	// ClientAuth is none, optional or require client certificates
+	ClientAuth string `json:"clientAuth" env:"TLS_CLIENT_AUTH" flag:"tls-client-auth"`
+	// ClientCAFile is the PEM encoded CA bundle file path verifying client certificates
+	ClientCAFile string `json:"clientCAFile" env:"TLS_CLIENT_CA_FILE" flag:"tls-client-ca-file"`
+	// ReloadInterval is the interval of checking the certificate and client CA bundle for changes
+	ReloadInterval Duration `json:"reloadInterval" env:"TLS_RELOAD_INTERVAL" flag:"tls-reload-interval"`
+}
+
+// Enabled returns true if the api is served over TLS
+func (config TLSConfig) Enabled() bool {
+	return config.CertFile != "" || config.Secret != ""
+}
+
+// Defaults returns the default configuration
+func Defaults() *Config {
+	return &Config{
+		Environment: "development",
+		Server: ServerConfig{
+			Port:         ":5000",
+			MetricsPort:  ":9090",
+			ReadTimeout:  NewDuration(60),
+			DrainTimeout: NewDuration(25),
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Output: "stdout",
+		},
+		CORS: CORSConfig{
+			AllowOrigins: []string{"*"},
+		},
+		Kubernetes: KubernetesConfig{
+			GetTimeout:         NewDuration(10),
+			ListTimeout:        NewDuration(30),
+			WriteTimeout:       NewDuration(30),
+			ClusterName:        "default",
+			OperatorDeployment: "kotal/kotal-controller-manager",
+		},
+		Authorization: AuthorizationConfig{
+			PolicyReloadInterval: NewDuration(30),
+		},
+		Audit: AuditConfig{
+			MaxSize:    100,
+			MaxBackups: 5,
+			Events:     true,
+		},
+		Pagination: PaginationConfig{
+			DefaultLimit: 10,
+			MaxLimit:     100,
+		},
+		Stats: StatsConfig{
+			StatusInterval: NewDuration(1),
+			StatsInterval:  NewDuration(1),
+			RPCTimeout:     NewDuration(5),
+		},
+		TLS: TLSConfig{
+			ClientAuth:     ClientAuthNone,
+			ReloadInterval: NewDuration(30),
+		},
+	}
+}
+
+// current is the effective configuration, it's the defaults until Load is called
+var current = Defaults()
+
+// Get returns the effective configuration
+func Get() *Config {
+	return current
+}
+
+// Load loads the configuration and makes it the effective configuration returned by Get
+// 1-parse the command line flags, -config or KOTAL_API_CONFIG is the yaml, json or toml config file path
+// 2-start from the defaults and override them by the config file, unknown fields are rejected
+// toml files are read by their .toml extension, other files are read as yaml which json is a subset of
+// 3-override by the set environment variables
+// 4-override by the given command line flags
+// 5-validate the configuration, the effective configuration isn't changed if it's not valid
+// flag.ErrHelp is returned if -h or -help flag is given
+func Load(args []string) (*Config, error) {
+	flagSet := flag.NewFlagSet("kotal-api", flag.ContinueOnError)
+	configFile := flagSet.String(configFlag, os.Getenv(envConfigFile), fmt.Sprintf("yaml, json or toml config file path (env %s)", envConfigFile))
+
+	// flags are applied after the config file and environment variables
+	type flagValue struct{ name, value string }
+	flagValues := []flagValue{}
+	for _, field := range fields(Defaults()) {
+		name := field.flag
+		usage := fmt.Sprintf("%s (env %s, default %v)", field.path, field.env, field.value.Interface())
+		flagSet.Var(flagFunc{
+			boolean: field.value.Kind() == reflect.Bool,
+			set: func(value string) error {
+				flagValues = append(flagValues, flagValue{name, value})
+				return nil
+			},
+		}, name, usage)
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, err
+	}
+
+	config := Defaults()
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read config file: %s", err)
+		}
+		if strings.EqualFold(filepath.Ext(*configFile), ".toml") {
+			if data, err = tomlToJSON(data); err != nil {
+				return nil, fmt.Errorf("can't parse config file %s: %s", *configFile, err)
+			}
+		}
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("can't parse config file %s: %s", *configFile, err)
+		}
+	}
+
+	configFields := map[string]field{}
+	for _, field := range fields(config) {
+		configFields[field.flag] = field
+		value, ok := os.LookupEnv(field.env)
+		if deprecated, found := deprecatedEnvs[field.env]; found && (!ok || value == "") {
+			value, ok = os.LookupEnv(deprecated)
+		}
+		if !ok || value == "" {
+			continue
+		}
+		if err := field.set(value); err != nil {
+			return nil, fmt.Errorf("invalid %s environment variable %q: %s", field.env, value, err)
+		}
+	}
+
+	for _, flagValue := range flagValues {
+		if err := configFields[flagValue.name].set(flagValue.value); err != nil {
+			return nil, fmt.Errorf("invalid -%s flag %q: %s", flagValue.name, flagValue.value, err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	current = config
+	return config, nil
+}
+
+// tomlToJSON converts toml config file to json, so it's decoded like yaml and json files using the json tags
+func tomlToJSON(data []byte) ([]byte, error) {
+	values := map[string]interface{}{}
+	if _, err := toml.Decode(string(data), &values); err != nil {
+		return nil, err
+	}
+	return json.Marshal(values)
+}
+
+// Validate returns error listing all the invalid configuration values
+func (config *Config) Validate() error {
+	problems := []string{}
+	invalid := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	for path, address := range map[string]string{"server.port": config.Server.Port, "server.metricsPort": config.Server.MetricsPort} {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			invalid("%s %q must be address like :5000", path, address)
+		}
+	}
+	if config.Server.Port == config.Server.MetricsPort {
+		invalid("server.port and server.metricsPort must be different")
+	}
+	for path, duration := range map[string]Duration{
+		"server.readTimeout":      config.Server.ReadTimeout,
+		"server.writeTimeout":     config.Server.WriteTimeout,
+		"server.idleTimeout":      config.Server.IdleTimeout,
+		"server.drainTimeout":     config.Server.DrainTimeout,
+		"kubernetes.getTimeout":   config.Kubernetes.GetTimeout,
+		"kubernetes.listTimeout":  config.Kubernetes.ListTimeout,
+		"kubernetes.writeTimeout": config.Kubernetes.WriteTimeout,
+		"stats.rpcTimeout":        config.Stats.RPCTimeout,
+	} {
+		if duration.Duration < 0 {
+			invalid("%s can't be negative", path)
+		}
+	}
+
+	switch config.Log.Level {
+	case "debug", "info", "error":
+	default:
+		invalid("log.level %q must be debug, info or error", config.Log.Level)
+	}
+	if config.Log.Output == "" {
+		invalid("log.output is required")
+	}
+
+	if len(config.CORS.AllowOrigins) == 0 {
+		invalid("cors.allowOrigins is required, use * to allow all origins")
+	}
+	for _, origin := range config.CORS.AllowOrigins {
+		if origin == "*" {
+			continue
+		}
+		if parsed, err := url.Parse(origin); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			invalid("cors.allowOrigins %q must be origin like https://app.kotal.co", origin)
+		}
+	}
+
+	if config.Kubernetes.ClusterName == "" {
+		invalid("kubernetes.clusterName is required")
+	}
+	if config.Kubernetes.OperatorDeployment == "" {
+		invalid("kubernetes.operatorDeployment is required")
+	}
+	for path, name := range map[string]string{
+		"kubernetes.clustersSecret":     config.Kubernetes.ClustersSecret,
+		"kubernetes.operatorDeployment": config.Kubernetes.OperatorDeployment,
+		"auth.apiKeysSecret":            config.Auth.APIKeysSecret,
+		"authorization.policyConfigMap": config.Authorization.PolicyConfigMap,
+	} {
+		if name != "" && strings.Count(name, "/") != 1 {
+			invalid("%s %q must be in the form of namespace/name", path, name)
+		}
+	}
+	if config.Authorization.PolicyReloadInterval.Duration <= 0 {
+		invalid("authorization.policyReloadInterval must be positive")
+	}
+	if config.Audit.MaxSize == 0 {
+		invalid("audit.maxSize must be positive")
+	}
+
+	if config.Pagination.DefaultLimit == 0 || config.Pagination.DefaultLimit > config.Pagination.MaxLimit {
+		invalid("pagination.defaultLimit must be between 1 and pagination.maxLimit")
+	}
+
+	if config.Stats.StatusInterval.Duration <= 0 {
+		invalid("stats.statusInterval must be positive")
+	}
+	if config.Stats.StatsInterval.Duration <= 0 {
+		invalid("stats.statsInterval must be positive")
+	}
+
+	if (config.TLS.CertFile == "") != (config.TLS.KeyFile == "") {
+		invalid("tls.certFile and tls.keyFile must be set together")
+	}
+	if config.TLS.CertFile != "" && config.TLS.Secret != "" {
+		invalid("tls.secret can't be set with tls.certFile and tls.keyFile")
+	}
+	if config.TLS.Secret != "" && strings.Count(config.TLS.Secret, "/") != 1 {
+		invalid("tls.secret %q must be in the form of namespace/name", config.TLS.Secret)
+	}
+	switch config.TLS.ClientAuth {
+	case ClientAuthNone:
+	case ClientAuthOptional, ClientAuthRequire:
+		if !config.TLS.Enabled() || config.TLS.ClientCAFile == "" {
+			invalid("tls.clientAuth %s requires TLS certificate and tls.clientCAFile", config.TLS.ClientAuth)
+		}
+	default:
+		invalid("tls.clientAuth %q must be none, optional or require", config.TLS.ClientAuth)
+	}
+	if config.TLS.ReloadInterval.Duration <= 0 {
+		invalid("tls.reloadInterval must be positive")
+	}
+	for path, file := range map[string]string{"tls.certFile": config.TLS.CertFile, "tls.keyFile": config.TLS.KeyFile, "tls.clientCAFile": config.TLS.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			invalid("%s %s", path, err)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	// maps iteration order is random
+	sort.Strings(problems)
+	return fmt.Errorf("invalid config: %s", strings.Join(problems, ", "))
+}
+
+// flagFunc is command line flag calling the function with the flag value
+// boolean flags can be given without value like -simulator
+type flagFunc struct {
+	boolean bool
+	set     func(value string) error
+}
+
+func (flag flagFunc) String() string { return "" }
+
+func (flag flagFunc) Set(value string) error { return flag.set(value) }
+
+func (flag flagFunc) IsBoolFlag() bool { return flag.boolean }
+
+// field is configuration field which can be set by environment variable and command line flag
+type field struct {
+	// path is the field path in the config file like server.port
+	path string
+	env  string
+	flag string
+	// value is the addressable field value
+	value reflect.Value
+}
+
+// fields returns the config fields having env and flag tags
+func fields(config *Config) []field {
+	return structFields(reflect.ValueOf(config).Elem(), "")
+}
+
+// structFields returns the fields of the struct value and its nested structs
+func structFields(value reflect.Value, prefix string) []field {
+	result := []field{}
+	for i := 0; i < value.NumField(); i++ {
+		structField := value.Type().Field(i)
+		path := prefix + strings.Split(structField.Tag.Get("json"), ",")[0]
+
+		if structField.Type.Kind() == reflect.Struct && structField.Type != reflect.TypeOf(Duration{}) {
+			result = append(result, structFields(value.Field(i), path+".")...)
+			continue
+		}
+		if structField.Tag.Get("flag") == "" {
+			continue
+		}
+
+		result = append(result, field{
+			path:  path,
+			env:   structField.Tag.Get("env"),
+			flag:  structField.Tag.Get("flag"),
+			value: value.Field(i),
+		})
+	}
+	return result
+}
+
+// set parses the environment variable or flag value and sets the field to it
+// lists are comma separated
+func (field field) set(value string) error {
+	switch target := field.value.Addr().Interface().(type) {
+	case *string:
+		*target = value
+	case *Secret:
+		*target = Secret(value)
+	case *bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		*target = parsed
+	case *uint:
+		parsed, err := strconv.ParseUint(value, 10, 0)
+		if err != nil {
+			return fmt.Errorf("must be positive integer")
+		}
+		*target = uint(parsed)
+	case *[]string:
+		*target = []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*target = append(*target, item)
+			}
+		}
+	case *Duration:
+		return target.Set(value)
+	default:
+		return fmt.Errorf("unsupported config field type %T", target)
+	}
+	return nil
+}
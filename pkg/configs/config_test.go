@@ -0,0 +1,282 @@
+package configs
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes the config file to temp dir and returns its path
+func writeConfigFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// isolate unsets the config environment variables and restores the effective configuration changed by Load
+func isolate(t *testing.T) {
+	t.Setenv(envConfigFile, "")
+	for _, field := range fields(Defaults()) {
+		t.Setenv(field.env, "")
+	}
+	for _, deprecated := range deprecatedEnvs {
+		t.Setenv(deprecated, "")
+	}
+
+	config := current
+	t.Cleanup(func() { current = config })
+}
+
+func TestLoadDefaults(t *testing.T) {
+	isolate(t)
+
+	config, err := Load(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Defaults(), config)
+	assert.Equal(t, config, Get())
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	isolate(t)
+
+	path := writeConfigFile(t, `
+server:
+  port: ":6000"
+  readTimeout: 30s
+  writeTimeout: 15
+cors:
+  allowOrigins: ["https://app.kotal.co"]
+pagination:
+  defaultLimit: 20
+log:
+  level: debug
+`)
+	t.Setenv(envConfigFile, path)
+	// environment variables override the config file
+	t.Setenv("SERVER_READ_TIMEOUT", "45")
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://app.kotal.co, https://staging.kotal.co")
+	t.Setenv("PAGE_SIZE", "25")
+
+	// flags override the environment variables
+	config, err := Load([]string{"-page-size", "50", "--kube-context=mainnet"})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, ":6000", config.Server.Port)
+	assert.Equal(t, ":9090", config.Server.MetricsPort)
+	assert.Equal(t, 45*time.Second, config.Server.ReadTimeout.Duration)
+	assert.Equal(t, 15*time.Second, config.Server.WriteTimeout.Duration)
+	assert.Equal(t, []string{"https://app.kotal.co", "https://staging.kotal.co"}, config.CORS.AllowOrigins)
+	assert.Equal(t, uint(50), config.Pagination.DefaultLimit)
+	assert.Equal(t, uint(100), config.Pagination.MaxLimit)
+	assert.Equal(t, "debug", config.Log.Level)
+	assert.Equal(t, "mainnet", config.Kubernetes.Context)
+	assert.Equal(t, config, Get())
+}
+
+func TestLoadTOML(t *testing.T) {
+	isolate(t)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[server]
+port = ":6000"
+readTimeout = "30s"
+writeTimeout = 15
+
+[cors]
+allowOrigins = ["https://app.kotal.co"]
+
+[kubernetes]
+contexts = ["*"]
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envConfigFile, path)
+
+	config, err := Load(nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, ":6000", config.Server.Port)
+	assert.Equal(t, 30*time.Second, config.Server.ReadTimeout.Duration)
+	assert.Equal(t, 15*time.Second, config.Server.WriteTimeout.Duration)
+	assert.Equal(t, []string{"https://app.kotal.co"}, config.CORS.AllowOrigins)
+	assert.Equal(t, []string{"*"}, config.Kubernetes.Contexts)
+
+	// unknown fields are rejected like in yaml files
+	if err := os.WriteFile(path, []byte("[server]\nprot = \":6000\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Load(nil)
+	assert.NotNil(t, err)
+}
+
+func TestLoadSettings(t *testing.T) {
+	isolate(t)
+
+	path := writeConfigFile(t, `
+auth:
+  jwtHMACSecret: s3cr3t
+  tokenReview: true
+authorization:
+  policyConfigMap: kotal/kotal-api-policy
+audit:
+  events: false
+`)
+	t.Setenv(envConfigFile, path)
+	t.Setenv("AUTH_TOKEN_REVIEW_AUDIENCES", "kotal-api,kubernetes")
+	t.Setenv("AUDIT_LOG_MAX_BACKUPS", "0")
+	// deprecated environment variable is read if the replacing one isn't set
+	t.Setenv("MOCK", "true")
+
+	config, err := Load([]string{"-kube-impersonation", "-kube-contexts", "*", "-authorization-policy-reload-interval", "1m"})
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	assert.Equal(t, "s3cr3t", config.Auth.JWTHMACSecret.Value())
+	assert.True(t, config.Auth.TokenReview)
+	assert.Equal(t, []string{"kotal-api", "kubernetes"}, config.Auth.TokenReviewAudiences)
+	assert.Equal(t, "kotal/kotal-api-policy", config.Authorization.PolicyConfigMap)
+	assert.Equal(t, time.Minute, config.Authorization.PolicyReloadInterval.Duration)
+	assert.False(t, config.Audit.Events)
+	assert.Equal(t, uint(100), config.Audit.MaxSize)
+	assert.Equal(t, uint(0), config.Audit.MaxBackups)
+	assert.True(t, config.Kubernetes.Impersonation)
+	assert.Equal(t, []string{"*"}, config.Kubernetes.Contexts)
+	assert.Equal(t, "default", config.Kubernetes.ClusterName)
+	assert.True(t, config.Simulator)
+
+	// replacing environment variable takes precedence over the deprecated one
+	t.Setenv("SIMULATOR", "false")
+	config, err = Load(nil)
+	if assert.Nil(t, err) {
+		assert.False(t, config.Simulator)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	isolate(t)
+
+	testCases := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		err  string
+	}{
+		{
+			name: "unknown config file field",
+			file: "server:\n  prot: \":6000\"\n",
+			err:  `unknown field "prot"`,
+		},
+		{
+			name: "invalid environment variable",
+			env:  map[string]string{"MAX_PAGE_SIZE": "-1"},
+			err:  "invalid MAX_PAGE_SIZE environment variable",
+		},
+		{
+			name: "invalid flag",
+			args: []string{"-read-timeout", "soon"},
+			err:  "invalid -read-timeout flag",
+		},
+		{
+			name: "negative drain timeout",
+			env:  map[string]string{"SERVER_DRAIN_TIMEOUT": "-5s"},
+			err:  "invalid config: server.drainTimeout can't be negative",
+		},
+		{
+			name: "negative rpc timeout",
+			args: []string{"-rpc-timeout", "-1s"},
+			err:  "invalid config: stats.rpcTimeout can't be negative",
+		},
+		{
+			name: "invalid values",
+			args: []string{"-port", "5000", "-log-level", "trace", "-page-size", "200", "-cors-allow-origins", "kotal.co", "-tls-cert-file", "tls.crt"},
+			err:  "invalid config: cors.allowOrigins \"kotal.co\" must be origin like https://app.kotal.co, log.level \"trace\" must be debug, info or error, pagination.defaultLimit must be between 1 and pagination.maxLimit, server.port \"5000\" must be address like :5000, tls.certFile and tls.keyFile must be set together, tls.certFile stat tls.crt: no such file or directory",
+		},
+		{
+			name: "invalid boolean",
+			env:  map[string]string{"AUTH_TOKEN_REVIEW": "yes"},
+			err:  "invalid AUTH_TOKEN_REVIEW environment variable \"yes\": must be true or false",
+		},
+		{
+			name: "invalid settings",
+			args: []string{"-auth-api-keys-secret", "api-keys", "-authorization-policy-reload-interval", "0", "-kube-cluster-name", "", "-audit-log-max-size", "0"},
+			err:  "invalid config: audit.maxSize must be positive, auth.apiKeysSecret \"api-keys\" must be in the form of namespace/name, authorization.policyReloadInterval must be positive, kubernetes.clusterName is required",
+		},
+		{
+			name: "client certificates without client CA",
+			args: []string{"-tls-secret", "kotal/kotal-api-tls", "-tls-client-auth", "require"},
+			err:  "invalid config: tls.clientAuth require requires TLS certificate and tls.clientCAFile",
+		},
+		{
+			name: "invalid TLS secret",
+			args: []string{"-tls-secret", "kotal-api-tls", "-tls-client-auth", "always"},
+			err:  "invalid config: tls.clientAuth \"always\" must be none, optional or require, tls.secret \"kotal-api-tls\" must be in the form of namespace/name",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if testCase.file != "" {
+				t.Setenv(envConfigFile, writeConfigFile(t, testCase.file))
+			}
+			for name, value := range testCase.env {
+				t.Setenv(name, value)
+			}
+
+			_, err := Load(testCase.args)
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), testCase.err)
+			}
+			// the effective configuration isn't changed by invalid configuration
+			assert.Equal(t, Defaults(), Get())
+		})
+	}
+
+	_, err := Load([]string{"-h"})
+	assert.True(t, errors.Is(err, flag.ErrHelp))
+}
+
+func TestDuration(t *testing.T) {
+	duration := Duration{}
+	assert.Nil(t, duration.Set("90"))
+	assert.Equal(t, 90*time.Second, duration.Duration)
+	assert.Nil(t, duration.Set("1m30s"))
+	assert.Equal(t, 90*time.Second, duration.Duration)
+	assert.NotNil(t, duration.Set("soon"))
+
+	data, err := duration.MarshalJSON()
+	assert.Nil(t, err)
+	assert.Equal(t, `"1m30s"`, string(data))
+
+	assert.Nil(t, duration.UnmarshalJSON([]byte("2.5")))
+	assert.Equal(t, 2500*time.Millisecond, duration.Duration)
+	assert.NotNil(t, duration.UnmarshalJSON([]byte("true")))
+}
+
+func TestSecret(t *testing.T) {
+	data, err := json.Marshal(AuthConfig{JWTHMACSecret: "s3cr3t"})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"jwtHMACSecret":"REDACTED"`)
+	assert.NotContains(t, string(data), "s3cr3t")
+	assert.Equal(t, "REDACTED", fmt.Sprint(Secret("s3cr3t")))
+
+	// unset secret is written as empty string
+	data, err = json.Marshal(AuthConfig{})
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"jwtHMACSecret":""`)
+}
@@ -0,0 +1,56 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration is config duration written as Go duration like 30s or 1m, or as number of seconds
+type Duration struct {
+	time.Duration
+}
+
+// NewDuration returns duration of the given number of seconds
+func NewDuration(seconds int) Duration {
+	return Duration{time.Duration(seconds) * time.Second}
+}
+
+// Set parses duration like 30s or number of seconds like 30
+func (duration *Duration) Set(value string) error {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		*duration = NewDuration(seconds)
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("must be duration like 30s or number of seconds")
+	}
+	duration.Duration = parsed
+	return nil
+}
+
+// MarshalJSON writes the duration as Go duration like 1m0s
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(duration.String())
+}
+
+// UnmarshalJSON reads Go duration like 30s or number of seconds
+func (duration *Duration) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch value := value.(type) {
+	case string:
+		return duration.Set(value)
+	case float64:
+		duration.Duration = time.Duration(value * float64(time.Second))
+		return nil
+	default:
+		return fmt.Errorf("duration must be like 30s or number of seconds")
+	}
+}
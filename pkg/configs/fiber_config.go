@@ -1,28 +1,60 @@
 package configs
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	restErrors "github.com/kotalco/api/pkg/errors"
 	"github.com/kotalco/api/pkg/logger"
-	"strconv"
-	"time"
 )
 
+// FiberConfig returns fiber config using the server timeouts of the effective configuration
 func FiberConfig() fiber.Config {
-	readTimeoutSecondsCount, _ := strconv.Atoi(EnvironmentConf["SERVER_READ_TIMEOUT"])
+	server := Get().Server
 	return fiber.Config{
-		ReadTimeout:  time.Second * time.Duration(readTimeoutSecondsCount),
+		ReadTimeout:  server.ReadTimeout.Duration,
+		WriteTimeout: server.WriteTimeout.Duration,
+		IdleTimeout:  server.IdleTimeout.Duration,
 		ErrorHandler: defaultErrorHandler,
 	}
 }
 
-//defaultErrorHandler used to catch all unhandled  run time errors mainly panics
-//logs errors using logger pkg
+// panicError is handler panic recovered by Recover middleware
+type panicError struct {
+	value interface{}
+}
+
+func (err panicError) Error() string {
+	return fmt.Sprint(err.value)
+}
+
+// Recover middleware recovers the handlers panics and passes them to the error handler
+func Recover(c *fiber.Ctx) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = panicError{value: value}
+		}
+	}()
+	return c.Next()
+}
+
+//defaultErrorHandler used to catch all unhandled errors
+//fiber errors like 404 of unknown routes or 413 of large bodies are sent with their status and matching code
+//panics and other unexpected errors are logged using logger pkg and sent as internal errors
 //return custom error struct using restError pkg
-var defaultErrorHandler = func(c *fiber.Ctx, err error) error {
-	go logger.Panic("PANICKING", err)
+func defaultErrorHandler(c *fiber.Ctx, err error) error {
+	fiberErr := &fiber.Error{}
+	if errors.As(err, &fiberErr) {
+		return restErrors.Send(c, restErrors.NewStatusError(fiberErr.Code, fiberErr.Error()))
+	}
+
+	if errors.As(err, &panicError{}) {
+		go logger.Panic("PANICKING", err)
+	} else {
+		go logger.Error(defaultErrorHandler, err)
+	}
 
 	internalErr := restErrors.NewInternalServerError("some thing went wrong...")
 
-	return c.Status(internalErr.Status).JSON(internalErr)
+	return restErrors.Send(c, internalErr)
 }
@@ -0,0 +1,51 @@
+package configs
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(FiberConfig())
+	app.Use(Recover)
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("nil map")
+	})
+	app.Get("/failed", func(c *fiber.Ctx) error {
+		return errors.New("can't write response")
+	})
+	app.Post("/large", func(c *fiber.Ctx) error {
+		return fiber.ErrRequestEntityTooLarge
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fiber.ErrNotFound
+	})
+
+	for _, test := range []struct {
+		method, path string
+		status       int
+		code         restErrors.Code
+	}{
+		{http.MethodGet, "/missing", http.StatusNotFound, restErrors.CodeNotFound},
+		{http.MethodPut, "/panic", http.StatusMethodNotAllowed, restErrors.CodeMethodNotAllowed},
+		{http.MethodPost, "/large", http.StatusRequestEntityTooLarge, restErrors.CodeBadRequest},
+		{http.MethodGet, "/failed", http.StatusInternalServerError, restErrors.CodeInternal},
+		{http.MethodGet, "/panic", http.StatusInternalServerError, restErrors.CodeInternal},
+	} {
+		resp, err := app.Test(httptest.NewRequest(test.method, test.path, nil))
+		if !assert.Nil(t, err) {
+			continue
+		}
+		restErr := restErrors.RestErr{}
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(&restErr))
+		assert.Equal(t, test.status, resp.StatusCode, test.path)
+		assert.Equal(t, test.status, restErr.Status, test.path)
+		assert.Equal(t, test.code, restErr.Code, test.path)
+	}
+}
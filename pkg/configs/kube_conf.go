@@ -1,38 +1,82 @@
 package configs
 
 import (
-	"go/build"
+	"fmt"
+	"github.com/kotalco/api/pkg/logger"
 	"log"
 	"os"
 	"path/filepath"
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
-	"sigs.k8s.io/controller-runtime/pkg/envtest"
 )
 
+// AllKubeContexts selects all the contexts of the kubeconfig
+const AllKubeContexts = "*"
+
 // KubeConfig returns REST config based on the environment
 func KubeConfig() (*rest.Config, error) {
 
 	// if we're in k8s cluster, create in cluster config using service account
-	// otherwise, create out of cluster config using kubeconfig at $HOME/.kube/config
-	if os.Getenv("MOCK") == "true" {
-		log.Println("creating k8s client using test environment ...")
-		testEnv := envtest.Environment{
-			CRDDirectoryPaths: []string{
-				filepath.Join(build.Default.GOPATH, "pkg", "mod", "github.com", "kotalco", "kotal@v0.0.0-20220212203531-a88fa0a8809f", "config", "crd", "bases"),
-			},
-			ErrorIfCRDPathMissing: true,
-		}
-		return testEnv.Start()
-	} else if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+	// otherwise, create out of cluster config using kubeconfig at kubernetes.kubeconfig ($KUBECONFIG) or $HOME/.kube/config
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" && Get().Kubernetes.Kubeconfig == "" {
 		log.Println("creating k8s client using in-cluster config ...")
 		return rest.InClusterConfig()
 	} else {
 		log.Println("creating k8s client using out-of-cluster config ...")
-		kubeconfig := filepath.Join(homedir.HomeDir(), ".kube", "config")
-		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+		return kubeConfigLoader().ClientConfig()
+	}
+
+}
+
+// KubeContextsConfigs returns REST configs of the given kubeconfig contexts by context name
+// kubeconfig is loaded from $KUBECONFIG or $HOME/.kube/config, AllKubeContexts selects all the contexts
+// contexts selected by AllKubeContexts which can't be resolved are logged and skipped, explicitly named ones fail
+func KubeContextsConfigs(contexts ...string) (map[string]*rest.Config, error) {
+	rawConfig, err := kubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// names are the selected contexts, true if the context is explicitly named
+	names := map[string]bool{}
+	for _, name := range contexts {
+		if name != AllKubeContexts {
+			names[name] = true
+			continue
+		}
+		for contextName := range rawConfig.Contexts {
+			if _, selected := names[contextName]; !selected {
+				names[contextName] = false
+			}
+		}
+	}
+
+	configs := map[string]*rest.Config{}
+	for name, explicit := range names {
+		config, err := clientcmd.NewNonInteractiveClientConfig(rawConfig, name, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+		if err != nil {
+			if explicit {
+				return nil, err
+			}
+			go logger.Error("KUBE_CONTEXTS", fmt.Errorf("skipping context %s: %w", name, err))
+			continue
+		}
+		configs[name] = config
+	}
+
+	return configs, nil
+}
+
+// kubeConfigLoader returns kubeconfig loader following kubectl loading rules
+// kubeconfig path and current context are overridden by the kubernetes config
+func kubeConfigLoader() clientcmd.ClientConfig {
+	kubernetes := Get().Kubernetes
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubernetes.Kubeconfig != "" {
+		rules.Precedence = filepath.SplitList(kubernetes.Kubeconfig)
 	}
 
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: kubernetes.Context})
 }
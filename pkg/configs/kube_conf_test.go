@@ -0,0 +1,44 @@
+package configs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: testnet
+clusters:
+  - name: testnet
+    cluster:
+      server: https://testnet.kotal.local
+contexts:
+  - name: testnet
+    context:
+      cluster: testnet
+  - name: broken
+    context:
+      cluster: missing
+`
+
+func TestKubeContextsConfigs(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	assert.Nil(t, os.WriteFile(kubeconfig, []byte(testKubeConfig), 0600))
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	// contexts which can't be resolved are skipped if they're selected by the wildcard
+	contextsConfigs, err := KubeContextsConfigs(AllKubeContexts)
+	assert.Nil(t, err)
+	if assert.Len(t, contextsConfigs, 1) {
+		assert.Equal(t, "https://testnet.kotal.local", contextsConfigs["testnet"].Host)
+	}
+
+	// explicitly named contexts which can't be resolved fail
+	_, err = KubeContextsConfigs(AllKubeContexts, "broken")
+	assert.NotNil(t, err)
+
+	_, err = KubeContextsConfigs("unknown")
+	assert.NotNil(t, err)
+}
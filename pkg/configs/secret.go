@@ -0,0 +1,30 @@
+package configs
+
+import "encoding/json"
+
+// redacted is written instead of the set secrets values
+const redacted = "REDACTED"
+
+// Secret is config value which isn't written when the configuration is served or logged
+type Secret string
+
+// MarshalJSON writes the secret as REDACTED if it's set
+func (secret Secret) MarshalJSON() ([]byte, error) {
+	if secret == "" {
+		return json.Marshal("")
+	}
+	return json.Marshal(redacted)
+}
+
+// String returns the secret as REDACTED if it's set, so it's not printed by accident
+func (secret Secret) String() string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Value returns the secret value
+func (secret Secret) Value() string {
+	return string(secret)
+}
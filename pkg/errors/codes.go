@@ -0,0 +1,41 @@
+package errors
+
+// Code is stable machine readable error code, clients switch on it instead of parsing error messages
+type Code string
+
+const (
+	// CodeBadRequest is returned for malformed requests like invalid body or query parameters
+	CodeBadRequest Code = "BAD_REQUEST"
+	// CodeSpecInvalid is returned if the resource spec is rejected by the api validation or the operator admission webhooks
+	CodeSpecInvalid Code = "SPEC_INVALID"
+	// CodeUnauthorized is returned if the caller can't be authenticated
+	CodeUnauthorized Code = "UNAUTHORIZED"
+	// CodeForbidden is returned if the caller isn't allowed to perform the action
+	CodeForbidden Code = "FORBIDDEN"
+	// CodeNotFound is returned if the requested resource doesn't exist
+	CodeNotFound Code = "NOT_FOUND"
+	// CodeNodeNotFound is returned if the requested protocol node doesn't exist
+	CodeNodeNotFound Code = "NODE_NOT_FOUND"
+	// CodeMethodNotAllowed is returned if the resource doesn't support the action
+	CodeMethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	// CodeAlreadyExists is returned if a resource with the same name already exists
+	CodeAlreadyExists Code = "ALREADY_EXISTS"
+	// CodeConflict is returned if the resource has been modified since it was read
+	CodeConflict Code = "CONFLICT"
+	// CodeUnsupportedMediaType is returned if the request content type isn't supported
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+	// CodeRPCDisabled is returned if the node stats are requested while its JSON-RPC server is disabled
+	CodeRPCDisabled Code = "RPC_DISABLED"
+	// CodeTooManyRequests is returned if the caller or the k8s api server is rate limited
+	CodeTooManyRequests Code = "TOO_MANY_REQUESTS"
+	// CodeInternal is returned for unexpected errors
+	CodeInternal Code = "INTERNAL_ERROR"
+	// CodeUpstreamUnavailable is returned if the k8s api server or the node JSON-RPC server can't be reached
+	CodeUpstreamUnavailable Code = "UPSTREAM_UNAVAILABLE"
+	// CodeTimeout is returned if the k8s api server doesn't respond within the operation deadline
+	CodeTimeout Code = "TIMEOUT"
+	// CodeCanceled is returned if the call is cancelled before completing, like if the client closes the connection
+	CodeCanceled Code = "CANCELED"
+	// CodeNotReady is returned by the readiness probe if the api server can't serve calls yet
+	CodeNotReady Code = "NOT_READY"
+)
@@ -0,0 +1,100 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"net/url"
+	"strings"
+)
+
+// FromK8sError maps k8s api server errors to their rest error counterpart
+// forbidden errors returned when impersonated callers lack rbac permissions are mapped to forbidden error
+// invalid errors returned when the operator admission webhooks reject the spec are mapped to validation error
+// conflict, already exists, not found and bad request errors are mapped to their rest error
+// calls exceeding their deadline and api server timeouts are mapped to gateway timeout error
+// cancelled calls, like calls of disconnected clients, are mapped to client closed request error
+// unreachable or unavailable api server errors are mapped to upstream unavailable error
+// all other errors are mapped to the given fallback error
+// the details of mapped errors carry the kind and name of the k8s resource
+func FromK8sError(err error, fallback *RestErr) *RestErr {
+	var restErr *RestErr
+	var urlErr *url.Error
+
+	switch {
+	case apiErrors.IsForbidden(err):
+		restErr = NewForbiddenError(err.Error())
+	case apiErrors.IsInvalid(err):
+		restErr = validationErrorFromK8sError(err)
+	case apiErrors.IsAlreadyExists(err):
+		restErr = NewAlreadyExistsError(err.Error())
+	case apiErrors.IsConflict(err):
+		restErr = NewConflictError(err.Error())
+	case apiErrors.IsNotFound(err):
+		restErr = NewNotFoundError(err.Error())
+	case apiErrors.IsBadRequest(err):
+		restErr = NewBadRequestError(err.Error())
+	case apiErrors.IsTooManyRequests(err):
+		restErr = NewTooManyRequestsError(err.Error())
+	case errors.Is(err, context.DeadlineExceeded), apiErrors.IsTimeout(err), apiErrors.IsServerTimeout(err):
+		restErr = NewGatewayTimeoutError(err.Error())
+	case errors.Is(err, context.Canceled):
+		restErr = NewCanceledError(err.Error())
+	case apiErrors.IsServiceUnavailable(err), errors.As(err, &urlErr):
+		restErr = NewUpstreamUnavailableError(err.Error())
+	default:
+		return fallback
+	}
+
+	if details := detailsFromK8sError(err); len(details) != 0 {
+		restErr.Details = details
+	}
+
+	return restErr
+}
+
+// detailsFromK8sError returns the group, kind and name of the k8s resource the api server error is about
+func detailsFromK8sError(err error) map[string]interface{} {
+	details := map[string]interface{}{}
+
+	status, ok := err.(apiErrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return details
+	}
+
+	statusDetails := status.Status().Details
+	if statusDetails.Group != "" {
+		details["group"] = statusDetails.Group
+	}
+	if statusDetails.Kind != "" {
+		details["kind"] = statusDetails.Kind
+	}
+	if statusDetails.Name != "" {
+		details["name"] = statusDetails.Name
+	}
+
+	return details
+}
+
+// validationErrorFromK8sError returns validation error keyed by the dto field of every rejected spec field
+// like spec.coinbase and spec.resources.cpuLimit which are mapped to coinbase and cpuLimit
+func validationErrorFromK8sError(err error) *RestErr {
+	validations := map[string]string{}
+
+	if status, ok := err.(apiErrors.APIStatus); ok && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			field := strings.TrimPrefix(cause.Field, "spec.")
+			field = strings.TrimPrefix(field, "resources.")
+			if _, exists := validations[field]; !exists {
+				validations[field] = cause.Message
+			}
+		}
+	}
+
+	validationErr := NewValidationError(validations)
+	if len(validations) == 0 {
+		validationErr.Message = err.Error()
+	}
+
+	return validationErr
+}
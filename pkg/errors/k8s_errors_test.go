@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestFromK8sError(t *testing.T) {
+	fallback := NewInternalServerError("failed to create node")
+
+	forbidden := apiErrors.NewForbidden(schema.GroupResource{Group: "ethereum.kotal.io", Resource: "nodes"}, "my-node", errors.New("alice can't create nodes"))
+	err := FromK8sError(forbidden, fallback)
+	assert.EqualValues(t, http.StatusForbidden, err.Status)
+	assert.EqualValues(t, forbidden.Error(), err.Message)
+
+	conflict := apiErrors.NewConflict(schema.GroupResource{Group: "ethereum.kotal.io", Resource: "nodes"}, "my-node", errors.New("the object has been modified"))
+	err = FromK8sError(conflict, fallback)
+	assert.EqualValues(t, http.StatusConflict, err.Status)
+
+	alreadyExists := apiErrors.NewAlreadyExists(schema.GroupResource{Group: "ethereum.kotal.io", Resource: "nodes"}, "my-node")
+	err = FromK8sError(alreadyExists, fallback)
+	assert.EqualValues(t, http.StatusConflict, err.Status)
+	assert.EqualValues(t, CodeAlreadyExists, err.Code)
+	assert.EqualValues(t, "my-node", err.Details["name"])
+	assert.EqualValues(t, "ethereum.kotal.io", err.Details["group"])
+
+	notFound := apiErrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "my-node-0")
+	err = FromK8sError(notFound, fallback)
+	assert.EqualValues(t, http.StatusNotFound, err.Status)
+
+	unreachable := &url.Error{Op: "Get", URL: "https://kubernetes.default", Err: errors.New("connection refused")}
+	err = FromK8sError(unreachable, fallback)
+	assert.EqualValues(t, http.StatusBadGateway, err.Status)
+	assert.EqualValues(t, CodeUpstreamUnavailable, err.Code)
+
+	// deadline exceeded while waiting for the api server is reported as timeout, not as unreachable api server
+	deadlineExceeded := &url.Error{Op: "Get", URL: "https://kubernetes.default", Err: context.DeadlineExceeded}
+	err = FromK8sError(deadlineExceeded, fallback)
+	assert.EqualValues(t, http.StatusGatewayTimeout, err.Status)
+	assert.EqualValues(t, CodeTimeout, err.Code)
+
+	err = FromK8sError(apiErrors.NewTimeoutError("request did not complete within requested timeout", 0), fallback)
+	assert.EqualValues(t, http.StatusGatewayTimeout, err.Status)
+
+	canceled := &url.Error{Op: "Get", URL: "https://kubernetes.default", Err: context.Canceled}
+	err = FromK8sError(canceled, fallback)
+	assert.EqualValues(t, StatusClientClosedRequest, err.Status)
+	assert.EqualValues(t, CodeCanceled, err.Code)
+
+	err = FromK8sError(errors.New("connection refused"), fallback)
+	assert.EqualValues(t, fallback, err)
+}
+
+func TestFromK8sInvalidError(t *testing.T) {
+	invalid := apiErrors.NewInvalid(schema.GroupKind{Group: "ethereum.kotal.io", Kind: "Node"}, "my-node", field.ErrorList{
+		field.Invalid(field.NewPath("spec").Child("coinbase"), "", "must provide coinbase if miner is true"),
+		field.Invalid(field.NewPath("spec").Child("resources").Child("cpuLimit"), "1", "must be greater than or equal to cpu 2"),
+	})
+
+	err := FromK8sError(invalid, NewInternalServerError("failed to create node"))
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, CodeSpecInvalid, err.Code)
+	assert.Contains(t, err.Validations["coinbase"], "must provide coinbase if miner is true")
+	assert.Contains(t, err.Validations["cpuLimit"], "must be greater than or equal to cpu 2")
+}
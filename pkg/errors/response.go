@@ -0,0 +1,21 @@
+package errors
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestIDLocalsKey is the locals key of the request id saved by the request id middleware
+const RequestIDLocalsKey = "requestid"
+
+// WithRequestID returns copy of the error tagged with the given request id
+// the error is copied because errors can be shared by concurrent requests
+func (err RestErr) WithRequestID(requestID string) *RestErr {
+	err.RequestID = requestID
+	return &err
+}
+
+// Send writes the error response tagged with the request id saved to locals
+func Send(c *fiber.Ctx, err *RestErr) error {
+	requestID, _ := c.Locals(RequestIDLocalsKey).(string)
+	return c.Status(err.Status).JSON(err.WithRequestID(requestID))
+}
@@ -4,11 +4,17 @@ import (
 	"net/http"
 )
 
+// RestErr is the error response of all the api calls and websockets
+// Code is stable machine readable error code, RequestID correlates the error with the server logs
+// Validations are the rejected dto fields messages and Details is optional error specific payload
 type RestErr struct {
-	Message     string            `json:"message"`
-	Status      int               `json:"status"`
-	Name        string            `json:"name"`
-	Validations map[string]string `json:"validations,omitempty"`
+	Message     string                 `json:"message"`
+	Status      int                    `json:"status"`
+	Name        string                 `json:"name"`
+	Code        Code                   `json:"code"`
+	RequestID   string                 `json:"requestId,omitempty"`
+	Validations map[string]string      `json:"validations,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
 }
 
 //Error used to mimic build in error pkg so this can be replaceable  for go error pkg
@@ -16,11 +22,18 @@ func (err RestErr) Error() string {
 	return err.Message
 }
 
+// WithDetails returns the error with the given details payload
+func (err *RestErr) WithDetails(details map[string]interface{}) *RestErr {
+	err.Details = details
+	return err
+}
+
 func NewValidationError(validations map[string]string) *RestErr {
 	return &RestErr{
 		Message:     "Invalid Body Request",
 		Status:      http.StatusBadRequest,
 		Name:        "Bad Request",
+		Code:        CodeSpecInvalid,
 		Validations: validations,
 	}
 }
@@ -29,6 +42,7 @@ func NewBadRequestError(message string) *RestErr {
 		Message: message,
 		Status:  http.StatusBadRequest,
 		Name:    "Bad Request",
+		Code:    CodeBadRequest,
 	}
 }
 func NewNotFoundError(message string) *RestErr {
@@ -36,6 +50,7 @@ func NewNotFoundError(message string) *RestErr {
 		Message: message,
 		Status:  http.StatusNotFound,
 		Name:    "Not Found",
+		Code:    CodeNotFound,
 	}
 }
 func NewInternalServerError(message string) *RestErr {
@@ -43,6 +58,7 @@ func NewInternalServerError(message string) *RestErr {
 		Message: message,
 		Status:  http.StatusInternalServerError,
 		Name:    "Internal Server Error",
+		Code:    CodeInternal,
 	}
 }
 
@@ -51,6 +67,7 @@ func NewUnAuthorizedError(message string) *RestErr {
 		Message: message,
 		Status:  http.StatusUnauthorized,
 		Name:    "UnAuthorized",
+		Code:    CodeUnauthorized,
 	}
 }
 
@@ -59,6 +76,7 @@ func NewForbiddenError(message string) *RestErr {
 		Message: message,
 		Status:  http.StatusForbidden,
 		Name:    "Forbidden",
+		Code:    CodeForbidden,
 	}
 }
 
@@ -67,6 +85,7 @@ func NewTooManyRequestsError(message string) *RestErr {
 		Message: message,
 		Status:  http.StatusTooManyRequests,
 		Name:    "Too Many Requests",
+		Code:    CodeTooManyRequests,
 	}
 }
 
@@ -75,5 +94,124 @@ func NewConflictError(message string) *RestErr {
 		Message: message,
 		Status:  http.StatusConflict,
 		Name:    "Conflict",
+		Code:    CodeConflict,
+	}
+}
+
+func NewUnsupportedMediaTypeError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusUnsupportedMediaType,
+		Name:    "Unsupported Media Type",
+		Code:    CodeUnsupportedMediaType,
+	}
+}
+
+// NewNodeNotFoundError returns not found error of protocol node
+func NewNodeNotFoundError(message string) *RestErr {
+	err := NewNotFoundError(message)
+	err.Code = CodeNodeNotFound
+	return err
+}
+
+// NewAlreadyExistsError returns conflict error of resource created with the name of existing resource
+func NewAlreadyExistsError(message string) *RestErr {
+	err := NewConflictError(message)
+	err.Code = CodeAlreadyExists
+	return err
+}
+
+// NewMethodNotAllowedError returns error of action the resource doesn't support
+func NewMethodNotAllowedError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusMethodNotAllowed,
+		Name:    "Method Not Allowed",
+		Code:    CodeMethodNotAllowed,
+	}
+}
+
+// NewRPCDisabledError returns error of node stats requested while the node JSON-RPC server is disabled
+func NewRPCDisabledError(message string) *RestErr {
+	err := NewBadRequestError(message)
+	err.Code = CodeRPCDisabled
+	return err
+}
+
+// NewUpstreamUnavailableError returns error of k8s api server or node JSON-RPC server which can't be reached
+func NewUpstreamUnavailableError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusBadGateway,
+		Name:    "Bad Gateway",
+		Code:    CodeUpstreamUnavailable,
+	}
+}
+
+// NewGatewayTimeoutError returns error of k8s api server call which didn't complete within its deadline
+func NewGatewayTimeoutError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusGatewayTimeout,
+		Name:    "Gateway Timeout",
+		Code:    CodeTimeout,
+	}
+}
+
+// StatusClientClosedRequest is the non standard status of calls cancelled because the client closed the connection
+const StatusClientClosedRequest = 499
+
+// NewCanceledError returns error of call cancelled before completing, like if the client closes the connection
+func NewCanceledError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  StatusClientClosedRequest,
+		Name:    "Client Closed Request",
+		Code:    CodeCanceled,
+	}
+}
+
+// NewNotReadyError returns service unavailable error of api server which can't serve calls yet
+func NewNotReadyError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Status:  http.StatusServiceUnavailable,
+		Name:    "Service Unavailable",
+		Code:    CodeNotReady,
+	}
+}
+
+// statusCodes are the error codes of the http statuses returned by the framework like 404 of unknown routes
+var statusCodes = map[int]Code{
+	http.StatusBadRequest:           CodeBadRequest,
+	http.StatusUnauthorized:         CodeUnauthorized,
+	http.StatusForbidden:            CodeForbidden,
+	http.StatusNotFound:             CodeNotFound,
+	http.StatusMethodNotAllowed:     CodeMethodNotAllowed,
+	http.StatusConflict:             CodeConflict,
+	http.StatusUnsupportedMediaType: CodeUnsupportedMediaType,
+	http.StatusTooManyRequests:      CodeTooManyRequests,
+	http.StatusBadGateway:           CodeUpstreamUnavailable,
+	http.StatusServiceUnavailable:   CodeNotReady,
+	http.StatusGatewayTimeout:       CodeTimeout,
+	StatusClientClosedRequest:       CodeCanceled,
+}
+
+// NewStatusError returns error of the http status with its matching code
+// other client errors like 413 have BAD_REQUEST code, and other server errors have INTERNAL_ERROR code
+func NewStatusError(status int, message string) *RestErr {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeInternal
+		if status < http.StatusInternalServerError {
+			code = CodeBadRequest
+		}
+	}
+
+	return &RestErr{
+		Message: message,
+		Status:  status,
+		Name:    http.StatusText(status),
+		Code:    code,
 	}
 }
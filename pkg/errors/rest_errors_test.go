@@ -22,3 +22,49 @@ func TestNewInternalServerError(t *testing.T) {
 	assert.EqualValues(t, err.Message, "internal server error")
 	assert.EqualValues(t, http.StatusInternalServerError, err.Status)
 }
+
+func TestErrorCodes(t *testing.T) {
+	assert.EqualValues(t, CodeSpecInvalid, NewValidationError(map[string]string{"name": "required"}).Code)
+	assert.EqualValues(t, CodeNodeNotFound, NewNodeNotFoundError("node by name my-node doesn't exist").Code)
+
+	err := NewAlreadyExistsError("node by name my-node already exists")
+	assert.EqualValues(t, http.StatusConflict, err.Status)
+	assert.EqualValues(t, CodeAlreadyExists, err.Code)
+
+	err = NewRPCDisabledError("rpc is not enabled")
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.EqualValues(t, CodeRPCDisabled, err.Code)
+
+	err = NewUpstreamUnavailableError("connection refused").WithDetails(map[string]interface{}{"method": "eth_syncing"})
+	assert.EqualValues(t, http.StatusBadGateway, err.Status)
+	assert.EqualValues(t, CodeUpstreamUnavailable, err.Code)
+	assert.EqualValues(t, "eth_syncing", err.Details["method"])
+
+	err = NewNotReadyError("kubernetes api server can't be reached")
+	assert.EqualValues(t, http.StatusServiceUnavailable, err.Status)
+	assert.EqualValues(t, CodeNotReady, err.Code)
+}
+
+func TestWithRequestID(t *testing.T) {
+	err := NewNotFoundError("Not Found")
+	tagged := err.WithRequestID("my-request")
+
+	assert.EqualValues(t, "my-request", tagged.RequestID)
+	// the original error isn't modified
+	assert.Empty(t, err.RequestID)
+}
+
+func TestNewStatusError(t *testing.T) {
+	for status, code := range map[int]Code{
+		http.StatusNotFound:              CodeNotFound,
+		http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+		http.StatusRequestEntityTooLarge: CodeBadRequest,
+		http.StatusServiceUnavailable:    CodeNotReady,
+		http.StatusNotImplemented:        CodeInternal,
+	} {
+		err := NewStatusError(status, "Cannot GET /api/v1/unknown")
+		assert.EqualValues(t, status, err.Status)
+		assert.EqualValues(t, code, err.Code, status)
+		assert.EqualValues(t, http.StatusText(status), err.Name)
+	}
+}
@@ -0,0 +1,199 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/api/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"strings"
+	"time"
+)
+
+// cacheSyncTimeout is the max duration reads wait for the informer of a kind to sync
+// reads of kinds failing to sync fall through to the api server
+const cacheSyncTimeout = 10 * time.Second
+
+// kotalGroupSuffix is the api group suffix of the kotal custom resources
+const kotalGroupSuffix = ".kotal.io"
+
+// managedByLabel selects the pods and statefulsets created by kotal operator for the nodes
+const managedByLabel = "app.kubernetes.io/managed-by=kotal"
+
+// newCache returns the cluster shared informer cache created and started once
+// informers are created on the first read of each kind and kept in sync by watching the api server
+// the cache informers are stopped once the cache is reset, see resetCache
+func (cluster *Cluster) newCache() (cache.Cache, error) {
+	cluster.cacheLock.Lock()
+	defer cluster.cacheLock.Unlock()
+
+	if cluster.informerCache != nil {
+		return cluster.informerCache, nil
+	}
+
+	mapper, err := cluster.restMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	managedBy, err := labels.Parse(managedByLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedCache, err := cache.New(cluster.config, cache.Options{
+		Scheme: scheme,
+		Mapper: mapper,
+		// only pods and statefulsets of the nodes are cached to keep the cache memory bounded
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.Pod{}:         {Label: managedBy},
+			&appsv1.StatefulSet{}: {Label: managedBy},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := sharedCache.Start(ctx); err != nil {
+			go logger.Error("K8S_CACHE", err)
+		}
+	}()
+
+	// wait for the cache to start so reads aren't rejected with cache not started error
+	sharedCache.WaitForCacheSync(ctx)
+
+	cluster.informerCache = sharedCache
+	cluster.stopCache = cancel
+	return cluster.informerCache, nil
+}
+
+// resetCache stops the cluster cache informers, the cache is created again on the next cached read
+// the informers of a single kind can't be stopped, so the cache is reset to stop the informers of uncached kinds
+func (cluster *Cluster) resetCache() {
+	cluster.cacheLock.Lock()
+	defer cluster.cacheLock.Unlock()
+
+	if cluster.stopCache != nil {
+		cluster.stopCache()
+	}
+	cluster.informerCache, cluster.stopCache = nil, nil
+}
+
+// isCachedRead returns true if reads of the object kind for the given context are served by the informer cache
+// kotal resources, storage classes and nodes pods and statefulsets are cached
+// other kinds like secrets are always read from the api server
+// impersonated reads aren't cached because the cache is filled using the api server service account permissions
+func isCachedRead(ctx context.Context, obj runtime.Object) bool {
+	if _, ok := ImpersonationFromContext(ctx); ok {
+		return false
+	}
+
+	cluster, err := clusterFor(ctx)
+	if err != nil {
+		return false
+	}
+
+	groupKind, err := groupKindFor(obj)
+	return err == nil && cluster.isCachedKind(groupKind)
+}
+
+// cacheFor returns the informer cache serving reads of the object kind for the given context, see isCachedRead
+// every cluster has its own cache
+func cacheFor(ctx context.Context, obj runtime.Object) (cache.Cache, bool) {
+	if !isCachedRead(ctx, obj) {
+		return nil, false
+	}
+
+	cluster, err := clusterFor(ctx)
+	if err != nil {
+		return nil, false
+	}
+
+	sharedCache, err := cluster.newCache()
+	if err != nil {
+		go logger.Error("K8S_CACHE", err)
+		return nil, false
+	}
+
+	return sharedCache, true
+}
+
+// cachedRead reads the object using the informer cache
+// returns false if the read isn't served by the cache and should fall through to the api server
+func cachedRead(ctx context.Context, obj runtime.Object, read func(ctx context.Context, reader client.Reader) error) (bool, error) {
+	sharedCache, ok := cacheFor(ctx, obj)
+	if !ok {
+		return false, nil
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, cacheSyncTimeout)
+	defer cancel()
+
+	err := read(syncCtx, sharedCache)
+	if err == nil || apiErrors.IsNotFound(err) {
+		return true, err
+	}
+
+	// kinds whose informers fail to sync like missing watch permission aren't cached anymore
+	// and the cache is reset so their informers stop retrying to list and watch them
+	// other errors like unindexed field selectors fall through to the api server
+	if ctx.Err() == nil && apiErrors.IsTimeout(err) {
+		cluster, _ := clusterFor(ctx)
+		groupKind, _ := groupKindFor(obj)
+		cluster.uncachedKindsLock.Lock()
+		cluster.uncachedKinds[groupKind] = true
+		cluster.uncachedKindsLock.Unlock()
+		cluster.resetCache()
+		go logger.Error("K8S_CACHE", fmt.Errorf("%s informer didn't sync, reads will be served by the api server: %w", groupKind, err))
+	}
+
+	return false, nil
+}
+
+// isCachedKind returns true if reads of the kind are served by the informer cache
+// pods and statefulsets are cached only if they're created by kotal operator, others aren't found
+func (cluster *Cluster) isCachedKind(groupKind schema.GroupKind) bool {
+	cluster.uncachedKindsLock.RLock()
+	defer cluster.uncachedKindsLock.RUnlock()
+
+	if cluster.uncachedKinds[groupKind] {
+		return false
+	}
+
+	if strings.HasSuffix(groupKind.Group, kotalGroupSuffix) {
+		return true
+	}
+
+	switch groupKind {
+	case storagev1.SchemeGroupVersion.WithKind("StorageClass").GroupKind(),
+		corev1.SchemeGroupVersion.WithKind("Pod").GroupKind(),
+		appsv1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind():
+		return true
+	default:
+		return false
+	}
+}
+
+// groupKindFor returns the group kind of the object, list objects are mapped to their items group kind
+func groupKindFor(obj runtime.Object) (schema.GroupKind, error) {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return schema.GroupKind{}, err
+	}
+
+	if _, isList := obj.(client.ObjectList); isList {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+
+	return gvk.GroupKind(), nil
+}
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"testing"
+)
+
+func TestIsCachedKind(t *testing.T) {
+	testCases := []struct {
+		obj    runtime.Object
+		cached bool
+	}{
+		{&ethereumv1alpha1.Node{}, true},
+		{&ethereumv1alpha1.NodeList{}, true},
+		{&storagev1.StorageClassList{}, true},
+		{&corev1.Pod{}, true},
+		{&appsv1.StatefulSet{}, true},
+		{&corev1.Secret{}, false},
+		{&corev1.SecretList{}, false},
+		{&corev1.NamespaceList{}, false},
+		{partialList(ethereumv1alpha1.GroupVersion.WithKind("NodeList")), true},
+		{partialList(corev1.SchemeGroupVersion.WithKind("SecretList")), false},
+	}
+
+	for _, testCase := range testCases {
+		groupKind, err := groupKindFor(testCase.obj)
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.cached, newCluster("test", nil).isCachedKind(groupKind), "%T", testCase.obj)
+	}
+}
+
+// partialList returns metadata only list of the given list kind
+func partialList(gvk schema.GroupVersionKind) *metav1.PartialObjectMetadataList {
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(gvk)
+	return list
+}
+
+func TestGroupKindForList(t *testing.T) {
+	groupKind, err := groupKindFor(&ethereumv1alpha1.NodeList{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "ethereum.kotal.io", groupKind.Group)
+	assert.Equal(t, "Node", groupKind.Kind)
+}
+
+func TestResetCache(t *testing.T) {
+	cluster := newCluster("testnet", &rest.Config{Host: "https://testnet.kotal.local"})
+	// skips api discovery of the unreachable test cluster
+	cluster.mapper = meta.NewDefaultRESTMapper(nil)
+
+	first, err := cluster.newCache()
+	assert.Nil(t, err)
+	cached, err := cluster.newCache()
+	assert.Nil(t, err)
+	assert.Same(t, first, cached)
+
+	// the cache is created again once it's reset
+	cluster.resetCache()
+	assert.Nil(t, cluster.informerCache)
+	second, err := cluster.newCache()
+	assert.Nil(t, err)
+	assert.NotSame(t, first, second)
+	cluster.resetCache()
+}
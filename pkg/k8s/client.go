@@ -2,8 +2,6 @@ package k8s
 
 import (
 	"context"
-	"github.com/kotalco/api/pkg/configs"
-	"github.com/kotalco/api/pkg/logger"
 	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
 	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
 	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
@@ -13,36 +11,51 @@ import (
 	polkadotv1alpha1 "github.com/kotalco/kotal/apis/polkadot/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sync"
+	"time"
 )
 
-var clientLock = &sync.Mutex{}
-var controllerRuntimeClient client.Client
+// runtimeClient returns controller-runtime client of the cluster created once
+func (cluster *Cluster) runtimeClient() (client.Client, error) {
+	cluster.clientLock.Lock()
+	defer cluster.clientLock.Unlock()
 
-func newClient() client.Client {
-	var err error
-	clientLock.Lock()
-	defer clientLock.Unlock()
-	if controllerRuntimeClient == nil {
-		controllerRuntimeClient, err = NewRuntimeClient()
+	if cluster.client == nil {
+		opts, err := cluster.clientOptions()
 		if err != nil {
-			logger.Panic("K8S_CLIENT", err)
+			return nil, err
 		}
+		runtimeClient, err := client.New(cluster.config, opts)
+		if err != nil {
+			return nil, err
+		}
+		cluster.client = runtimeClient
 	}
 
-	return controllerRuntimeClient
+	return cluster.client, nil
 }
 
-// NewRuntimeClient creates new controller-runtime k8s client
-func NewRuntimeClient() (client.Client, error) {
-
-	config, err := configs.KubeConfig()
+// clientFor returns the client to be used for the given context
+// the client of the cluster selected by the context, impersonated client is returned if the context carries impersonation config
+// returns error if the cluster isn't registered or its client can't be created, like if its api server can't be reached
+func clientFor(ctx context.Context) (client.Client, error) {
+	cluster, err := clusterFor(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	impersonation, ok := ImpersonationFromContext(ctx)
+	if !ok {
+		return cluster.runtimeClient()
+	}
+
+	return cluster.impersonatedClient(impersonation)
+}
+
+// NewScheme returns runtime scheme with k8s core types and kotal custom resources registered
+func NewScheme() *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	clientgoscheme.AddToScheme(scheme)
 	ethereumv1alpha1.AddToScheme(scheme)
@@ -53,9 +66,7 @@ func NewRuntimeClient() (client.Client, error) {
 	polkadotv1alpha1.AddToScheme(scheme)
 	nearv1alpha1.AddToScheme(scheme)
 
-	opts := client.Options{Scheme: scheme}
-
-	return client.New(config, opts)
+	return scheme
 }
 
 type k8sClientService struct{}
@@ -64,6 +75,7 @@ type ObjectKey = types.NamespacedName
 type K8sClientServiceInterface interface {
 	client.Reader
 	client.Writer
+	Watch(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error)
 }
 
 func NewClientService() K8sClientServiceInterface {
@@ -72,43 +84,176 @@ func NewClientService() K8sClientServiceInterface {
 }
 
 // Get retrieves an obj for the given object key from the Kubernetes Cluster.
+// cached kinds are served from the shared informer cache, see cacheFor
 // obj must be a struct pointer so that obj can be updated with the response
 // returned by the Server.
 func (k8sClient k8sClientService) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-	return newClient().Get(ctx, key, obj)
+	ctx, cancel := WithTimeout(ctx, "get")
+	defer cancel()
+
+	served, err := cachedRead(ctx, obj, func(ctx context.Context, reader client.Reader) error {
+		return reader.Get(ctx, key, obj)
+	})
+	if served {
+		return err
+	}
+
+	runtimeClient, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = runtimeClient.Get(ctx, key, obj)
+	observe("get", obj, start, err)
+	return err
 }
 
-// List retrieves list of objects for a given namespace and list options. On a
+// List retrieves list of objects for a given namespace and list options
+// cached kinds are served from the shared informer cache, see cacheFor. On a
 // successful call, Items field in the list will be populated with the
 // result returned from the server.
+// paginated lists are always served by the api server, the cache doesn't issue continue tokens
 func (k8sClient k8sClientService) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
-	return newClient().List(ctx, list, opts...)
+	ctx, cancel := WithTimeout(ctx, "list")
+	defer cancel()
 
+	if listOptions := (&client.ListOptions{}).ApplyOptions(opts); listOptions.Limit == 0 && listOptions.Continue == "" {
+		served, err := cachedRead(ctx, list, func(ctx context.Context, reader client.Reader) error {
+			return reader.List(ctx, list, opts...)
+		})
+		if served {
+			return err
+		}
+	}
+
+	runtimeClient, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = runtimeClient.List(ctx, list, opts...)
+	observe("list", list, start, err)
+	return err
 }
 
 // Create saves the object obj in the Kubernetes cluster.
+// the object isn't persisted if the context marks write calls as dry run
 func (k8sClient k8sClientService) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
-	return newClient().Create(ctx, obj, opts...)
+	ctx, cancel := WithTimeout(ctx, "create")
+	defer cancel()
+
+	if IsDryRun(ctx) {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	runtimeClient, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = runtimeClient.Create(ctx, obj, opts...)
+	observe("create", obj, start, err)
+	return err
 }
 
 // Delete deletes the given obj from Kubernetes cluster.
+// the object isn't deleted if the context marks write calls as dry run
+// or if it has been modified since the resource version the context conditions deletes on
 func (k8sClient k8sClientService) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
-	return newClient().Delete(ctx, obj, opts...)
+	ctx, cancel := WithTimeout(ctx, "delete")
+	defer cancel()
+
+	if IsDryRun(ctx) {
+		opts = append(opts, client.DryRunAll)
+	}
+	if resourceVersion, ok := ResourceVersionFromContext(ctx); ok {
+		opts = append(opts, client.Preconditions{ResourceVersion: &resourceVersion})
+	}
+
+	runtimeClient, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = runtimeClient.Delete(ctx, obj, opts...)
+	observe("delete", obj, start, err)
+	return err
 }
 
 // Update updates the given obj in the Kubernetes cluster. obj must be a
 // struct pointer so that obj can be updated with the content returned by the Server.
+// the object isn't persisted if the context marks write calls as dry run
 func (k8sClient k8sClientService) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
-	return newClient().Update(ctx, obj, opts...)
+	ctx, cancel := WithTimeout(ctx, "update")
+	defer cancel()
+
+	if IsDryRun(ctx) {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	runtimeClient, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = runtimeClient.Update(ctx, obj, opts...)
+	observe("update", obj, start, err)
+	return err
 }
 
 // Patch patches the given obj in the Kubernetes cluster. obj must be a
 // struct pointer so that obj can be updated with the content returned by the Server.
+// the object isn't persisted if the context marks write calls as dry run
 func (k8sClient k8sClientService) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
-	return newClient().Patch(ctx, obj, patch, opts...)
+	ctx, cancel := WithTimeout(ctx, "patch")
+	defer cancel()
+
+	if IsDryRun(ctx) {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	runtimeClient, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = runtimeClient.Patch(ctx, obj, patch, opts...)
+	observe("patch", obj, start, err)
+	return err
 }
 
 // DeleteAllOf deletes all objects of the given type matching the given options.
 func (k8sClient k8sClientService) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
-	return newClient().DeleteAllOf(ctx, obj, opts...)
+	ctx, cancel := WithTimeout(ctx, "deletecollection")
+	defer cancel()
+
+	runtimeClient, err := clientFor(ctx)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = runtimeClient.DeleteAllOf(ctx, obj, opts...)
+	observe("deletecollection", obj, start, err)
+	return err
+}
+
+// Watch watches changes of the list items kind matching the list options.
+// watches are always served by the Kubernetes cluster, not the informer cache.
+func (k8sClient k8sClientService) Watch(ctx context.Context, list client.ObjectList, opts ...client.ListOption) (watch.Interface, error) {
+	watchClient, err := watchClientFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	watcher, err := watchClient.Watch(ctx, list, opts...)
+	observe("watch", list, start, err)
+	return watcher, err
 }
@@ -1,36 +1,62 @@
 package k8s
 
 import (
-	"github.com/kotalco/api/pkg/configs"
+	"context"
 	"github.com/kotalco/api/pkg/logger"
 	"k8s.io/client-go/kubernetes"
-	"sync"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
-var clientSetLock = &sync.Mutex{}
-var KubernetesClientset *kubernetes.Clientset
-
-// Clientset create k8s client once
+// Clientset returns client-go clientset of the default cluster created once
 func Clientset() *kubernetes.Clientset {
-	var err error
-	clientSetLock.Lock()
-	defer clientSetLock.Unlock()
-	if KubernetesClientset == nil {
-		KubernetesClientset, err = NewClientset()
+	cluster, err := loadClusters()
+	if err != nil {
+		logger.Panic("K8S_CLIENT_SET", err)
+	}
+
+	clientset, err := cluster.Clientset()
+	if err != nil {
+		logger.Panic("K8S_CLIENT_SET", err)
+	}
+
+	return clientset
+}
+
+// Clientset returns client-go clientset of the cluster created once
+func (cluster *Cluster) Clientset() (*kubernetes.Clientset, error) {
+	cluster.clientsetLock.Lock()
+	defer cluster.clientsetLock.Unlock()
+
+	if cluster.clientset == nil {
+		clientset, err := kubernetes.NewForConfig(cluster.config)
 		if err != nil {
-			logger.Panic("K8S_CLIENT_SET", err)
+			return nil, err
 		}
+		cluster.clientset = clientset
 	}
 
-	return KubernetesClientset
+	return cluster.clientset, nil
 }
 
-// NewClientset returns client-go clientset
-func NewClientset() (*kubernetes.Clientset, error) {
-	config, err := configs.KubeConfig()
-	if err != nil {
-		return nil, err
-	}
+type clientsetService struct{}
+
+// ClientsetServiceInterface returns the client-go clientsets to be used for the given context
+// like K8sClientServiceInterface, the clientsets of the cluster selected by the context impersonating its user
+type ClientsetServiceInterface interface {
+	Clientset(ctx context.Context) (kubernetes.Interface, error)
+	MetricsClientset(ctx context.Context) (metrics.Interface, error)
+}
+
+func NewClientsetService() ClientsetServiceInterface {
+	return clientsetService{}
+}
+
+// Clientset returns the clientset to be used for the given context, see ClientsetFor
+func (service clientsetService) Clientset(ctx context.Context) (kubernetes.Interface, error) {
+	return ClientsetFor(ctx)
+}
 
-	return kubernetes.NewForConfig(config)
+// MetricsClientset returns the metrics client to be used for the given context, see MetricsClientsetFor
+func (service clientsetService) MetricsClientset(ctx context.Context) (metrics.Interface, error) {
+	return MetricsClientsetFor(ctx)
 }
@@ -0,0 +1,259 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"sync"
+)
+
+const (
+	// DefaultClusterName is the name of the cluster the api server is configured with if kubernetes.clusterName isn't set
+	DefaultClusterName = "default"
+	// ClusterLocalsKey is the fiber locals key holding the selected cluster name
+	// used by websocket handlers which can't access the request user context
+	ClusterLocalsKey = "cluster"
+)
+
+type clusterKey struct{}
+
+// Cluster is a k8s cluster managed by the api server
+// every cluster has its own REST config, clients and informer cache created on first use
+type Cluster struct {
+	// Name is the cluster name used to select the cluster in api calls
+	Name string
+	// Default is true for the cluster used if api calls don't select a cluster
+	Default bool
+
+	config *rest.Config
+
+	mapperLock sync.Mutex
+	mapper     meta.RESTMapper
+
+	clientLock sync.Mutex
+	client     client.Client
+
+	clientsetLock sync.Mutex
+	clientset     *kubernetes.Clientset
+
+	metricsClientsetLock sync.Mutex
+	metricsClientset     *metrics.Clientset
+
+	watchClientLock sync.Mutex
+	watchClient     client.WithWatch
+
+	cacheLock     sync.Mutex
+	informerCache cache.Cache
+	stopCache     context.CancelFunc
+
+	uncachedKindsLock sync.RWMutex
+	uncachedKinds     map[schema.GroupKind]bool
+
+	impersonatedClientsLock sync.Mutex
+	impersonatedClients     map[string]*impersonatedClients
+}
+
+var (
+	clustersLock   = &sync.Mutex{}
+	clusters       map[string]*Cluster
+	defaultCluster *Cluster
+)
+
+// newCluster returns cluster using the given REST config, no call is made to the cluster api server
+func newCluster(name string, config *rest.Config) *Cluster {
+	return &Cluster{
+		Name:                name,
+		config:              config,
+		uncachedKinds:       map[schema.GroupKind]bool{},
+		impersonatedClients: map[string]*impersonatedClients{},
+	}
+}
+
+// WithCluster returns a copy of the context selecting the cluster by name
+// k8s calls made using this context are sent to the selected cluster
+func WithCluster(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, clusterKey{}, name)
+}
+
+// ClusterFromContext returns the name of the cluster selected by the context
+func ClusterFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(clusterKey{}).(string)
+	return name, ok
+}
+
+// loadClusters loads the clusters registry once
+// the default cluster is configured by the environment, see configs.KubeConfig
+// KUBE_CONTEXTS adds the comma separated kubeconfig contexts or all of them using *
+// KUBE_CLUSTERS_SECRET adds clusters from secret in the form of namespace/name,
+// every secret key is the cluster name and its value is the cluster kubeconfig
+// additional clusters failing to load are logged and skipped
+func loadClusters() (*Cluster, error) {
+	clustersLock.Lock()
+	defer clustersLock.Unlock()
+
+	if defaultCluster != nil {
+		return defaultCluster, nil
+	}
+
+	config, err := configs.KubeConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetes := configs.Get().Kubernetes
+	name := kubernetes.ClusterName
+	if name == "" {
+		name = DefaultClusterName
+	}
+
+	loaded := map[string]*Cluster{}
+	primary := newCluster(name, config)
+	primary.Default = true
+	loaded[name] = primary
+
+	add := func(name string, config *rest.Config) {
+		if _, exists := loaded[name]; exists {
+			go logger.Error("K8S_CLUSTERS", fmt.Errorf("cluster %s is already registered", name))
+			return
+		}
+		loaded[name] = newCluster(name, config)
+	}
+
+	if len(kubernetes.Contexts) != 0 {
+		contextsConfigs, err := configs.KubeContextsConfigs(kubernetes.Contexts...)
+		if err != nil {
+			go logger.Error("K8S_CLUSTERS", err)
+		}
+		for contextName, contextConfig := range contextsConfigs {
+			add(contextName, contextConfig)
+		}
+	}
+
+	if kubernetes.ClustersSecret != "" {
+		secretsConfigs, err := clustersFromSecret(primary, NamespacedNameFromString(kubernetes.ClustersSecret))
+		if err != nil {
+			go logger.Error("K8S_CLUSTERS", err)
+		}
+		for clusterName, clusterConfig := range secretsConfigs {
+			add(clusterName, clusterConfig)
+		}
+	}
+
+	clusters = loaded
+	defaultCluster = primary
+
+	return defaultCluster, nil
+}
+
+// UseClusters registers the clusters by name instead of loading them from the environment, the first one is the default cluster
+// registered clusters have no REST config, it's used if k8s calls are served in memory like the simulator
+// the informer caches of the replaced clusters are stopped
+func UseClusters(names ...string) {
+	clustersLock.Lock()
+	defer clustersLock.Unlock()
+
+	for _, cluster := range clusters {
+		cluster.resetCache()
+	}
+
+	clusters = map[string]*Cluster{}
+	for i, name := range names {
+		cluster := newCluster(name, nil)
+		if i == 0 {
+			cluster.Default = true
+			defaultCluster = cluster
+		}
+		clusters[name] = cluster
+	}
+}
+
+// clustersFromSecret reads clusters REST configs from secret stored in the given cluster
+// every secret key is the cluster name and its value is the cluster kubeconfig
+func clustersFromSecret(cluster *Cluster, key ObjectKey) (map[string]*rest.Config, error) {
+	runtimeClient, err := cluster.runtimeClient()
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := runtimeClient.Get(context.Background(), key, secret); err != nil {
+		return nil, err
+	}
+
+	kubeconfigs := map[string]*rest.Config{}
+	for name, kubeconfig := range secret.Data {
+		config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		if err != nil {
+			go logger.Error("K8S_CLUSTERS", fmt.Errorf("can't load cluster %s kubeconfig: %w", name, err))
+			continue
+		}
+		kubeconfigs[name] = config
+	}
+
+	return kubeconfigs, nil
+}
+
+// Clusters returns the registered clusters sorted by name, the default cluster first
+func Clusters() ([]*Cluster, error) {
+	if _, err := loadClusters(); err != nil {
+		return nil, err
+	}
+
+	clustersLock.Lock()
+	defer clustersLock.Unlock()
+
+	list := make([]*Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		list = append(list, cluster)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Default != list[j].Default {
+			return list[i].Default
+		}
+		return list[i].Name < list[j].Name
+	})
+
+	return list, nil
+}
+
+// GetCluster returns the registered cluster by name
+func GetCluster(name string) (*Cluster, bool, error) {
+	if _, err := loadClusters(); err != nil {
+		return nil, false, err
+	}
+
+	clustersLock.Lock()
+	defer clustersLock.Unlock()
+
+	cluster, ok := clusters[name]
+	return cluster, ok, nil
+}
+
+// clusterFor returns the cluster selected by the given context or the default cluster
+func clusterFor(ctx context.Context) (*Cluster, error) {
+	name, ok := ClusterFromContext(ctx)
+	if !ok {
+		return loadClusters()
+	}
+
+	cluster, ok, err := GetCluster(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("cluster %s isn't registered", name)
+	}
+
+	return cluster, nil
+}
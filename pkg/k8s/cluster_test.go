@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: testnet
+clusters:
+  - name: testnet
+    cluster:
+      server: https://testnet.kotal.local
+  - name: mainnet
+    cluster:
+      server: https://mainnet.kotal.local
+contexts:
+  - name: testnet
+    context:
+      cluster: testnet
+  - name: mainnet
+    context:
+      cluster: mainnet
+`
+
+// setTestClusters loads the clusters registry from test kubeconfig with the given contexts
+func setTestClusters(t *testing.T, contexts ...string) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	assert.Nil(t, os.WriteFile(kubeconfig, []byte(testKubeConfig), 0600))
+
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBECONFIG", kubeconfig)
+	kubernetes := &configs.Get().Kubernetes
+	clusterName, previousContexts := kubernetes.ClusterName, kubernetes.Contexts
+	t.Cleanup(func() { kubernetes.ClusterName, kubernetes.Contexts = clusterName, previousContexts })
+	kubernetes.ClusterName, kubernetes.Contexts = "", contexts
+
+	clustersLock.Lock()
+	clusters, defaultCluster = nil, nil
+	clustersLock.Unlock()
+}
+
+func TestLoadClustersFromContexts(t *testing.T) {
+	setTestClusters(t, "*")
+
+	list, err := Clusters()
+	assert.Nil(t, err)
+	if !assert.Len(t, list, 3) {
+		return
+	}
+
+	assert.Equal(t, DefaultClusterName, list[0].Name)
+	assert.True(t, list[0].Default)
+	assert.Equal(t, "https://testnet.kotal.local", list[0].config.Host)
+	assert.Equal(t, "mainnet", list[1].Name)
+	assert.Equal(t, "https://mainnet.kotal.local", list[1].config.Host)
+	assert.Equal(t, "testnet", list[2].Name)
+	assert.False(t, list[2].Default)
+}
+
+func TestClusterFor(t *testing.T) {
+	setTestClusters(t, "mainnet")
+
+	cluster, err := clusterFor(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultClusterName, cluster.Name)
+
+	cluster, err = clusterFor(WithCluster(context.Background(), "mainnet"))
+	assert.Nil(t, err)
+	assert.Equal(t, "https://mainnet.kotal.local", cluster.config.Host)
+
+	_, err = clusterFor(WithCluster(context.Background(), "testnet"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,36 @@
+package k8s
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var scheme = NewScheme()
+
+// restMapper returns the REST mapper shared by all the cluster clients
+// sharing the mapper saves impersonated clients from doing api discovery on creation
+func (cluster *Cluster) restMapper() (meta.RESTMapper, error) {
+	cluster.mapperLock.Lock()
+	defer cluster.mapperLock.Unlock()
+
+	if cluster.mapper == nil {
+		mapper, err := apiutil.NewDynamicRESTMapper(cluster.config)
+		if err != nil {
+			return nil, err
+		}
+		cluster.mapper = mapper
+	}
+
+	return cluster.mapper, nil
+}
+
+// clientOptions returns controller-runtime client options of the cluster clients
+func (cluster *Cluster) clientOptions() (client.Options, error) {
+	mapper, err := cluster.restMapper()
+	if err != nil {
+		return client.Options{}, err
+	}
+
+	return client.Options{Scheme: scheme, Mapper: mapper}, nil
+}
@@ -0,0 +1,35 @@
+package k8s
+
+import (
+	"context"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Count returns the number of objects of the given list kind matching the list options
+// cached kinds are counted from the informer cache typed list, see isCachedRead
+// other kinds are counted by listing only the objects metadata, so the objects specs and statuses aren't read
+// listing the metadata of cached kinds would start another metadata informer of the same kind
+func Count(ctx context.Context, reader client.Reader, list client.ObjectList, opts ...client.ListOption) (int, error) {
+	if isCachedRead(ctx, list) {
+		if err := reader.List(ctx, list, opts...); err != nil {
+			return 0, err
+		}
+		return meta.LenList(list), nil
+	}
+
+	gvk, err := apiutil.GVKForObject(list, scheme)
+	if err != nil {
+		return 0, err
+	}
+
+	metadata := &metav1.PartialObjectMetadataList{}
+	metadata.SetGroupVersionKind(gvk)
+	if err := reader.List(ctx, metadata, opts...); err != nil {
+		return 0, err
+	}
+
+	return len(metadata.Items), nil
+}
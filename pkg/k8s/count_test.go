@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientFake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"testing"
+)
+
+// listRecorder records the lists read by the client
+type listRecorder struct {
+	client.Client
+	lists []client.ObjectList
+}
+
+func (recorder *listRecorder) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	recorder.lists = append(recorder.lists, list)
+	return recorder.Client.List(ctx, list, opts...)
+}
+
+func TestCount(t *testing.T) {
+	setTestClusters(t)
+
+	node := func(name, namespace string) client.Object {
+		return &ethereumv1alpha1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "my-secret", Namespace: "default"}}
+	newRecorder := func() *listRecorder {
+		return &listRecorder{Client: clientFake.NewClientBuilder().WithScheme(NewScheme()).WithObjects(
+			node("node-1", "default"), node("node-2", "default"), node("node-3", "kotal"), secret,
+		).Build()}
+	}
+
+	// cached kinds are counted using the typed list served by the cache
+	recorder := newRecorder()
+	count, err := Count(context.Background(), recorder, &ethereumv1alpha1.NodeList{}, client.InNamespace("default"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+	if assert.Len(t, recorder.lists, 1) {
+		assert.IsType(t, &ethereumv1alpha1.NodeList{}, recorder.lists[0])
+	}
+
+	// only the metadata of uncached kinds and impersonated reads is listed
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		list  client.ObjectList
+		count int
+		gvk   schema.GroupVersionKind
+	}{
+		{
+			name:  "uncached kind",
+			ctx:   context.Background(),
+			list:  &corev1.SecretList{},
+			count: 1,
+			gvk:   corev1.SchemeGroupVersion.WithKind("SecretList"),
+		},
+		{
+			name:  "impersonated",
+			ctx:   WithImpersonation(context.Background(), rest.ImpersonationConfig{UserName: "alice"}),
+			list:  &ethereumv1alpha1.NodeList{},
+			count: 2,
+			gvk:   ethereumv1alpha1.GroupVersion.WithKind("NodeList"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := newRecorder()
+			count, err := Count(test.ctx, recorder, test.list, client.InNamespace("default"))
+			assert.Nil(t, err)
+			assert.Equal(t, test.count, count)
+
+			if assert.Len(t, recorder.lists, 1) {
+				metadata, ok := recorder.lists[0].(*metav1.PartialObjectMetadataList)
+				if assert.True(t, ok, "%T", recorder.lists[0]) {
+					assert.Equal(t, test.gvk, metadata.GroupVersionKind())
+				}
+			}
+		})
+	}
+}
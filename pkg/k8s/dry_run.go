@@ -0,0 +1,19 @@
+package k8s
+
+import (
+	"context"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a copy of the context marking k8s write calls as dry run
+// dry run calls are defaulted and validated by the api server and admission webhooks without being persisted
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun returns true if the context marks k8s write calls as dry run
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
@@ -1,10 +1,13 @@
 package k8s
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+)
 
 type MetaDataDto struct {
-	Name      string `json:"name"`
-	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name" validate:"required,dns1123label"`
+	Namespace string `json:"namespace,omitempty" validate:"dns1123label"`
 }
 
 func (workspace *MetaDataDto) ObjectMetaFromMetadataDto() metav1.ObjectMeta {
@@ -16,3 +19,12 @@ func (workspace *MetaDataDto) ObjectMetaFromMetadataDto() metav1.ObjectMeta {
 		Namespace: workspace.Namespace,
 	}
 }
+
+// NamespacedNameFromString parses namespaced name in the form of namespace/name
+// names without namespace are in the default namespace
+func NamespacedNameFromString(value string) ObjectKey {
+	if parts := strings.SplitN(value, "/", 2); len(parts) == 2 {
+		return ObjectKey{Namespace: parts[0], Name: parts[1]}
+	}
+	return ObjectKey{Namespace: "default", Name: value}
+}
@@ -0,0 +1,43 @@
+// Package fake provides in memory k8s clients for testing handlers and services without a cluster
+package fake
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	metricsFake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientFake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// NewClientService returns in memory k8s client holding the given objects
+// kotal resources and k8s core types are registered in its scheme
+func NewClientService(objs ...client.Object) k8s.K8sClientServiceInterface {
+	return clientFake.NewClientBuilder().WithScheme(k8s.NewScheme()).WithObjects(objs...).Build()
+}
+
+type clientsetService struct {
+	clientset        kubernetes.Interface
+	metricsClientset metrics.Interface
+}
+
+// NewClientsetService returns in memory clientsets holding the given objects
+func NewClientsetService(objs ...runtime.Object) k8s.ClientsetServiceInterface {
+	return clientsetService{
+		clientset:        kubernetesFake.NewSimpleClientset(objs...),
+		metricsClientset: metricsFake.NewSimpleClientset(),
+	}
+}
+
+// Clientset returns the in memory clientset regardless of the context
+func (service clientsetService) Clientset(ctx context.Context) (kubernetes.Interface, error) {
+	return service.clientset, nil
+}
+
+// MetricsClientset returns the in memory metrics clientset regardless of the context
+func (service clientsetService) MetricsClientset(ctx context.Context) (metrics.Interface, error) {
+	return service.metricsClientset, nil
+}
@@ -0,0 +1,180 @@
+package k8s
+
+import (
+	"context"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ImpersonationLocalsKey is the fiber locals key holding the impersonation config
+// used by websocket handlers which can't access the request user context
+const ImpersonationLocalsKey = "impersonation"
+
+// maxImpersonatedClients is the max number of cached impersonated clients per cluster
+const maxImpersonatedClients = 256
+
+type impersonationKey struct{}
+
+// WithImpersonation returns a copy of the context carrying the impersonation config
+// k8s calls made using this context are authorized by the cluster RBAC as the impersonated user
+func WithImpersonation(ctx context.Context, impersonation rest.ImpersonationConfig) context.Context {
+	return context.WithValue(ctx, impersonationKey{}, impersonation)
+}
+
+// ImpersonationFromContext returns the impersonation config carried by the context
+func ImpersonationFromContext(ctx context.Context) (rest.ImpersonationConfig, bool) {
+	impersonation, ok := ctx.Value(impersonationKey{}).(rest.ImpersonationConfig)
+	return impersonation, ok
+}
+
+// impersonatedClients are the cluster clients impersonating one user, each created once on first use
+type impersonatedClients struct {
+	config *rest.Config
+
+	lock             sync.Mutex
+	client           client.Client
+	clientset        kubernetes.Interface
+	metricsClientset metrics.Interface
+	watchClient      client.WithWatch
+}
+
+// impersonationCacheKey returns unique key for the impersonated user, groups and extra
+func impersonationCacheKey(impersonation rest.ImpersonationConfig) string {
+	extra := make([]string, 0, len(impersonation.Extra))
+	for name, values := range impersonation.Extra {
+		extra = append(extra, name+"="+strings.Join(values, ","))
+	}
+	sort.Strings(extra)
+
+	return impersonation.UserName + "|" + strings.Join(impersonation.Groups, ",") + "|" + strings.Join(extra, ";")
+}
+
+// impersonatedConfig returns copy of the cluster REST config impersonating the user
+func (cluster *Cluster) impersonatedConfig(impersonation rest.ImpersonationConfig) *rest.Config {
+	impersonated := rest.CopyConfig(cluster.config)
+	impersonated.Impersonate = impersonation
+
+	return impersonated
+}
+
+// impersonated returns the cached clients of the cluster impersonating the user
+// the cache is reset once it holds maxImpersonatedClients users
+func (cluster *Cluster) impersonated(impersonation rest.ImpersonationConfig) *impersonatedClients {
+	key := impersonationCacheKey(impersonation)
+
+	cluster.impersonatedClientsLock.Lock()
+	defer cluster.impersonatedClientsLock.Unlock()
+
+	if clients, ok := cluster.impersonatedClients[key]; ok {
+		return clients
+	}
+
+	if len(cluster.impersonatedClients) >= maxImpersonatedClients {
+		cluster.impersonatedClients = map[string]*impersonatedClients{}
+	}
+	clients := &impersonatedClients{config: cluster.impersonatedConfig(impersonation)}
+	cluster.impersonatedClients[key] = clients
+
+	return clients
+}
+
+// impersonatedClient returns cached controller-runtime client of the cluster impersonating the user
+func (cluster *Cluster) impersonatedClient(impersonation rest.ImpersonationConfig) (client.Client, error) {
+	clients := cluster.impersonated(impersonation)
+
+	clients.lock.Lock()
+	defer clients.lock.Unlock()
+
+	if clients.client == nil {
+		opts, err := cluster.clientOptions()
+		if err != nil {
+			return nil, err
+		}
+
+		clients.client, err = client.New(clients.config, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return clients.client, nil
+}
+
+// impersonatedClientset returns cached clientset of the cluster impersonating the user
+func (cluster *Cluster) impersonatedClientset(impersonation rest.ImpersonationConfig) (kubernetes.Interface, error) {
+	clients := cluster.impersonated(impersonation)
+
+	clients.lock.Lock()
+	defer clients.lock.Unlock()
+
+	if clients.clientset == nil {
+		clientset, err := kubernetes.NewForConfig(clients.config)
+		if err != nil {
+			return nil, err
+		}
+		clients.clientset = clientset
+	}
+
+	return clients.clientset, nil
+}
+
+// impersonatedMetricsClientset returns cached metrics client of the cluster impersonating the user
+func (cluster *Cluster) impersonatedMetricsClientset(impersonation rest.ImpersonationConfig) (metrics.Interface, error) {
+	clients := cluster.impersonated(impersonation)
+
+	clients.lock.Lock()
+	defer clients.lock.Unlock()
+
+	if clients.metricsClientset == nil {
+		metricsClientset, err := metrics.NewForConfig(clients.config)
+		if err != nil {
+			return nil, err
+		}
+		clients.metricsClientset = metricsClientset
+	}
+
+	return clients.metricsClientset, nil
+}
+
+// impersonatedWatchClient returns cached watching client of the cluster impersonating the user
+func (cluster *Cluster) impersonatedWatchClient(impersonation rest.ImpersonationConfig) (client.WithWatch, error) {
+	clients := cluster.impersonated(impersonation)
+
+	clients.lock.Lock()
+	defer clients.lock.Unlock()
+
+	if clients.watchClient == nil {
+		opts, err := cluster.clientOptions()
+		if err != nil {
+			return nil, err
+		}
+
+		clients.watchClient, err = client.NewWithWatch(clients.config, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return clients.watchClient, nil
+}
+
+// ClientsetFor returns the clientset to be used for the given context
+// the clientset of the cluster selected by the context, impersonating the user if the context carries impersonation config
+func ClientsetFor(ctx context.Context) (kubernetes.Interface, error) {
+	cluster, err := clusterFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	impersonation, ok := ImpersonationFromContext(ctx)
+	if !ok {
+		return cluster.Clientset()
+	}
+
+	return cluster.impersonatedClientset(impersonation)
+}
@@ -0,0 +1,54 @@
+package k8s
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/rest"
+	"testing"
+)
+
+func TestImpersonatedClientsAreCached(t *testing.T) {
+	setTestClusters(t)
+
+	cluster, err := clusterFor(context.Background())
+	if !assert.Nil(t, err) {
+		return
+	}
+	// skips api discovery of the unreachable test cluster
+	cluster.mapper = meta.NewDefaultRESTMapper(nil)
+
+	alice := WithImpersonation(context.Background(), rest.ImpersonationConfig{UserName: "alice", Groups: []string{"dev"}})
+	aliceAgain := WithImpersonation(context.Background(), rest.ImpersonationConfig{UserName: "alice", Groups: []string{"dev"}})
+	bob := WithImpersonation(context.Background(), rest.ImpersonationConfig{UserName: "bob", Groups: []string{"dev"}})
+	aliceElsewhere := WithImpersonation(context.Background(), rest.ImpersonationConfig{
+		UserName: "alice",
+		Groups:   []string{"dev"},
+		Extra:    map[string][]string{"scopes": {"read"}},
+	})
+
+	clientFors := map[string]func(ctx context.Context) (interface{}, error){
+		"clientset": func(ctx context.Context) (interface{}, error) { return ClientsetFor(ctx) },
+		"metrics":   func(ctx context.Context) (interface{}, error) { return MetricsClientsetFor(ctx) },
+		"watch":     func(ctx context.Context) (interface{}, error) { return watchClientFor(ctx) },
+		"client":    func(ctx context.Context) (interface{}, error) { return clientFor(ctx) },
+	}
+
+	for name, clientFor := range clientFors {
+		t.Run(name, func(t *testing.T) {
+			first, err := clientFor(alice)
+			assert.Nil(t, err)
+			second, err := clientFor(aliceAgain)
+			assert.Nil(t, err)
+			assert.Same(t, first, second)
+
+			other, err := clientFor(bob)
+			assert.Nil(t, err)
+			assert.NotSame(t, first, other)
+
+			other, err = clientFor(aliceElsewhere)
+			assert.Nil(t, err)
+			assert.NotSame(t, first, other)
+		})
+	}
+}
@@ -1,33 +1,38 @@
 package k8s
 
 import (
-	"github.com/kotalco/api/pkg/configs"
+	"context"
 	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
-	"sync"
 )
 
-var metricsClientset *metrics.Clientset
-var metricsClientsetOnce sync.Once
+// MetricsClientset returns metrics client of the cluster created once
+func (cluster *Cluster) MetricsClientset() (*metrics.Clientset, error) {
+	cluster.metricsClientsetLock.Lock()
+	defer cluster.metricsClientsetLock.Unlock()
 
-// MetricsClientset create k8s metrics client once
-func MetricsClientset() *metrics.Clientset {
-	var err error
-	metricsClientsetOnce.Do(func() {
-		metricsClientset, err = NewMetricsClientset()
+	if cluster.metricsClientset == nil {
+		metricsClientset, err := metrics.NewForConfig(cluster.config)
 		if err != nil {
-			// TODO: Don't panic
-			panic(err)
+			return nil, err
 		}
-	})
-	return metricsClientset
+		cluster.metricsClientset = metricsClientset
+	}
+
+	return cluster.metricsClientset, nil
 }
 
-// NewMetricsClientset returns metrics client
-func NewMetricsClientset() (*metrics.Clientset, error) {
-	config, err := configs.KubeConfig()
+// MetricsClientsetFor returns the metrics client to be used for the given context
+// the client of the cluster selected by the context, impersonating the user if the context carries impersonation config
+func MetricsClientsetFor(ctx context.Context) (metrics.Interface, error) {
+	cluster, err := clusterFor(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return metrics.NewForConfig(config)
+	impersonation, ok := ImpersonationFromContext(ctx)
+	if !ok {
+		return cluster.MetricsClientset()
+	}
+
+	return cluster.impersonatedMetricsClientset(impersonation)
 }
@@ -0,0 +1,22 @@
+package k8s
+
+import (
+	"github.com/kotalco/api/pkg/metrics"
+	"k8s.io/apimachinery/pkg/runtime"
+	"time"
+)
+
+// observe records the latency and error of k8s api server call started at the given time
+// reads served by the informer cache aren't api server calls, so they aren't recorded
+func observe(operation string, obj runtime.Object, start time.Time, err error) {
+	metrics.ObserveK8sRequest(operation, kindOf(obj), time.Since(start), err)
+}
+
+// kindOf returns the kind of the object, lists are recorded as their items kind
+func kindOf(obj runtime.Object) string {
+	groupKind, err := groupKindFor(obj)
+	if err != nil {
+		return "Unknown"
+	}
+	return groupKind.Kind
+}
@@ -0,0 +1,45 @@
+package k8s
+
+import (
+	"github.com/kotalco/api/pkg/configs"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+	"strings"
+)
+
+// OperatorDeployment returns kotal operator deployment set by kubernetes.operatorDeployment in the form of namespace/name
+func OperatorDeployment() ObjectKey {
+	return NamespacedNameFromString(configs.Get().Kubernetes.OperatorDeployment)
+}
+
+// KotalGroupVersions returns the group versions of kotal custom resources registered in the scheme sorted by group
+func KotalGroupVersions() []schema.GroupVersion {
+	groupVersions := []schema.GroupVersion{}
+	for _, groupVersion := range scheme.PrioritizedVersionsAllGroups() {
+		if strings.HasSuffix(groupVersion.Group, kotalGroupSuffix) {
+			groupVersions = append(groupVersions, groupVersion)
+		}
+	}
+
+	sort.Slice(groupVersions, func(i, j int) bool {
+		return groupVersions[i].String() < groupVersions[j].String()
+	})
+
+	return groupVersions
+}
+
+// KotalKinds returns the kinds of kotal custom resources of the group version sorted by name
+// kinds are the registered types having list type, other registered types are options and watch events
+func KotalKinds(groupVersion schema.GroupVersion) []string {
+	types := scheme.KnownTypes(groupVersion)
+
+	kinds := []string{}
+	for kind := range types {
+		if _, ok := types[kind+"List"]; ok {
+			kinds = append(kinds, kind)
+		}
+	}
+	sort.Strings(kinds)
+
+	return kinds
+}
@@ -0,0 +1,19 @@
+package k8s
+
+import (
+	"context"
+)
+
+type resourceVersionKey struct{}
+
+// WithResourceVersion returns a copy of the context conditioning k8s deletes on the given resource version
+// the api server rejects deleting objects modified since that version with conflict error
+func WithResourceVersion(ctx context.Context, resourceVersion string) context.Context {
+	return context.WithValue(ctx, resourceVersionKey{}, resourceVersion)
+}
+
+// ResourceVersionFromContext returns the resource version k8s deletes are conditioned on
+func ResourceVersionFromContext(ctx context.Context) (string, bool) {
+	resourceVersion, ok := ctx.Value(resourceVersionKey{}).(string)
+	return resourceVersion, ok
+}
@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/configs"
+	"time"
+)
+
+// Timeout returns the deadline duration of the k8s operation like get, list or create, zero means no deadline
+// get and list calls use kubernetes.getTimeout and kubernetes.listTimeout, and all the writes use kubernetes.writeTimeout
+func Timeout(operation string) time.Duration {
+	config := configs.Get().Kubernetes
+
+	switch operation {
+	case "get":
+		return config.GetTimeout.Duration
+	case "list":
+		return config.ListTimeout.Duration
+	default:
+		return config.WriteTimeout.Duration
+	}
+}
+
+// WithTimeout returns context with the deadline of the k8s operation, see Timeout
+// it is applied to the k8s client calls, and must be used by the clientset calls like discovery, metrics and token reviews
+// earlier deadline of the caller context is kept, and no deadline is set if the operation timeout is zero
+func WithTimeout(ctx context.Context, operation string) (context.Context, context.CancelFunc) {
+	timeout := Timeout(operation)
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	for operation, timeout := range map[string]time.Duration{
+		"get":    10 * time.Second,
+		"list":   30 * time.Second,
+		"create": 30 * time.Second,
+		"delete": 30 * time.Second,
+	} {
+		ctx, cancel := WithTimeout(context.Background(), operation)
+		deadline, ok := ctx.Deadline()
+		cancel()
+		if assert.True(t, ok, operation) {
+			assert.WithinDuration(t, time.Now().Add(timeout), deadline, time.Second, operation)
+		}
+	}
+
+	// earlier deadline of the caller is kept
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Second)
+	defer cancelParent()
+	ctx, cancel := WithTimeout(parent, "list")
+	defer cancel()
+	parentDeadline, _ := parent.Deadline()
+	deadline, _ := ctx.Deadline()
+	assert.Equal(t, parentDeadline, deadline)
+}
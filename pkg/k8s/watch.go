@@ -0,0 +1,36 @@
+package k8s
+
+import (
+	"context"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// watchClientFor returns the client watching changes for the given context
+// the client of the cluster selected by the context, impersonating the user if the context carries impersonation config
+func watchClientFor(ctx context.Context) (client.WithWatch, error) {
+	cluster, err := clusterFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if impersonation, ok := ImpersonationFromContext(ctx); ok {
+		return cluster.impersonatedWatchClient(impersonation)
+	}
+
+	cluster.watchClientLock.Lock()
+	defer cluster.watchClientLock.Unlock()
+
+	if cluster.watchClient == nil {
+		opts, err := cluster.clientOptions()
+		if err != nil {
+			return nil, err
+		}
+
+		cluster.watchClient, err = client.NewWithWatch(cluster.config, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cluster.watchClient, nil
+}
@@ -1,20 +1,16 @@
 package logger
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"os"
 	"reflect"
 	"runtime"
 	"strings"
 )
 
-const (
-	envLogLevel  = "LOG_LEVEL"
-	envLogOutput = "LOG_OUTPUT"
-)
-
 var (
 	log logger
 )
@@ -29,9 +25,17 @@ type logger struct {
 }
 
 func init() {
+	if err := Configure("info", "stdout"); err != nil {
+		panic(err)
+	}
+}
+
+// Configure replaces the logger by logger of the given level and output
+// level is debug, info or error, output is stdout, stderr or file path
+func Configure(level, output string) error {
 	logConfig := zap.Config{
-		OutputPaths: []string{getOutput()},
-		Level:       zap.NewAtomicLevelAt(getLevel()),
+		OutputPaths: []string{output},
+		Level:       zap.NewAtomicLevelAt(getLevel(level)),
 		Encoding:    "json",
 		EncoderConfig: zapcore.EncoderConfig{
 			LevelKey:     "level",
@@ -43,14 +47,16 @@ func init() {
 		},
 	}
 
-	var err error
-	if log.log, err = logConfig.Build(); err != nil {
-		panic(err)
+	built, err := logConfig.Build()
+	if err != nil {
+		return err
 	}
+	log.log = built
+	return nil
 }
 
-func getLevel() zapcore.Level {
-	switch strings.ToLower(os.Getenv(envLogLevel)) {
+func getLevel(level string) zapcore.Level {
+	switch strings.ToLower(level) {
 	case "debug":
 		return zap.DebugLevel
 	case "info":
@@ -62,14 +68,6 @@ func getLevel() zapcore.Level {
 	}
 }
 
-func getOutput() string {
-	output := os.Getenv(envLogOutput)
-	if output == "" {
-		return "stdout"
-	}
-	return output
-}
-
 func GetLogger() Logger {
 	return log
 }
@@ -91,13 +89,21 @@ func Info(msg string, tags ...zap.Field) {
 	log.log.Sync()
 }
 
+// Error logs the error of the given location, function or name
+// cancelled calls like calls of disconnected clients aren't failures, they're logged at debug level
 func Error(location interface{}, err error, tags ...zap.Field) {
 	tags = append(tags, zap.NamedError("error", err))
-	switch location.(type) {
-	case string:
-		log.log.Error(location.(string), tags...)
-	default:
-		log.log.Error(errorLocation(location), tags...)
+	level := zap.ErrorLevel
+	if errors.Is(err, context.Canceled) {
+		level = zap.DebugLevel
+	}
+
+	msg, ok := location.(string)
+	if !ok {
+		msg = errorLocation(location)
+	}
+	if entry := log.log.Check(level, msg); entry != nil {
+		entry.Write(tags...)
 	}
 	log.log.Sync()
 }
@@ -0,0 +1,104 @@
+// Package metrics holds the prometheus metrics of the api server
+// like api calls rates and latencies, k8s calls latencies and errors, open websockets and nodes JSON-RPC probe failures
+// metrics are served by separate http server, see NewServer
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"strconv"
+	"time"
+)
+
+// namespace is the prefix of all the api server metrics
+const namespace = "kotal_api"
+
+// Registry holds the api server metrics and the go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of api calls by route, protocol, resource and response status code",
+	}, []string{"method", "route", "protocol", "resource", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of api calls by route, protocol and resource",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "protocol", "resource"})
+
+	k8sRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "k8s_request_duration_seconds",
+		Help:      "Latency of k8s api server calls by operation and kind",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "kind"})
+
+	k8sRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "k8s_request_errors_total",
+		Help:      "Number of failed k8s api server calls by operation, kind and k8s status reason",
+	}, []string{"operation", "kind", "reason"})
+
+	websockets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websockets_open",
+		Help:      "Number of open websockets by protocol and stream like logs, status or stats",
+	}, []string{"protocol", "stream"})
+
+	rpcProbeFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_probe_failures_total",
+		Help:      "Number of failed nodes JSON-RPC calls made to stream nodes stats by protocol and method",
+	}, []string{"protocol", "method"})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		requests,
+		requestDuration,
+		k8sRequestDuration,
+		k8sRequestErrors,
+		websockets,
+		rpcProbeFailures,
+	)
+}
+
+// ObserveRequest records api call served by the given route template like /api/v1/ethereum/nodes/:name
+// protocol and resource are the permission group and resource of the route, empty if the route has no permission
+func ObserveRequest(method, route, protocol, resource string, status int, duration time.Duration) {
+	requests.WithLabelValues(method, route, protocol, resource, strconv.Itoa(status)).Inc()
+	requestDuration.WithLabelValues(method, route, protocol, resource).Observe(duration.Seconds())
+}
+
+// ObserveK8sRequest records k8s api server call like get or list of the given kind
+// failed calls are counted by their k8s status reason like NotFound, or Unknown if it's not k8s status error
+func ObserveK8sRequest(operation, kind string, duration time.Duration, err error) {
+	k8sRequestDuration.WithLabelValues(operation, kind).Observe(duration.Seconds())
+	if err == nil {
+		return
+	}
+
+	reason := apiErrors.ReasonForError(err)
+	if reason == "" {
+		reason = "Unknown"
+	}
+	k8sRequestErrors.WithLabelValues(operation, kind, string(reason)).Inc()
+}
+
+// WebsocketOpened counts the open websocket until the returned function is called
+func WebsocketOpened(protocol, stream string) (closed func()) {
+	gauge := websockets.WithLabelValues(protocol, stream)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// RPCProbeFailed counts failed node JSON-RPC call
+func RPCProbeFailed(protocol, method string) {
+	rpcProbeFailures.WithLabelValues(protocol, method).Inc()
+}
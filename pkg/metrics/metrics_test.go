@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"io"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestObserveRequest(t *testing.T) {
+	ObserveRequest(http.MethodGet, "/api/v1/ethereum/nodes/:name", "ethereum", "nodes", http.StatusOK, time.Millisecond)
+	ObserveRequest(http.MethodGet, "/api/v1/ethereum/nodes/:name", "ethereum", "nodes", http.StatusOK, time.Millisecond)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(requests.WithLabelValues(http.MethodGet, "/api/v1/ethereum/nodes/:name", "ethereum", "nodes", "200")))
+}
+
+func TestObserveK8sRequest(t *testing.T) {
+	ObserveK8sRequest("get", "Node", time.Millisecond, nil)
+	ObserveK8sRequest("get", "Node", time.Millisecond, apiErrors.NewNotFound(schema.GroupResource{Resource: "nodes"}, "my-node"))
+	ObserveK8sRequest("get", "Node", time.Millisecond, errors.New("connection refused"))
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(k8sRequestErrors.WithLabelValues("get", "Node", "NotFound")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(k8sRequestErrors.WithLabelValues("get", "Node", "Unknown")))
+}
+
+func TestWebsocketOpened(t *testing.T) {
+	gauge := websockets.WithLabelValues("near", "stats")
+
+	closeFirst := WebsocketOpened("near", "stats")
+	closeSecond := WebsocketOpened("near", "stats")
+	assert.Equal(t, float64(2), testutil.ToFloat64(gauge))
+
+	closeFirst()
+	closeSecond()
+	assert.Equal(t, float64(0), testutil.ToFloat64(gauge))
+}
+
+func TestHandler(t *testing.T) {
+	RPCProbeFailed("polkadot", "system_health")
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	body, _ := io.ReadAll(recorder.Body)
+	assert.Contains(t, string(body), `kotal_api_rpc_probe_failures_total{method="system_health",protocol="polkadot"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import (
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const metricsPath = "/metrics"
+
+// Handler serves the registry metrics in prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// NewServer returns http server serving the metrics at /metrics
+// it listens on server.metricsPort (default :9090), separate from the api port so metrics aren't exposed with the api
+func NewServer() *http.Server {
+	port := configs.Get().Server.MetricsPort
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, Handler())
+
+	return &http.Server{Addr: port, Handler: mux}
+}
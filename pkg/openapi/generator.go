@@ -0,0 +1,502 @@
+package openapi
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/kotalco/api/pkg/patch"
+	"github.com/kotalco/api/pkg/shared"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	jsonContentType = "application/json"
+	// eventStreamContentType is the content type of server-sent events streams
+	eventStreamContentType = "text/event-stream"
+	// TotalCountHeader is the header carrying the total number of resources
+	TotalCountHeader = "X-Total-Count"
+	// LinkHeader is the header carrying the list pages urls
+	LinkHeader = "Link"
+	// ETagHeader is the header carrying the resource version of single resource
+	ETagHeader = "ETag"
+	// ClusterHeader is the header selecting the cluster of the resource
+	ClusterHeader = "X-Kotal-Cluster"
+	// ErrorSchema is the component name of the rest error schema
+	ErrorSchema = "RestErr"
+	// bearerAuth is the bearer token security scheme name
+	bearerAuth = "bearerAuth"
+)
+
+// Resource is a protocol resource served under its collection path
+// like ethereum nodes served under /api/v1/ethereum/nodes
+type Resource struct {
+	// Path is the resource collection path
+	Path string
+	// Tag groups the resource operations, usually the protocol name
+	Tag string
+	// Name is the human readable resource name used in operations summary
+	Name string
+	// Dto is the resource data transfer object
+	Dto interface{}
+	// Watch is true if the resource changes can be streamed using watch query parameter
+	Watch bool
+}
+
+// Endpoint is a single route which isn't a resource route like /healthz or /api/v1/config
+type Endpoint struct {
+	Method string
+	// Path is the endpoint fiber path
+	Path    string
+	Tag     string
+	Summary string
+	// Dto is the response data dto
+	Dto interface{}
+	// List is true if the response data is a page of dtos filtered and sorted by the query parameters
+	List bool
+	// Clustered is true if the endpoint is served by the cluster selected by the cluster header
+	Clustered bool
+	// Public is true if the endpoint doesn't require authentication
+	Public bool
+	// Parameters are the endpoint specific query parameters
+	Parameters []Parameter
+}
+
+// Generator generates OpenAPI document from routes of the registered resources and endpoints
+type Generator struct {
+	Info      Info
+	Resources []Resource
+	Endpoints []Endpoint
+	// BasePath is the path prefix of the resources like /api/v1
+	BasePath string
+	// ClusterPath is the path prefix serving BasePath routes by the cluster path parameter like /api/v1/clusters/:cluster
+	ClusterPath string
+	// ErrorDto is the dto returned by all failed operations
+	ErrorDto interface{}
+}
+
+// Generate creates OpenAPI document for routes matching registered resources and endpoints
+// routes served under ClusterPath are documented with the cluster path parameter instead of the cluster header
+// routes that don't belong to any resource or endpoint are ignored
+func (generator Generator) Generate(routes []*fiber.Route) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    generator.Info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []map[string][]string{{bearerAuth: {}}},
+	}
+
+	errorSchema := Ref(ErrorSchema)
+	if generator.ErrorDto != nil {
+		errorSchema = doc.Register(generator.ErrorDto)
+	}
+
+	tags := map[string]bool{}
+	for _, route := range routes {
+		path, clustered := generator.clusterRoute(route.Path)
+
+		var operation *Operation
+		if resource, suffix, ok := generator.resourceOf(path); ok {
+			if operation, ok = resourceOperation(doc, resource, route.Method, suffix, errorSchema); !ok {
+				continue
+			}
+		} else if endpoint, ok := generator.endpointOf(route.Method, path); ok {
+			operation = endpointOperation(doc, endpoint, errorSchema)
+		} else {
+			continue
+		}
+
+		if clustered {
+			path = generator.ClusterPath + strings.TrimPrefix(path, generator.BasePath)
+			generator.clusterOperation(operation)
+		}
+
+		item := doc.PathItem(openAPIPath(path))
+		// fiber registers HEAD for every GET route, keep the first registered operation
+		if item.Operation(route.Method) != nil {
+			continue
+		}
+		item.SetOperation(route.Method, operation)
+		tags[operation.Tags[0]] = true
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool {
+		return doc.Tags[i].Name < doc.Tags[j].Name
+	})
+
+	return doc
+}
+
+// clusterRoute returns the BasePath route of the route served under ClusterPath and true
+// other routes are returned as they are
+func (generator Generator) clusterRoute(path string) (string, bool) {
+	path = strings.TrimSuffix(path, "/")
+	if generator.ClusterPath == "" || !strings.HasPrefix(path, generator.ClusterPath+"/") {
+		return path, false
+	}
+	return generator.BasePath + strings.TrimPrefix(path, generator.ClusterPath), true
+}
+
+// endpointOf returns the endpoint of the route method and path
+func (generator Generator) endpointOf(method, path string) (Endpoint, bool) {
+	for _, endpoint := range generator.Endpoints {
+		if endpoint.Method == method && endpoint.Path == path {
+			return endpoint, true
+		}
+	}
+	return Endpoint{}, false
+}
+
+// resourceOf returns the resource owning the route path and the path suffix after the collection path
+func (generator Generator) resourceOf(path string) (Resource, string, bool) {
+	for _, resource := range generator.Resources {
+		if path == resource.Path {
+			return resource, "", true
+		}
+		if strings.HasPrefix(path, resource.Path+"/") {
+			return resource, strings.TrimPrefix(path, resource.Path), true
+		}
+	}
+	return Resource{}, "", false
+}
+
+// openAPIPath converts fiber path params like /:name to OpenAPI path params like /{name}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// resourceOperation describes the operation of http method on resource path suffix
+// following the api conventions for collection and single resource routes
+func resourceOperation(doc *Document, resource Resource, method, suffix string, errorSchema *Schema) (*Operation, bool) {
+	dtoSchema := doc.Register(resource.Dto)
+	single := &Schema{Type: "object", Properties: map[string]*Schema{"data": dtoSchema}}
+	list := &Schema{Type: "object", Properties: map[string]*Schema{
+		"data": {Type: "array", Items: dtoSchema},
+		"meta": doc.Register(shared.ListMeta{}),
+	}}
+	totalCount := map[string]Header{
+		TotalCountHeader: {Description: "total number of resources", Schema: &Schema{Type: "integer"}},
+	}
+	etag := map[string]Header{
+		ETagHeader: {Description: "resource version to be sent in If-Match header of updates and deletes", Schema: &Schema{Type: "string"}},
+	}
+
+	operation := &Operation{
+		Tags:       []string{resource.Tag},
+		Parameters: []Parameter{namespaceParameter(), clusterParameter()},
+		Responses:  map[string]*Response{},
+	}
+
+	segments := strings.Split(strings.TrimPrefix(suffix, "/"), "/")
+	if suffix != "" {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:     strings.TrimPrefix(segments[0], ":"),
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	switch {
+	case suffix == "" && method == http.MethodPost:
+		operation.Summary = fmt.Sprintf("Create %s", resource.Name)
+		operation.Parameters = append(operation.Parameters, dryRunParameter())
+		operation.RequestBody = jsonBody(dtoSchema)
+		operation.Responses["201"] = jsonResponse("created", single)
+	case suffix == "" && method == http.MethodHead:
+		operation.Summary = fmt.Sprintf("Count %ss", resource.Name)
+		operation.Parameters = append(operation.Parameters, selectorParameters()...)
+		operation.Responses["200"] = &Response{Description: "total count", Headers: totalCount}
+	case suffix == "" && method == http.MethodGet:
+		operation.Summary = fmt.Sprintf("List %ss", resource.Name)
+		operation.Parameters = append(operation.Parameters, pageParameters()...)
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:        "continue",
+			In:          "query",
+			Description: "next page token returned in meta continue of continue pagination",
+			Schema:      &Schema{Type: "string"},
+		})
+		operation.Parameters = append(operation.Parameters, selectorParameters()...)
+		operation.Parameters = append(operation.Parameters, sortParameters("createdAt")...)
+		response := jsonResponse("list", list)
+		response.Headers = map[string]Header{
+			TotalCountHeader: totalCount[TotalCountHeader],
+			LinkHeader:       {Description: "first, prev, next and last pages urls", Schema: &Schema{Type: "string"}},
+		}
+		if resource.Watch {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:        "watch",
+				In:          "query",
+				Description: "if true resources changes are streamed as server-sent events instead of listing them",
+				Schema:      &Schema{Type: "boolean"},
+			}, Parameter{
+				Name:        "resourceVersion",
+				In:          "query",
+				Description: "watch changes after the given resource version, Last-Event-ID header takes precedence",
+				Schema:      &Schema{Type: "string"},
+			})
+			response.Description = "list, or changes stream if watch is true"
+			response.Content[eventStreamContentType] = MediaType{Schema: watchEventSchema(single)}
+		}
+		operation.Responses["200"] = response
+	case len(segments) == 1 && method == http.MethodGet:
+		operation.Summary = fmt.Sprintf("Get %s", resource.Name)
+		response := jsonResponse("found", single)
+		response.Headers = etag
+		operation.Responses["200"] = response
+	case len(segments) == 1 && method == http.MethodPut:
+		operation.Summary = fmt.Sprintf("Update %s", resource.Name)
+		operation.Parameters = append(operation.Parameters, ifMatchParameter(), dryRunParameter())
+		operation.RequestBody = jsonBody(dtoSchema)
+		response := jsonResponse("updated", single)
+		response.Headers = etag
+		operation.Responses["200"] = response
+	case len(segments) == 1 && method == http.MethodPatch:
+		operation.Summary = fmt.Sprintf("Patch %s", resource.Name)
+		operation.Parameters = append(operation.Parameters, ifMatchParameter(), dryRunParameter())
+		operation.RequestBody = patchBody(dtoSchema)
+		response := jsonResponse("patched", single)
+		response.Headers = etag
+		operation.Responses["200"] = response
+	case len(segments) == 1 && method == http.MethodDelete:
+		operation.Summary = fmt.Sprintf("Delete %s", resource.Name)
+		operation.Parameters = append(operation.Parameters, ifMatchParameter(), dryRunParameter())
+		operation.Responses["204"] = &Response{Description: "deleted"}
+	case len(segments) == 2 && method == http.MethodGet:
+		operation.Summary = fmt.Sprintf("Stream %s %s over websocket", resource.Name, segments[1])
+		operation.Responses["101"] = &Response{Description: "switching protocols to websocket"}
+	default:
+		return nil, false
+	}
+
+	operation.OperationID = operationID(method, resource.Path+suffix)
+	operation.Responses["default"] = jsonResponse("error", errorSchema)
+
+	return operation, true
+}
+
+// endpointOperation describes the endpoint operation responding with its dto or page of dtos
+func endpointOperation(doc *Document, endpoint Endpoint, errorSchema *Schema) *Operation {
+	data := doc.Register(endpoint.Dto)
+	body := &Schema{Type: "object", Properties: map[string]*Schema{"data": data}}
+
+	operation := &Operation{
+		Tags:        []string{endpoint.Tag},
+		Summary:     endpoint.Summary,
+		OperationID: operationID(endpoint.Method, endpoint.Path),
+		Parameters:  []Parameter{},
+		Responses:   map[string]*Response{},
+	}
+	if endpoint.Clustered {
+		operation.Parameters = append(operation.Parameters, clusterParameter())
+	}
+	if endpoint.Public {
+		// empty security requirement allows anonymous calls
+		operation.Security = []map[string][]string{{}}
+	}
+
+	response := jsonResponse("ok", body)
+	if endpoint.List {
+		body.Properties["data"] = &Schema{Type: "array", Items: data}
+		body.Properties["meta"] = doc.Register(shared.ListMeta{})
+		operation.Parameters = append(operation.Parameters, pageParameters()...)
+		operation.Parameters = append(operation.Parameters, sortParameters("the listing order")...)
+		response.Headers = map[string]Header{
+			TotalCountHeader: {Description: "total number of dtos", Schema: &Schema{Type: "integer"}},
+			LinkHeader:       {Description: "first, prev, next and last pages urls", Schema: &Schema{Type: "string"}},
+		}
+	}
+	operation.Parameters = append(operation.Parameters, endpoint.Parameters...)
+
+	operation.Responses["200"] = response
+	operation.Responses["default"] = jsonResponse("error", errorSchema)
+
+	return operation
+}
+
+// clusterOperation replaces the operation cluster header by the cluster path parameter of ClusterPath routes
+func (generator Generator) clusterOperation(operation *Operation) {
+	parameters := []Parameter{{
+		Name:        strings.TrimPrefix(generator.ClusterPath[strings.LastIndex(generator.ClusterPath, "/")+1:], ":"),
+		In:          "path",
+		Description: "registered cluster name",
+		Required:    true,
+		Schema:      &Schema{Type: "string"},
+	}}
+	for _, parameter := range operation.Parameters {
+		if parameter.Name != ClusterHeader {
+			parameters = append(parameters, parameter)
+		}
+	}
+	operation.Parameters = parameters
+	operation.OperationID = clusterOperationID(operation.OperationID)
+}
+
+// operationID returns unique operation id of the route like getApiV1EthereumNodesName
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, word := range strings.Split(strings.Trim(path, "/"), "/") {
+		word = strings.TrimPrefix(word, ":")
+		if word == "" {
+			continue
+		}
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return id
+}
+
+// clusterOperationID returns the operation id of the ClusterPath route like getClusterApiV1EthereumNodesName
+func clusterOperationID(id string) string {
+	method := strings.IndexFunc(id, unicode.IsUpper)
+	if method == -1 {
+		return id + "Cluster"
+	}
+	return id[:method] + "Cluster" + id[method:]
+}
+
+func namespaceParameter() Parameter {
+	return Parameter{
+		Name:        "namespace",
+		In:          "query",
+		Description: "resource namespace, defaults to default namespace, list calls accept * for all namespaces",
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+// clusterParameter returns the cluster header parameter
+// resources of the cluster are served under /api/v1/clusters/{cluster} too
+func clusterParameter() Parameter {
+	return Parameter{
+		Name:        ClusterHeader,
+		In:          "header",
+		Description: "registered cluster name, defaults to the api server cluster, can be set using /api/v1/clusters/{cluster} path prefix too",
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+// pageParameters returns the page and limit parameters of the paginated lists
+func pageParameters() []Parameter {
+	return []Parameter{{
+		Name:        "page",
+		In:          "query",
+		Description: "zero based page index",
+		Schema:      &Schema{Type: "integer"},
+	}, {
+		Name:        "limit",
+		In:          "query",
+		Description: fmt.Sprintf("page size, defaults to %d and can't exceed %d, continue pagination is used if page is omitted", shared.PerPage(), shared.MaxPerPage()),
+		Schema:      &Schema{Type: "integer"},
+	}}
+}
+
+// sortParameters returns the sort and order parameters of the lists sorted by the given field by default
+func sortParameters(defaultField string) []Parameter {
+	return []Parameter{{
+		Name:        "sort",
+		In:          "query",
+		Description: fmt.Sprintf("dto field to sort by, defaults to %s", defaultField),
+		Schema:      &Schema{Type: "string"},
+	}, {
+		Name:        "order",
+		In:          "query",
+		Description: "sort direction, defaults to desc if sort is omitted and asc otherwise",
+		Schema:      &Schema{Type: "string", Enum: []string{"asc", "desc"}},
+	}}
+}
+
+// selectorParameters returns the list and count selectors parameters
+// dto scalar fields like network=goerli are filters too but aren't listed as parameters
+func selectorParameters() []Parameter {
+	return []Parameter{{
+		Name:        "labelSelector",
+		In:          "query",
+		Description: "kubernetes label selector like app=my-node, dto fields can be used as filters too like client=besu,geth",
+		Schema:      &Schema{Type: "string"},
+	}, {
+		Name:        "fieldSelector",
+		In:          "query",
+		Description: "kubernetes field selector like metadata.name=my-node",
+		Schema:      &Schema{Type: "string"},
+	}}
+}
+
+func ifMatchParameter() Parameter {
+	return Parameter{
+		Name:        "If-Match",
+		In:          "header",
+		Description: "resource ETag, the call fails with 409 conflict if the resource has been modified",
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+func dryRunParameter() Parameter {
+	return Parameter{
+		Name:        "dryRun",
+		In:          "query",
+		Description: "if true the request is defaulted and validated by the operator without being persisted",
+		Schema:      &Schema{Type: "boolean"},
+	}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{jsonContentType: {Schema: schema}},
+	}
+}
+
+// watchEventSchema returns the schema of the watch stream events data carrying the dto schema
+func watchEventSchema(schema *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"type":            {Type: "string", Enum: []string{"ADDED", "MODIFIED", "DELETED", "BOOKMARK"}},
+			"resourceVersion": {Type: "string"},
+			"object":          schema,
+		},
+	}
+}
+
+// patchBody returns json merge patch body of the dto schema and json patch body of the patch operations
+func patchBody(schema *Schema) *RequestBody {
+	operation := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"op":    {Type: "string", Enum: []string{"add", "remove", "replace", "move", "copy", "test"}},
+			"path":  {Type: "string"},
+			"from":  {Type: "string"},
+			"value": {},
+		},
+	}
+
+	return &RequestBody{
+		Required: true,
+		Content: map[string]MediaType{
+			patch.MergePatchType: {Schema: schema},
+			patch.JSONPatchType:  {Schema: &Schema{Type: "array", Items: operation}},
+		},
+	}
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{
+		Description: description,
+		Content:     map[string]MediaType{jsonContentType: {Schema: schema}},
+	}
+}
@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"embed"
+	"github.com/gofiber/fiber/v2"
+	"path/filepath"
+	"sync"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// swaggerUI are the swagger-ui-dist v5.18.2 assets of the docs page
+// they're served by the api, so the docs page doesn't load third party scripts
+//
+//go:embed swagger-ui/swagger-ui.css swagger-ui/swagger-ui-bundle.js
+var swaggerUI embed.FS
+
+// SpecHandler returns handler serving the document generated from the app routes
+// the document is generated on first request after all routes are registered
+func SpecHandler(app *fiber.App, generator Generator) fiber.Handler {
+	var once sync.Once
+	var doc *Document
+
+	return func(c *fiber.Ctx) error {
+		once.Do(func() {
+			doc = generator.Generate(Routes(app))
+		})
+		return c.JSON(doc)
+	}
+}
+
+// DocsHandler returns handler serving interactive docs page for the spec served at /openapi.json next to it
+func DocsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Type("html", "utf-8")
+		return c.Send(docsPage)
+	}
+}
+
+// DocsAssetsHandler returns handler serving the docs page assets by the :file param
+func DocsAssetsHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		file := c.Params("file")
+		asset, err := swaggerUI.ReadFile("swagger-ui/" + file)
+		if err != nil {
+			return fiber.ErrNotFound
+		}
+
+		c.Type(filepath.Ext(file), "utf-8")
+		return c.Send(asset)
+	}
+}
+
+// Routes returns all the app routes except the middlewares
+// middlewares are registered to all the methods stacks, and the api serves no TRACE calls
+// so the paths of TRACE stack routes are the middlewares paths
+func Routes(app *fiber.App) []*fiber.Route {
+	middlewares := map[string]bool{}
+	for _, stack := range app.Stack() {
+		for _, route := range stack {
+			if route.Method == fiber.MethodTrace {
+				middlewares[route.Path] = true
+			}
+		}
+	}
+
+	routes := []*fiber.Route{}
+	for _, stack := range app.Stack() {
+		for _, route := range stack {
+			if !middlewares[route.Path] {
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes
+}
@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const componentsRef = "#/components/schemas/"
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Ref returns reference schema to the named component schema
+func Ref(name string) *Schema {
+	return &Schema{Ref: componentsRef + name}
+}
+
+// Register adds the schema of the dto type and all the struct types it references to the components
+// returns reference schema to the dto component
+func (doc *Document) Register(dto interface{}) *Schema {
+	return doc.schemaOf(reflect.TypeOf(dto))
+}
+
+// schemaOf returns the schema of type t, struct types are registered as components and referenced
+func (doc *Document) schemaOf(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := doc.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := float64(0)
+		return &Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: doc.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: doc.schemaOf(t.Elem())}
+	case reflect.Struct:
+		// times and structs marshaled by themselves like durations are written as strings
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Implements(marshalerType) {
+			return &Schema{Type: "string"}
+		}
+		name := t.Name()
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			// register before adding properties to support recursive types
+			doc.Components.Schemas[name] = schema
+			doc.addProperties(schema, t)
+		}
+		return Ref(name)
+	default:
+		return &Schema{}
+	}
+}
+
+// addProperties adds the struct json fields to the schema properties
+// fields of embedded structs are flattened like encoding/json does
+func (doc *Document) addProperties(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, ok := JSONName(field)
+		if !ok {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			doc.addProperties(schema, embedded)
+			continue
+		}
+
+		schema.Properties[name] = doc.schemaOf(field.Type)
+	}
+}
+
+// JSONName returns the json name of the struct field
+// returns empty name for embedded structs without json tag which are flattened
+// returns false for ignored and unexported fields
+func JSONName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if field.Anonymous && name == "" {
+		return "", true
+	}
+	if field.PkgPath != "" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
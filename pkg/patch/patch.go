@@ -0,0 +1,58 @@
+// Package patch applies JSON merge patch and JSON patch documents to resources dtos
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	jsonpatch "github.com/evanphx/json-patch"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"mime"
+	"reflect"
+)
+
+const (
+	// MergePatchType is JSON merge patch content type as defined in RFC 7386
+	MergePatchType = "application/merge-patch+json"
+	// JSONPatchType is JSON patch content type as defined in RFC 6902
+	JSONPatchType = "application/json-patch+json"
+)
+
+// Apply applies the patch document of the given content type to the dto json representation
+// the dto is replaced by the patched representation, fields removed by the patch are left with zero values
+// which are reset to the operator defaults when the dto is translated to the resource spec
+func Apply(contentType string, document []byte, dto interface{}) *restErrors.RestErr {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	original, err := json.Marshal(dto)
+	if err != nil {
+		return restErrors.NewInternalServerError("can't marshal resource")
+	}
+
+	var patched []byte
+	switch mediaType {
+	case MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, document)
+	case JSONPatchType:
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(document)
+		if err == nil {
+			patched, err = operations.Apply(original)
+		}
+	default:
+		return restErrors.NewUnsupportedMediaTypeError(fmt.Sprintf("content type must be %s or %s", MergePatchType, JSONPatchType))
+	}
+	if err != nil {
+		return restErrors.NewBadRequestError(fmt.Sprintf("can't apply patch: %s", err))
+	}
+
+	value := reflect.ValueOf(dto).Elem()
+	value.Set(reflect.Zero(value.Type()))
+	if err := json.Unmarshal(patched, dto); err != nil {
+		return restErrors.NewBadRequestError(fmt.Sprintf("invalid patched resource: %s", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package patch
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+type testImport struct {
+	SecretName string `json:"secretName"`
+}
+
+type testDto struct {
+	Client    string      `json:"client,omitempty"`
+	P2PPort   uint        `json:"p2pPort,omitempty"`
+	RPC       *bool       `json:"rpc,omitempty"`
+	Hosts     []string    `json:"hosts,omitempty"`
+	Bootnodes *[]string   `json:"bootnodes,omitempty"`
+	Import    *testImport `json:"import,omitempty"`
+}
+
+func newTestDto() *testDto {
+	rpc := true
+	bootnodes := []string{"enode://a", "enode://b"}
+	return &testDto{
+		Client:    "geth",
+		P2PPort:   30303,
+		RPC:       &rpc,
+		Hosts:     []string{"*"},
+		Bootnodes: &bootnodes,
+		Import:    &testImport{SecretName: "key"},
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	dto := newTestDto()
+
+	err := Apply(MergePatchType, []byte(`{"p2pPort": 30304, "hosts": null, "import": null}`), dto)
+
+	assert.Nil(t, err)
+	assert.EqualValues(t, 30304, dto.P2PPort)
+	assert.Equal(t, "geth", dto.Client)
+	assert.True(t, *dto.RPC)
+	assert.Nil(t, dto.Hosts)
+	assert.Nil(t, dto.Import)
+	assert.Equal(t, []string{"enode://a", "enode://b"}, *dto.Bootnodes)
+}
+
+func TestApplyMergePatchWithParameters(t *testing.T) {
+	dto := newTestDto()
+
+	err := Apply(MergePatchType+"; charset=utf-8", []byte(`{"client": "besu"}`), dto)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "besu", dto.Client)
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	dto := newTestDto()
+
+	document := `[
+		{"op": "replace", "path": "/client", "value": "nethermind"},
+		{"op": "remove", "path": "/rpc"},
+		{"op": "remove", "path": "/bootnodes/0"},
+		{"op": "add", "path": "/hosts/-", "value": "localhost"}
+	]`
+	err := Apply(JSONPatchType, []byte(document), dto)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "nethermind", dto.Client)
+	assert.Nil(t, dto.RPC)
+	assert.Equal(t, []string{"enode://b"}, *dto.Bootnodes)
+	assert.Equal(t, []string{"*", "localhost"}, dto.Hosts)
+	assert.Equal(t, "key", dto.Import.SecretName)
+}
+
+func TestApplyErrors(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		document    string
+		status      int
+	}{
+		{"unsupported content type", "application/json", `{"client": "besu"}`, http.StatusUnsupportedMediaType},
+		{"missing content type", "", `{"client": "besu"}`, http.StatusUnsupportedMediaType},
+		{"invalid merge patch", MergePatchType, `{"client":`, http.StatusBadRequest},
+		{"invalid json patch", JSONPatchType, `{"op": "remove"}`, http.StatusBadRequest},
+		{"missing json patch path", JSONPatchType, `[{"op": "remove", "path": "/import/password"}]`, http.StatusBadRequest},
+		{"invalid patched type", MergePatchType, `{"p2pPort": "30304"}`, http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dto := newTestDto()
+
+			err := Apply(c.contentType, []byte(c.document), dto)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, c.status, err.Status)
+		})
+	}
+}
@@ -0,0 +1,362 @@
+// Package query parses list endpoints query parameters
+// label and field selectors are pushed down to k8s as list options
+// dto filters like network=goerli and sorting are applied to the listed resources dtos
+// lists are paginated by page index, or by k8s continue tokens if limit is sent without page
+package query
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/openapi"
+	"github.com/kotalco/api/pkg/shared"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// LabelSelectorQuery is k8s label selector like app=my-app,env in (prod, staging)
+	LabelSelectorQuery = "labelSelector"
+	// FieldSelectorQuery is k8s field selector like metadata.name=my-node
+	FieldSelectorQuery = "fieldSelector"
+	// SortQuery is the dto field json name the list is sorted by
+	SortQuery = "sort"
+	// OrderQuery is the sort direction, asc or desc
+	OrderQuery = "order"
+	// PageQuery is the zero based page index
+	PageQuery = "page"
+	// LimitQuery is the page size
+	LimitQuery = "limit"
+	// ContinueQuery is the opaque token of the next page returned by continue pagination
+	ContinueQuery = "continue"
+
+	defaultSortField = "createdAt"
+	ascending        = "asc"
+	descending       = "desc"
+)
+
+// reserved are query parameters which aren't dto filters even if the dto has a field with the same name
+var reserved = map[string]bool{
+	LabelSelectorQuery: true,
+	FieldSelectorQuery: true,
+	SortQuery:          true,
+	OrderQuery:         true,
+	PageQuery:          true,
+	LimitQuery:         true,
+	ContinueQuery:      true,
+	"namespace":        true,
+	"watch":            true,
+	"resourceVersion":  true,
+	"dryRun":           true,
+}
+
+// Query is the parsed list query
+type Query struct {
+	// ListOptions are the label and field selectors pushed down to k8s
+	ListOptions []client.ListOption
+	// Page is the zero based page index of page pagination
+	Page uint
+	// Limit is the page size
+	Limit uint
+	// Continue is the token of the requested page of continue pagination
+	Continue   string
+	cursor     bool
+	filters    []filter
+	sortField  *dtoField
+	descending bool
+}
+
+// filter matches dtos whose field value is any of the given values
+type filter struct {
+	field  dtoField
+	values map[string]bool
+}
+
+// Parse parses list query parameters of the given dto type
+// dto scalar fields are used as filters, comma separated values match any of them like client=besu,geth
+// unknown parameters are ignored, invalid selectors, filter values and sort fields are rejected with bad request error
+// lists are sorted by creation time descending by default
+// continue pagination returns resources in k8s order, so it can't be combined with sort and order
+func Parse(c *fiber.Ctx, dto interface{}) (*Query, *restErrors.RestErr) {
+	query := &Query{Limit: shared.PerPage()}
+	dtoFields := fieldsOf(reflect.TypeOf(dto))
+
+	if value := c.Query(LimitQuery); value != "" {
+		limit, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || limit == 0 || uint(limit) > shared.MaxPerPage() {
+			return nil, restErrors.NewBadRequestError(fmt.Sprintf("%s must be between 1 and %d", LimitQuery, shared.MaxPerPage()))
+		}
+		query.Limit = uint(limit)
+	}
+
+	// invalid page index defaults to the first page
+	page, _ := strconv.ParseUint(c.Query(PageQuery), 10, 32)
+	query.Page = uint(page)
+
+	query.Continue = c.Query(ContinueQuery)
+	query.cursor = query.Continue != "" || c.Query(LimitQuery) != "" && c.Query(PageQuery) == ""
+
+	if query.cursor && c.Query(PageQuery) != "" {
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("%s can't be combined with %s", ContinueQuery, PageQuery))
+	}
+	if query.cursor && (c.Query(SortQuery) != "" || c.Query(OrderQuery) != "") {
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("%s and %s require %s query parameter", SortQuery, OrderQuery, PageQuery))
+	}
+
+	if value := c.Query(LabelSelectorQuery); value != "" {
+		selector, err := labels.Parse(value)
+		if err != nil {
+			return nil, restErrors.NewBadRequestError(fmt.Sprintf("invalid %s: %s", LabelSelectorQuery, err))
+		}
+		query.ListOptions = append(query.ListOptions, matchingLabels{selector})
+	}
+
+	if value := c.Query(FieldSelectorQuery); value != "" {
+		selector, err := fields.ParseSelector(value)
+		if err != nil {
+			return nil, restErrors.NewBadRequestError(fmt.Sprintf("invalid %s: %s", FieldSelectorQuery, err))
+		}
+		query.ListOptions = append(query.ListOptions, client.MatchingFieldsSelector{Selector: selector})
+	}
+
+	var parseErr *restErrors.RestErr
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		name := string(key)
+		field, ok := dtoFields[name]
+		if !ok || reserved[name] || parseErr != nil {
+			return
+		}
+
+		values := map[string]bool{}
+		for _, v := range strings.Split(string(value), ",") {
+			normalized, err := field.normalize(strings.TrimSpace(v))
+			if err != nil {
+				parseErr = restErrors.NewBadRequestError(fmt.Sprintf("invalid %s filter: %s", name, err))
+				return
+			}
+			values[normalized] = true
+		}
+		query.filters = append(query.filters, filter{field: field, values: values})
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	if query.cursor {
+		return query, nil
+	}
+
+	sortName, order := c.Query(SortQuery), c.Query(OrderQuery)
+	if sortName == "" {
+		sortName = defaultSortField
+		if order == "" {
+			order = descending
+		}
+	}
+
+	if sortField, ok := dtoFields[sortName]; ok {
+		query.sortField = &sortField
+	} else if sortName != defaultSortField {
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("can't sort by %s", sortName))
+	}
+
+	switch order {
+	case "", ascending:
+	case descending:
+		query.descending = true
+	default:
+		return nil, restErrors.NewBadRequestError(fmt.Sprintf("%s must be %s or %s", OrderQuery, ascending, descending))
+	}
+
+	return query, nil
+}
+
+// Cursor returns true if the list is paginated using k8s limit and continue tokens instead of page index
+func (query *Query) Cursor() bool {
+	return query.cursor
+}
+
+// PageListOptions returns the list options fetching the requested page
+// the list options fetch all resources matching the selectors for page pagination
+// because the page is sliced after filtering and sorting the dtos
+func (query *Query) PageListOptions() []client.ListOption {
+	if !query.cursor {
+		return query.ListOptions
+	}
+
+	options := append([]client.ListOption{}, query.ListOptions...)
+	return append(options, client.Limit(query.Limit), client.Continue(query.Continue))
+}
+
+// HasFilters returns true if the query has dto filters which must be applied to the listed dtos
+func (query *Query) HasFilters() bool {
+	return len(query.filters) != 0
+}
+
+// Apply filters and sorts the dtos list in place, list must be pointer to slice of dtos
+func (query *Query) Apply(list interface{}) {
+	slice := reflect.ValueOf(list).Elem()
+
+	filtered := reflect.MakeSlice(slice.Type(), 0, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		if query.matches(slice.Index(i)) {
+			filtered = reflect.Append(filtered, slice.Index(i))
+		}
+	}
+
+	if query.sortField != nil {
+		field := *query.sortField
+		sort.SliceStable(filtered.Interface(), func(i, j int) bool {
+			a, b := field.valueOf(filtered.Index(i)), field.valueOf(filtered.Index(j))
+			if query.descending {
+				return field.less(b, a)
+			}
+			return field.less(a, b)
+		})
+	}
+
+	slice.Set(filtered)
+}
+
+// matches returns true if the dto matches all the query filters
+func (query *Query) matches(dto reflect.Value) bool {
+	for _, f := range query.filters {
+		if !f.values[f.field.format(f.field.valueOf(dto))] {
+			return false
+		}
+	}
+	return true
+}
+
+// dtoField is dto scalar field which can be used for filtering and sorting
+type dtoField struct {
+	index []int
+	kind  reflect.Kind
+}
+
+// fieldsOf returns the dto scalar fields keyed by their json name
+// fields of embedded structs are flattened like encoding/json does
+func fieldsOf(dtoType reflect.Type) map[string]dtoField {
+	result := map[string]dtoField{}
+
+	for i := 0; i < dtoType.NumField(); i++ {
+		field := dtoType.Field(i)
+
+		name, ok := openapi.JSONName(field)
+		if !ok {
+			continue
+		}
+
+		if name == "" {
+			if field.Type.Kind() == reflect.Struct {
+				for embeddedName, embedded := range fieldsOf(field.Type) {
+					embedded.index = append([]int{i}, embedded.index...)
+					result[embeddedName] = embedded
+				}
+			}
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch kind := fieldType.Kind(); kind {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			result[name] = dtoField{index: []int{i}, kind: kind}
+		}
+	}
+
+	return result
+}
+
+// valueOf returns the field value of the dto, nil pointers are returned as zero values
+func (field dtoField) valueOf(dto reflect.Value) reflect.Value {
+	value := dto.FieldByIndex(field.index)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return reflect.Zero(value.Type().Elem())
+		}
+		return value.Elem()
+	}
+	return value
+}
+
+// format returns the field value string representation which filter values are compared to
+func (field dtoField) format(value reflect.Value) string {
+	switch field.kind {
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	default:
+		return value.String()
+	}
+}
+
+// normalize parses the filter value according to the field type and returns its string representation
+// so filter values like archive=1 and archive=true are equivalent
+func (field dtoField) normalize(value string) (string, error) {
+	switch field.kind {
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a boolean", value)
+		}
+		return strconv.FormatBool(parsed), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s is not an integer", value)
+		}
+		return strconv.FormatInt(parsed, 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a positive integer", value)
+		}
+		return strconv.FormatUint(parsed, 10), nil
+	default:
+		return value, nil
+	}
+}
+
+// less returns true if field value a is less than b
+func (field dtoField) less(a, b reflect.Value) bool {
+	switch field.kind {
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return a.Uint() < b.Uint()
+	default:
+		return a.String() < b.String()
+	}
+}
+
+// matchingLabels selects resources matching the label selector in addition to the labels required by the service
+// unlike client.MatchingLabelsSelector it doesn't replace selectors like kotal secrets or workspaces labels
+type matchingLabels struct {
+	labels.Selector
+}
+
+// ApplyToList adds the label selector requirements to the list options label selector
+func (m matchingLabels) ApplyToList(opts *client.ListOptions) {
+	if opts.LabelSelector == nil {
+		opts.LabelSelector = m.Selector
+		return
+	}
+	requirements, _ := m.Selector.Requirements()
+	opts.LabelSelector = opts.LabelSelector.Add(requirements...)
+}
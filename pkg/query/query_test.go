@@ -0,0 +1,169 @@
+package query
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+type testTime struct {
+	CreatedAt string `json:"createdAt"`
+}
+
+type testDto struct {
+	testTime
+	Name    string   `json:"name"`
+	Network string   `json:"network"`
+	Client  string   `json:"client"`
+	RPC     *bool    `json:"rpc"`
+	P2PPort uint     `json:"p2pPort"`
+	Hosts   []string `json:"hosts"`
+}
+
+func newTestDtos() []testDto {
+	rpc := true
+	return []testDto{
+		{testTime{"2022-01-01T00:00:00.000Z"}, "a", "goerli", "besu", &rpc, 30303, nil},
+		{testTime{"2022-01-03T00:00:00.000Z"}, "b", "mainnet", "geth", nil, 30304, nil},
+		{testTime{"2022-01-02T00:00:00.000Z"}, "c", "goerli", "geth", &rpc, 30305, nil},
+	}
+}
+
+// queryNames parses the query, applies it to the test dtos and returns the resulting dtos names
+func queryNames(t *testing.T, rawQuery string) ([]string, int) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		listQuery, err := Parse(c, testDto{})
+		if err != nil {
+			return c.Status(err.Status).JSON(err)
+		}
+		dtos := newTestDtos()
+		listQuery.Apply(&dtos)
+		names := []string{}
+		for _, dto := range dtos {
+			names = append(names, dto.Name)
+		}
+		return c.JSON(names)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/?"+rawQuery, nil))
+	assert.Nil(t, err)
+
+	var names []string
+	if resp.StatusCode == http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		assert.Nil(t, json.Unmarshal(body, &names))
+	}
+
+	return names, resp.StatusCode
+}
+
+func TestQuery(t *testing.T) {
+	testCases := []struct {
+		query  string
+		status int
+		names  []string
+	}{
+		{"", http.StatusOK, []string{"b", "c", "a"}},
+		{"network=goerli", http.StatusOK, []string{"c", "a"}},
+		{"client=besu,geth&network=goerli", http.StatusOK, []string{"c", "a"}},
+		{"rpc=true", http.StatusOK, []string{"c", "a"}},
+		{"rpc=0", http.StatusOK, []string{"b"}},
+		{"p2pPort=30304", http.StatusOK, []string{"b"}},
+		{"network=ropsten", http.StatusOK, []string{}},
+		{"unknown=value&namespace=default", http.StatusOK, []string{"b", "c", "a"}},
+		{"sort=name", http.StatusOK, []string{"a", "b", "c"}},
+		{"sort=name&order=desc", http.StatusOK, []string{"c", "b", "a"}},
+		{"sort=client&order=asc", http.StatusOK, []string{"a", "b", "c"}},
+		{"sort=p2pPort&order=desc", http.StatusOK, []string{"c", "b", "a"}},
+		{"order=asc", http.StatusOK, []string{"a", "c", "b"}},
+		{"rpc=yes", http.StatusBadRequest, nil},
+		{"p2pPort=-1", http.StatusBadRequest, nil},
+		{"sort=hosts", http.StatusBadRequest, nil},
+		{"order=random", http.StatusBadRequest, nil},
+		{"labelSelector=app%20in%20(", http.StatusBadRequest, nil},
+		{"fieldSelector=metadata.name", http.StatusBadRequest, nil},
+		{"page=1&limit=2&sort=name", http.StatusOK, []string{"a", "b", "c"}},
+		{"limit=2", http.StatusOK, []string{"a", "b", "c"}},
+		{"limit=0", http.StatusBadRequest, nil},
+		{"limit=101", http.StatusBadRequest, nil},
+		{"limit=2&sort=name", http.StatusBadRequest, nil},
+		{"continue=token&page=1", http.StatusBadRequest, nil},
+	}
+
+	for _, testCase := range testCases {
+		names, status := queryNames(t, testCase.query)
+		assert.Equal(t, testCase.status, status, testCase.query)
+		assert.Equal(t, testCase.names, names, testCase.query)
+	}
+}
+
+func TestSelectorsListOptions(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		listQuery, err := Parse(c, testDto{})
+		assert.Nil(t, err)
+		assert.Len(t, listQuery.ListOptions, 2)
+		assert.False(t, listQuery.HasFilters())
+		return nil
+	})
+
+	_, err := app.Test(httptest.NewRequest(http.MethodGet, "/?labelSelector=app%3Dmy-node&fieldSelector=metadata.name%3Dmy-node", nil))
+	assert.Nil(t, err)
+}
+
+func TestPagination(t *testing.T) {
+	testCases := []struct {
+		query   string
+		cursor  bool
+		page    uint
+		limit   uint
+		options int
+	}{
+		{"", false, 0, 10, 0},
+		{"page=2", false, 2, 10, 0},
+		{"page=2&limit=20", false, 2, 20, 0},
+		{"page=invalid", false, 0, 10, 0},
+		{"limit=20", true, 0, 20, 2},
+		{"continue=token", true, 0, 10, 2},
+		{"limit=20&labelSelector=app%3Dmy-node", true, 0, 20, 3},
+	}
+
+	for _, testCase := range testCases {
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			listQuery, err := Parse(c, testDto{})
+			assert.Nil(t, err, testCase.query)
+			assert.Equal(t, testCase.cursor, listQuery.Cursor(), testCase.query)
+			assert.Equal(t, testCase.page, listQuery.Page, testCase.query)
+			assert.Equal(t, testCase.limit, listQuery.Limit, testCase.query)
+			assert.Len(t, listQuery.PageListOptions(), testCase.options, testCase.query)
+			return nil
+		})
+
+		_, err := app.Test(httptest.NewRequest(http.MethodGet, "/?"+testCase.query, nil))
+		assert.Nil(t, err)
+	}
+}
+
+func TestLabelSelectorKeepsRequiredLabels(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		listQuery, err := Parse(c, testDto{})
+		assert.Nil(t, err)
+
+		listOptions := &client.ListOptions{}
+		listOptions.ApplyOptions(append([]client.ListOption{client.HasLabels{"kotal.io/key-type"}}, listQuery.ListOptions...))
+
+		assert.Equal(t, "app=my-node,kotal.io/key-type", listOptions.LabelSelector.String())
+		return nil
+	})
+
+	_, err := app.Test(httptest.NewRequest(http.MethodGet, "/?labelSelector=app%3Dmy-node", nil))
+	assert.Nil(t, err)
+}
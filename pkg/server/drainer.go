@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"sync"
+)
+
+// Drainer tracks the long running streams like websockets and watches, and stops them when the server shuts down
+// in-flight api calls aren't affected, they're drained by the http server shutdown
+type Drainer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// lock guards closed, so no stream is tracked once the drainer started waiting for the tracked streams
+	lock    sync.Mutex
+	closed  bool
+	streams sync.WaitGroup
+}
+
+// NewDrainer returns drainer tracking no streams
+func NewDrainer() *Drainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Drainer{ctx: ctx, cancel: cancel}
+}
+
+// Context returns context that is done once the server starts draining
+func (drainer *Drainer) Context() context.Context {
+	return drainer.ctx
+}
+
+// Draining returns true if the server has started draining
+func (drainer *Drainer) Draining() bool {
+	return drainer.ctx.Err() != nil
+}
+
+// Track counts the stream as open until the returned function is called
+// returns false if the server has started draining, the stream isn't tracked and shouldn't be served
+func (drainer *Drainer) Track() (func(), bool) {
+	drainer.lock.Lock()
+	defer drainer.lock.Unlock()
+
+	if drainer.closed {
+		return func() {}, false
+	}
+
+	drainer.streams.Add(1)
+	var once sync.Once
+	return func() { once.Do(drainer.streams.Done) }, true
+}
+
+// Drain cancels the drainer context and waits for the tracked streams to close
+// returns the context error if the streams didn't close before the context is done
+func (drainer *Drainer) Drain(ctx context.Context) error {
+	drainer.lock.Lock()
+	drainer.closed = true
+	drainer.cancel()
+	drainer.lock.Unlock()
+
+	closed := make(chan struct{})
+	go func() {
+		drainer.streams.Wait()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	drainer := NewDrainer()
+	assert.False(t, drainer.Draining())
+
+	// tracked stream closes once the drainer context is done
+	done, tracked := drainer.Track()
+	assert.True(t, tracked)
+	go func() {
+		<-drainer.Context().Done()
+		done()
+	}()
+
+	assert.Nil(t, drainer.Drain(context.Background()))
+	assert.True(t, drainer.Draining())
+	// closing the stream again doesn't break the count
+	done()
+	assert.Nil(t, drainer.Drain(context.Background()))
+
+	// streams opened while draining aren't tracked
+	done, tracked = drainer.Track()
+	assert.False(t, tracked)
+	done()
+	assert.Nil(t, drainer.Drain(context.Background()))
+}
+
+func TestTrackWhileDraining(t *testing.T) {
+	drainer := NewDrainer()
+	done, _ := drainer.Track()
+
+	drained := make(chan error)
+	go func() { drained <- drainer.Drain(context.Background()) }()
+
+	// streams tracked concurrently with draining are either waited for or rejected
+	<-drainer.Context().Done()
+	for i := 0; i < 100; i++ {
+		untrack, tracked := drainer.Track()
+		assert.False(t, tracked)
+		untrack()
+	}
+	done()
+	assert.Nil(t, <-drained)
+}
+
+func TestDrainTimeout(t *testing.T) {
+	drainer := NewDrainer()
+	done, _ := drainer.Track()
+	defer done()
+
+	// stream ignoring the drainer context keeps it open until the drain timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, drainer.Drain(ctx), context.DeadlineExceeded)
+}
@@ -1,39 +1,90 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/kotalco/api/pkg/configs"
 	"github.com/kotalco/api/pkg/logger"
+	"net"
 	"os"
 	"os/signal"
+	"syscall"
 )
 
 // StartServerWithGracefulShutdown function for starting server with a graceful shutdown.
 // Create channel for idle connections.
-//check if  Received an interrupt signal, shutdown.
+//check if  Received an interrupt or termination signal, shutdown.
 // Error if closing listeners, or context timeout
 // Run server.
 // Error if  Run server with reason
-func StartServerWithGracefulShutdown(a *fiber.App) {
+// api is served over TLS using the given TLS config, or over plain http if it's nil
+// on shutdown the drainer streams are closed first, then in-flight calls are drained, both within server.drainTimeout
+func StartServerWithGracefulShutdown(a *fiber.App, tlsConfig *tls.Config, drainer *Drainer) {
 	idleConnsClosed := make(chan struct{})
 
 	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt) // Catch OS signals.
-		<-sigint
-		if err := a.Shutdown(); err != nil {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM) // Catch OS signals, kubernetes sends SIGTERM.
+		<-signals
+
+		ctx, cancel := context.WithTimeout(context.Background(), configs.Get().Server.DrainTimeout.Duration)
+		defer cancel()
+
+		if err := shutdown(ctx, a, drainer); err != nil {
 			go logger.Info(fmt.Sprintf("Oops... Server is not shutting down! Reason:  %v", err))
 		}
 		close(idleConnsClosed)
 	}()
 
-	port := os.Getenv("KOTAL_API_SERVER_PORT")
-	if port == "" {
-		port = configs.EnvironmentConf["KOTAL_API_SERVER_PORT"]
-	}
-	if err := a.Listen(port); err != nil {
+	if err := listen(a, configs.Get().Server.Port, tlsConfig); err != nil {
 		go logger.Info(fmt.Sprintf("Oops... Server is not running! Reason: %v", err))
 	}
 	<-idleConnsClosed
 }
+
+// shutdown closes the drainer streams and shuts the app down, it returns once they're done or the context is done
+// streams are closed before the listeners, so websockets clients get close frames and reconnect to other replicas
+// the app is shut down even if the streams didn't close in time, so the listeners stop accepting connections
+func shutdown(ctx context.Context, a *fiber.App, drainer *Drainer) error {
+	var drainErr error
+	if drainer != nil {
+		if err := drainer.Drain(ctx); err != nil {
+			drainErr = fmt.Errorf("streams are still open: %w", err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Shutdown()
+	}()
+
+	var shutdownErr error
+	select {
+	case shutdownErr = <-done:
+	case <-ctx.Done():
+		shutdownErr = fmt.Errorf("in-flight calls are still running: %w", ctx.Err())
+	}
+
+	switch {
+	case drainErr != nil && shutdownErr != nil:
+		return fmt.Errorf("%v, %w", drainErr, shutdownErr)
+	case drainErr != nil:
+		return drainErr
+	default:
+		return shutdownErr
+	}
+}
+
+// listen serves the app on the given address over TLS if the TLS config isn't nil
+func listen(a *fiber.App, address string, tlsConfig *tls.Config) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	return a.Listener(listener)
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestShutdownAfterDrainTimeout(t *testing.T) {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(listener)
+
+	dial := func() error {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err == nil {
+			conn.Close()
+		}
+		return err
+	}
+	assert.Eventually(t, func() bool { return dial() == nil }, 5*time.Second, 10*time.Millisecond)
+
+	// the tracked stream never closes
+	drainer := NewDrainer()
+	_, tracked := drainer.Track()
+	assert.True(t, tracked)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err = shutdown(ctx, app, drainer)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "streams are still open")
+	}
+
+	// the app is shut down even though the streams didn't close
+	assert.Eventually(t, func() bool { return dial() != nil }, 5*time.Second, 10*time.Millisecond)
+}
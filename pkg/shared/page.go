@@ -1,6 +1,16 @@
 package shared
 
-const PerPage = 2
+import "github.com/kotalco/api/pkg/configs"
+
+// PerPage returns the default page size set by pagination.defaultLimit config
+func PerPage() uint {
+	return configs.Get().Pagination.DefaultLimit
+}
+
+// MaxPerPage returns the max page size which can be requested using limit query parameter set by pagination.maxLimit config
+func MaxPerPage() uint {
+	return configs.Get().Pagination.MaxLimit
+}
 
 type Pagination struct {
 	Page int
@@ -10,15 +20,20 @@ type Pagination struct {
 // given a length and page index
 // it returns 0,0 [] if length or page is 0
 func Page(length, page uint) (start, end uint) {
+	return PageOfSize(length, page, PerPage())
+}
+
+// PageOfSize returns page start and end index of the given page size
+func PageOfSize(length, page, size uint) (start, end uint) {
 	if length == 0 {
 		return
 	}
 
-	start = page * PerPage
+	start = page * size
 	if start > length {
 		start = length
 	}
-	end = start + PerPage
+	end = start + size
 	if end > length {
 		end = length
 	}
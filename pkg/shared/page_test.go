@@ -39,3 +39,30 @@ func TestPage(t *testing.T) {
 		}
 	}
 }
+
+func TestPageOfSize(t *testing.T) {
+	testCases := []struct {
+		len   uint
+		page  uint
+		size  uint
+		start uint
+		end   uint
+	}{
+		{0, 0, 5, 0, 0},
+		{12, 0, 5, 0, 5},
+		{12, 2, 5, 10, 12},
+		{12, 3, 5, 12, 12},
+		{12, 0, 100, 0, 12},
+	}
+
+	for _, testCase := range testCases {
+		gotStart, gotEnd := PageOfSize(testCase.len, testCase.page, testCase.size)
+
+		if testCase.start != gotStart {
+			t.Errorf("expected start to be %d, got %d", testCase.start, gotStart)
+		}
+		if testCase.end != gotEnd {
+			t.Errorf("expected end to be %d, got %d", testCase.end, gotEnd)
+		}
+	}
+}
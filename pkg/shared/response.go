@@ -2,6 +2,21 @@ package shared
 
 type response struct {
 	Data interface{} `json:"data"`
+	Meta *ListMeta   `json:"meta,omitempty"`
+}
+
+// ListMeta is the pagination metadata of list responses
+type ListMeta struct {
+	// Total is the number of resources matching the list query, omitted if it's unknown
+	Total *int `json:"total,omitempty"`
+	// Page is the zero based page index of page pagination
+	Page *uint `json:"page,omitempty"`
+	// Limit is the page size
+	Limit uint `json:"limit"`
+	// Continue is the opaque token of the next page of continue pagination
+	Continue string `json:"continue,omitempty"`
+	// Remaining is the number of resources after the page of continue pagination if it's known
+	Remaining *int64 `json:"remaining,omitempty"`
 }
 
 type SuccessMessage struct {
@@ -9,5 +24,10 @@ type SuccessMessage struct {
 }
 
 func NewResponse(data interface{}) interface{} {
-	return response{data}
+	return response{Data: data}
+}
+
+// NewListResponse returns list response carrying the page data and its pagination metadata
+func NewListResponse(data interface{}, meta ListMeta) interface{} {
+	return response{Data: data, Meta: &meta}
 }
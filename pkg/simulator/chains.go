@@ -0,0 +1,160 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// chainHeight is the chain height when the simulator starts
+	chainHeight = 10_000_000
+	// chainBehind is the number of blocks new nodes are behind the chain head
+	chainBehind = 100_000
+	// syncRate is the number of blocks per second syncing nodes download
+	syncRate = 40
+	// maxPeers is the number of peers nodes connect to
+	maxPeers = 25
+)
+
+// blockTime is the time between chain blocks
+var blockTime = 12 * time.Second
+
+// chain is the chain seen by a node since it was first called
+type chain struct {
+	started time.Time
+}
+
+// progress returns the node current block, the chain highest block and the node peers count
+func (chain chain) progress() (current, highest, peers uint64) {
+	elapsed := time.Since(chain.started)
+
+	highest = chainHeight + uint64(elapsed/blockTime)
+	current = chainHeight - chainBehind + uint64(elapsed.Seconds()*syncRate)
+	if current > highest {
+		current = highest
+	}
+
+	peers = uint64(elapsed / (2 * time.Second))
+	if peers > maxPeers {
+		peers = maxPeers
+	}
+
+	return
+}
+
+// syncing returns true if the node hasn't reached the chain head
+func (chain chain) syncing() bool {
+	current, highest, _ := chain.progress()
+	return current < highest
+}
+
+// rpcMethods are the scripted JSON-RPC methods of the protocols nodes stats
+var rpcMethods = map[string]func(chain) interface{}{
+	// ethereum
+	"eth_syncing": func(chain chain) interface{} {
+		current, highest, _ := chain.progress()
+		if !chain.syncing() {
+			return false
+		}
+		return map[string]string{
+			"currentBlock": fmt.Sprintf("0x%x", current),
+			"highestBlock": fmt.Sprintf("0x%x", highest),
+		}
+	},
+	"net_peerCount": func(chain chain) interface{} {
+		_, _, peers := chain.progress()
+		return fmt.Sprintf("0x%x", peers)
+	},
+	// polkadot
+	"system_syncState": func(chain chain) interface{} {
+		current, highest, _ := chain.progress()
+		return map[string]uint64{
+			"startingBlock": chainHeight - chainBehind,
+			"currentBlock":  current,
+			"highestBlock":  highest,
+		}
+	},
+	"system_health": func(chain chain) interface{} {
+		_, _, peers := chain.progress()
+		return map[string]interface{}{
+			"peers":           peers,
+			"isSyncing":       chain.syncing(),
+			"shouldHavePeers": true,
+		}
+	},
+	// near
+	"status": func(chain chain) interface{} {
+		current, _, _ := chain.progress()
+		return map[string]interface{}{
+			"sync_info": map[string]interface{}{
+				"latest_block_height":   current,
+				"earliest_block_height": chainHeight - chainBehind,
+				"syncing":               chain.syncing(),
+			},
+		}
+	},
+	"network_info": func(chain chain) interface{} {
+		_, _, peers := chain.progress()
+		return map[string]uint64{
+			"num_active_peers":       peers,
+			"peer_max_count":         maxPeers,
+			"sent_bytes_per_sec":     peers * 1024,
+			"received_bytes_per_sec": peers * 4096,
+		}
+	},
+}
+
+// chains answers nodes JSON-RPC calls with scripted chain stats
+// every node by host name syncs its own chain since it's first called
+type chains struct {
+	lock   sync.Mutex
+	chains map[string]chain
+}
+
+func newChains() *chains {
+	return &chains{chains: map[string]chain{}}
+}
+
+// chain returns the chain of the node by host name
+func (chains *chains) chain(host string) chain {
+	chains.lock.Lock()
+	defer chains.lock.Unlock()
+
+	nodeChain, ok := chains.chains[host]
+	if !ok {
+		nodeChain = chain{started: time.Now()}
+		chains.chains[host] = nodeChain
+	}
+
+	return nodeChain
+}
+
+// RoundTrip answers JSON-RPC call sent to the node
+func (chains *chains) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := struct {
+		ID     int    `json:"id"`
+		Method string `json:"method"`
+	}{}
+	if err := json.NewDecoder(req.Body).Decode(&call); err != nil {
+		return nil, err
+	}
+	req.Body.Close()
+
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      call.ID,
+	}
+	if method, ok := rpcMethods[call.Method]; ok {
+		response["result"] = method(chains.chain(req.URL.Hostname()))
+	} else {
+		response["error"] = map[string]interface{}{
+			"code":    -32601,
+			"message": fmt.Sprintf("the method %s does not exist/is not available", call.Method),
+		}
+	}
+
+	return jsonResponse(req, http.StatusOK, response)
+}
@@ -0,0 +1,212 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/kotalco/api/pkg/k8s"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	kubernetesFake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+// kubernetesVersion is the version reported by the simulated api server
+var kubernetesVersion = version.Info{
+	Major:      "1",
+	Minor:      "23",
+	GitVersion: "v1.23.3+simulator",
+	Platform:   "simulator",
+}
+
+// clientset is in memory clientset serving pods logs, kotal resources discovery and the api server version from the simulated cluster
+type clientset struct {
+	*kubernetesFake.Clientset
+	restClient *rest.RESTClient
+}
+
+// newClientset returns clientset streaming the logs of the pods held by the given client
+func newClientset(podsClient client.Client) *clientset {
+	restClient, err := rest.RESTClientFor(&rest.Config{
+		Host:    "http://simulator",
+		APIPath: "/api",
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &corev1.SchemeGroupVersion,
+			NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		},
+		Transport: &apiServer{client: podsClient},
+	})
+	if err != nil {
+		// config is static, it can't be invalid
+		panic(err)
+	}
+
+	fakeClientset := kubernetesFake.NewSimpleClientset()
+	fakeClientset.Resources = kotalResources()
+
+	return &clientset{
+		Clientset:  fakeClientset,
+		restClient: restClient,
+	}
+}
+
+// kotalResources returns the kotal custom resources served by the simulated api server
+func kotalResources() []*metav1.APIResourceList {
+	resources := []*metav1.APIResourceList{}
+	for _, groupVersion := range k8s.KotalGroupVersions() {
+		list := &metav1.APIResourceList{GroupVersion: groupVersion.String()}
+		for _, kind := range k8s.KotalKinds(groupVersion) {
+			plural, _ := meta.UnsafeGuessKindToResource(groupVersion.WithKind(kind))
+			list.APIResources = append(list.APIResources, metav1.APIResource{
+				Name:       plural.Resource,
+				Kind:       kind,
+				Namespaced: true,
+				Verbs:      metav1.Verbs{"create", "delete", "get", "list", "patch", "update", "watch"},
+			})
+		}
+		resources = append(resources, list)
+	}
+	return resources
+}
+
+// CoreV1 returns core client streaming simulated pods logs
+func (clientset *clientset) CoreV1() corev1client.CoreV1Interface {
+	return &coreV1{CoreV1Interface: clientset.Clientset.CoreV1(), restClient: clientset.restClient}
+}
+
+// Discovery returns discovery client reporting the simulated api server version
+func (clientset *clientset) Discovery() discovery.DiscoveryInterface {
+	return &discoveryClient{DiscoveryInterface: clientset.Clientset.Discovery(), restClient: clientset.restClient}
+}
+
+type coreV1 struct {
+	corev1client.CoreV1Interface
+	restClient *rest.RESTClient
+}
+
+// Pods returns pods client streaming simulated pods logs
+func (core *coreV1) Pods(namespace string) corev1client.PodInterface {
+	return &podsClient{PodInterface: core.CoreV1Interface.Pods(namespace), namespace: namespace, restClient: core.restClient}
+}
+
+type podsClient struct {
+	corev1client.PodInterface
+	namespace  string
+	restClient *rest.RESTClient
+}
+
+// GetLogs returns request streaming the pod scripted logs
+func (pods *podsClient) GetLogs(name string, opts *corev1.PodLogOptions) *rest.Request {
+	return pods.restClient.Get().Namespace(pods.namespace).Resource("pods").Name(name).SubResource("log").VersionedParams(opts, scheme.ParameterCodec)
+}
+
+type discoveryClient struct {
+	discovery.DiscoveryInterface
+	restClient *rest.RESTClient
+}
+
+// RESTClient returns rest client of the simulated api server
+func (discoveryClient *discoveryClient) RESTClient() rest.Interface {
+	return discoveryClient.restClient
+}
+
+// ServerVersion returns the simulated api server version
+func (discoveryClient *discoveryClient) ServerVersion() (*version.Info, error) {
+	info := kubernetesVersion
+	return &info, nil
+}
+
+// apiServer serves the api server calls the in memory clients can't serve
+type apiServer struct {
+	client client.Client
+}
+
+// RoundTrip serves /version, kotal resources discovery and pods logs calls
+func (server *apiServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/version" {
+		return jsonResponse(req, http.StatusOK, kubernetesVersion)
+	}
+
+	// /apis/{group}/{version}
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) == 3 && parts[0] == "apis" {
+		for _, resources := range kotalResources() {
+			if resources.GroupVersion == parts[1]+"/"+parts[2] {
+				return jsonResponse(req, http.StatusOK, resources)
+			}
+		}
+	}
+
+	// /api/v1/namespaces/{namespace}/pods/{name}/log
+	if len(parts) == 7 && parts[2] == "namespaces" && parts[4] == "pods" && parts[6] == "log" {
+		return server.logs(req, client.ObjectKey{Namespace: parts[3], Name: parts[5]})
+	}
+
+	return statusResponse(req, apiErrors.NewNotFound(schema.GroupResource{}, req.URL.Path))
+}
+
+// logs streams the pod scripted logs until the request is canceled or the pod is deleted
+func (server *apiServer) logs(req *http.Request, key client.ObjectKey) (*http.Response, error) {
+	pod := &corev1.Pod{}
+	if err := server.client.Get(req.Context(), key, pod); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return statusResponse(req, apiErrors.NewNotFound(corev1.Resource("pods"), key.Name))
+		}
+		return nil, err
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		reason := "ContainerCreating"
+		if statuses := pod.Status.ContainerStatuses; len(statuses) != 0 && statuses[0].State.Waiting != nil {
+			reason = statuses[0].State.Waiting.Reason
+		}
+		message := fmt.Sprintf("container %q in pod %q is waiting to start: %s", containerName, key.Name, reason)
+		return statusResponse(req, apiErrors.NewBadRequest(message))
+	}
+
+	reader, writer := io.Pipe()
+	go streamLogs(req.Context(), writer, pod.Labels[protocolLabel], func() bool {
+		return server.client.Get(req.Context(), key, &corev1.Pod{}) == nil
+	})
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/plain"}},
+		Body:       reader,
+		Request:    req,
+	}, nil
+}
+
+// statusResponse returns response carrying the api server status of the given error
+func statusResponse(req *http.Request, err *apiErrors.StatusError) (*http.Response, error) {
+	status := err.Status()
+	status.Kind = "Status"
+	status.APIVersion = "v1"
+	return jsonResponse(req, int(status.Code), status)
+}
+
+// jsonResponse returns response with the given status code and json encoded body
+func jsonResponse(req *http.Request, statusCode int, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(payload)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package simulator
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"strings"
+)
+
+// kotalGroupSuffix is the api group suffix of kotal custom resources
+const kotalGroupSuffix = ".kotal.io"
+
+// defaulter is implemented by kotal resources defaulted by the operator mutating webhooks
+type defaulter interface {
+	Default()
+}
+
+// cluster is in memory k8s cluster
+// it plays the api server and kotal operator parts the in memory client doesn't play
+type cluster struct {
+	client.WithWatch
+	pods *pods
+}
+
+// Create creates the object defaulted like the api server and operator webhooks do
+// kotal nodes get pods starting in the background
+func (cluster *cluster) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if k8s.IsDryRun(ctx) {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	if defaultable, ok := obj.(defaulter); ok {
+		defaultable.Default()
+	}
+	if creationTimestamp := obj.GetCreationTimestamp(); creationTimestamp.IsZero() {
+		obj.SetCreationTimestamp(metav1.Now())
+	}
+	if obj.GetUID() == "" {
+		obj.SetUID(uuid.NewUUID())
+	}
+
+	if err := cluster.WithWatch.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+
+	if protocol, ok := cluster.protocol(obj); ok && !k8s.IsDryRun(ctx) {
+		cluster.pods.start(obj, protocol)
+	}
+
+	return nil
+}
+
+// Update updates the object defaulted like the operator webhooks do
+func (cluster *cluster) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if k8s.IsDryRun(ctx) {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	if defaultable, ok := obj.(defaulter); ok {
+		defaultable.Default()
+	}
+
+	return cluster.WithWatch.Update(ctx, obj, opts...)
+}
+
+// Patch patches the object, the object isn't persisted if the context marks write calls as dry run
+func (cluster *cluster) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if k8s.IsDryRun(ctx) {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	return cluster.WithWatch.Patch(ctx, obj, patch, opts...)
+}
+
+// Delete deletes the object, kotal nodes pods are terminated in the background
+// the object isn't deleted if the context marks write calls as dry run
+func (cluster *cluster) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if k8s.IsDryRun(ctx) {
+		// the in memory client doesn't support dry run deletes
+		return cluster.WithWatch.Get(ctx, client.ObjectKeyFromObject(obj), obj.DeepCopyObject().(client.Object))
+	}
+
+	if err := cluster.WithWatch.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+
+	if _, ok := cluster.protocol(obj); ok {
+		cluster.pods.stop(obj)
+	}
+
+	return nil
+}
+
+// protocol returns the protocol of kotal resource like ethereum, ipfs or polkadot
+func (cluster *cluster) protocol(obj client.Object) (string, bool) {
+	gvk, err := apiutil.GVKForObject(obj, cluster.Scheme())
+	if err != nil || !strings.HasSuffix(gvk.Group, kotalGroupSuffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(gvk.Group, kotalGroupSuffix), true
+}
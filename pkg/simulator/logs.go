@@ -0,0 +1,89 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// logInterval is the time between scripted log lines
+var logInterval = time.Second
+
+// logBacklog is the number of log lines written at once when the stream starts
+const logBacklog = 10
+
+// logScripts are the log lines of every protocol nodes, %d is replaced by the line number
+var logScripts = map[string][]string{
+	"ethereum": {
+		"INFO Imported new chain segment blocks=1 txs=%d mgas=12.4",
+		"INFO Looking for peers peercount=%d",
+		"DEBUG Served eth_blockNumber reqid=%d duration=0.05ms",
+	},
+	"ethereum2": {
+		"INFO Synced slot=%d peers=32",
+		"INFO Received attestation committee=%d",
+		"DEBUG Processed block epoch=%d",
+	},
+	"ipfs": {
+		"INFO Announced provider records=%d",
+		"INFO Connected to peer swarm=%d",
+	},
+	"filecoin": {
+		"INFO chainstore: head changed height=%d",
+		"INFO hello: received hello message peers=%d",
+	},
+	"chainlink": {
+		"INFO Received new head height=%d",
+		"DEBUG Job run completed run=%d",
+	},
+	"near": {
+		"INFO stats: #%d Downloading blocks 12 peers",
+		"INFO network: peer connected id=%d",
+	},
+	"polkadot": {
+		"INFO Syncing 54.2 bps, target=#%d",
+		"INFO Idle (24 peers), best: #%d",
+	},
+}
+
+// defaultLogScript is the log lines of unknown protocols nodes
+var defaultLogScript = []string{"INFO running line=%d"}
+
+// logLine returns the protocol node log line by number
+func logLine(protocol string, number int) string {
+	script, ok := logScripts[protocol]
+	if !ok {
+		script = defaultLogScript
+	}
+
+	line := fmt.Sprintf(script[number%len(script)], number)
+	return fmt.Sprintf("%s %s\n", time.Now().UTC().Format("2006-01-02T15:04:05.000Z"), line)
+}
+
+// streamLogs writes the protocol scripted logs until the context is canceled or the pod isn't running
+func streamLogs(ctx context.Context, writer *io.PipeWriter, protocol string, running func() bool) {
+	defer writer.Close()
+
+	ticker := time.NewTicker(logInterval)
+	defer ticker.Stop()
+
+	for number := 0; ; number++ {
+		if _, err := io.WriteString(writer, logLine(protocol, number)); err != nil {
+			return
+		}
+
+		if number < logBacklog {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !running() {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	chainlinkv1alpha1 "github.com/kotalco/kotal/apis/chainlink/v1alpha1"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	ethereum2v1alpha1 "github.com/kotalco/kotal/apis/ethereum2/v1alpha1"
+	filecoinv1alpha1 "github.com/kotalco/kotal/apis/filecoin/v1alpha1"
+	ipfsv1alpha1 "github.com/kotalco/kotal/apis/ipfs/v1alpha1"
+	nearv1alpha1 "github.com/kotalco/kotal/apis/near/v1alpha1"
+	polkadotv1alpha1 "github.com/kotalco/kotal/apis/polkadot/v1alpha1"
+	"github.com/kotalco/kotal/controllers/shared"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"time"
+)
+
+const (
+	// protocolLabel is the label of simulated pods holding the protocol of their node
+	protocolLabel = "kotal.io/protocol"
+	// terminationFinalizer keeps deleted pods terminating for a stage duration
+	terminationFinalizer = "simulator.kotal.io/termination"
+	containerName        = "node"
+)
+
+// podStageDuration is the time simulated pods spend in every lifecycle stage
+var podStageDuration = 3 * time.Second
+
+// podStage is a stage of pod lifecycle
+type podStage struct {
+	phase corev1.PodPhase
+	// waiting is the node container waiting reason
+	waiting string
+}
+
+// podLifecycle are the stages pods go through from scheduling until they're running
+var podLifecycle = []podStage{
+	{phase: corev1.PodPending},
+	{phase: corev1.PodPending, waiting: "PodInitializing"},
+	{phase: corev1.PodPending, waiting: "ContainerCreating"},
+	{phase: corev1.PodRunning},
+}
+
+// pods plays the kotal operator and kubelet parts, creating nodes statefulsets and advancing their pods lifecycle
+type pods struct {
+	client client.Client
+}
+
+// podName returns the name of the pod of the node statefulset
+func podName(node client.Object) string {
+	return fmt.Sprintf("%s-0", node.GetName())
+}
+
+// labeledNode collects the labels set by kotal operator on the node resources, see shared.UpdateLabels
+type labeledNode struct {
+	client.Object
+	gvk    schema.GroupVersionKind
+	labels map[string]string
+}
+
+func (node *labeledNode) GroupVersionKind() schema.GroupVersionKind {
+	return node.gvk
+}
+
+func (node *labeledNode) SetLabels(labels map[string]string) {
+	node.labels = labels
+}
+
+// operatorLabels returns the labels kotal operator sets on the node statefulset and pod like app.kubernetes.io/managed-by=kotal
+// the labels are set using the operator helper, so the simulated pods are selected by the api server pods cache
+func (pods *pods) operatorLabels(node client.Object) map[string]string {
+	gvk, _ := apiutil.GVKForObject(node, pods.client.Scheme())
+	labeled := &labeledNode{Object: node, gvk: gvk}
+	shared.UpdateLabels(labeled, clientName(node))
+	return labeled.labels
+}
+
+// clientName returns the node client the operator labels the node resources with
+func clientName(node client.Object) string {
+	switch node := node.(type) {
+	case *ethereumv1alpha1.Node:
+		return string(node.Spec.Client)
+	case *ethereum2v1alpha1.BeaconNode:
+		return string(node.Spec.Client)
+	case *ethereum2v1alpha1.Validator:
+		return string(node.Spec.Client)
+	case *filecoinv1alpha1.Node:
+		return "lotus"
+	case *ipfsv1alpha1.Peer:
+		return "go-ipfs"
+	case *ipfsv1alpha1.ClusterPeer:
+		return "ipfs-cluster-service"
+	case *polkadotv1alpha1.Node:
+		return "polkadot"
+	case *nearv1alpha1.Node:
+		return "nearcore"
+	case *chainlinkv1alpha1.Node:
+		return "chainlink"
+	default:
+		return ""
+	}
+}
+
+// start creates the node statefulset and pod, then advances the pod to running in the background
+func (pods *pods) start(node client.Object, protocol string) {
+	ctx := context.Background()
+	labels := pods.operatorLabels(node)
+	labels[protocolLabel] = protocol
+	replicas := int32(1)
+
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              node.GetName(),
+			Namespace:         node.GetNamespace(),
+			Labels:            labels,
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+		},
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              podName(node),
+			Namespace:         node.GetNamespace(),
+			UID:               uuid.NewUUID(),
+			Labels:            labels,
+			Finalizers:        []string{terminationFinalizer},
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: containerName}},
+		},
+	}
+
+	// statefulset and pod of node by the same name may be still terminating
+	pods.client.Delete(ctx, sts)
+	pods.remove(ctx, client.ObjectKeyFromObject(pod), "")
+
+	if err := pods.client.Create(ctx, sts); err != nil {
+		return
+	}
+	if err := pods.client.Create(ctx, pod); err != nil {
+		return
+	}
+
+	go pods.advance(client.ObjectKeyFromObject(pod), pod.UID)
+}
+
+// advance moves the pod through the lifecycle stages until it's running, deleted or replaced
+func (pods *pods) advance(key client.ObjectKey, uid types.UID) {
+	ctx := context.Background()
+
+	for _, stage := range podLifecycle {
+		pod := &corev1.Pod{}
+		if err := pods.client.Get(ctx, key, pod); err != nil || pod.DeletionTimestamp != nil || pod.UID != uid {
+			return
+		}
+
+		pod.Status.Phase = stage.phase
+		containerStatus := corev1.ContainerStatus{Name: containerName}
+		if stage.waiting != "" {
+			containerStatus.State.Waiting = &corev1.ContainerStateWaiting{Reason: stage.waiting}
+		} else if stage.phase == corev1.PodRunning {
+			containerStatus.Ready = true
+			containerStatus.State.Running = &corev1.ContainerStateRunning{StartedAt: metav1.Now()}
+		}
+		if stage.waiting != "" || stage.phase == corev1.PodRunning {
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{containerStatus}
+		}
+
+		if err := pods.client.Update(ctx, pod); err != nil {
+			return
+		}
+
+		time.Sleep(podStageDuration)
+	}
+}
+
+// stop deletes the node statefulset and terminates its pod in the background
+func (pods *pods) stop(node client.Object) {
+	ctx := context.Background()
+
+	pods.client.Delete(ctx, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: node.GetName(), Namespace: node.GetNamespace()},
+	})
+
+	pod := &corev1.Pod{}
+	key := client.ObjectKey{Namespace: node.GetNamespace(), Name: podName(node)}
+	if err := pods.client.Get(ctx, key, pod); err != nil {
+		return
+	}
+
+	// pods with finalizers are marked as terminating
+	if err := pods.client.Delete(ctx, pod); err != nil {
+		return
+	}
+
+	go func() {
+		time.Sleep(podStageDuration)
+		pods.remove(ctx, key, pod.UID)
+	}()
+}
+
+// remove removes the pod finalizer so it's deleted right away
+// the pod isn't removed if it's replaced by another pod unless uid is empty
+func (pods *pods) remove(ctx context.Context, key client.ObjectKey, uid types.UID) {
+	pod := &corev1.Pod{}
+	if err := pods.client.Get(ctx, key, pod); err != nil || (uid != "" && pod.UID != uid) {
+		return
+	}
+
+	pod.Finalizers = nil
+	if pod.DeletionTimestamp == nil {
+		now := metav1.Now()
+		pod.DeletionTimestamp = &now
+	}
+
+	pods.client.Update(ctx, pod)
+}
@@ -0,0 +1,141 @@
+// Package simulator serves k8s calls from an in memory cluster for demos and front-end development
+// resources are defaulted like the operator admission webhooks do, nodes get pods advancing from Pending to Running,
+// pods stream scripted logs and nodes JSON-RPC servers respond with scripted chain stats
+package simulator
+
+import (
+	"context"
+	"github.com/kotalco/api/pkg/configs"
+	"github.com/kotalco/api/pkg/k8s"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metrics "k8s.io/metrics/pkg/client/clientset/versioned"
+	metricsFake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientFake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// Enabled returns true if the api server is configured to use the simulator by simulator config
+func Enabled() bool {
+	return configs.Get().Simulator
+}
+
+// Simulator is in memory k8s cluster with simulated nodes pods and JSON-RPC servers
+type Simulator struct {
+	cluster   *cluster
+	clientset *clientset
+	metrics   metrics.Interface
+	chains    *chains
+}
+
+// New returns simulator holding the default namespace, a storage class and kotal operator deployment and webhooks
+func New() *Simulator {
+	fakeClient := clientFake.NewClientBuilder().WithScheme(k8s.NewScheme()).WithObjects(seed()...).Build()
+	return &Simulator{
+		cluster:   &cluster{WithWatch: fakeClient, pods: &pods{client: fakeClient}},
+		clientset: newClientset(fakeClient),
+		metrics:   metricsFake.NewSimpleClientset(),
+		chains:    newChains(),
+	}
+}
+
+// seed returns the objects found in a fresh cluster with kotal operator deployed
+func seed() []client.Object {
+	reclaimPolicy := corev1.PersistentVolumeReclaimDelete
+	allowVolumeExpansion := true
+	replicas := int32(1)
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	webhookService := admissionregistrationv1.ServiceReference{Name: "kotal-webhook-service", Namespace: "kotal"}
+	webhookClientConfig := admissionregistrationv1.WebhookClientConfig{
+		Service:  &webhookService,
+		CABundle: []byte("simulator"),
+	}
+	webhookRules := []admissionregistrationv1.RuleWithOperations{{
+		Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+		Rule: admissionregistrationv1.Rule{
+			APIGroups:   []string{"ethereum.kotal.io"},
+			APIVersions: []string{"v1alpha1"},
+			Resources:   []string{"nodes"},
+		},
+	}}
+
+	return []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kotal"}},
+		&storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: "standard"},
+			Provisioner:          "kubernetes.io/gce-pd",
+			ReclaimPolicy:        &reclaimPolicy,
+			AllowVolumeExpansion: &allowVolumeExpansion,
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "kotal-controller-manager", Namespace: "kotal"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "manager", Image: "kotalco/kotal:simulator"}},
+					},
+				},
+			},
+			Status: appsv1.DeploymentStatus{Replicas: replicas, ReadyReplicas: replicas, AvailableReplicas: replicas},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: webhookService.Name, Namespace: webhookService.Namespace},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.10"}},
+				Ports:     []corev1.EndpointPort{{Port: 9443}},
+			}},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "kotal-mutating-webhook-configuration"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name:                    "mnode.kb.io",
+				ClientConfig:            webhookClientConfig,
+				Rules:                   webhookRules,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			}},
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "kotal-validating-webhook-configuration"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+				Name:                    "vnode.kb.io",
+				ClientConfig:            webhookClientConfig,
+				Rules:                   webhookRules,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			}},
+		},
+	}
+}
+
+// K8sClient returns the simulated cluster client
+func (simulator *Simulator) K8sClient() k8s.K8sClientServiceInterface {
+	return simulator.cluster
+}
+
+// ClientsetService returns the simulated cluster clientsets
+func (simulator *Simulator) ClientsetService() k8s.ClientsetServiceInterface {
+	return simulator
+}
+
+// Clientset returns the simulated cluster clientset regardless of the context
+func (simulator *Simulator) Clientset(ctx context.Context) (kubernetes.Interface, error) {
+	return simulator.clientset, nil
+}
+
+// MetricsClientset returns the simulated cluster metrics clientset regardless of the context
+func (simulator *Simulator) MetricsClientset(ctx context.Context) (metrics.Interface, error) {
+	return simulator.metrics, nil
+}
+
+// RPCClient returns http client answering nodes JSON-RPC calls with scripted chain stats
+func (simulator *Simulator) RPCClient() *http.Client {
+	return &http.Client{Transport: simulator.chains}
+}
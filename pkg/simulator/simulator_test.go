@@ -0,0 +1,183 @@
+package simulator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/kotalco/api/pkg/k8s"
+	ethereumv1alpha1 "github.com/kotalco/kotal/apis/ethereum/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/ybbus/jsonrpc/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+	"time"
+)
+
+func newTestNode() *ethereumv1alpha1.Node {
+	return &ethereumv1alpha1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-node", Namespace: "default"},
+		Spec: ethereumv1alpha1.NodeSpec{
+			Network: "goerli",
+			Client:  ethereumv1alpha1.BesuClient,
+			RPC:     true,
+		},
+	}
+}
+
+// newTestSimulator returns simulator advancing pods lifecycle stages right away
+func newTestSimulator(t *testing.T) *Simulator {
+	stageDuration := podStageDuration
+	podStageDuration = 10 * time.Millisecond
+	t.Cleanup(func() { podStageDuration = stageDuration })
+
+	return New()
+}
+
+func TestCreateDefaults(t *testing.T) {
+	simulator := newTestSimulator(t)
+	ctx := context.Background()
+
+	node := newTestNode()
+	assert.Nil(t, simulator.K8sClient().Create(ctx, node))
+	assert.NotZero(t, node.Spec.P2PPort)
+	assert.False(t, node.CreationTimestamp.IsZero())
+	assert.NotEmpty(t, node.UID)
+
+	stored := &ethereumv1alpha1.Node{}
+	assert.Nil(t, simulator.K8sClient().Get(ctx, k8s.ObjectKey{Namespace: "default", Name: "my-node"}, stored))
+	assert.Equal(t, node.Spec.P2PPort, stored.Spec.P2PPort)
+
+	// dry run calls aren't persisted
+	dryRun := newTestNode()
+	dryRun.Name = "dry-run"
+	assert.Nil(t, simulator.K8sClient().Create(k8s.WithDryRun(ctx), dryRun))
+	assert.NotZero(t, dryRun.Spec.P2PPort)
+	err := simulator.K8sClient().Get(ctx, k8s.ObjectKey{Namespace: "default", Name: "dry-run"}, &ethereumv1alpha1.Node{})
+	assert.True(t, apiErrors.IsNotFound(err))
+}
+
+func TestPodLifecycle(t *testing.T) {
+	simulator := newTestSimulator(t)
+	ctx := context.Background()
+	k8sClient := simulator.K8sClient()
+	podKey := k8s.ObjectKey{Namespace: "default", Name: "my-node-0"}
+
+	node := newTestNode()
+	assert.Nil(t, k8sClient.Create(ctx, node))
+	assert.Nil(t, k8sClient.Get(ctx, k8s.ObjectKey{Namespace: "default", Name: "my-node"}, &appsv1.StatefulSet{}))
+
+	reasons := map[string]bool{}
+	assert.Eventually(t, func() bool {
+		pod := &corev1.Pod{}
+		if err := k8sClient.Get(ctx, podKey, pod); err != nil {
+			return false
+		}
+		if statuses := pod.Status.ContainerStatuses; len(statuses) != 0 && statuses[0].State.Waiting != nil {
+			reasons[statuses[0].State.Waiting.Reason] = true
+		}
+		return pod.Status.Phase == corev1.PodRunning
+	}, time.Second, time.Millisecond)
+	assert.True(t, reasons["ContainerCreating"])
+
+	assert.Nil(t, k8sClient.Delete(ctx, node))
+	pod := &corev1.Pod{}
+	assert.Nil(t, k8sClient.Get(ctx, podKey, pod))
+	assert.NotNil(t, pod.DeletionTimestamp)
+
+	assert.Eventually(t, func() bool {
+		return apiErrors.IsNotFound(k8sClient.Get(ctx, podKey, &corev1.Pod{}))
+	}, time.Second, time.Millisecond)
+}
+
+func TestPodLabels(t *testing.T) {
+	simulator := newTestSimulator(t)
+	ctx := context.Background()
+	k8sClient := simulator.K8sClient()
+	assert.Nil(t, k8sClient.Create(ctx, newTestNode()))
+
+	// pods are labeled like the operator does, so they're selected by the api server pods cache
+	pods := &corev1.PodList{}
+	assert.Nil(t, k8sClient.List(ctx, pods, client.InNamespace("default"), client.MatchingLabels{"app.kubernetes.io/managed-by": "kotal"}))
+	if assert.Len(t, pods.Items, 1) {
+		labels := pods.Items[0].Labels
+		assert.Equal(t, "my-node", labels["app.kubernetes.io/instance"])
+		assert.Equal(t, "besu", labels["app.kubernetes.io/name"])
+		assert.Equal(t, "ethereum-node", labels["app.kubernetes.io/component"])
+		assert.Equal(t, "ethereum", labels[protocolLabel])
+	}
+
+	sts := &appsv1.StatefulSet{}
+	assert.Nil(t, k8sClient.Get(ctx, k8s.ObjectKey{Namespace: "default", Name: "my-node"}, sts))
+	assert.Equal(t, "kotal", sts.Labels["app.kubernetes.io/managed-by"])
+}
+
+func TestLogs(t *testing.T) {
+	simulator := newTestSimulator(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientset, err := simulator.Clientset(ctx)
+	assert.Nil(t, err)
+
+	_, err = clientset.CoreV1().Pods("default").GetLogs("my-node-0", &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	assert.True(t, apiErrors.IsNotFound(err))
+
+	assert.Nil(t, simulator.K8sClient().Create(ctx, newTestNode()))
+	_, err = clientset.CoreV1().Pods("default").GetLogs("my-node-0", &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	assert.True(t, apiErrors.IsBadRequest(err))
+
+	assert.Eventually(t, func() bool {
+		pod := &corev1.Pod{}
+		simulator.K8sClient().Get(ctx, k8s.ObjectKey{Namespace: "default", Name: "my-node-0"}, pod)
+		return pod.Status.Phase == corev1.PodRunning
+	}, time.Second, time.Millisecond)
+
+	stream, err := clientset.CoreV1().Pods("default").GetLogs("my-node-0", &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	assert.Nil(t, err)
+	defer stream.Close()
+
+	line, err := bufio.NewReader(stream).ReadString('\n')
+	assert.Nil(t, err)
+	assert.Contains(t, line, "Imported new chain segment")
+}
+
+func TestKubernetesVersion(t *testing.T) {
+	simulator := newTestSimulator(t)
+	clientset, _ := simulator.Clientset(context.Background())
+
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(context.Background()).Raw()
+	assert.Nil(t, err)
+
+	info := version.Info{}
+	assert.Nil(t, json.Unmarshal(body, &info))
+	assert.Equal(t, kubernetesVersion.GitVersion, info.GitVersion)
+}
+
+func TestChainStats(t *testing.T) {
+	simulator := newTestSimulator(t)
+	client := jsonrpc.NewClientWithOpts("http://my-node:8545", &jsonrpc.RPCClientOpts{HTTPClient: simulator.RPCClient()})
+
+	syncStatus := struct {
+		CurrentBlock string `json:"currentBlock"`
+		HighestBlock string `json:"highestBlock"`
+	}{}
+	assert.Nil(t, client.CallFor(&syncStatus, "eth_syncing"))
+	assert.NotEmpty(t, syncStatus.CurrentBlock)
+	assert.NotEmpty(t, syncStatus.HighestBlock)
+
+	syncState := struct {
+		CurrentBlock uint64 `json:"currentBlock"`
+		HighestBlock uint64 `json:"highestBlock"`
+	}{}
+	assert.Nil(t, client.CallFor(&syncState, "system_syncState"))
+	assert.Less(t, syncState.CurrentBlock, syncState.HighestBlock)
+
+	response, err := client.Call("unknown_method")
+	assert.Nil(t, err)
+	assert.NotNil(t, response.Error)
+}
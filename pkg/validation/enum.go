@@ -0,0 +1,33 @@
+package validation
+
+import (
+	"fmt"
+	"sync"
+)
+
+var (
+	enumsLock = &sync.RWMutex{}
+	enums     = map[string][]string{}
+)
+
+// RegisterEnum registers the allowed values of the named enum used by enum=name rule
+// protocols register their enums from the operator constants, so the api stays in sync with the operator
+func RegisterEnum(name string, values ...string) {
+	enumsLock.Lock()
+	defer enumsLock.Unlock()
+
+	enums[name] = values
+}
+
+// EnumValues returns the allowed values of the named enum
+// it panics if the enum is not registered to catch typos in dtos declarations early
+func EnumValues(name string) []string {
+	enumsLock.RLock()
+	defer enumsLock.RUnlock()
+
+	values, ok := enums[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown validation enum %s", name))
+	}
+	return values
+}
@@ -0,0 +1,34 @@
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ValidateResources validates cpu and memory requests don't exceed their limits
+// requests and limits are validated only if both of them are provided and valid quantities
+func ValidateResources(errs Errors, cpu, cpuLimit, memory, memoryLimit string) {
+	if exceeds(cpu, cpuLimit) {
+		errs.Add("cpuLimit", "must be greater than or equal to cpu")
+	}
+	if exceeds(memory, memoryLimit) {
+		errs.Add("memoryLimit", "must be greater than or equal to memory")
+	}
+}
+
+// exceeds returns true if the request quantity is greater than the limit quantity
+func exceeds(request, limit string) bool {
+	if request == "" || limit == "" {
+		return false
+	}
+
+	requestQuantity, err := resource.ParseQuantity(request)
+	if err != nil {
+		return false
+	}
+	limitQuantity, err := resource.ParseQuantity(limit)
+	if err != nil {
+		return false
+	}
+
+	return requestQuantity.Cmp(limitQuantity) == 1
+}
@@ -0,0 +1,74 @@
+package validation
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/resource"
+	k8sValidation "k8s.io/apimachinery/pkg/util/validation"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// requiredRule fails if the field is omitted on create
+const requiredRule = "required"
+
+// enumRule fails if the field value is not one of the registered enum values
+const enumRule = "enum"
+
+// rule validates a single non empty field value using the rule param
+// returns the validation error message or empty string if the value is valid
+type rule func(value reflect.Value, param string) string
+
+var rules = map[string]rule{
+	// dns1123label is used for resources names which are used as names of the created pods and services
+	"dns1123label": func(value reflect.Value, _ string) string {
+		if errs := k8sValidation.IsDNS1123Label(value.String()); len(errs) != 0 {
+			return "must be a valid DNS-1123 label, " + errs[0]
+		}
+		return ""
+	},
+	// dns1123subdomain is used for referenced resources names like secrets and storage classes
+	"dns1123subdomain": func(value reflect.Value, _ string) string {
+		if errs := k8sValidation.IsDNS1123Subdomain(value.String()); len(errs) != 0 {
+			return "must be a valid DNS-1123 subdomain, " + errs[0]
+		}
+		return ""
+	},
+	enumRule: func(value reflect.Value, param string) string {
+		values := EnumValues(param)
+		for _, allowed := range values {
+			if value.String() == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(values, ", "))
+	},
+	"port": func(value reflect.Value, _ string) string {
+		if value.Uint() > 65535 {
+			return "must be a valid port number between 1 and 65535"
+		}
+		return ""
+	},
+	"quantity": func(value reflect.Value, _ string) string {
+		if _, err := resource.ParseQuantity(value.String()); err != nil {
+			return "must be a valid quantity like 500m, 4 or 8Gi"
+		}
+		return ""
+	},
+	// url validates absolute urls, the param restricts the allowed schemes like url=ws|wss
+	"url": func(value reflect.Value, param string) string {
+		parsed, err := url.Parse(value.String())
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return "must be a valid absolute url"
+		}
+		if param == "" {
+			return ""
+		}
+		for _, scheme := range strings.Split(param, "|") {
+			if parsed.Scheme == scheme {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be a url with scheme %s", strings.ReplaceAll(param, "|", " or "))
+	},
+}
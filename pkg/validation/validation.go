@@ -0,0 +1,183 @@
+// Package validation validates request dtos using rules declared in the dto fields validate tag
+// like `validate:"required,dns1123label"` and dto specific cross field rules
+// validation errors are returned as rest validation error keyed by the field json path
+package validation
+
+import (
+	"fmt"
+	restErrors "github.com/kotalco/api/pkg/errors"
+	"github.com/kotalco/api/pkg/openapi"
+	"reflect"
+	"strings"
+)
+
+// tagName is the struct tag declaring field validation rules
+const tagName = "validate"
+
+// Operation is the operation the dto is validated for
+type Operation int
+
+const (
+	// Create validates dto for creating new resource, required fields must be provided
+	Create Operation = iota
+	// Update validates dto for updating existing resource, omitted fields are left unchanged
+	Update
+)
+
+// Errors is field json path to validation error message
+type Errors map[string]string
+
+// Add adds validation error of the field, only the first error of each field is kept
+func (errs Errors) Add(field, message string) {
+	if _, exists := errs[field]; !exists {
+		errs[field] = message
+	}
+}
+
+// CrossFieldValidator is implemented by dtos having rules that span multiple fields
+// like coinbase is required if miner is true
+type CrossFieldValidator interface {
+	ValidateCrossFields(operation Operation, errs Errors)
+}
+
+// Validate validates the dto fields rules and cross field rules for the given operation
+// returns validation error keyed by field json path, or nil if the dto is valid
+func Validate(dto interface{}, operation Operation) *restErrors.RestErr {
+	errs := Errors{}
+
+	value := reflect.Indirect(reflect.ValueOf(dto))
+	if value.Kind() == reflect.Struct {
+		validateStruct(value, "", operation, errs)
+	}
+
+	if validator, ok := dto.(CrossFieldValidator); ok {
+		validator.ValidateCrossFields(operation, errs)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return restErrors.NewValidationError(errs)
+}
+
+// validateStruct validates struct fields rules and nested structs fields
+func validateStruct(value reflect.Value, path string, operation Operation, errs Errors) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		name, ok := openapi.JSONName(field)
+		if !ok {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		// fields of embedded structs are flattened like encoding/json does
+		if name == "" {
+			if embedded := reflect.Indirect(fieldValue); embedded.Kind() == reflect.Struct {
+				validateStruct(embedded, path, operation, errs)
+			}
+			continue
+		}
+
+		validateField(fieldValue, join(path, name), parseRules(field.Tag.Get(tagName)), operation, errs)
+	}
+}
+
+// validateField validates field value against its rules
+// rules of slice fields are applied to every item, required rule is applied to the slice itself
+func validateField(value reflect.Value, path string, fieldRules []fieldRule, operation Operation, errs Errors) {
+	if isEmpty(value) {
+		for _, rule := range fieldRules {
+			if rule.name == requiredRule && operation == Create {
+				errs.Add(path, "is required")
+			}
+		}
+		return
+	}
+
+	value = reflect.Indirect(value)
+
+	switch value.Kind() {
+	case reflect.Struct:
+		validateStruct(value, path, operation, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			validateField(value.Index(i), fmt.Sprintf("%s[%d]", path, i), withoutRequired(fieldRules), operation, errs)
+		}
+	default:
+		for _, rule := range fieldRules {
+			if rule.name == requiredRule {
+				continue
+			}
+			if message := rules[rule.name](value, rule.param); message != "" {
+				errs.Add(path, message)
+				return
+			}
+		}
+	}
+}
+
+// isEmpty returns true if the field is omitted from the request body
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.Struct:
+		// nested structs fields are validated individually
+		return false
+	default:
+		return value.IsZero()
+	}
+}
+
+func withoutRequired(fieldRules []fieldRule) []fieldRule {
+	result := []fieldRule{}
+	for _, rule := range fieldRules {
+		if rule.name != requiredRule {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// fieldRule is a single rule declared in the validate tag like enum=ethereumClient
+type fieldRule struct {
+	name  string
+	param string
+}
+
+// parseRules parses the validate tag rules
+// it panics on unknown rules and enums to catch typos in dtos declarations early
+func parseRules(tag string) []fieldRule {
+	fieldRules := []fieldRule{}
+	if tag == "" {
+		return fieldRules
+	}
+
+	for _, declaration := range strings.Split(tag, ",") {
+		parts := strings.SplitN(declaration, "=", 2)
+		rule := fieldRule{name: parts[0]}
+		if len(parts) == 2 {
+			rule.param = parts[1]
+		}
+		if _, ok := rules[rule.name]; !ok && rule.name != requiredRule {
+			panic(fmt.Sprintf("unknown validation rule %s", rule.name))
+		}
+		if rule.name == enumRule {
+			EnumValues(rule.param)
+		}
+		fieldRules = append(fieldRules, rule)
+	}
+
+	return fieldRules
+}
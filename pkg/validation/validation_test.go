@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+type testCredentials struct {
+	SecretName string `json:"secretName" validate:"required,dns1123subdomain"`
+}
+
+type testMetadata struct {
+	Name string `json:"name" validate:"required,dns1123label"`
+}
+
+type testDto struct {
+	testMetadata
+	Client      string            `json:"client" validate:"required,enum=testClient"`
+	P2PPort     uint              `json:"p2pPort" validate:"port"`
+	APIs        []string          `json:"apis" validate:"enum=testAPI"`
+	Endpoint    string            `json:"endpoint" validate:"url=ws|wss"`
+	CPU         string            `json:"cpu" validate:"quantity"`
+	CPULimit    string            `json:"cpuLimit" validate:"quantity"`
+	Credentials *testCredentials  `json:"credentials" validate:"required"`
+	Keystores   []testCredentials `json:"keystores"`
+	Miner       *bool             `json:"miner"`
+	Coinbase    string            `json:"coinbase"`
+}
+
+func (dto *testDto) ValidateCrossFields(operation Operation, errs Errors) {
+	if dto.Miner != nil && *dto.Miner && dto.Coinbase == "" {
+		errs.Add("coinbase", "is required if miner is true")
+	}
+	ValidateResources(errs, dto.CPU, dto.CPULimit, "", "")
+}
+
+func init() {
+	RegisterEnum("testClient", "geth", "besu")
+	RegisterEnum("testAPI", "eth", "net")
+}
+
+func TestValidateValidDto(t *testing.T) {
+	dto := &testDto{
+		testMetadata: testMetadata{Name: "my-node"},
+		Client:       "geth",
+		P2PPort:      30303,
+		APIs:         []string{"eth", "net"},
+		Endpoint:     "wss://mainnet.infura.io/ws",
+		CPU:          "500m",
+		CPULimit:     "1",
+		Credentials:  &testCredentials{SecretName: "my-secret"},
+	}
+
+	assert.Nil(t, Validate(dto, Create))
+}
+
+func TestValidateCreate(t *testing.T) {
+	miner := true
+	dto := &testDto{
+		testMetadata: testMetadata{Name: "My_Node"},
+		P2PPort:      70000,
+		APIs:         []string{"eth", "admin"},
+		Endpoint:     "http://localhost:8545",
+		CPU:          "2",
+		CPULimit:     "1",
+		Keystores:    []testCredentials{{SecretName: "keystore"}, {}},
+		Miner:        &miner,
+	}
+
+	err := Validate(dto, Create)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, http.StatusBadRequest, err.Status)
+	assert.Contains(t, err.Validations["name"], "DNS-1123 label")
+	assert.EqualValues(t, "is required", err.Validations["client"])
+	assert.EqualValues(t, "is required", err.Validations["credentials"])
+	assert.Contains(t, err.Validations["p2pPort"], "port")
+	assert.EqualValues(t, "must be one of eth, net", err.Validations["apis[1]"])
+	assert.NotContains(t, err.Validations, "apis[0]")
+	assert.EqualValues(t, "must be a url with scheme ws or wss", err.Validations["endpoint"])
+	assert.EqualValues(t, "is required", err.Validations["keystores[1].secretName"])
+	assert.EqualValues(t, "must be greater than or equal to cpu", err.Validations["cpuLimit"])
+	assert.EqualValues(t, "is required if miner is true", err.Validations["coinbase"])
+}
+
+func TestValidateUpdate(t *testing.T) {
+	// omitted fields are left unchanged on update
+	assert.Nil(t, Validate(&testDto{}, Update))
+
+	err := Validate(&testDto{Client: "parity", CPU: "two"}, Update)
+	assert.NotNil(t, err)
+	assert.EqualValues(t, "must be one of geth, besu", err.Validations["client"])
+	assert.Contains(t, err.Validations["cpu"], "quantity")
+	assert.NotContains(t, err.Validations, "name")
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+	type invalidDto struct {
+		Name string `json:"name" validate:"dns"`
+	}
+	assert.Panics(t, func() {
+		Validate(&invalidDto{Name: "name"}, Create)
+	})
+}
+
+func TestUnknownEnumPanics(t *testing.T) {
+	type invalidDto struct {
+		Client string `json:"client" validate:"enum=unknownClient"`
+	}
+	assert.Panics(t, func() {
+		Validate(&invalidDto{}, Update)
+	})
+}